@@ -13,34 +13,58 @@ import (
 	"github.com/gorilla/pat"
 	"github.com/markbates/goth"
 	"github.com/markbates/goth/gothic"
+	"github.com/markbates/goth/providers/adobe"
 	"github.com/markbates/goth/providers/amazon"
 	"github.com/markbates/goth/providers/apple"
+	"github.com/markbates/goth/providers/arcgis"
+	"github.com/markbates/goth/providers/atlassian"
 	"github.com/markbates/goth/providers/auth0"
 	"github.com/markbates/goth/providers/autodeskforge"
 	"github.com/markbates/goth/providers/azuread"
+	"github.com/markbates/goth/providers/bamboohr"
 	"github.com/markbates/goth/providers/battlenet"
+	"github.com/markbates/goth/providers/bentleyitwin"
+	"github.com/markbates/goth/providers/bigcommerce"
 	"github.com/markbates/goth/providers/bitbucket"
+	"github.com/markbates/goth/providers/bluesky"
 	"github.com/markbates/goth/providers/box"
+	"github.com/markbates/goth/providers/classlink"
+	"github.com/markbates/goth/providers/clever"
 	"github.com/markbates/goth/providers/dailymotion"
 	"github.com/markbates/goth/providers/deezer"
 	"github.com/markbates/goth/providers/digitalocean"
 	"github.com/markbates/goth/providers/discord"
+	"github.com/markbates/goth/providers/docusign"
 	"github.com/markbates/goth/providers/dropbox"
+	"github.com/markbates/goth/providers/ebay"
+	"github.com/markbates/goth/providers/epicgames"
+	"github.com/markbates/goth/providers/etsy"
 	"github.com/markbates/goth/providers/eveonline"
 	"github.com/markbates/goth/providers/facebook"
 	"github.com/markbates/goth/providers/fitbit"
+	"github.com/markbates/goth/providers/frameio"
+	"github.com/markbates/goth/providers/franceconnect"
+	"github.com/markbates/goth/providers/freshbooks"
+	"github.com/markbates/goth/providers/garmin"
 	"github.com/markbates/goth/providers/gitea"
 	"github.com/markbates/goth/providers/github"
 	"github.com/markbates/goth/providers/gitlab"
 	"github.com/markbates/goth/providers/google"
+	"github.com/markbates/goth/providers/govuk"
 	"github.com/markbates/goth/providers/gplus"
+	"github.com/markbates/goth/providers/gusto"
 	"github.com/markbates/goth/providers/heroku"
+	"github.com/markbates/goth/providers/hubspot"
+	"github.com/markbates/goth/providers/idme"
 	"github.com/markbates/goth/providers/instagram"
 	"github.com/markbates/goth/providers/intercom"
+	"github.com/markbates/goth/providers/intuit"
 	"github.com/markbates/goth/providers/kakao"
+	"github.com/markbates/goth/providers/kick"
 	"github.com/markbates/goth/providers/lastfm"
 	"github.com/markbates/goth/providers/line"
 	"github.com/markbates/goth/providers/linkedin"
+	"github.com/markbates/goth/providers/logingov"
 	"github.com/markbates/goth/providers/mastodon"
 	"github.com/markbates/goth/providers/meetup"
 	"github.com/markbates/goth/providers/microsoftonline"
@@ -48,27 +72,53 @@ import (
 	"github.com/markbates/goth/providers/nextcloud"
 	"github.com/markbates/goth/providers/okta"
 	"github.com/markbates/goth/providers/onedrive"
+	"github.com/markbates/goth/providers/onshape"
 	"github.com/markbates/goth/providers/openidConnect"
+	"github.com/markbates/goth/providers/orcid"
 	"github.com/markbates/goth/providers/paypal"
+	"github.com/markbates/goth/providers/pinterest"
+	"github.com/markbates/goth/providers/pipedrive"
+	"github.com/markbates/goth/providers/polar"
+	"github.com/markbates/goth/providers/procore"
+	"github.com/markbates/goth/providers/reddit"
+	"github.com/markbates/goth/providers/roblox"
 	"github.com/markbates/goth/providers/salesforce"
 	"github.com/markbates/goth/providers/seatalk"
+	"github.com/markbates/goth/providers/servicenow"
 	"github.com/markbates/goth/providers/shopify"
 	"github.com/markbates/goth/providers/slack"
+	"github.com/markbates/goth/providers/snapchat"
 	"github.com/markbates/goth/providers/soundcloud"
 	"github.com/markbates/goth/providers/spotify"
+	"github.com/markbates/goth/providers/square"
+	"github.com/markbates/goth/providers/squarespace"
 	"github.com/markbates/goth/providers/steam"
 	"github.com/markbates/goth/providers/strava"
 	"github.com/markbates/goth/providers/stripe"
+	"github.com/markbates/goth/providers/stripeconnect"
+	"github.com/markbates/goth/providers/telegram"
+	"github.com/markbates/goth/providers/trimble"
 	"github.com/markbates/goth/providers/twitch"
 	"github.com/markbates/goth/providers/twitter"
+	"github.com/markbates/goth/providers/twitterv2oauth2"
 	"github.com/markbates/goth/providers/typetalk"
 	"github.com/markbates/goth/providers/uber"
 	"github.com/markbates/goth/providers/vk"
+	"github.com/markbates/goth/providers/webex"
+	"github.com/markbates/goth/providers/webflow"
+	"github.com/markbates/goth/providers/wechat"
 	"github.com/markbates/goth/providers/wepay"
+	"github.com/markbates/goth/providers/whoop"
+	"github.com/markbates/goth/providers/withings"
+	"github.com/markbates/goth/providers/wordpresscom"
+	"github.com/markbates/goth/providers/workday"
 	"github.com/markbates/goth/providers/xero"
 	"github.com/markbates/goth/providers/yahoo"
 	"github.com/markbates/goth/providers/yammer"
 	"github.com/markbates/goth/providers/yandex"
+	"github.com/markbates/goth/providers/zendesk"
+	"github.com/markbates/goth/providers/zoho"
+	"github.com/markbates/goth/providers/zoom"
 )
 
 func main() {
@@ -138,6 +188,54 @@ func main() {
 		strava.New(os.Getenv("STRAVA_KEY"), os.Getenv("STRAVA_SECRET"), "http://localhost:3000/auth/strava/callback"),
 		okta.New(os.Getenv("OKTA_ID"), os.Getenv("OKTA_SECRET"), os.Getenv("OKTA_ORG_URL"), "http://localhost:3000/auth/okta/callback", "openid", "profile", "email"),
 		mastodon.New(os.Getenv("MASTODON_KEY"), os.Getenv("MASTODON_SECRET"), "http://localhost:3000/auth/mastodon/callback", "read:accounts"),
+		atlassian.New(os.Getenv("ATLASSIAN_KEY"), os.Getenv("ATLASSIAN_SECRET"), "http://localhost:3000/auth/atlassian/callback"),
+		twitterv2oauth2.New(os.Getenv("TWITTER_OAUTH2_KEY"), os.Getenv("TWITTER_OAUTH2_SECRET"), "http://localhost:3000/auth/twitterv2oauth2/callback"),
+		reddit.New(os.Getenv("REDDIT_KEY"), os.Getenv("REDDIT_SECRET"), "http://localhost:3000/auth/reddit/callback"),
+		pinterest.New(os.Getenv("PINTEREST_KEY"), os.Getenv("PINTEREST_SECRET"), "http://localhost:3000/auth/pinterest/callback"),
+		snapchat.New(os.Getenv("SNAPCHAT_KEY"), os.Getenv("SNAPCHAT_SECRET"), "http://localhost:3000/auth/snapchat/callback"),
+		kick.New(os.Getenv("KICK_KEY"), os.Getenv("KICK_SECRET"), "http://localhost:3000/auth/kick/callback"),
+		roblox.New(os.Getenv("ROBLOX_KEY"), os.Getenv("ROBLOX_SECRET"), "http://localhost:3000/auth/roblox/callback"),
+		epicgames.New(os.Getenv("EPICGAMES_KEY"), os.Getenv("EPICGAMES_SECRET"), "http://localhost:3000/auth/epicgames/callback"),
+		zoom.New(os.Getenv("ZOOM_KEY"), os.Getenv("ZOOM_SECRET"), "http://localhost:3000/auth/zoom/callback"),
+		webex.New(os.Getenv("WEBEX_KEY"), os.Getenv("WEBEX_SECRET"), "http://localhost:3000/auth/webex/callback"),
+		docusign.New(os.Getenv("DOCUSIGN_KEY"), os.Getenv("DOCUSIGN_SECRET"), "http://localhost:3000/auth/docusign/callback"),
+		freshbooks.New(os.Getenv("FRESHBOOKS_KEY"), os.Getenv("FRESHBOOKS_SECRET"), "http://localhost:3000/auth/freshbooks/callback"),
+		zoho.New(os.Getenv("ZOHO_KEY"), os.Getenv("ZOHO_SECRET"), "http://localhost:3000/auth/zoho/callback"),
+		hubspot.New(os.Getenv("HUBSPOT_KEY"), os.Getenv("HUBSPOT_SECRET"), "http://localhost:3000/auth/hubspot/callback"),
+		pipedrive.New(os.Getenv("PIPEDRIVE_KEY"), os.Getenv("PIPEDRIVE_SECRET"), "http://localhost:3000/auth/pipedrive/callback"),
+		zendesk.New(os.Getenv("ZENDESK_KEY"), os.Getenv("ZENDESK_SECRET"), "http://localhost:3000/auth/zendesk/callback", os.Getenv("ZENDESK_SUBDOMAIN")),
+		servicenow.New(os.Getenv("SERVICENOW_KEY"), os.Getenv("SERVICENOW_SECRET"), "http://localhost:3000/auth/servicenow/callback", os.Getenv("SERVICENOW_INSTANCE_URL")),
+		workday.New(os.Getenv("WORKDAY_KEY"), os.Getenv("WORKDAY_SECRET"), "http://localhost:3000/auth/workday/callback", os.Getenv("WORKDAY_HOST_URL"), os.Getenv("WORKDAY_TENANT")),
+		bamboohr.New(os.Getenv("BAMBOOHR_KEY"), os.Getenv("BAMBOOHR_SECRET"), "http://localhost:3000/auth/bamboohr/callback", os.Getenv("BAMBOOHR_COMPANY")),
+		gusto.New(os.Getenv("GUSTO_KEY"), os.Getenv("GUSTO_SECRET"), "http://localhost:3000/auth/gusto/callback"),
+		stripeconnect.New(os.Getenv("STRIPE_CONNECT_KEY"), os.Getenv("STRIPE_CONNECT_SECRET"), "http://localhost:3000/auth/stripeconnect/callback"),
+		square.New(os.Getenv("SQUARE_KEY"), os.Getenv("SQUARE_SECRET"), "http://localhost:3000/auth/square/callback"),
+		etsy.New(os.Getenv("ETSY_KEY"), os.Getenv("ETSY_SECRET"), "http://localhost:3000/auth/etsy/callback"),
+		ebay.New(os.Getenv("EBAY_KEY"), os.Getenv("EBAY_SECRET"), os.Getenv("EBAY_RUNAME")),
+		bigcommerce.New(os.Getenv("BIGCOMMERCE_KEY"), os.Getenv("BIGCOMMERCE_SECRET"), "http://localhost:3000/auth/bigcommerce/callback"),
+		wordpresscom.New(os.Getenv("WORDPRESSCOM_KEY"), os.Getenv("WORDPRESSCOM_SECRET"), "http://localhost:3000/auth/wordpresscom/callback"),
+		squarespace.New(os.Getenv("SQUARESPACE_KEY"), os.Getenv("SQUARESPACE_SECRET"), "http://localhost:3000/auth/squarespace/callback"),
+		webflow.New(os.Getenv("WEBFLOW_KEY"), os.Getenv("WEBFLOW_SECRET"), "http://localhost:3000/auth/webflow/callback"),
+		procore.New(os.Getenv("PROCORE_KEY"), os.Getenv("PROCORE_SECRET"), "http://localhost:3000/auth/procore/callback"),
+		trimble.New(os.Getenv("TRIMBLE_KEY"), os.Getenv("TRIMBLE_SECRET"), "http://localhost:3000/auth/trimble/callback"),
+		bentleyitwin.New(os.Getenv("BENTLEY_ITWIN_KEY"), os.Getenv("BENTLEY_ITWIN_SECRET"), "http://localhost:3000/auth/bentleyitwin/callback"),
+		onshape.New(os.Getenv("ONSHAPE_KEY"), os.Getenv("ONSHAPE_SECRET"), "http://localhost:3000/auth/onshape/callback"),
+		arcgis.New(os.Getenv("ARCGIS_KEY"), os.Getenv("ARCGIS_SECRET"), "http://localhost:3000/auth/arcgis/callback"),
+		frameio.New(os.Getenv("FRAMEIO_KEY"), os.Getenv("FRAMEIO_SECRET"), "http://localhost:3000/auth/frameio/callback"),
+		adobe.New(os.Getenv("ADOBE_KEY"), os.Getenv("ADOBE_SECRET"), "http://localhost:3000/auth/adobe/callback"),
+		garmin.New(os.Getenv("GARMIN_KEY"), os.Getenv("GARMIN_SECRET"), "http://localhost:3000/auth/garmin/callback"),
+		whoop.New(os.Getenv("WHOOP_KEY"), os.Getenv("WHOOP_SECRET"), "http://localhost:3000/auth/whoop/callback"),
+		polar.New(os.Getenv("POLAR_KEY"), os.Getenv("POLAR_SECRET"), "http://localhost:3000/auth/polar/callback"),
+		withings.New(os.Getenv("WITHINGS_KEY"), os.Getenv("WITHINGS_SECRET"), "http://localhost:3000/auth/withings/callback"),
+		orcid.New(os.Getenv("ORCID_KEY"), os.Getenv("ORCID_SECRET"), "http://localhost:3000/auth/orcid/callback"),
+		logingov.New(os.Getenv("LOGINGOV_KEY"), nil, "http://localhost:3000/auth/logingov/callback"),
+		govuk.New(os.Getenv("GOVUK_KEY"), os.Getenv("GOVUK_SECRET"), "http://localhost:3000/auth/govuk/callback"),
+		franceconnect.New(os.Getenv("FRANCECONNECT_KEY"), os.Getenv("FRANCECONNECT_SECRET"), "http://localhost:3000/auth/franceconnect/callback"),
+		idme.New(os.Getenv("IDME_KEY"), os.Getenv("IDME_SECRET"), "http://localhost:3000/auth/idme/callback"),
+		clever.New(os.Getenv("CLEVER_KEY"), os.Getenv("CLEVER_SECRET"), "http://localhost:3000/auth/clever/callback"),
+		classlink.New(os.Getenv("CLASSLINK_KEY"), os.Getenv("CLASSLINK_SECRET"), "http://localhost:3000/auth/classlink/callback"),
+		telegram.New(os.Getenv("TELEGRAM_BOT_TOKEN"), "http://localhost:3000/auth/telegram/callback"),
+		wechat.New(os.Getenv("WECHAT_KEY"), os.Getenv("WECHAT_SECRET"), "http://localhost:3000/auth/wechat/callback"),
 	)
 
 	// OpenID Connect is based on OpenID Connect Auto Discovery URL (https://openid.net/specs/openid-connect-discovery-1_0-17.html)
@@ -148,6 +246,17 @@ func main() {
 		goth.UseProviders(openidConnect)
 	}
 
+	// Bluesky identifies the client by the URL of its metadata document, which must be publicly reachable,
+	// see bluesky.Provider.ClientMetadata
+	if bsky, err := bluesky.New(os.Getenv("BLUESKY_CLIENT_ID"), "http://localhost:3000/auth/bluesky/callback", nil, bluesky.ScopeTransitionGeneric); err == nil {
+		goth.UseProviders(bsky)
+	}
+
+	// Intuit reads its endpoints from a discovery document, use intuit.NewSandbox for sandbox companies
+	if intuitProvider, err := intuit.New(os.Getenv("INTUIT_KEY"), os.Getenv("INTUIT_SECRET"), "http://localhost:3000/auth/intuit/callback"); err == nil {
+		goth.UseProviders(intuitProvider)
+	}
+
 	m := make(map[string]string)
 	m["amazon"] = "Amazon"
 	m["autodeskforge"] = "Autodesk Forge"
@@ -205,6 +314,56 @@ func main() {
 	m["strava"] = "Strava"
 	m["okta"] = "Okta"
 	m["mastodon"] = "Mastodon"
+	m["atlassian"] = "Atlassian"
+	m["bluesky"] = "Bluesky"
+	m["twitterv2oauth2"] = "Twitter (OAuth 2.0)"
+	m["reddit"] = "Reddit"
+	m["pinterest"] = "Pinterest"
+	m["snapchat"] = "Snapchat"
+	m["kick"] = "Kick"
+	m["roblox"] = "Roblox"
+	m["epicgames"] = "Epic Games"
+	m["zoom"] = "Zoom"
+	m["webex"] = "Webex"
+	m["docusign"] = "DocuSign"
+	m["intuit"] = "Intuit (QuickBooks)"
+	m["freshbooks"] = "FreshBooks"
+	m["zoho"] = "Zoho"
+	m["hubspot"] = "HubSpot"
+	m["pipedrive"] = "Pipedrive"
+	m["zendesk"] = "Zendesk"
+	m["servicenow"] = "ServiceNow"
+	m["workday"] = "Workday"
+	m["bamboohr"] = "BambooHR"
+	m["gusto"] = "Gusto"
+	m["stripeconnect"] = "Stripe Connect"
+	m["square"] = "Square"
+	m["etsy"] = "Etsy"
+	m["ebay"] = "eBay"
+	m["bigcommerce"] = "BigCommerce"
+	m["wordpresscom"] = "WordPress.com"
+	m["squarespace"] = "Squarespace"
+	m["webflow"] = "Webflow"
+	m["procore"] = "Procore"
+	m["trimble"] = "Trimble"
+	m["bentleyitwin"] = "Bentley iTwin"
+	m["onshape"] = "Onshape"
+	m["arcgis"] = "ArcGIS"
+	m["frameio"] = "Frame.io"
+	m["adobe"] = "Adobe"
+	m["garmin"] = "Garmin"
+	m["whoop"] = "WHOOP"
+	m["polar"] = "Polar"
+	m["withings"] = "Withings"
+	m["orcid"] = "ORCID"
+	m["logingov"] = "Login.gov"
+	m["govuk"] = "GOV.UK One Login"
+	m["franceconnect"] = "FranceConnect"
+	m["idme"] = "ID.me"
+	m["clever"] = "Clever"
+	m["classlink"] = "ClassLink"
+	m["telegram"] = "Telegram"
+	m["wechat"] = "WeChat"
 
 	var keys []string
 	for k := range m {
@@ -215,7 +374,7 @@ func main() {
 	providerIndex := &ProviderIndex{Providers: keys, ProvidersMap: m}
 
 	p := pat.New()
-	p.Get("/auth/{provider}/callback", func(res http.ResponseWriter, req *http.Request) {
+	callback := func(res http.ResponseWriter, req *http.Request) {
 		user, err := gothic.CompleteUserAuth(res, req)
 		if err != nil {
 			fmt.Fprintln(res, err)
@@ -223,7 +382,10 @@ func main() {
 		}
 		t, _ := template.New("foo").Parse(userTemplate)
 		t.Execute(res, user)
-	})
+	}
+	// Telegram sends the user back with the login in the fragment of the URL
+	p.Get("/auth/telegram/callback", telegram.RelayResult(http.HandlerFunc(callback)).ServeHTTP)
+	p.Get("/auth/{provider}/callback", callback)
 
 	p.Get("/logout/{provider}", func(res http.ResponseWriter, req *http.Request) {
 		gothic.Logout(res, req)
@@ -0,0 +1,41 @@
+package gothgraphql_test
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/gothgraphql"
+)
+
+// Resolver stands for graphql.Resolver of gqlgen.
+type Resolver func(ctx context.Context) (res interface{}, err error)
+
+// DirectiveRoot stands for the DirectiveRoot generated by gqlgen for the schema:
+//
+//	directive @auth on FIELD_DEFINITION
+type DirectiveRoot struct {
+	Auth func(ctx context.Context, obj interface{}, next Resolver) (res interface{}, err error)
+}
+
+func ExampleAuth() {
+	directives := DirectiveRoot{}
+	directives.Auth = func(ctx context.Context, obj interface{}, next Resolver) (interface{}, error) {
+		return gothgraphql.Auth(ctx, obj, next)
+	}
+
+	me := func(ctx context.Context) (interface{}, error) {
+		user, _ := gothgraphql.ForContext(ctx)
+		return user.Name, nil
+	}
+
+	_, err := directives.Auth(context.Background(), nil, me)
+	fmt.Println(err)
+
+	ctx := gothgraphql.NewContext(context.Background(), goth.User{Name: "Homer Simpson"})
+	name, _ := directives.Auth(ctx, nil, me)
+	fmt.Println(name)
+	// Output:
+	// gothgraphql: not authenticated
+	// Homer Simpson
+}
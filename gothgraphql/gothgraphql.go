@@ -0,0 +1,85 @@
+/*
+Package gothgraphql gives the resolvers of GraphQL servers, e.g. built with gqlgen, the
+user signed in with gothic.StoreUser.
+
+Middleware puts the user of the requests in their context, where the resolvers get it
+with ForContext:
+
+	srv := handler.NewDefaultServer(generated.NewExecutableSchema(generated.Config{Resolvers: resolvers}))
+	http.Handle("/query", gothgraphql.Middleware(srv))
+
+	func (r *queryResolver) Me(ctx context.Context) (*model.User, error) {
+		user, err := gothgraphql.ForContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		...
+	}
+
+The fields needing a user can instead be marked with an @auth directive, implemented
+by Auth:
+
+	directive @auth on FIELD_DEFINITION
+
+	type Query {
+		me: User! @auth
+	}
+
+	c := generated.Config{Resolvers: resolvers}
+	c.Directives.Auth = func(ctx context.Context, obj interface{}, next graphql.Resolver) (interface{}, error) {
+		return gothgraphql.Auth(ctx, obj, next)
+	}
+
+This package does not depend on gqlgen, nor on any other GraphQL library.
+*/
+package gothgraphql
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/gothic"
+)
+
+// ErrUnauthenticated is returned by ForContext and Auth when no user is signed in.
+var ErrUnauthenticated = errors.New("gothgraphql: not authenticated")
+
+type contextKey int
+
+const userKey contextKey = iota
+
+// Middleware puts the user signed in with gothic.StoreUser, if any, in the context of
+// the requests. The requests without a user are served too, see ForContext and Auth.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if user, err := gothic.GetUser(req); err == nil {
+			req = req.WithContext(NewContext(req.Context(), user))
+		}
+		next.ServeHTTP(res, req)
+	})
+}
+
+// NewContext returns a copy of ctx holding the user.
+func NewContext(ctx context.Context, user goth.User) context.Context {
+	return context.WithValue(ctx, userKey, user)
+}
+
+// ForContext returns the user put in the context by Middleware, or ErrUnauthenticated.
+func ForContext(ctx context.Context) (goth.User, error) {
+	user, ok := ctx.Value(userKey).(goth.User)
+	if !ok {
+		return goth.User{}, ErrUnauthenticated
+	}
+	return user, nil
+}
+
+// Auth implements an @auth directive: it resolves the field with next when a user is
+// signed in, and returns ErrUnauthenticated otherwise.
+func Auth(ctx context.Context, obj interface{}, next func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	if _, err := ForContext(ctx); err != nil {
+		return nil, err
+	}
+	return next(ctx)
+}
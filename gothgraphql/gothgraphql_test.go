@@ -0,0 +1,57 @@
+package gothgraphql_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/sessions"
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/gothgraphql"
+	"github.com/markbates/goth/gothic"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	gothic.Store = sessions.NewCookieStore([]byte("secret"))
+}
+
+func Test_Middleware(t *testing.T) {
+	a := assert.New(t)
+
+	var user goth.User
+	var err error
+	handler := gothgraphql.Middleware(http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		user, err = gothgraphql.ForContext(req.Context())
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/query", nil))
+	a.Equal(gothgraphql.ErrUnauthenticated, err)
+
+	res := httptest.NewRecorder()
+	a.NoError(gothic.StoreUser(res, httptest.NewRequest("GET", "/", nil), goth.User{UserID: "42", Provider: "faux"}))
+	req := httptest.NewRequest("POST", "/query", nil)
+	for _, c := range res.Result().Cookies() {
+		req.AddCookie(c)
+	}
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	a.NoError(err)
+	a.Equal("42", user.UserID)
+}
+
+func Test_Auth(t *testing.T) {
+	a := assert.New(t)
+
+	resolver := func(ctx context.Context) (interface{}, error) {
+		return "resolved", nil
+	}
+
+	_, err := gothgraphql.Auth(context.Background(), nil, resolver)
+	a.Equal(gothgraphql.ErrUnauthenticated, err)
+
+	ctx := gothgraphql.NewContext(context.Background(), goth.User{UserID: "42"})
+	res, err := gothgraphql.Auth(ctx, nil, resolver)
+	a.NoError(err)
+	a.Equal("resolved", res)
+}
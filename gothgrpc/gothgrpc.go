@@ -0,0 +1,119 @@
+/*
+Package gothgrpc authenticates the calls of gRPC services with the tokens issued by the
+providers, so that they share the identity layer of the web applications using goth.
+
+The interceptors read the bearer token from the "authorization" metadata, validate it
+with a Validator and put the user in the context of the call:
+
+	validator, err := gothgrpc.NewJWTFromDiscovery("google", "https://accounts.google.com/.well-known/openid-configuration", clientID)
+	if err != nil {
+		log.Fatal(err)
+	}
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(gothgrpc.UnaryServerInterceptor(validator)),
+		grpc.StreamInterceptor(gothgrpc.StreamServerInterceptor(validator)),
+	)
+
+The handlers then get the user with User(ctx). It is a separate module, so that goth
+does not depend on gRPC.
+*/
+package gothgrpc
+
+import (
+	"context"
+	"strings"
+
+	"github.com/markbates/goth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Validator validates the bearer tokens, and returns their users.
+type Validator interface {
+	Validate(ctx context.Context, token string) (goth.User, error)
+}
+
+// ValidatorFunc is a function validating the bearer tokens.
+type ValidatorFunc func(ctx context.Context, token string) (goth.User, error)
+
+// Validate calls f(ctx, token).
+func (f ValidatorFunc) Validate(ctx context.Context, token string) (goth.User, error) {
+	return f(ctx, token)
+}
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx holding the user.
+func NewContext(ctx context.Context, user goth.User) context.Context {
+	return context.WithValue(ctx, contextKey{}, user)
+}
+
+// User returns the user authenticated by the interceptors.
+func User(ctx context.Context) (goth.User, bool) {
+	user, ok := ctx.Value(contextKey{}).(goth.User)
+	return user, ok
+}
+
+// UnaryServerInterceptor rejects the unary calls without a valid bearer token with
+// codes.Unauthenticated, and puts the user of the token in the context of the others.
+func UnaryServerInterceptor(v Validator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticate(ctx, v)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor rejects the streams without a valid bearer token with
+// codes.Unauthenticated, and puts the user of the token in the context of the others.
+func StreamServerInterceptor(v Validator) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(ss.Context(), v)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// serverStream is a grpc.ServerStream with the context holding the user.
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+// authenticate validates the bearer token of the call and returns a context holding
+// its user.
+func authenticate(ctx context.Context, v Validator) (context.Context, error) {
+	token, ok := bearerToken(ctx)
+	if !ok {
+		return ctx, status.Error(codes.Unauthenticated, "missing bearer token")
+	}
+	user, err := v.Validate(ctx, token)
+	if err != nil {
+		return ctx, status.Errorf(codes.Unauthenticated, "invalid bearer token: %v", err)
+	}
+	return NewContext(ctx, user), nil
+}
+
+// bearerToken returns the token of the "authorization" metadata of the call.
+func bearerToken(ctx context.Context) (string, bool) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", false
+	}
+	for _, value := range md.Get("authorization") {
+		if len(value) > 7 && strings.EqualFold(value[:7], "bearer ") {
+			return strings.TrimSpace(value[7:]), true
+		}
+	}
+	return "", false
+}
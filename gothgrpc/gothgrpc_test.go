@@ -0,0 +1,75 @@
+package gothgrpc_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/gothgrpc"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var validator = gothgrpc.ValidatorFunc(func(ctx context.Context, token string) (goth.User, error) {
+	if token != "valid" {
+		return goth.User{}, errors.New("unknown token")
+	}
+	return goth.User{UserID: "42", Provider: "faux"}, nil
+})
+
+func withAuthorization(value string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", value))
+}
+
+func Test_UnaryServerInterceptor(t *testing.T) {
+	a := assert.New(t)
+	interceptor := gothgrpc.UnaryServerInterceptor(validator)
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		user, ok := gothgrpc.User(ctx)
+		a.True(ok)
+		return user.UserID, nil
+	}
+
+	resp, err := interceptor(withAuthorization("Bearer valid"), nil, &grpc.UnaryServerInfo{}, handler)
+	a.NoError(err)
+	a.Equal("42", resp)
+
+	for _, ctx := range []context.Context{
+		context.Background(),
+		withAuthorization("Basic dXNlcjpwYXNz"),
+		withAuthorization("Bearer invalid"),
+	} {
+		_, err = interceptor(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+		a.Equal(codes.Unauthenticated, status.Code(err))
+	}
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func Test_StreamServerInterceptor(t *testing.T) {
+	a := assert.New(t)
+	interceptor := gothgrpc.StreamServerInterceptor(validator)
+	handler := func(srv interface{}, ss grpc.ServerStream) error {
+		user, ok := gothgrpc.User(ss.Context())
+		a.True(ok)
+		a.Equal("42", user.UserID)
+		return nil
+	}
+
+	err := interceptor(nil, &serverStream{ctx: withAuthorization("bearer valid")}, &grpc.StreamServerInfo{}, handler)
+	a.NoError(err)
+
+	err = interceptor(nil, &serverStream{ctx: context.Background()}, &grpc.StreamServerInfo{}, handler)
+	a.Equal(codes.Unauthenticated, status.Code(err))
+}
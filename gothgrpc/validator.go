@@ -0,0 +1,236 @@
+package gothgrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/internal/jwks"
+	"github.com/markbates/goth/providers/openidConnect"
+)
+
+// Introspection validates opaque access tokens with the token introspection endpoint of
+// a provider, see https://tools.ietf.org/html/rfc7662
+type Introspection struct {
+	providerName string
+	url          string
+	clientID     string
+	clientSecret string
+	client       *http.Client
+}
+
+// NewIntrospection creates a Validator posting the tokens to the introspection endpoint
+// at url, authenticated with the credentials of the client. The users get the name of
+// the provider.
+func NewIntrospection(providerName, url, clientID, clientSecret string) *Introspection {
+	return &Introspection{
+		providerName: providerName,
+		url:          url,
+		clientID:     clientID,
+		clientSecret: clientSecret,
+	}
+}
+
+// WithHTTPClient sets the client calling the endpoint, http.DefaultClient by default.
+func (i *Introspection) WithHTTPClient(client *http.Client) *Introspection {
+	i.client = client
+	return i
+}
+
+// Validate returns the user of the token when the provider tells it is active.
+func (i *Introspection) Validate(ctx context.Context, token string) (goth.User, error) {
+	form := url.Values{"token": {token}, "token_type_hint": {"access_token"}}
+	req, err := http.NewRequest("POST", i.url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return goth.User{}, err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(i.clientID), url.QueryEscape(i.clientSecret))
+
+	resp, err := goth.HTTPClientWithFallBack(i.client).Do(req)
+	if err != nil {
+		return goth.User{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return goth.User{}, fmt.Errorf("%s responded with a %d trying to introspect the token", i.providerName, resp.StatusCode)
+	}
+
+	claims := map[string]interface{}{}
+	if err = json.NewDecoder(resp.Body).Decode(&claims); err != nil {
+		return goth.User{}, err
+	}
+	if active, _ := claims["active"].(bool); !active {
+		return goth.User{}, errors.New("the token is not active")
+	}
+	user := userFromClaims(i.providerName, token, claims)
+	if !user.ExpiresAt.IsZero() && user.ExpiresAt.Before(time.Now()) {
+		return goth.User{}, errors.New("the token has expired")
+	}
+	return user, nil
+}
+
+// JWT validates access tokens which are JSON Web Tokens signed by the provider, with
+// the keys it publishes as a JSON Web Key Set.
+type JWT struct {
+	providerName string
+	jwksURL      string
+	issuer       string
+	audiences    []string
+	client       *http.Client
+	keys         jwks.Cache
+}
+
+// NewJWT creates a Validator verifying the signature of the tokens with the keys
+// published at jwksURL, and that they are issued by the issuer for one of the
+// audiences, e.g. the client ID or the identifier of the API. The issuer and an
+// audience are required, as the tokens the provider issues to its other clients are
+// validly signed too. The users get the name of the provider.
+func NewJWT(providerName, jwksURL, issuer string, audiences ...string) (*JWT, error) {
+	if jwksURL == "" || issuer == "" {
+		return nil, errors.New("gothgrpc: the keys URL and the issuer of the JWTs are required")
+	}
+	if len(audiences) == 0 {
+		return nil, errors.New("gothgrpc: an audience of the JWTs is required")
+	}
+	for _, audience := range audiences {
+		if audience == "" {
+			return nil, errors.New("gothgrpc: the audiences of the JWTs cannot be empty")
+		}
+	}
+	return &JWT{
+		providerName: providerName,
+		jwksURL:      jwksURL,
+		issuer:       issuer,
+		audiences:    audiences,
+	}, nil
+}
+
+// NewJWTFromDiscovery creates a JWT validating the tokens of the provider with the
+// issuer and the keys URL of its OpenID Connect discovery document at discoveryURL,
+// e.g. https://accounts.google.com/.well-known/openid-configuration
+func NewJWTFromDiscovery(providerName, discoveryURL string, audiences ...string) (*JWT, error) {
+	resp, err := http.Get(discoveryURL)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s responded with a %d trying to fetch the discovery document", providerName, resp.StatusCode)
+	}
+
+	config := openidConnect.OpenIDConfig{}
+	if err = json.NewDecoder(resp.Body).Decode(&config); err != nil {
+		return nil, err
+	}
+	return NewJWT(providerName, config.JwksURI, config.Issuer, audiences...)
+}
+
+// NewJWTFromProvider creates a JWT validating the tokens of an OpenID Connect provider
+// with the issuer and the keys URL of its discovery document, and its HTTP client. The
+// audience is the client ID of the provider by default.
+func NewJWTFromProvider(p *openidConnect.Provider, audiences ...string) (*JWT, error) {
+	if len(audiences) == 0 {
+		audiences = []string{p.ClientKey}
+	}
+	j, err := NewJWT(p.Name(), p.OpenIDConfig.JwksURI, p.OpenIDConfig.Issuer, audiences...)
+	if err != nil {
+		return nil, err
+	}
+	return j.WithHTTPClient(p.HTTPClient), nil
+}
+
+// WithHTTPClient sets the client fetching the keys, http.DefaultClient by default.
+func (j *JWT) WithHTTPClient(client *http.Client) *JWT {
+	j.client = client
+	return j
+}
+
+// Validate returns the user of the token once its signature, expiry, issuer and
+// audience are verified. The tokens without an expiry are rejected, and the ones with
+// a "nbf" or "iat" claim in the future too.
+func (j *JWT) Validate(ctx context.Context, token string) (goth.User, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		switch t.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS, *jwt.SigningMethodECDSA:
+		default:
+			return nil, fmt.Errorf("unexpected signing method %v", t.Header["alg"])
+		}
+		kid, _ := t.Header["kid"].(string)
+		return j.keys.Key(goth.HTTPClientWithFallBack(j.client), j.jwksURL, kid)
+	})
+	if err != nil {
+		return goth.User{}, err
+	}
+	// MapClaims.Valid accepts the tokens without these claims, the ones without an
+	// expiry would never expire
+	now := time.Now().Unix()
+	if !claims.VerifyExpiresAt(now, true) {
+		return goth.User{}, errors.New("the token has expired or has no expiry")
+	}
+	if !claims.VerifyNotBefore(now, false) || !claims.VerifyIssuedAt(now, false) {
+		return goth.User{}, errors.New("the token is not valid yet")
+	}
+	// the JWTs which are not created by NewJWT have no issuer and no audience, they
+	// reject every token
+	if j.issuer == "" || !claims.VerifyIssuer(j.issuer, true) {
+		return goth.User{}, errors.New("the token has an unexpected issuer")
+	}
+	if !hasAudience(claims, j.audiences) {
+		return goth.User{}, errors.New("the token is for another audience")
+	}
+	return userFromClaims(j.providerName, token, claims), nil
+}
+
+// hasAudience tells whether the "aud" claim, a string or an array, has one of the
+// audiences.
+func hasAudience(claims jwt.MapClaims, audiences []string) bool {
+	var auds []interface{}
+	switch aud := claims["aud"].(type) {
+	case string:
+		auds = []interface{}{aud}
+	case []interface{}:
+		auds = aud
+	}
+	for _, aud := range auds {
+		for _, audience := range audiences {
+			if aud == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// userFromClaims builds the user from the claims of a token, named as in OpenID Connect.
+func userFromClaims(providerName, token string, claims map[string]interface{}) goth.User {
+	user := goth.User{
+		RawData:     claims,
+		Provider:    providerName,
+		AccessToken: token,
+	}
+	user.UserID, _ = claims["sub"].(string)
+	user.Name, _ = claims["name"].(string)
+	user.NickName, _ = claims["preferred_username"].(string)
+	if user.NickName == "" {
+		user.NickName, _ = claims["username"].(string)
+	}
+	user.FirstName, _ = claims["given_name"].(string)
+	user.LastName, _ = claims["family_name"].(string)
+	user.Email, _ = claims["email"].(string)
+	user.AvatarURL, _ = claims["picture"].(string)
+	if exp, ok := claims["exp"].(float64); ok {
+		user.ExpiresAt = time.Unix(int64(exp), 0)
+	}
+	return user
+}
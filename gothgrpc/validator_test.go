@@ -0,0 +1,200 @@
+package gothgrpc_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/markbates/goth/gothgrpc"
+	"github.com/markbates/goth/providers/openidConnect"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Introspection(t *testing.T) {
+	a := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, secret, _ := r.BasicAuth()
+		a.Equal("client", id)
+		a.Equal("secret", secret)
+		if r.FormValue("token") != "valid" {
+			json.NewEncoder(w).Encode(map[string]interface{}{"active": false})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"active":   true,
+			"sub":      "42",
+			"username": "homer",
+			"exp":      time.Now().Add(time.Hour).Unix(),
+		})
+	}))
+	defer server.Close()
+
+	v := gothgrpc.NewIntrospection("oauth", server.URL, "client", "secret")
+	user, err := v.Validate(context.Background(), "valid")
+	a.NoError(err)
+	a.Equal("42", user.UserID)
+	a.Equal("homer", user.NickName)
+	a.Equal("oauth", user.Provider)
+	a.Equal("valid", user.AccessToken)
+
+	_, err = v.Validate(context.Background(), "revoked")
+	a.Error(err)
+}
+
+func Test_JWT(t *testing.T) {
+	a := assert.New(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	a.NoError(err)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/certs", r.URL.Path)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": "key",
+				"alg": "RS256",
+				"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+			}},
+		})
+	}))
+	defer server.Close()
+
+	sign := func(method jwt.SigningMethod, signingKey interface{}, claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(method, claims)
+		token.Header["kid"] = "key"
+		signed, err := token.SignedString(signingKey)
+		a.NoError(err)
+		return signed
+	}
+	claims := func(iss string, aud interface{}, exp time.Duration) jwt.MapClaims {
+		return jwt.MapClaims{
+			"sub":   "42",
+			"email": "homer@example.com",
+			"iss":   iss,
+			"aud":   aud,
+			"exp":   time.Now().Add(exp).Unix(),
+		}
+	}
+
+	v, err := gothgrpc.NewJWT("oidc", server.URL+"/certs", "https://issuer", "api")
+	a.NoError(err)
+	user, err := v.Validate(context.Background(), sign(jwt.SigningMethodRS256, key, claims("https://issuer", []string{"other", "api"}, time.Hour)))
+	a.NoError(err)
+	a.Equal("42", user.UserID)
+	a.Equal("homer@example.com", user.Email)
+	a.Equal("oidc", user.Provider)
+
+	for _, token := range []string{
+		sign(jwt.SigningMethodRS256, key, claims("https://other", "api", time.Hour)),
+		sign(jwt.SigningMethodRS256, key, claims("https://issuer", "other", time.Hour)),
+		sign(jwt.SigningMethodRS256, key, claims("https://issuer", "api", -time.Hour)),
+		sign(jwt.SigningMethodHS256, []byte("secret"), claims("https://issuer", "api", time.Hour)),
+		sign(jwt.SigningMethodRS256, key, jwt.MapClaims{"sub": "42", "iss": "https://issuer", "aud": "api"}),
+		sign(jwt.SigningMethodRS256, key, jwt.MapClaims{
+			"sub": "42",
+			"iss": "https://issuer",
+			"aud": "api",
+			"exp": time.Now().Add(2 * time.Hour).Unix(),
+			"nbf": time.Now().Add(time.Hour).Unix(),
+		}),
+		"not a token",
+	} {
+		_, err = v.Validate(context.Background(), token)
+		a.Error(err)
+	}
+
+	// the JWTs not created by NewJWT reject every token
+	_, err = (&gothgrpc.JWT{}).Validate(context.Background(), sign(jwt.SigningMethodRS256, key, claims("", "", time.Hour)))
+	a.Error(err)
+}
+
+func Test_NewJWT(t *testing.T) {
+	a := assert.New(t)
+
+	for _, args := range [][]string{
+		{"", "https://issuer", "api"},
+		{"https://keys", "", "api"},
+		{"https://keys", "https://issuer"},
+		{"https://keys", "https://issuer", ""},
+	} {
+		_, err := gothgrpc.NewJWT("oidc", args[0], args[1], args[2:]...)
+		a.Error(err)
+	}
+}
+
+func Test_NewJWTFromDiscovery(t *testing.T) {
+	a := assert.New(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	a.NoError(err)
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"issuer":                 server.URL,
+				"authorization_endpoint": server.URL + "/authorize",
+				"token_endpoint":         server.URL + "/token",
+				"jwks_uri":               server.URL + "/certs",
+			})
+		case "/certs":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"keys": []map[string]string{{
+					"kty": "RSA",
+					"kid": "key",
+					"alg": "RS256",
+					"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+					"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+				}},
+			})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	sign := func(aud string) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+			"sub": "42",
+			"iss": server.URL,
+			"aud": aud,
+			"exp": time.Now().Add(time.Hour).Unix(),
+		})
+		token.Header["kid"] = "key"
+		signed, err := token.SignedString(key)
+		a.NoError(err)
+		return signed
+	}
+
+	v, err := gothgrpc.NewJWTFromDiscovery("oidc", server.URL+"/.well-known/openid-configuration", "api")
+	a.NoError(err)
+	user, err := v.Validate(context.Background(), sign("api"))
+	a.NoError(err)
+	a.Equal("42", user.UserID)
+	_, err = v.Validate(context.Background(), sign("client"))
+	a.Error(err)
+
+	// the audience of the provider is its client ID by default
+	p, err := openidConnect.New("client", "secret", "/foo", server.URL+"/.well-known/openid-configuration")
+	a.NoError(err)
+	v, err = gothgrpc.NewJWTFromProvider(p)
+	a.NoError(err)
+	user, err = v.Validate(context.Background(), sign("client"))
+	a.NoError(err)
+	a.Equal("openid-connect", user.Provider)
+	_, err = v.Validate(context.Background(), sign("api"))
+	a.Error(err)
+
+	_, err = gothgrpc.NewJWTFromDiscovery("oidc", server.URL+"/missing", "api")
+	a.Error(err)
+}
@@ -0,0 +1,108 @@
+/*
+Package chiauth registers the routes of gothic on a chi.Router, reading the name of the
+provider from the URL parameters, and puts the goth.User in the context of the requests.
+
+	r := chi.NewRouter()
+	chiauth.Routes(r, http.RedirectHandler("/", http.StatusFound))
+	r.With(chiauth.Logout).Get("/logout", http.RedirectHandler("/", http.StatusFound).ServeHTTP)
+
+	r.Group(func(r chi.Router) {
+		r.Use(chiauth.RequireAuth)
+		r.Get("/me", func(w http.ResponseWriter, r *http.Request) {
+			user, _ := chiauth.User(r.Context())
+			json.NewEncoder(w).Encode(user)
+		})
+	})
+
+The users are signed in with gothic.StoreUser, which keeps their profile in the
+gothic.Store.
+*/
+package chiauth
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/gothic"
+)
+
+// ProviderParam is the name of the URL parameter holding the name of the provider.
+var ProviderParam = "provider"
+
+type contextKey int
+
+const userKey contextKey = iota
+
+// Routes registers "/auth/{provider}", redirecting the users to the provider, and
+// "/auth/{provider}/callback", signing them in and calling success with the user in the
+// context of the request.
+func Routes(r chi.Router, success http.Handler) {
+	r.Get("/auth/{"+ProviderParam+"}", BeginAuth)
+	r.With(Callback).Handle("/auth/{"+ProviderParam+"}/callback", success)
+}
+
+// withProvider puts the name of the provider of the URL in the context of the request.
+func withProvider(r *http.Request) *http.Request {
+	return gothic.GetContextWithProvider(r, chi.URLParam(r, ProviderParam))
+}
+
+// BeginAuth redirects the user to the provider to authenticate.
+func BeginAuth(w http.ResponseWriter, r *http.Request) {
+	gothic.BeginAuthHandler(w, withProvider(r))
+}
+
+// Callback is a middleware completing the authentication with the provider, signing the
+// user in and putting the user in the context of the request for the next handler. It
+// responds with a 401 when the authentication fails.
+func Callback(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r = withProvider(r)
+		user, err := gothic.CompleteUserAuth(w, r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+		if err = gothic.StoreUser(w, r, user); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		next.ServeHTTP(w, WithUser(r, user))
+	})
+}
+
+// RequireAuth is a middleware putting the signed in user in the context of the request,
+// and responding with a 401 when there is none.
+func RequireAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, err := gothic.GetUser(r)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, WithUser(r, user))
+	})
+}
+
+// Logout is a middleware signing the user out before the next handler.
+func Logout(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := gothic.LogoutUser(w, r); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// WithUser returns a copy of the request with the user in its context.
+func WithUser(r *http.Request, user goth.User) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), userKey, user))
+}
+
+// User returns the user put in the context by Callback or RequireAuth.
+func User(ctx context.Context) (goth.User, bool) {
+	user, ok := ctx.Value(userKey).(goth.User)
+	return user, ok
+}
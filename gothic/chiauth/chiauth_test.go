@@ -0,0 +1,85 @@
+package chiauth_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/gorilla/sessions"
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/gothic"
+	"github.com/markbates/goth/gothic/chiauth"
+	"github.com/markbates/goth/providers/faux"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	gothic.Store = sessions.NewCookieStore([]byte("secret"))
+	goth.UseProviders(&faux.Provider{})
+}
+
+func router() http.Handler {
+	r := chi.NewRouter()
+	chiauth.Routes(r, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, _ := chiauth.User(r.Context())
+		w.Write([]byte(user.Provider))
+	}))
+	r.With(chiauth.Logout).Get("/logout", func(w http.ResponseWriter, r *http.Request) {})
+	r.With(chiauth.RequireAuth).Get("/me", func(w http.ResponseWriter, r *http.Request) {
+		user, _ := chiauth.User(r.Context())
+		w.Write([]byte(user.UserID))
+	})
+	return r
+}
+
+func serve(h http.Handler, target string, cookies []*http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", target, nil)
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	res := httptest.NewRecorder()
+	h.ServeHTTP(res, req)
+	return res
+}
+
+func Test_Routes(t *testing.T) {
+	a := assert.New(t)
+	r := router()
+
+	res := serve(r, "/me", nil)
+	a.Equal(http.StatusUnauthorized, res.Code)
+
+	res = serve(r, "/auth/faux", nil)
+	a.Equal(http.StatusTemporaryRedirect, res.Code)
+	location, err := url.Parse(res.Header().Get("Location"))
+	a.NoError(err)
+	a.Equal("example.com", location.Host)
+
+	res = serve(r, "/auth/faux/callback?state="+url.QueryEscape(location.Query().Get("state")), res.Result().Cookies())
+	a.Equal(http.StatusOK, res.Code)
+	a.Equal("faux", res.Body.String())
+
+	var userCookie *http.Cookie
+	for _, c := range res.Result().Cookies() {
+		if c.Name == gothic.UserSessionName {
+			userCookie = c
+		}
+	}
+	a.NotNil(userCookie)
+	res = serve(r, "/me", []*http.Cookie{userCookie})
+	a.Equal(http.StatusOK, res.Code)
+	a.Equal("id", res.Body.String())
+
+	res = serve(r, "/logout", []*http.Cookie{userCookie})
+	a.Equal(http.StatusOK, res.Code)
+	a.Equal(-1, res.Result().Cookies()[0].MaxAge)
+}
+
+func Test_Callback_Error(t *testing.T) {
+	a := assert.New(t)
+
+	res := serve(router(), "/auth/faux/callback", nil)
+	a.Equal(http.StatusUnauthorized, res.Code)
+}
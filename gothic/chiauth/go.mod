@@ -0,0 +1,23 @@
+module github.com/markbates/goth/gothic/chiauth
+
+go 1.23.0
+
+require (
+	github.com/go-chi/chi/v5 v5.0.12
+	github.com/gorilla/sessions v1.1.1
+	github.com/markbates/goth v1.82.0
+	github.com/stretchr/testify v1.8.4
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/gorilla/context v1.1.1 // indirect
+	github.com/gorilla/mux v1.6.2 // indirect
+	github.com/gorilla/securecookie v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/net v0.0.0-20200930145003-4acb6c075d10 // indirect
+	golang.org/x/oauth2 v0.0.0-20200902213428-5d25da1a8d43 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/markbates/goth => ../../
@@ -0,0 +1,101 @@
+/*
+Package echoauth provides Echo handlers and middleware for gothic, reading the name of the
+provider from the path parameters, e.g. "/auth/:provider", and putting the goth.User in
+the echo.Context.
+
+	e := echo.New()
+	e.GET("/auth/:provider", echoauth.BeginAuth)
+	e.GET("/auth/:provider/callback", func(c echo.Context) error {
+		return c.Redirect(http.StatusFound, "/")
+	}, echoauth.Callback)
+	e.GET("/logout", func(c echo.Context) error {
+		return c.Redirect(http.StatusFound, "/")
+	}, echoauth.Logout)
+
+	private := e.Group("", echoauth.RequireAuth)
+	private.GET("/me", func(c echo.Context) error {
+		user, _ := echoauth.User(c)
+		return c.JSON(http.StatusOK, user)
+	})
+
+The users are signed in with gothic.StoreUser, which keeps their profile in the
+gothic.Store.
+*/
+package echoauth
+
+import (
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/gothic"
+)
+
+// UserKey is the key of the goth.User in the echo.Context.
+const UserKey = "goth_user"
+
+// ProviderParam is the name of the path parameter holding the name of the provider.
+var ProviderParam = "provider"
+
+// withProvider puts the name of the provider of the path in the context of the request.
+func withProvider(c echo.Context) *http.Request {
+	req := gothic.GetContextWithProvider(c.Request(), c.Param(ProviderParam))
+	c.SetRequest(req)
+	return req
+}
+
+// BeginAuth redirects the user to the provider to authenticate.
+func BeginAuth(c echo.Context) error {
+	url, err := gothic.GetAuthURL(c.Response(), withProvider(c))
+	if err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, err.Error()).SetInternal(err)
+	}
+	return c.Redirect(http.StatusTemporaryRedirect, url)
+}
+
+// Callback is a middleware completing the authentication with the provider, signing the
+// user in and putting the user in the context for the next handler. It responds with a
+// 401 when the authentication fails.
+func Callback(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		req := withProvider(c)
+		user, err := gothic.CompleteUserAuth(c.Response(), req)
+		if err != nil {
+			return echo.NewHTTPError(http.StatusUnauthorized).SetInternal(err)
+		}
+		if err = gothic.StoreUser(c.Response(), req, user); err != nil {
+			return err
+		}
+		c.Set(UserKey, user)
+		return next(c)
+	}
+}
+
+// RequireAuth is a middleware putting the signed in user in the context, and responding
+// with a 401 when there is none.
+func RequireAuth(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		user, err := gothic.GetUser(c.Request())
+		if err != nil {
+			return echo.NewHTTPError(http.StatusUnauthorized).SetInternal(err)
+		}
+		c.Set(UserKey, user)
+		return next(c)
+	}
+}
+
+// Logout is a middleware signing the user out before the next handler.
+func Logout(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		if err := gothic.LogoutUser(c.Response(), c.Request()); err != nil {
+			return err
+		}
+		return next(c)
+	}
+}
+
+// User returns the user put in the context by Callback or RequireAuth.
+func User(c echo.Context) (goth.User, bool) {
+	user, ok := c.Get(UserKey).(goth.User)
+	return user, ok
+}
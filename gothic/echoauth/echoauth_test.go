@@ -0,0 +1,92 @@
+package echoauth_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gorilla/sessions"
+	"github.com/labstack/echo/v4"
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/gothic"
+	"github.com/markbates/goth/gothic/echoauth"
+	"github.com/markbates/goth/providers/faux"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	gothic.Store = sessions.NewCookieStore([]byte("secret"))
+	goth.UseProviders(&faux.Provider{})
+}
+
+func server() *echo.Echo {
+	e := echo.New()
+	e.GET("/auth/:provider", echoauth.BeginAuth)
+	e.GET("/auth/:provider/callback", func(c echo.Context) error {
+		user, _ := echoauth.User(c)
+		return c.String(http.StatusOK, user.Provider)
+	}, echoauth.Callback)
+	e.GET("/logout", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	}, echoauth.Logout)
+	e.GET("/me", func(c echo.Context) error {
+		user, _ := echoauth.User(c)
+		return c.String(http.StatusOK, user.UserID)
+	}, echoauth.RequireAuth)
+	return e
+}
+
+func serve(h http.Handler, target string, cookies []*http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", target, nil)
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	res := httptest.NewRecorder()
+	h.ServeHTTP(res, req)
+	return res
+}
+
+func Test_Auth(t *testing.T) {
+	a := assert.New(t)
+	e := server()
+
+	res := serve(e, "/me", nil)
+	a.Equal(http.StatusUnauthorized, res.Code)
+
+	res = serve(e, "/auth/faux", nil)
+	a.Equal(http.StatusTemporaryRedirect, res.Code)
+	location, err := url.Parse(res.Header().Get("Location"))
+	a.NoError(err)
+	a.Equal("example.com", location.Host)
+
+	res = serve(e, "/auth/faux/callback?state="+url.QueryEscape(location.Query().Get("state")), res.Result().Cookies())
+	a.Equal(http.StatusOK, res.Code)
+	a.Equal("faux", res.Body.String())
+
+	var userCookie *http.Cookie
+	for _, c := range res.Result().Cookies() {
+		if c.Name == gothic.UserSessionName {
+			userCookie = c
+		}
+	}
+	a.NotNil(userCookie)
+	res = serve(e, "/me", []*http.Cookie{userCookie})
+	a.Equal(http.StatusOK, res.Code)
+	a.Equal("id", res.Body.String())
+
+	res = serve(e, "/logout", []*http.Cookie{userCookie})
+	a.Equal(http.StatusOK, res.Code)
+	a.Equal(-1, res.Result().Cookies()[0].MaxAge)
+}
+
+func Test_Errors(t *testing.T) {
+	a := assert.New(t)
+	e := server()
+
+	res := serve(e, "/auth/faux/callback", nil)
+	a.Equal(http.StatusUnauthorized, res.Code)
+
+	res = serve(e, "/auth/unknown", nil)
+	a.Equal(http.StatusBadRequest, res.Code)
+}
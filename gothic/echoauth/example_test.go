@@ -0,0 +1,32 @@
+package echoauth_test
+
+import (
+	"net/http"
+	"os"
+
+	"github.com/labstack/echo/v4"
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/gothic/echoauth"
+	"github.com/markbates/goth/providers/github"
+)
+
+func Example() {
+	goth.UseProviders(github.New(os.Getenv("GITHUB_KEY"), os.Getenv("GITHUB_SECRET"), "http://localhost:3000/auth/github/callback"))
+
+	e := echo.New()
+	e.GET("/auth/:provider", echoauth.BeginAuth)
+	e.GET("/auth/:provider/callback", func(c echo.Context) error {
+		return c.Redirect(http.StatusFound, "/")
+	}, echoauth.Callback)
+	e.GET("/logout", func(c echo.Context) error {
+		return c.Redirect(http.StatusFound, "/")
+	}, echoauth.Logout)
+
+	private := e.Group("", echoauth.RequireAuth)
+	private.GET("/", func(c echo.Context) error {
+		user, _ := echoauth.User(c)
+		return c.String(http.StatusOK, "Hello "+user.Name)
+	})
+
+	e.Logger.Fatal(e.Start(":3000"))
+}
@@ -0,0 +1,168 @@
+/*
+Package fiberauth provides Fiber handlers for gothic, reading the name of the provider
+from the path parameters, e.g. "/auth/:provider", and putting the goth.User in the
+locals of the fiber.Ctx.
+
+	app := fiber.New()
+	app.Get("/auth/:provider", fiberauth.BeginAuth)
+	app.Get("/auth/:provider/callback", fiberauth.Callback, func(c *fiber.Ctx) error {
+		return c.Redirect("/")
+	})
+	app.Get("/logout", fiberauth.Logout, func(c *fiber.Ctx) error {
+		return c.Redirect("/")
+	})
+
+	private := app.Group("/", fiberauth.RequireAuth)
+	private.Get("/me", func(c *fiber.Ctx) error {
+		user, _ := fiberauth.User(c)
+		return c.JSON(user)
+	})
+
+gothic works with the net/http package, the fasthttp requests are copied to a
+net/http request and the headers set by gothic, e.g. its cookies, are copied to the
+fasthttp response. The users are signed in with gothic.StoreUser, which keeps their
+profile in the gothic.Store.
+*/
+package fiberauth
+
+import (
+	"bytes"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/gothic"
+)
+
+// UserKey is the key of the goth.User in the locals of the fiber.Ctx.
+const UserKey = "goth_user"
+
+// ProviderParam is the name of the path parameter holding the name of the provider.
+var ProviderParam = "provider"
+
+// request copies the fasthttp request of the context to a net/http request, with the
+// name of the provider of the path in its context. Unlike the fasthttp requests, it
+// can be kept after the handler returns, e.g. by the registry of gorilla/sessions.
+func request(c *fiber.Ctx) (*http.Request, error) {
+	body := append([]byte(nil), c.Body()...)
+	req, err := http.NewRequest(c.Method(), string(c.Request().URI().FullURI()), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	c.Request().Header.VisitAll(func(key, value []byte) {
+		req.Header.Add(string(key), string(value))
+	})
+	req.Host = string(c.Request().Host())
+	req.RemoteAddr = c.Context().RemoteAddr().String()
+	req = req.WithContext(c.UserContext())
+	return gothic.GetContextWithProvider(req, strings.Clone(c.Params(ProviderParam))), nil
+}
+
+// responseWriter is the http.ResponseWriter given to gothic, writing to the fasthttp
+// response of the context.
+type responseWriter struct {
+	c      *fiber.Ctx
+	header http.Header
+}
+
+func newResponseWriter(c *fiber.Ctx) *responseWriter {
+	return &responseWriter{c: c, header: http.Header{}}
+}
+
+func (w *responseWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *responseWriter) WriteHeader(status int) {
+	w.flush()
+	w.c.Status(status)
+}
+
+func (w *responseWriter) Write(b []byte) (int, error) {
+	w.flush()
+	return w.c.Write(b)
+}
+
+// flush copies the headers set since the last flush to the fasthttp response.
+func (w *responseWriter) flush() {
+	for key, values := range w.header {
+		for _, value := range values {
+			w.c.Response().Header.Add(key, value)
+		}
+	}
+	w.header = http.Header{}
+}
+
+// BeginAuth redirects the user to the provider to authenticate.
+func BeginAuth(c *fiber.Ctx) error {
+	req, err := request(c)
+	if err != nil {
+		return err
+	}
+	w := newResponseWriter(c)
+	url, err := gothic.GetAuthURL(w, req)
+	w.flush()
+	if err != nil {
+		return fiber.NewError(fiber.StatusBadRequest, err.Error())
+	}
+	return c.Redirect(url, fiber.StatusTemporaryRedirect)
+}
+
+// Callback completes the authentication with the provider, signs the user in and puts
+// the user in the locals of the context for the next handler. It responds with a 401
+// when the authentication fails.
+func Callback(c *fiber.Ctx) error {
+	req, err := request(c)
+	if err != nil {
+		return err
+	}
+	w := newResponseWriter(c)
+	defer w.flush()
+	user, err := gothic.CompleteUserAuth(w, req)
+	if err != nil {
+		return fiber.NewError(fiber.StatusUnauthorized, err.Error())
+	}
+	if err = gothic.StoreUser(w, req, user); err != nil {
+		return err
+	}
+	w.flush()
+	c.Locals(UserKey, user)
+	return c.Next()
+}
+
+// RequireAuth puts the signed in user in the locals of the context for the next
+// handler, and responds with a 401 when there is none.
+func RequireAuth(c *fiber.Ctx) error {
+	req, err := request(c)
+	if err != nil {
+		return err
+	}
+	user, err := gothic.GetUser(req)
+	if err != nil {
+		return fiber.ErrUnauthorized
+	}
+	c.Locals(UserKey, user)
+	return c.Next()
+}
+
+// Logout signs the user out before the next handler.
+func Logout(c *fiber.Ctx) error {
+	req, err := request(c)
+	if err != nil {
+		return err
+	}
+	w := newResponseWriter(c)
+	err = gothic.LogoutUser(w, req)
+	w.flush()
+	if err != nil {
+		return err
+	}
+	return c.Next()
+}
+
+// User returns the user put in the locals of the context by Callback or RequireAuth.
+func User(c *fiber.Ctx) (goth.User, bool) {
+	user, ok := c.Locals(UserKey).(goth.User)
+	return user, ok
+}
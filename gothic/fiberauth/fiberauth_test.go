@@ -0,0 +1,101 @@
+package fiberauth_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gorilla/sessions"
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/gothic"
+	"github.com/markbates/goth/gothic/fiberauth"
+	"github.com/markbates/goth/providers/faux"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	gothic.Store = sessions.NewCookieStore([]byte("secret"))
+	goth.UseProviders(&faux.Provider{})
+}
+
+func app() *fiber.App {
+	app := fiber.New()
+	app.Get("/auth/:provider", fiberauth.BeginAuth)
+	app.Get("/auth/:provider/callback", fiberauth.Callback, func(c *fiber.Ctx) error {
+		user, _ := fiberauth.User(c)
+		return c.SendString(user.Provider)
+	})
+	app.Get("/logout", fiberauth.Logout, func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusOK)
+	})
+	app.Get("/me", fiberauth.RequireAuth, func(c *fiber.Ctx) error {
+		user, _ := fiberauth.User(c)
+		return c.SendString(user.UserID)
+	})
+	return app
+}
+
+func serve(t *testing.T, app *fiber.App, target string, cookies []*http.Cookie) (*http.Response, string) {
+	req := httptest.NewRequest("GET", target, nil)
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	res, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res, string(body)
+}
+
+func Test_Auth(t *testing.T) {
+	a := assert.New(t)
+	app := app()
+
+	res, _ := serve(t, app, "/me", nil)
+	a.Equal(http.StatusUnauthorized, res.StatusCode)
+
+	res, _ = serve(t, app, "/auth/faux", nil)
+	a.Equal(http.StatusTemporaryRedirect, res.StatusCode)
+	location, err := url.Parse(res.Header.Get("Location"))
+	a.NoError(err)
+	a.Equal("example.com", location.Host)
+
+	res, body := serve(t, app, "/auth/faux/callback?state="+url.QueryEscape(location.Query().Get("state")), res.Cookies())
+	a.Equal(http.StatusOK, res.StatusCode)
+	a.Equal("faux", body)
+
+	var userCookie *http.Cookie
+	for _, c := range res.Cookies() {
+		if c.Name == gothic.UserSessionName {
+			userCookie = c
+		}
+	}
+	a.NotNil(userCookie)
+	res, body = serve(t, app, "/me", []*http.Cookie{userCookie})
+	a.Equal(http.StatusOK, res.StatusCode)
+	a.Equal("id", body)
+
+	res, _ = serve(t, app, "/logout", []*http.Cookie{userCookie})
+	a.Equal(http.StatusOK, res.StatusCode)
+	a.Equal(gothic.UserSessionName, res.Cookies()[0].Name)
+	a.Equal(-1, res.Cookies()[0].MaxAge)
+}
+
+func Test_Errors(t *testing.T) {
+	a := assert.New(t)
+	app := app()
+
+	res, _ := serve(t, app, "/auth/faux/callback", nil)
+	a.Equal(http.StatusUnauthorized, res.StatusCode)
+
+	res, _ = serve(t, app, "/auth/unknown", nil)
+	a.Equal(http.StatusBadRequest, res.StatusCode)
+}
@@ -0,0 +1,92 @@
+/*
+Package ginauth provides gin handlers for gothic, reading the name of the provider from
+the path parameters, e.g. "/auth/:provider", and putting the goth.User in the keys of
+the gin.Context.
+
+	r := gin.Default()
+	r.GET("/auth/:provider", ginauth.BeginAuth)
+	r.GET("/auth/:provider/callback", ginauth.Callback, func(c *gin.Context) {
+		c.Redirect(http.StatusFound, "/")
+	})
+	r.GET("/logout", ginauth.Logout)
+
+	private := r.Group("/", ginauth.RequireAuth)
+	private.GET("/me", func(c *gin.Context) {
+		user, _ := ginauth.User(c)
+		c.JSON(http.StatusOK, user)
+	})
+
+The users are signed in with gothic.StoreUser, which keeps their profile in the
+gothic.Store.
+*/
+package ginauth
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/gothic"
+)
+
+// UserKey is the key of the goth.User in the keys of the gin.Context.
+const UserKey = "goth_user"
+
+// ProviderParam is the name of the path parameter holding the name of the provider.
+var ProviderParam = "provider"
+
+// withProvider puts the name of the provider of the path in the context of the request.
+func withProvider(c *gin.Context) *http.Request {
+	c.Request = gothic.GetContextWithProvider(c.Request, c.Param(ProviderParam))
+	return c.Request
+}
+
+// BeginAuth redirects the user to the provider to authenticate.
+func BeginAuth(c *gin.Context) {
+	gothic.BeginAuthHandler(c.Writer, withProvider(c))
+}
+
+// Callback completes the authentication with the provider, signs the user in and puts
+// the user in the keys of the context for the next handlers. It aborts with a 401 when
+// the authentication fails.
+func Callback(c *gin.Context) {
+	req := withProvider(c)
+	user, err := gothic.CompleteUserAuth(c.Writer, req)
+	if err != nil {
+		c.AbortWithError(http.StatusUnauthorized, err)
+		return
+	}
+	if err = gothic.StoreUser(c.Writer, req, user); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+		return
+	}
+	c.Set(UserKey, user)
+}
+
+// RequireAuth is a middleware putting the signed in user in the keys of the context,
+// and aborting with a 401 when there is none.
+func RequireAuth(c *gin.Context) {
+	user, err := gothic.GetUser(c.Request)
+	if err != nil {
+		c.AbortWithStatus(http.StatusUnauthorized)
+		return
+	}
+	c.Set(UserKey, user)
+}
+
+// Logout signs the user out.
+func Logout(c *gin.Context) {
+	if err := gothic.LogoutUser(c.Writer, c.Request); err != nil {
+		c.AbortWithError(http.StatusInternalServerError, err)
+	}
+}
+
+// User returns the user put in the keys of the context by Callback or RequireAuth.
+func User(c *gin.Context) (goth.User, bool) {
+	user, ok := c.Get(UserKey)
+	if !ok {
+		return goth.User{}, false
+	}
+	u, ok := user.(goth.User)
+	return u, ok
+}
@@ -0,0 +1,87 @@
+package ginauth_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/sessions"
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/gothic"
+	"github.com/markbates/goth/gothic/ginauth"
+	"github.com/markbates/goth/providers/faux"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+	gothic.Store = sessions.NewCookieStore([]byte("secret"))
+	goth.UseProviders(&faux.Provider{})
+}
+
+func router() *gin.Engine {
+	r := gin.New()
+	r.GET("/auth/:provider", ginauth.BeginAuth)
+	r.GET("/auth/:provider/callback", ginauth.Callback, func(c *gin.Context) {
+		user, _ := ginauth.User(c)
+		c.String(http.StatusOK, user.Provider)
+	})
+	r.GET("/logout", ginauth.Logout)
+	r.GET("/me", ginauth.RequireAuth, func(c *gin.Context) {
+		user, _ := ginauth.User(c)
+		c.String(http.StatusOK, user.UserID)
+	})
+	return r
+}
+
+func serve(r http.Handler, target string, cookies []*http.Cookie) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", target, nil)
+	for _, c := range cookies {
+		req.AddCookie(c)
+	}
+	res := httptest.NewRecorder()
+	r.ServeHTTP(res, req)
+	return res
+}
+
+func Test_Auth(t *testing.T) {
+	a := assert.New(t)
+	r := router()
+
+	res := serve(r, "/me", nil)
+	a.Equal(http.StatusUnauthorized, res.Code)
+
+	res = serve(r, "/auth/faux", nil)
+	a.Equal(http.StatusTemporaryRedirect, res.Code)
+	location, err := url.Parse(res.Header().Get("Location"))
+	a.NoError(err)
+	a.Equal("example.com", location.Host)
+
+	res = serve(r, "/auth/faux/callback?state="+url.QueryEscape(location.Query().Get("state")), res.Result().Cookies())
+	a.Equal(http.StatusOK, res.Code)
+	a.Equal("faux", res.Body.String())
+
+	var userCookie *http.Cookie
+	for _, c := range res.Result().Cookies() {
+		if c.Name == gothic.UserSessionName {
+			userCookie = c
+		}
+	}
+	a.NotNil(userCookie)
+	res = serve(r, "/me", []*http.Cookie{userCookie})
+	a.Equal(http.StatusOK, res.Code)
+	a.Equal("id", res.Body.String())
+
+	res = serve(r, "/logout", []*http.Cookie{userCookie})
+	a.Equal(http.StatusOK, res.Code)
+	a.Equal(-1, res.Result().Cookies()[0].MaxAge)
+}
+
+func Test_Callback_Error(t *testing.T) {
+	a := assert.New(t)
+
+	res := serve(router(), "/auth/faux/callback", nil)
+	a.Equal(http.StatusUnauthorized, res.Code)
+}
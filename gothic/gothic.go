@@ -13,6 +13,7 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
@@ -25,6 +26,7 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/gorilla/sessions"
 	"github.com/markbates/goth"
+	"github.com/markbates/goth/store"
 )
 
 // SessionName is the key used to access the session store.
@@ -34,6 +36,26 @@ const SessionName = "_gothic_session"
 var Store sessions.Store
 var defaultStore sessions.Store
 
+// TokenStore can be set by applications to keep the tokens of the users once they are
+// authenticated by CompleteUserAuth, e.g. for background jobs. The default is none.
+var TokenStore store.TokenStore
+
+// MinimizeSessions can be set by applications to keep the sessions once the users are
+// authorized by CompleteUserAuth, e.g. to call the provider with the session later,
+// instead of deleting them. They are shrunk as they may exceed the size limit of the
+// cookies, see MinimizeSession, and their tokens are stripped when they are saved into
+// the TokenStore, see TokenSessionFields. The default is false.
+var MinimizeSessions = false
+
+// TransientSessionFields are the fields of the sessions of the providers stripped by
+// MinimizeSessions, as they are not needed anymore once the users are authorized.
+var TransientSessionFields = []string{"CodeVerifier", "RequestToken"}
+
+// TokenSessionFields are the fields of the sessions of the providers holding their
+// tokens, stripped by MinimizeSessions when the tokens are saved into the TokenStore so
+// that they are not kept in the cookies. The short ones are those of azureadv2.
+var TokenSessionFields = []string{"AccessToken", "RefreshToken", "IDToken", "at", "rt", "idt"}
+
 var keySet = false
 
 type key int
@@ -130,7 +152,12 @@ func GetAuthURL(res http.ResponseWriter, req *http.Request) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	sess, err := provider.BeginAuth(SetState(req))
+	var sess goth.Session
+	if pp, ok := provider.(goth.ParamsProvider); ok {
+		sess, err = pp.BeginAuthWithParams(SetState(req), req.URL.Query())
+	} else {
+		sess, err = provider.BeginAuth(SetState(req))
+	}
 	if err != nil {
 		return "", err
 	}
@@ -140,7 +167,7 @@ func GetAuthURL(res http.ResponseWriter, req *http.Request) (string, error) {
 		return "", err
 	}
 
-	err = StoreInSession(providerName, sess.Marshal(), req, res)
+	err = StoreInSession(providerName, goth.MarshalSession(sess), req, res)
 
 	if err != nil {
 		return "", err
@@ -173,12 +200,19 @@ var CompleteUserAuth = func(res http.ResponseWriter, req *http.Request) (goth.Us
 		return goth.User{}, err
 	}
 
-	value, err := GetFromSession(providerName, req)
+	value, err := getFromSession(providerName, req)
 	if err != nil {
 		return goth.User{}, err
 	}
-	defer Logout(res, req)
-	sess, err := provider.UnmarshalSession(value)
+	// the session is kept once the user is authorized with MinimizeSessions, otherwise
+	// it is deleted
+	keepSession := false
+	defer func() {
+		if !keepSession {
+			Logout(res, req)
+		}
+	}()
+	sess, err := goth.UnmarshalSession(provider, value)
 	if err != nil {
 		return goth.User{}, err
 	}
@@ -191,6 +225,7 @@ var CompleteUserAuth = func(res http.ResponseWriter, req *http.Request) (goth.Us
 	user, err := provider.FetchUser(sess)
 	if err == nil {
 		// user can be found with existing session data
+		keepSession, err = completeSession(res, req, providerName, sess, user)
 		return user, err
 	}
 
@@ -206,16 +241,99 @@ var CompleteUserAuth = func(res http.ResponseWriter, req *http.Request) (goth.Us
 		return goth.User{}, err
 	}
 
-	err = StoreInSession(providerName, sess.Marshal(), req, res)
-
+	gu, err := provider.FetchUser(sess)
 	if err != nil {
-		return goth.User{}, err
+		return gu, err
 	}
-
-	gu, err := provider.FetchUser(sess)
+	keepSession, err = completeSession(res, req, providerName, sess, gu)
 	return gu, err
 }
 
+// completeSession saves the token of the authorized user into the TokenStore and, with
+// MinimizeSessions, stores the minimized session of the provider without the tokens
+// saved. It tells whether the session is kept.
+func completeSession(res http.ResponseWriter, req *http.Request, providerName string, sess goth.Session, user goth.User) (bool, error) {
+	if err := saveToken(req, user); err != nil {
+		return false, err
+	}
+	if !MinimizeSessions {
+		return false, nil
+	}
+
+	value := MinimizeSession(goth.MarshalSession(sess))
+	if TokenStore != nil {
+		value = editSession(value, func(fields map[string]json.RawMessage) {
+			for _, field := range TokenSessionFields {
+				delete(fields, field)
+			}
+		})
+	}
+	if err := StoreInSession(providerName, value, req, res); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// saveToken saves the token of the user into the TokenStore, if any.
+func saveToken(req *http.Request, user goth.User) error {
+	if TokenStore == nil {
+		return nil
+	}
+	return TokenStore.Save(req.Context(), store.TokenFromUser(user))
+}
+
+// MinimizeSession strips the fields of a marshaled session which are only needed to
+// begin the authentication (see TransientSessionFields) and its empty fields, and
+// reduces its AuthURL to the state validating the callbacks. It is meant for the
+// sessions which are authorized, before storing them with StoreInSession, which gzips
+// them. The version of the sessions marshaled by goth.MarshalSession is kept. The
+// sessions which are not JSON objects are returned as is.
+func MinimizeSession(value string) string {
+	return editSession(value, func(fields map[string]json.RawMessage) {
+		for _, field := range TransientSessionFields {
+			delete(fields, field)
+		}
+		for field, raw := range fields {
+			switch string(raw) {
+			case `""`, "null", "false", "0", "{}", "[]", `"0001-01-01T00:00:00Z"`:
+				delete(fields, field)
+			}
+		}
+
+		var rawAuthURL string
+		if err := json.Unmarshal(fields["AuthURL"], &rawAuthURL); err == nil {
+			if authURL, err := url.Parse(rawAuthURL); err == nil {
+				// validateState only needs the state
+				state := url.Values{}
+				if s := authURL.Query().Get("state"); s != "" {
+					state.Set("state", s)
+				}
+				fields["AuthURL"], _ = json.Marshal("?" + state.Encode())
+			}
+		}
+	})
+}
+
+// editSession edits the fields of a marshaled session, keeping its version. The
+// sessions which are not JSON objects are returned as is.
+func editSession(value string, edit func(fields map[string]json.RawMessage)) string {
+	version, session := goth.ParseSessionVersion(value)
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal([]byte(session), &fields); err != nil {
+		return value
+	}
+	edit(fields)
+
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return value
+	}
+	if version > 0 {
+		return fmt.Sprintf("v%d:%s", version, b)
+	}
+	return string(b)
+}
+
 // validateState ensures that the state token param from the original
 // AuthURL matches the one included in the current (callback) request.
 func validateState(req *http.Request, sess goth.Session) error {
@@ -320,7 +438,23 @@ func StoreInSession(key string, value string, req *http.Request, res http.Respon
 
 // GetFromSession retrieves a previously-stored value from the session.
 // If no value has previously been stored at the specified key, it will return an error.
+// The sessions of the providers are migrated to the current version and returned as
+// marshaled by their Marshal method, so that they can be passed to the UnmarshalSession
+// method of the provider, see goth.StripSessionVersion.
 func GetFromSession(key string, req *http.Request) (string, error) {
+	value, err := getFromSession(key, req)
+	if err != nil {
+		return "", err
+	}
+	if _, err = goth.GetProvider(key); err != nil {
+		return value, nil
+	}
+	return goth.StripSessionVersion(key, value)
+}
+
+// getFromSession retrieves a value from the session as it is stored, with the version
+// of the sessions of the providers.
+func getFromSession(key string, req *http.Request) (string, error) {
 	session, _ := Store.Get(req, SessionName)
 	value, err := getSessionValue(session, key)
 	if err != nil {
@@ -330,6 +464,34 @@ func GetFromSession(key string, req *http.Request) (string, error) {
 	return value, nil
 }
 
+// MigrateSession upgrades the sessions of the providers in use kept in the session to
+// the current version of goth, see goth.MigrateSession. It returns if any has changed,
+// the session then needs to be saved.
+func MigrateSession(session *sessions.Session) (bool, error) {
+	changed := false
+	for name := range goth.GetProviders() {
+		if _, ok := session.Values[name].(string); !ok {
+			continue
+		}
+		value, err := getSessionValue(session, name)
+		if err != nil {
+			return changed, err
+		}
+		value, migrated, err := goth.MigrateSession(name, value)
+		if err != nil {
+			return changed, err
+		}
+		if !migrated {
+			continue
+		}
+		if err = updateSessionValue(session, name, value); err != nil {
+			return changed, err
+		}
+		changed = true
+	}
+	return changed, nil
+}
+
 func getSessionValue(session *sessions.Session, key string) (string, error) {
 	value := session.Values[key]
 	if value == nil {
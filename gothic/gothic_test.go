@@ -16,6 +16,7 @@ import (
 	"github.com/markbates/goth"
 	. "github.com/markbates/goth/gothic"
 	"github.com/markbates/goth/providers/faux"
+	"github.com/markbates/goth/store"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -83,7 +84,7 @@ func Test_BeginAuthHandler(t *testing.T) {
 		t.Fatalf("Gothic session not stored as marshalled string; was %T (value %v)",
 			sess.Values["faux"], sess.Values["faux"])
 	}
-	gothSession, err := fauxProvider.UnmarshalSession(ungzipString(sessStr))
+	gothSession, err := goth.UnmarshalSession(fauxProvider, ungzipString(sessStr))
 	if err != nil {
 		t.Fatalf("error unmarshalling faux Gothic session: %v", err)
 	}
@@ -125,6 +126,35 @@ func Test_GetAuthURL(t *testing.T) {
 	a.NotEqual(parsed.Query().Get("state"), parsed2.Query().Get("state"))
 }
 
+// paramsProvider is a faux provider beginning the authentication with the parameters
+// of the request.
+type paramsProvider struct {
+	faux.Provider
+}
+
+func (p *paramsProvider) Name() string {
+	return "faux-params"
+}
+
+func (p *paramsProvider) BeginAuthWithParams(state string, params goth.Params) (goth.Session, error) {
+	return &faux.Session{AuthURL: "http://example.com/auth?state=" + state + "&shop=" + params.Get("shop")}, nil
+}
+
+func Test_GetAuthURLWithParamsProvider(t *testing.T) {
+	a := assert.New(t)
+
+	goth.UseProviders(&paramsProvider{})
+	res := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/auth?provider=faux-params&shop=other-shop", nil)
+	a.NoError(err)
+
+	u, err := GetAuthURL(res, req)
+	a.NoError(err)
+	parsed, err := url.Parse(u)
+	a.NoError(err)
+	a.Equal("other-shop", parsed.Query().Get("shop"))
+}
+
 func Test_CompleteUserAuth(t *testing.T) {
 	a := assert.New(t)
 
@@ -145,6 +175,30 @@ func Test_CompleteUserAuth(t *testing.T) {
 	a.Equal(user.Email, "homer@example.com")
 }
 
+func Test_CompleteUserAuthWithTokenStore(t *testing.T) {
+	a := assert.New(t)
+
+	TokenStore = store.NewMemoryStore()
+	defer func() { TokenStore = nil }()
+
+	res := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/auth/callback?provider=faux", nil)
+	a.NoError(err)
+
+	sess := faux.Session{ID: "42", Name: "Homer Simpson", AccessToken: "access"}
+	session, _ := Store.Get(req, SessionName)
+	session.Values["faux"] = gzipString(sess.Marshal())
+	err = session.Save(req, res)
+	a.NoError(err)
+
+	_, err = CompleteUserAuth(res, req)
+	a.NoError(err)
+
+	token, err := TokenStore.Load(req.Context(), "faux", "42")
+	a.NoError(err)
+	a.Equal("access", token.AccessToken)
+}
+
 func Test_CompleteUserAuthWithSessionDeducedProvider(t *testing.T) {
 	a := assert.New(t)
 
@@ -213,6 +267,33 @@ func Test_Logout(t *testing.T) {
 	a.Equal(session.Options.MaxAge, -1)
 }
 
+func Test_GetFromSession(t *testing.T) {
+	a := assert.New(t)
+
+	res := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/auth/callback?provider=faux", nil)
+	a.NoError(err)
+	sess := faux.Session{ID: "id", AccessToken: "access"}
+
+	// the sessions of the providers are returned as marshaled by them, whether they
+	// have been stored with their version or before the versions were introduced
+	for _, stored := range []string{goth.MarshalSession(&sess), sess.Marshal()} {
+		a.NoError(StoreInSession("faux", stored, req, res))
+		value, err := GetFromSession("faux", req)
+		a.NoError(err)
+		a.Equal(sess.Marshal(), value)
+		s, err := fauxProvider.UnmarshalSession(value)
+		a.NoError(err)
+		a.Equal("access", s.(*faux.Session).AccessToken)
+	}
+
+	// the other values are returned as is
+	a.NoError(StoreInSession("other", "v1:not a session", req, res))
+	value, err := GetFromSession("other", req)
+	a.NoError(err)
+	a.Equal("v1:not a session", value)
+}
+
 func Test_SetState(t *testing.T) {
 	a := assert.New(t)
 
@@ -251,6 +332,196 @@ func Test_StateValidation(t *testing.T) {
 	a.Error(err)
 }
 
+func Test_MinimizeSession(t *testing.T) {
+	a := assert.New(t)
+
+	sess := faux.Session{ID: "id", AuthURL: "http://example.com/auth?client_id=key&state=state_REAL", AccessToken: "access"}
+	value := MinimizeSession(sess.Marshal())
+	a.Equal(`{"AccessToken":"access","AuthURL":"?state=state_REAL","ID":"id"}`, value)
+	a.Equal("not json", MinimizeSession("not json"))
+
+	// the minimized sessions are still valid
+	Store = NewProviderStore()
+	res := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/auth/callback?provider=faux&state=state_REAL", nil)
+	a.NoError(err)
+	a.NoError(StoreInSession("faux", value, req, res))
+	user, err := CompleteUserAuth(res, req)
+	a.NoError(err)
+	a.Equal("id", user.UserID)
+}
+
+func Test_CompleteUserAuthWithMinimizeSessions(t *testing.T) {
+	a := assert.New(t)
+
+	Store = sessions.NewCookieStore([]byte("secret"))
+	MinimizeSessions = true
+	defer func() {
+		Store = NewProviderStore()
+		MinimizeSessions = false
+	}()
+
+	res := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/auth?provider=faux", nil)
+	a.NoError(err)
+	authURL, err := GetAuthURL(res, req)
+	a.NoError(err)
+	parsed, err := url.Parse(authURL)
+	a.NoError(err)
+
+	// the faux session has no token yet, it is authorized by the callback
+	res2 := httptest.NewRecorder()
+	req, err = http.NewRequest("GET", "/auth/callback?provider=faux&state="+url.QueryEscape(parsed.Query().Get("state")), nil)
+	a.NoError(err)
+	req.AddCookie(res.Result().Cookies()[0])
+	user, err := CompleteUserAuth(res2, req)
+	a.NoError(err)
+	a.Equal("access", user.AccessToken)
+
+	cookies := res2.Result().Cookies()
+	a.NotEqual(-1, cookies[len(cookies)-1].MaxAge)
+	req, err = http.NewRequest("GET", "/", nil)
+	a.NoError(err)
+	req.AddCookie(cookies[len(cookies)-1])
+	value, err := GetFromSession("faux", req)
+	a.NoError(err)
+	a.NotContains(value, "example.com")
+
+	full := goth.MarshalSession(&faux.Session{ID: "id", AuthURL: authURL, AccessToken: "access"})
+	a.Less(len(value), len(full))
+	sess, err := goth.UnmarshalSession(fauxProvider, value)
+	a.NoError(err)
+	a.Equal("access", sess.(*faux.Session).AccessToken)
+}
+
+func Test_CompleteUserAuthWithMinimizeSessionsAndTokenStore(t *testing.T) {
+	a := assert.New(t)
+
+	Store = sessions.NewCookieStore([]byte("secret"))
+	TokenStore = store.NewMemoryStore()
+	MinimizeSessions = true
+	defer func() {
+		Store = NewProviderStore()
+		TokenStore = nil
+		MinimizeSessions = false
+	}()
+
+	res := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/auth?provider=faux", nil)
+	a.NoError(err)
+	authURL, err := GetAuthURL(res, req)
+	a.NoError(err)
+	parsed, err := url.Parse(authURL)
+	a.NoError(err)
+
+	res2 := httptest.NewRecorder()
+	req, err = http.NewRequest("GET", "/auth/callback?provider=faux&state="+url.QueryEscape(parsed.Query().Get("state")), nil)
+	a.NoError(err)
+	req.AddCookie(res.Result().Cookies()[0])
+	_, err = CompleteUserAuth(res2, req)
+	a.NoError(err)
+
+	// the token is saved into the TokenStore instead of the cookie
+	token, err := TokenStore.Load(req.Context(), "faux", "id")
+	a.NoError(err)
+	a.Equal("access", token.AccessToken)
+	cookies := res2.Result().Cookies()
+	req, err = http.NewRequest("GET", "/", nil)
+	a.NoError(err)
+	req.AddCookie(cookies[len(cookies)-1])
+	value, err := GetFromSession("faux", req)
+	a.NoError(err)
+	a.NotContains(value, "access")
+	a.Contains(value, "state")
+}
+
+func Test_CompleteUserAuthWithMinimizeSessionsAndExistingToken(t *testing.T) {
+	a := assert.New(t)
+
+	Store = sessions.NewCookieStore([]byte("secret"))
+	TokenStore = store.NewMemoryStore()
+	MinimizeSessions = true
+	defer func() {
+		Store = NewProviderStore()
+		TokenStore = nil
+		MinimizeSessions = false
+	}()
+
+	// the user is fetched with the token of the session, without authorizing it
+	res := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/auth/callback?provider=faux&state=state_REAL", nil)
+	a.NoError(err)
+	sess := faux.Session{ID: "42", AuthURL: "http://example.com/auth?state=state_REAL", AccessToken: "existing"}
+	a.NoError(StoreInSession("faux", sess.Marshal(), req, res))
+	req.AddCookie(res.Result().Cookies()[0])
+
+	res2 := httptest.NewRecorder()
+	user, err := CompleteUserAuth(res2, req)
+	a.NoError(err)
+	a.Equal("existing", user.AccessToken)
+
+	token, err := TokenStore.Load(req.Context(), "faux", "42")
+	a.NoError(err)
+	a.Equal("existing", token.AccessToken)
+	cookies := res2.Result().Cookies()
+	a.NotEqual(-1, cookies[len(cookies)-1].MaxAge)
+	req, err = http.NewRequest("GET", "/", nil)
+	a.NoError(err)
+	req.AddCookie(cookies[len(cookies)-1])
+	value, err := GetFromSession("faux", req)
+	a.NoError(err)
+	a.Contains(value, `"ID":"42"`)
+	a.NotContains(value, "existing")
+}
+
+func Test_MigrateSession(t *testing.T) {
+	a := assert.New(t)
+
+	session := sessions.NewSession(Store, SessionName)
+	session.Values["faux"] = gzipString(`{"ID":"id"}`)
+	session.Values["other"] = "not a session of a provider"
+
+	changed, err := MigrateSession(session)
+	a.NoError(err)
+	a.True(changed)
+	a.Equal(fmt.Sprintf(`v%d:{"ID":"id"}`, goth.SessionVersion), ungzipString(session.Values["faux"].(string)))
+	a.Equal("not a session of a provider", session.Values["other"])
+
+	changed, err = MigrateSession(session)
+	a.NoError(err)
+	a.False(changed)
+}
+
+func Test_StoreUser(t *testing.T) {
+	a := assert.New(t)
+
+	Store = NewProviderStore()
+	res := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/", nil)
+	a.NoError(err)
+
+	_, err = GetUser(req)
+	a.Equal(ErrNoUser, err)
+
+	a.NoError(StoreUser(res, req, goth.User{
+		Provider:    "faux",
+		UserID:      "42",
+		Name:        "Homer Simpson",
+		AccessToken: "access",
+		RawData:     map[string]interface{}{"id": 42},
+	}))
+	user, err := GetUser(req)
+	a.NoError(err)
+	a.Equal("42", user.UserID)
+	a.Equal("Homer Simpson", user.Name)
+	a.Empty(user.AccessToken)
+	a.Nil(user.RawData)
+
+	a.NoError(LogoutUser(res, req))
+	_, err = GetUser(req)
+	a.Equal(ErrNoUser, err)
+}
+
 func Test_AppleStateValidation(t *testing.T) {
 	a := assert.New(t)
 	appleStateValue := "xyz123-#"
@@ -0,0 +1,538 @@
+/*
+Package stdgothic is a variant of gothic only depending on the standard library, besides
+goth itself, for the applications which cannot depend on gorilla/sessions and
+gorilla/mux. It has the same functions as gothic, except MigrateSession, MinimizeSession
+and the Store of gorilla/sessions, and its TokenStore takes the users instead of
+store.Token, see TokenSaver.
+
+The sessions are kept in cookies encrypted with AES-GCM, whose key is derived from Key.
+They can instead be kept on the server by setting Store, the cookies then only hold
+their encrypted ID. The backends of the store package can be used as Store:
+
+	stdgothic.Key = []byte(os.Getenv("SESSION_SECRET"))
+	stdgothic.Store = sqlstore.New(db, sqlstore.Postgres)
+
+The name of the provider is read from the "provider" query parameter, see
+GetProviderName.
+*/
+package stdgothic
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/markbates/goth"
+)
+
+// SessionName is the name of the cookie of the sessions.
+const SessionName = "_gothic_session"
+
+// Key encrypts the cookies of the sessions, it is read from the SESSION_SECRET
+// environment variable by default.
+var Key = []byte(os.Getenv("SESSION_SECRET"))
+
+// Backend keeps the sessions on the server. It has the methods of store.SessionBackend,
+// so that the backends of the store package can be used without importing it. A
+// missing session is reported by an error with a NotFound() bool method returning true,
+// as store.ErrSessionNotFound.
+type Backend interface {
+	// LoadSession returns the encoded values of the session.
+	LoadSession(ctx context.Context, id string) (string, error)
+	// SaveSession saves the encoded values of the session until it expires.
+	SaveSession(ctx context.Context, id, data string, expiresAt time.Time) error
+	// DeleteSession deletes the session, if any.
+	DeleteSession(ctx context.Context, id string) error
+}
+
+// Store can be set by applications to keep the sessions on the server. The default is
+// none, the sessions are kept in the cookies.
+var Store Backend
+
+// CookieOptions are the attributes of the cookies of the sessions.
+type CookieOptions struct {
+	Path     string
+	Domain   string
+	MaxAge   int
+	Secure   bool
+	HttpOnly bool
+	SameSite http.SameSite
+}
+
+// Options are the attributes of the cookies of the sessions, they can be changed at
+// startup, e.g. to set Secure when serving over https.
+var Options = CookieOptions{
+	Path:     "/",
+	MaxAge:   86400 * 30,
+	HttpOnly: true,
+	SameSite: http.SameSiteLaxMode,
+}
+
+// TokenSaver keeps the tokens of the users. The stores of the store package can be used
+// through a TokenSaverFunc:
+//
+//	stdgothic.TokenStore = stdgothic.TokenSaverFunc(func(ctx context.Context, user goth.User) error {
+//		return tokens.Save(ctx, store.TokenFromUser(user))
+//	})
+type TokenSaver interface {
+	// SaveToken saves the token of the user, replacing the previous one if any.
+	SaveToken(ctx context.Context, user goth.User) error
+}
+
+// TokenSaverFunc is a function saving the tokens of the users.
+type TokenSaverFunc func(ctx context.Context, user goth.User) error
+
+// SaveToken calls f(ctx, user).
+func (f TokenSaverFunc) SaveToken(ctx context.Context, user goth.User) error {
+	return f(ctx, user)
+}
+
+// TokenStore can be set by applications to keep the tokens of the users once they are
+// authenticated by CompleteUserAuth, e.g. for background jobs. The default is none.
+var TokenStore TokenSaver
+
+// ErrNoKey is returned when Key is empty.
+var ErrNoKey = errors.New("stdgothic: no SESSION_SECRET environment variable is set, see Key")
+
+// maxCookieSize is the size of the largest cookie the browsers keep.
+const maxCookieSize = 4096
+
+type key int
+
+// ProviderParamKey can be used as a key in context when passing in a provider
+const ProviderParamKey key = iota
+
+/*
+BeginAuthHandler is a convenience handler for starting the authentication process.
+It expects to be able to get the name of the provider from the query parameters
+as either "provider" or ":provider".
+
+BeginAuthHandler will redirect the user to the appropriate authentication end-point
+for the requested provider.
+*/
+func BeginAuthHandler(res http.ResponseWriter, req *http.Request) {
+	url, err := GetAuthURL(res, req)
+	if err != nil {
+		res.WriteHeader(http.StatusBadRequest)
+		fmt.Fprintln(res, err)
+		return
+	}
+
+	http.Redirect(res, req, url, http.StatusTemporaryRedirect)
+}
+
+// SetState sets the state string associated with the given request.
+// If no state string is associated with the request, one will be generated.
+// This state is sent to the provider and can be retrieved during the
+// callback.
+var SetState = func(req *http.Request) string {
+	state := req.URL.Query().Get("state")
+	if len(state) > 0 {
+		return state
+	}
+
+	nonceBytes := make([]byte, 64)
+	_, err := io.ReadFull(rand.Reader, nonceBytes)
+	if err != nil {
+		panic("stdgothic: source of randomness unavailable: " + err.Error())
+	}
+	return base64.URLEncoding.EncodeToString(nonceBytes)
+}
+
+// GetState gets the state returned by the provider during the callback.
+// This is used to prevent CSRF attacks, see
+// http://tools.ietf.org/html/rfc6749#section-10.12
+var GetState = func(req *http.Request) string {
+	params := req.URL.Query()
+	if params.Encode() == "" && req.Method == http.MethodPost {
+		return req.FormValue("state")
+	}
+	return params.Get("state")
+}
+
+// GetAuthURL starts the authentication process with the requested provided.
+// It will return a URL that should be used to send users to.
+func GetAuthURL(res http.ResponseWriter, req *http.Request) (string, error) {
+	providerName, err := GetProviderName(req)
+	if err != nil {
+		return "", err
+	}
+
+	provider, err := goth.GetProvider(providerName)
+	if err != nil {
+		return "", err
+	}
+	var sess goth.Session
+	if pp, ok := provider.(goth.ParamsProvider); ok {
+		sess, err = pp.BeginAuthWithParams(SetState(req), req.URL.Query())
+	} else {
+		sess, err = provider.BeginAuth(SetState(req))
+	}
+	if err != nil {
+		return "", err
+	}
+
+	url, err := sess.GetAuthURL()
+	if err != nil {
+		return "", err
+	}
+
+	err = StoreInSession(providerName, goth.MarshalSession(sess), req, res)
+	if err != nil {
+		return "", err
+	}
+
+	return url, err
+}
+
+// CompleteUserAuth completes the authentication process and fetches all of the basic
+// information about the user from the provider.
+var CompleteUserAuth = func(res http.ResponseWriter, req *http.Request) (goth.User, error) {
+	providerName, err := GetProviderName(req)
+	if err != nil {
+		return goth.User{}, err
+	}
+
+	provider, err := goth.GetProvider(providerName)
+	if err != nil {
+		return goth.User{}, err
+	}
+
+	value, err := getFromSession(providerName, req)
+	if err != nil {
+		return goth.User{}, err
+	}
+	defer Logout(res, req)
+	sess, err := goth.UnmarshalSession(provider, value)
+	if err != nil {
+		return goth.User{}, err
+	}
+
+	err = validateState(req, sess)
+	if err != nil {
+		return goth.User{}, err
+	}
+
+	user, err := provider.FetchUser(sess)
+	if err == nil {
+		// user can be found with existing session data
+		return user, saveToken(req, user)
+	}
+
+	params := req.URL.Query()
+	if params.Encode() == "" && req.Method == http.MethodPost {
+		req.ParseForm()
+		params = req.Form
+	}
+
+	// get new token and retry fetch
+	_, err = sess.Authorize(provider, params)
+	if err != nil {
+		return goth.User{}, err
+	}
+
+	user, err = provider.FetchUser(sess)
+	if err != nil {
+		return user, err
+	}
+	return user, saveToken(req, user)
+}
+
+// saveToken saves the token of the user into the TokenStore, if any.
+func saveToken(req *http.Request, user goth.User) error {
+	if TokenStore == nil {
+		return nil
+	}
+	return TokenStore.SaveToken(req.Context(), user)
+}
+
+// validateState ensures that the state token param from the original
+// AuthURL matches the one included in the current (callback) request.
+func validateState(req *http.Request, sess goth.Session) error {
+	rawAuthURL, err := sess.GetAuthURL()
+	if err != nil {
+		return err
+	}
+
+	authURL, err := url.Parse(rawAuthURL)
+	if err != nil {
+		return err
+	}
+
+	originalState := authURL.Query().Get("state")
+	if originalState != "" && (originalState != GetState(req)) {
+		return errors.New("state token mismatch")
+	}
+	return nil
+}
+
+// Logout invalidates a user session.
+func Logout(res http.ResponseWriter, req *http.Request) error {
+	s, _ := loadSession(req, SessionName)
+	s.values = map[string]string{}
+	return s.save(res, req)
+}
+
+// GetProviderName is a function used to get the name of a provider
+// for a given request. By default, this provider is fetched from
+// the URL query string. If you provide it in a different way,
+// assign your own function to this variable that returns the provider
+// name for your request.
+var GetProviderName = getProviderName
+
+func getProviderName(req *http.Request) (string, error) {
+	if p := req.URL.Query().Get("provider"); p != "" {
+		return p, nil
+	}
+
+	if p := req.URL.Query().Get(":provider"); p != "" {
+		return p, nil
+	}
+
+	if p, ok := req.Context().Value(ProviderParamKey).(string); ok {
+		return p, nil
+	}
+
+	// As a fallback, use the provider of the session of the user, if they have
+	// already begun the authentication
+	s, _ := loadSession(req, SessionName)
+	for name := range goth.GetProviders() {
+		if _, ok := s.values[name]; ok {
+			return name, nil
+		}
+	}
+
+	return "", errors.New("you must select a provider")
+}
+
+// GetContextWithProvider returns a new request context containing the provider
+func GetContextWithProvider(req *http.Request, provider string) *http.Request {
+	return req.WithContext(context.WithValue(req.Context(), ProviderParamKey, provider))
+}
+
+// StoreInSession stores a specified key/value pair in the session.
+func StoreInSession(key string, value string, req *http.Request, res http.ResponseWriter) error {
+	s, _ := loadSession(req, SessionName)
+	s.values[key] = value
+	return s.save(res, req)
+}
+
+// GetFromSession retrieves a previously-stored value from the session.
+// If no value has previously been stored at the specified key, it will return an error.
+// The sessions of the providers are migrated to the current version and returned as
+// marshaled by their Marshal method, so that they can be passed to the UnmarshalSession
+// method of the provider, see goth.StripSessionVersion.
+func GetFromSession(key string, req *http.Request) (string, error) {
+	value, err := getFromSession(key, req)
+	if err != nil {
+		return "", err
+	}
+	if _, err = goth.GetProvider(key); err != nil {
+		return value, nil
+	}
+	return goth.StripSessionVersion(key, value)
+}
+
+// getFromSession retrieves a value from the session as it is stored, with the version
+// of the sessions of the providers.
+func getFromSession(key string, req *http.Request) (string, error) {
+	s, _ := loadSession(req, SessionName)
+	value, ok := s.values[key]
+	if !ok {
+		return "", errors.New("could not find a matching session for this request")
+	}
+	return value, nil
+}
+
+// session is the session of a request.
+type session struct {
+	name   string
+	id     string
+	values map[string]string
+}
+
+// loadSession returns the session of the request with the given name, which is empty
+// when it has no valid session.
+func loadSession(req *http.Request, name string) (*session, error) {
+	s := &session{name: name, values: map[string]string{}}
+	c, err := req.Cookie(name)
+	if err != nil {
+		return s, nil
+	}
+	payload, err := open(name, c.Value)
+	if err != nil {
+		return s, err
+	}
+
+	if Store == nil {
+		payload, err = gunzip(payload)
+		if err != nil {
+			return s, err
+		}
+		return s, json.Unmarshal(payload, &s.values)
+	}
+
+	data, err := Store.LoadSession(req.Context(), string(payload))
+	if notFound, ok := err.(interface{ NotFound() bool }); ok && notFound.NotFound() {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+	s.id = string(payload)
+	return s, json.Unmarshal([]byte(data), &s.values)
+}
+
+// save saves the session and sets its cookie, or deletes them when it is empty.
+func (s *session) save(res http.ResponseWriter, req *http.Request) error {
+	if len(s.values) == 0 {
+		if Store != nil && s.id != "" {
+			if err := Store.DeleteSession(req.Context(), s.id); err != nil {
+				return err
+			}
+		}
+		http.SetCookie(res, cookie(s.name, "", -1))
+		return nil
+	}
+
+	data, err := json.Marshal(s.values)
+	if err != nil {
+		return err
+	}
+	expiresAt := time.Now().Add(time.Duration(Options.MaxAge) * time.Second)
+	var payload []byte
+	if Store == nil {
+		if payload, err = gzipBytes(data); err != nil {
+			return err
+		}
+	} else {
+		if s.id == "" {
+			id := make([]byte, 32)
+			if _, err = io.ReadFull(rand.Reader, id); err != nil {
+				return err
+			}
+			s.id = base64.RawURLEncoding.EncodeToString(id)
+		}
+		if err = Store.SaveSession(req.Context(), s.id, string(data), expiresAt); err != nil {
+			return err
+		}
+		payload = []byte(s.id)
+	}
+
+	value, err := seal(s.name, payload, expiresAt)
+	if err != nil {
+		return err
+	}
+	if len(value) > maxCookieSize {
+		return errors.New("stdgothic: the session is too large for a cookie, see Store")
+	}
+	http.SetCookie(res, cookie(s.name, value, Options.MaxAge))
+	return nil
+}
+
+func cookie(name, value string, maxAge int) *http.Cookie {
+	c := &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     Options.Path,
+		Domain:   Options.Domain,
+		MaxAge:   maxAge,
+		Secure:   Options.Secure,
+		HttpOnly: Options.HttpOnly,
+		SameSite: Options.SameSite,
+	}
+	if maxAge > 0 {
+		c.Expires = time.Now().Add(time.Duration(maxAge) * time.Second)
+	} else {
+		c.Expires = time.Unix(1, 0)
+	}
+	return c
+}
+
+func newGCM() (cipher.AEAD, error) {
+	if len(Key) == 0 {
+		return nil, ErrNoKey
+	}
+	key := sha256.Sum256(Key)
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts the payload with its expiry into the value of the cookie with the
+// given name.
+func seal(name string, payload []byte, expiresAt time.Time) (string, error) {
+	aead, err := newGCM()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	plaintext := make([]byte, 8, 8+len(payload))
+	binary.BigEndian.PutUint64(plaintext, uint64(expiresAt.Unix()))
+	plaintext = append(plaintext, payload...)
+	sealed := aead.Seal(nonce, nonce, plaintext, []byte(name))
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// open decrypts the value of a cookie sealed by seal, which has not expired.
+func open(name, value string) ([]byte, error) {
+	aead, err := newGCM()
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("stdgothic: invalid cookie")
+	}
+	plaintext, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(name))
+	if err != nil || len(plaintext) < 8 {
+		return nil, errors.New("stdgothic: invalid cookie")
+	}
+	if time.Unix(int64(binary.BigEndian.Uint64(plaintext)), 0).Before(time.Now()) {
+		return nil, errors.New("stdgothic: expired cookie")
+	}
+	return plaintext[8:], nil
+}
+
+func gzipBytes(b []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(b); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func gunzip(b []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(r)
+}
@@ -0,0 +1,256 @@
+package stdgothic_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/markbates/goth"
+	. "github.com/markbates/goth/gothic/stdgothic"
+	"github.com/markbates/goth/providers/faux"
+	"github.com/markbates/goth/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	Key = []byte("secret")
+	goth.UseProviders(&faux.Provider{})
+}
+
+// beginAuth begins the authentication and returns the cookie of the session and the
+// state sent to the provider.
+func beginAuth(t *testing.T) (*http.Cookie, string) {
+	a := assert.New(t)
+
+	res := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/auth?provider=faux", nil)
+	BeginAuthHandler(res, req)
+	a.Equal(http.StatusTemporaryRedirect, res.Code)
+
+	u, err := url.Parse(res.Header().Get("Location"))
+	a.NoError(err)
+	cookies := res.Result().Cookies()
+	a.Len(cookies, 1)
+	a.Equal(SessionName, cookies[0].Name)
+	a.True(cookies[0].HttpOnly)
+	return cookies[0], u.Query().Get("state")
+}
+
+func completeUserAuth(cookie *http.Cookie, state string) (goth.User, *httptest.ResponseRecorder, error) {
+	res := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/auth/callback?provider=faux&state="+url.QueryEscape(state), nil)
+	req.AddCookie(cookie)
+	user, err := CompleteUserAuth(res, req)
+	return user, res, err
+}
+
+func Test_CompleteUserAuth(t *testing.T) {
+	a := assert.New(t)
+
+	cookie, state := beginAuth(t)
+	user, res, err := completeUserAuth(cookie, state)
+	a.NoError(err)
+	a.Equal("id", user.UserID)
+	a.Equal("access", user.AccessToken)
+
+	// the session is deleted once the authentication is completed
+	cookies := res.Result().Cookies()
+	a.Equal(-1, cookies[len(cookies)-1].MaxAge)
+}
+
+// paramsProvider is a faux provider beginning the authentication with the parameters
+// of the request.
+type paramsProvider struct {
+	faux.Provider
+}
+
+func (p *paramsProvider) Name() string {
+	return "faux-params"
+}
+
+func (p *paramsProvider) BeginAuthWithParams(state string, params goth.Params) (goth.Session, error) {
+	return &faux.Session{AuthURL: "http://example.com/auth?state=" + state + "&shop=" + params.Get("shop")}, nil
+}
+
+func Test_GetAuthURLWithParamsProvider(t *testing.T) {
+	a := assert.New(t)
+
+	goth.UseProviders(&paramsProvider{})
+	u, err := GetAuthURL(httptest.NewRecorder(), httptest.NewRequest("GET", "/auth?provider=faux-params&shop=other-shop", nil))
+	a.NoError(err)
+	parsed, err := url.Parse(u)
+	a.NoError(err)
+	a.Equal("other-shop", parsed.Query().Get("shop"))
+}
+
+func Test_CompleteUserAuthWithStateMismatch(t *testing.T) {
+	a := assert.New(t)
+
+	cookie, _ := beginAuth(t)
+	_, _, err := completeUserAuth(cookie, "forged")
+	a.Error(err)
+}
+
+func Test_CompleteUserAuthWithTamperedCookie(t *testing.T) {
+	a := assert.New(t)
+
+	cookie, state := beginAuth(t)
+	// flip a character in the middle, the last ones may only hold padding bits
+	i := len(cookie.Value) / 2
+	flipped := byte('A')
+	if cookie.Value[i] == 'A' {
+		flipped = 'B'
+	}
+	cookie.Value = cookie.Value[:i] + string(flipped) + cookie.Value[i+1:]
+	_, _, err := completeUserAuth(cookie, state)
+	a.Error(err)
+}
+
+func Test_CompleteUserAuthWithStore(t *testing.T) {
+	a := assert.New(t)
+
+	Store = store.NewMemoryStore()
+	defer func() { Store = nil }()
+
+	cookie, state := beginAuth(t)
+	user, _, err := completeUserAuth(cookie, state)
+	a.NoError(err)
+	a.Equal("id", user.UserID)
+
+	// the session has been deleted from the store
+	_, _, err = completeUserAuth(cookie, state)
+	a.Error(err)
+}
+
+func Test_CompleteUserAuthWithTokenStore(t *testing.T) {
+	a := assert.New(t)
+
+	tokens := store.NewMemoryStore()
+	TokenStore = TokenSaverFunc(func(ctx context.Context, user goth.User) error {
+		return tokens.Save(ctx, store.TokenFromUser(user))
+	})
+	defer func() { TokenStore = nil }()
+
+	cookie, state := beginAuth(t)
+	_, _, err := completeUserAuth(cookie, state)
+	a.NoError(err)
+
+	token, err := tokens.Load(context.Background(), "faux", "id")
+	a.NoError(err)
+	a.Equal("access", token.AccessToken)
+}
+
+func Test_StoreUser(t *testing.T) {
+	a := assert.New(t)
+
+	req := httptest.NewRequest("GET", "/", nil)
+	_, err := GetUser(req)
+	a.Equal(ErrNoUser, err)
+
+	res := httptest.NewRecorder()
+	a.NoError(StoreUser(res, req, goth.User{
+		Provider:    "faux",
+		UserID:      "42",
+		Name:        "Homer Simpson",
+		AccessToken: "access",
+		RawData:     map[string]interface{}{"id": 42},
+	}))
+	cookies := res.Result().Cookies()
+	a.Len(cookies, 1)
+	a.Equal(UserSessionName, cookies[0].Name)
+
+	req = httptest.NewRequest("GET", "/", nil)
+	req.AddCookie(cookies[0])
+	user, err := GetUser(req)
+	a.NoError(err)
+	a.Equal("42", user.UserID)
+	a.Equal("Homer Simpson", user.Name)
+	a.Empty(user.AccessToken)
+	a.Nil(user.RawData)
+
+	// the user is kept apart from the sessions of the providers
+	a.NoError(Logout(httptest.NewRecorder(), req))
+	_, err = GetUser(req)
+	a.NoError(err)
+}
+
+func Test_LogoutUser(t *testing.T) {
+	a := assert.New(t)
+
+	res := httptest.NewRecorder()
+	a.NoError(StoreUser(res, httptest.NewRequest("GET", "/", nil), goth.User{UserID: "42"}))
+	req := httptest.NewRequest("GET", "/", nil)
+	req.AddCookie(res.Result().Cookies()[0])
+
+	res = httptest.NewRecorder()
+	a.NoError(LogoutUser(res, req))
+	cookies := res.Result().Cookies()
+	a.Equal(UserSessionName, cookies[0].Name)
+	a.Equal(-1, cookies[0].MaxAge)
+
+	req = httptest.NewRequest("GET", "/", nil)
+	req.AddCookie(cookies[0])
+	_, err := GetUser(req)
+	a.Equal(ErrNoUser, err)
+}
+
+func Test_GetProviderNameFromContext(t *testing.T) {
+	a := assert.New(t)
+
+	req := httptest.NewRequest("GET", "/auth", nil)
+	_, err := GetProviderName(req)
+	a.Error(err)
+
+	name, err := GetProviderName(GetContextWithProvider(req, "faux"))
+	a.NoError(err)
+	a.Equal("faux", name)
+}
+
+func Test_StoreInSession(t *testing.T) {
+	a := assert.New(t)
+
+	res := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	a.NoError(StoreInSession("key", "value", req, res))
+
+	req = httptest.NewRequest("GET", "/", nil)
+	req.AddCookie(res.Result().Cookies()[0])
+	value, err := GetFromSession("key", req)
+	a.NoError(err)
+	a.Equal("value", value)
+
+	_, err = GetFromSession("other", req)
+	a.Error(err)
+}
+
+func Test_GetFromSessionWithProviderSession(t *testing.T) {
+	a := assert.New(t)
+
+	sess := faux.Session{ID: "id", AccessToken: "access"}
+	for _, stored := range []string{goth.MarshalSession(&sess), sess.Marshal()} {
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/", nil)
+		a.NoError(StoreInSession("faux", stored, req, res))
+
+		// the session is returned as marshaled by the provider, whether it has been
+		// stored with its version or before the versions were introduced
+		req = httptest.NewRequest("GET", "/", nil)
+		req.AddCookie(res.Result().Cookies()[0])
+		value, err := GetFromSession("faux", req)
+		a.NoError(err)
+		a.Equal(sess.Marshal(), value)
+	}
+}
+
+func Test_NoKey(t *testing.T) {
+	a := assert.New(t)
+
+	Key = nil
+	defer func() { Key = []byte("secret") }()
+
+	res := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/", nil)
+	a.Equal(ErrNoKey, StoreInSession("key", "value", req, res))
+}
@@ -0,0 +1,57 @@
+package stdgothic
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/markbates/goth"
+)
+
+// UserSessionName is the name of the cookie of the sessions keeping the users signed
+// in with StoreUser.
+const UserSessionName = "_gothic_user"
+
+// ErrNoUser is returned by GetUser when no user is signed in.
+var ErrNoUser = errors.New("stdgothic: no user is signed in")
+
+// StoreUser signs the user in, until LogoutUser is called or the session expires. Only
+// the profile of the user is kept in the session, not its tokens nor its raw data,
+// see TokenStore to keep the tokens.
+func StoreUser(res http.ResponseWriter, req *http.Request, user goth.User) error {
+	user.RawData = nil
+	user.AccessToken = ""
+	user.AccessTokenSecret = ""
+	user.RefreshToken = ""
+	user.IDToken = ""
+	b, err := json.Marshal(user)
+	if err != nil {
+		return err
+	}
+
+	s, _ := loadSession(req, UserSessionName)
+	s.values["user"] = string(b)
+	return s.save(res, req)
+}
+
+// GetUser returns the user signed in with StoreUser, or ErrNoUser.
+func GetUser(req *http.Request) (goth.User, error) {
+	user := goth.User{}
+	s, _ := loadSession(req, UserSessionName)
+	value, ok := s.values["user"]
+	if !ok {
+		return user, ErrNoUser
+	}
+	err := json.Unmarshal([]byte(value), &user)
+	return user, err
+}
+
+// LogoutUser signs out the user signed in with StoreUser.
+func LogoutUser(res http.ResponseWriter, req *http.Request) error {
+	s, err := loadSession(req, UserSessionName)
+	if err != nil {
+		return err
+	}
+	s.values = map[string]string{}
+	return s.save(res, req)
+}
@@ -0,0 +1,57 @@
+package gothic
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/markbates/goth"
+)
+
+// UserSessionName is the name of the session keeping the users signed in with StoreUser.
+const UserSessionName = "_gothic_user"
+
+// ErrNoUser is returned by GetUser when no user is signed in.
+var ErrNoUser = errors.New("gothic: no user is signed in")
+
+// StoreUser signs the user in, until LogoutUser is called or the session expires. Only
+// the profile of the user is kept in the session, not its tokens nor its raw data,
+// see TokenStore to keep the tokens.
+func StoreUser(res http.ResponseWriter, req *http.Request, user goth.User) error {
+	user.RawData = nil
+	user.AccessToken = ""
+	user.AccessTokenSecret = ""
+	user.RefreshToken = ""
+	user.IDToken = ""
+	b, err := json.Marshal(user)
+	if err != nil {
+		return err
+	}
+
+	session, _ := Store.New(req, UserSessionName)
+	session.Values["user"] = string(b)
+	return session.Save(req, res)
+}
+
+// GetUser returns the user signed in with StoreUser, or ErrNoUser.
+func GetUser(req *http.Request) (goth.User, error) {
+	user := goth.User{}
+	session, _ := Store.Get(req, UserSessionName)
+	value, ok := session.Values["user"].(string)
+	if !ok {
+		return user, ErrNoUser
+	}
+	err := json.Unmarshal([]byte(value), &user)
+	return user, err
+}
+
+// LogoutUser signs out the user signed in with StoreUser.
+func LogoutUser(res http.ResponseWriter, req *http.Request) error {
+	session, err := Store.Get(req, UserSessionName)
+	if err != nil {
+		return err
+	}
+	session.Options.MaxAge = -1
+	session.Values = make(map[interface{}]interface{})
+	return session.Save(req, res)
+}
@@ -0,0 +1,141 @@
+/*
+Package gothmigrate rewrites in bulk the gothic sessions kept in a store, so that they
+are in the format of the current version of goth after an upgrade, see
+goth.SessionVersion.
+
+The sessions are also migrated when they are read by gothic, so running the migration
+is only needed before removing the support of older versions, or to know which sessions
+cannot be migrated. The sessions kept in cookies cannot be migrated in bulk.
+
+The providers must be in use, as only their sessions are migrated:
+
+	goth.UseProviders(github.New(key, secret, callbackURL))
+	sessionStore := s.Sessions([]byte(os.Getenv("SESSION_SECRET")))
+
+	result, err := gothmigrate.New(s, sessionStore.Codecs...).Run(ctx)
+*/
+package gothmigrate
+
+import (
+	"context"
+	"time"
+
+	"github.com/gorilla/securecookie"
+	"github.com/gorilla/sessions"
+	"github.com/markbates/goth/gothic"
+	"github.com/markbates/goth/store"
+)
+
+// Backend is a store.SessionBackend able to list its sessions.
+type Backend interface {
+	store.SessionBackend
+	store.SessionLister
+}
+
+// Result counts the sessions seen by a migration.
+type Result struct {
+	// Migrated is the number of sessions rewritten in the current format.
+	Migrated int
+	// Unchanged is the number of sessions already in the current format, or changed by
+	// a request during the migration.
+	Unchanged int
+	// Failed is the number of sessions which cannot be decoded or migrated, they are
+	// deleted when the Migrator has been told so.
+	Failed int
+}
+
+// Migrator rewrites the gothic sessions kept in a Backend.
+type Migrator struct {
+	backend      Backend
+	codecs       []securecookie.Codec
+	sessionName  string
+	deleteFailed bool
+}
+
+// New creates a Migrator of the sessions kept in the backend, which are encoded with
+// the codecs of their store.SessionStore.
+func New(backend Backend, codecs ...securecookie.Codec) *Migrator {
+	return &Migrator{
+		backend:     backend,
+		codecs:      codecs,
+		sessionName: gothic.SessionName,
+	}
+}
+
+// WithSessionName sets the name of the sessions, gothic.SessionName by default.
+func (m *Migrator) WithSessionName(name string) *Migrator {
+	m.sessionName = name
+	return m
+}
+
+// WithDeleteFailed tells to delete the sessions which cannot be decoded or migrated,
+// their users will have to sign in again.
+func (m *Migrator) WithDeleteFailed(deleteFailed bool) *Migrator {
+	m.deleteFailed = deleteFailed
+	return m
+}
+
+type listedSession struct {
+	id        string
+	data      string
+	expiresAt time.Time
+}
+
+// Run migrates the sessions which have not expired.
+func (m *Migrator) Run(ctx context.Context) (Result, error) {
+	result := Result{}
+	listed := []listedSession{}
+	err := m.backend.ListSessions(ctx, func(id, data string, expiresAt time.Time) error {
+		listed = append(listed, listedSession{id: id, data: data, expiresAt: expiresAt})
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+
+	for _, l := range listed {
+		data, changed, err := m.migrate(l.data)
+		if err != nil {
+			result.Failed++
+			if m.deleteFailed {
+				if err = m.backend.DeleteSession(ctx, l.id); err != nil {
+					return result, err
+				}
+			}
+			continue
+		}
+		if !changed {
+			result.Unchanged++
+			continue
+		}
+
+		// leave the sessions changed since they were listed, they are migrated when read
+		current, err := m.backend.LoadSession(ctx, l.id)
+		if err == store.ErrSessionNotFound || (err == nil && current != l.data) {
+			result.Unchanged++
+			continue
+		}
+		if err != nil {
+			return result, err
+		}
+		if err = m.backend.SaveSession(ctx, l.id, data, l.expiresAt); err != nil {
+			return result, err
+		}
+		result.Migrated++
+	}
+	return result, nil
+}
+
+// migrate decodes the values of a session, migrates them and encodes them again.
+func (m *Migrator) migrate(data string) (string, bool, error) {
+	session := sessions.NewSession(nil, m.sessionName)
+	if err := securecookie.DecodeMulti(m.sessionName, data, &session.Values, m.codecs...); err != nil {
+		return "", false, err
+	}
+	changed, err := gothic.MigrateSession(session)
+	if err != nil || !changed {
+		return "", false, err
+	}
+	data, err = securecookie.EncodeMulti(m.sessionName, session.Values, m.codecs...)
+	return data, err == nil, err
+}
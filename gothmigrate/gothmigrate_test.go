@@ -0,0 +1,79 @@
+package gothmigrate_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/gothic"
+	"github.com/markbates/goth/gothmigrate"
+	"github.com/markbates/goth/providers/faux"
+	"github.com/markbates/goth/store"
+	"github.com/stretchr/testify/assert"
+)
+
+func gzipString(t *testing.T, value string) string {
+	var b bytes.Buffer
+	gz := gzip.NewWriter(&b)
+	if _, err := gz.Write([]byte(value)); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return b.String()
+}
+
+func ungzipString(t *testing.T, value string) string {
+	r, err := gzip.NewReader(bytes.NewReader([]byte(value)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func Test_Run(t *testing.T) {
+	a := assert.New(t)
+	ctx := context.Background()
+	goth.UseProviders(&faux.Provider{})
+
+	backend := store.NewMemoryStore()
+	sessionStore := backend.Sessions([]byte("secret"))
+
+	// a session saved before the versions
+	req := httptest.NewRequest("GET", "/", nil)
+	session, err := sessionStore.New(req, gothic.SessionName)
+	a.NoError(err)
+	session.Values["faux"] = gzipString(t, `{"ID":"id"}`)
+	res := httptest.NewRecorder()
+	a.NoError(session.Save(req, res))
+	cookie := res.Result().Cookies()[0]
+
+	a.NoError(backend.SaveSession(ctx, "broken", "not encoded", time.Now().Add(time.Hour)))
+
+	result, err := gothmigrate.New(backend, sessionStore.Codecs...).Run(ctx)
+	a.NoError(err)
+	a.Equal(gothmigrate.Result{Migrated: 1, Failed: 1}, result)
+
+	req = httptest.NewRequest("GET", "/", nil)
+	req.AddCookie(cookie)
+	session, err = sessionStore.New(req, gothic.SessionName)
+	a.NoError(err)
+	a.Equal(fmt.Sprintf(`v%d:{"ID":"id"}`, goth.SessionVersion), ungzipString(t, session.Values["faux"].(string)))
+
+	result, err = gothmigrate.New(backend, sessionStore.Codecs...).WithDeleteFailed(true).Run(ctx)
+	a.NoError(err)
+	a.Equal(gothmigrate.Result{Unchanged: 1, Failed: 1}, result)
+	_, err = backend.LoadSession(ctx, "broken")
+	a.Equal(store.ErrSessionNotFound, err)
+}
@@ -0,0 +1,52 @@
+// Package jwks caches the JSON Web Key Sets the providers publish to verify the tokens
+// they sign.
+package jwks
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/lestrrat-go/jwx/jwk"
+)
+
+// TTL is the time the keys are kept before they are fetched again.
+const TTL = time.Hour
+
+// refreshInterval is the minimum time between two fetches of the keys, so that tokens
+// signed with unknown keys cannot make the provider be called for each of them.
+const refreshInterval = time.Minute
+
+// Cache keeps the keys published at a URL, its zero value is ready to use.
+type Cache struct {
+	mu        sync.Mutex
+	url       string
+	set       *jwk.Set
+	fetchedAt time.Time
+}
+
+// Key returns the key with the given ID from the keys published at url. The keys are
+// fetched with the client once their TTL has elapsed, or when the key is unknown as the
+// provider may have rotated them.
+func (c *Cache) Key(client *http.Client, url, kid string) (interface{}, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	age := time.Since(c.fetchedAt)
+	if c.set == nil || c.url != url || age > TTL || (len(c.set.LookupKeyID(kid)) == 0 && age > refreshInterval) {
+		set, err := jwk.FetchHTTP(url, jwk.WithHTTPClient(client))
+		if err != nil {
+			return nil, err
+		}
+		c.url = url
+		c.set = set
+		c.fetchedAt = time.Now()
+	}
+
+	keys := c.set.LookupKeyID(kid)
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("unknown key %q", kid)
+	}
+	return keys[0].Materialize()
+}
@@ -0,0 +1,46 @@
+package jwks_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lestrrat-go/jwx/jwk"
+	"github.com/markbates/goth/internal/jwks"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Key(t *testing.T) {
+	a := assert.New(t)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	a.NoError(err)
+	fetches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		k, err := jwk.New(&key.PublicKey)
+		a.NoError(err)
+		k.Set(jwk.KeyIDKey, "key-1")
+		json.NewEncoder(w).Encode(jwk.Set{Keys: []jwk.Key{k}})
+	}))
+	defer server.Close()
+
+	c := jwks.Cache{}
+	for i := 0; i < 3; i++ {
+		k, err := c.Key(http.DefaultClient, server.URL, "key-1")
+		a.NoError(err)
+		a.Equal(&key.PublicKey, k)
+	}
+	a.Equal(1, fetches)
+
+	// the keys were just fetched, the unknown keys do not fetch them again
+	for i := 0; i < 3; i++ {
+		_, err = c.Key(http.DefaultClient, server.URL, "key-2")
+		a.Error(err)
+	}
+	a.Equal(1, fetches)
+}
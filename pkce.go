@@ -0,0 +1,43 @@
+package goth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+
+	"golang.org/x/oauth2"
+)
+
+// GenerateCodeVerifier returns a random PKCE code verifier as described in
+// https://tools.ietf.org/html/rfc7636#section-4.1
+// Providers that use PKCE keep the verifier in their session between BeginAuth
+// and Authorize.
+func GenerateCodeVerifier() (string, error) {
+	b := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// S256CodeChallenge derives the "S256" code challenge for the given code verifier.
+func S256CodeChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// PKCEChallengeOptions returns the options to add to an oauth2 AuthCodeURL call
+// to send the "S256" challenge for the given code verifier.
+func PKCEChallengeOptions(verifier string) []oauth2.AuthCodeOption {
+	return []oauth2.AuthCodeOption{
+		oauth2.SetAuthURLParam("code_challenge", S256CodeChallenge(verifier)),
+		oauth2.SetAuthURLParam("code_challenge_method", "S256"),
+	}
+}
+
+// PKCEVerifierOption returns the option to add to an oauth2 Exchange call
+// to send the code verifier.
+func PKCEVerifierOption(verifier string) oauth2.AuthCodeOption {
+	return oauth2.SetAuthURLParam("code_verifier", verifier)
+}
@@ -0,0 +1,27 @@
+package goth_test
+
+import (
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GenerateCodeVerifier(t *testing.T) {
+	a := assert.New(t)
+
+	v1, err := goth.GenerateCodeVerifier()
+	a.NoError(err)
+	v2, err := goth.GenerateCodeVerifier()
+	a.NoError(err)
+
+	a.Len(v1, 43)
+	a.NotEqual(v1, v2)
+}
+
+func Test_S256CodeChallenge(t *testing.T) {
+	a := assert.New(t)
+
+	// example from https://tools.ietf.org/html/rfc7636#appendix-B
+	a.Equal("E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM", goth.S256CodeChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"))
+}
@@ -21,6 +21,14 @@ type Provider interface {
 	RefreshTokenAvailable() bool                             //Refresh token is provided by auth provider or not
 }
 
+// ParamsProvider is implemented by the providers beginning the authentication with the
+// parameters of the request, e.g. the shop of Shopify for the apps installed in several
+// shops. gothic calls BeginAuthWithParams instead of BeginAuth for them.
+type ParamsProvider interface {
+	Provider
+	BeginAuthWithParams(state string, params Params) (Session, error)
+}
+
 const NoAuthUrlErrorMessage = "an AuthURL has not been set"
 
 // Providers is list of known/available providers.
@@ -0,0 +1,81 @@
+package adobe_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/adobe"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("ADOBE_KEY"))
+	a.Equal(p.Secret, os.Getenv("ADOBE_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	session, err := p.BeginAuth("test_state")
+	s := session.(*adobe.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "ims-na1.adobelogin.com/ims/authorize/v2")
+	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("ADOBE_KEY")))
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "scope=openid%2CAdobeID%2Cemail%2Cprofile")
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://ims-na1.adobelogin.com/ims/authorize/v2","AccessToken":"1234567890"}`)
+	a.NoError(err)
+
+	s := session.(*adobe.Session)
+	a.Equal(s.AuthURL, "https://ims-na1.adobelogin.com/ims/authorize/v2")
+	a.Equal(s.AccessToken, "1234567890")
+}
+
+func Test_FetchUser(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("Bearer 1234567890", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"sub":"A1B2C3D4E5F6@AdobeID","account_type":"type1","email_verified":true,"name":"Jane Doe","given_name":"Jane","family_name":"Doe","email":"jane@example.com"}`))
+	}))
+	defer ts.Close()
+
+	originalProfileURL := adobe.ProfileURL
+	adobe.ProfileURL = ts.URL
+	defer func() { adobe.ProfileURL = originalProfileURL }()
+
+	user, err := provider().FetchUser(&adobe.Session{AccessToken: "1234567890"})
+	a.NoError(err)
+	a.Equal("A1B2C3D4E5F6@AdobeID", user.UserID)
+	a.Equal("Jane Doe", user.Name)
+	a.Equal("Jane", user.FirstName)
+	a.Equal("Doe", user.LastName)
+	a.Equal("jane@example.com", user.Email)
+}
+
+func provider() *adobe.Provider {
+	return adobe.New(os.Getenv("ADOBE_KEY"), os.Getenv("ADOBE_SECRET"), "/foo")
+}
@@ -21,6 +21,26 @@ const (
 	endpointProfile string = "https://api.amazon.com/user/profile"
 )
 
+// Region is a region of Login with Amazon, with its own token and profile endpoints.
+type Region string
+
+// Regions
+const (
+	// RegionNA is North America, its endpoints are the global ones used by default.
+	RegionNA Region = "NA"
+	// RegionEU is Europe.
+	RegionEU Region = "EU"
+	// RegionFE is the Far East, e.g. Japan and Australia.
+	RegionFE Region = "FE"
+)
+
+// regionEndpoints are the token and profile endpoints of the regions.
+var regionEndpoints = map[Region][2]string{
+	RegionNA: {tokenURL, endpointProfile},
+	RegionEU: {"https://api.amazon.co.uk/auth/o2/token", "https://api.amazon.co.uk/user/profile"},
+	RegionFE: {"https://api.amazon.co.jp/auth/o2/token", "https://api.amazon.co.jp/user/profile"},
+}
+
 // Provider is the implementation of `goth.Provider` for accessing Amazon.
 type Provider struct {
 	ClientKey    string
@@ -29,6 +49,7 @@ type Provider struct {
 	HTTPClient   *http.Client
 	config       *oauth2.Config
 	providerName string
+	profileURL   string
 }
 
 // New creates a new Amazon provider and sets up important connection details.
@@ -40,11 +61,25 @@ func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
 		Secret:       secret,
 		CallbackURL:  callbackURL,
 		providerName: "amazon",
+		profileURL:   endpointProfile,
 	}
 	p.config = newConfig(p, scopes)
 	return p
 }
 
+// WithRegion makes the provider use the token and profile endpoints of the region, as
+// required for the accounts of Alexa and the Selling Partner API in Europe or the Far
+// East. The authorization page is the same in every region. Unknown regions are ignored.
+func (p *Provider) WithRegion(region Region) *Provider {
+	endpoints, ok := regionEndpoints[region]
+	if !ok {
+		return p
+	}
+	p.config.Endpoint.TokenURL = endpoints[0]
+	p.profileURL = endpoints[1]
+	return p
+}
+
 func (p *Provider) Client() *http.Client {
 	return goth.HTTPClientWithFallBack(p.HTTPClient)
 }
@@ -84,7 +119,7 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
 	}
 
-	response, err := goth.HTTPClientWithFallBack(p.Client()).Get(endpointProfile + "?access_token=" + url.QueryEscape(sess.AccessToken))
+	response, err := goth.HTTPClientWithFallBack(p.Client()).Get(p.profileURL + "?access_token=" + url.QueryEscape(sess.AccessToken))
 
 	if err != nil {
 		return user, err
@@ -1,11 +1,18 @@
 package amazon_test
 
 import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
 	"github.com/markbates/goth"
 	"github.com/markbates/goth/providers/amazon"
 	"github.com/stretchr/testify/assert"
-	"os"
-	"testing"
 )
 
 func Test_New(t *testing.T) {
@@ -47,6 +54,84 @@ func Test_SessionFromJSON(t *testing.T) {
 	a.Equal(s.AccessToken, "1234567890")
 }
 
+func Test_WithRegion(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	for region, host := range map[amazon.Region]string{
+		"":              "api.amazon.com",
+		"XX":            "api.amazon.com",
+		amazon.RegionNA: "api.amazon.com",
+		amazon.RegionEU: "api.amazon.co.uk",
+		amazon.RegionFE: "api.amazon.co.jp",
+	} {
+		p := provider()
+		if region != "" {
+			p.WithRegion(region)
+		}
+		withMockServer(p, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			a.Equal(host, r.Host)
+			switch r.URL.Path {
+			case "/auth/o2/token":
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprint(w, `{"access_token":"token","token_type":"bearer","expires_in":3600}`)
+			case "/user/profile":
+				fmt.Fprint(w, `{"user_id":"amzn1.account.K2LI23KL2LK2"}`)
+			default:
+				t.Errorf("unexpected path %s", r.URL.Path)
+			}
+		}), func() {
+			token, err := p.RefreshToken("refresh")
+			a.NoError(err)
+			a.Equal("token", token.AccessToken)
+
+			user, err := p.FetchUser(&amazon.Session{AccessToken: "token"})
+			a.NoError(err)
+			a.Equal("amzn1.account.K2LI23KL2LK2", user.UserID)
+		})
+
+		// the authorization page is the same in every region
+		session, err := p.BeginAuth("test_state")
+		a.NoError(err)
+		a.Contains(session.(*amazon.Session).AuthURL, "https://www.amazon.com/ap/oa")
+	}
+}
+
+func Test_FetchUser_Region(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := amazon.New("key", "secret", "/foo").WithRegion(amazon.RegionEU)
+	withMockServer(p, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("api.amazon.co.uk", r.Host)
+		a.Equal("/user/profile", r.URL.Path)
+		fmt.Fprint(w, `{"user_id":"amzn1.account.K2LI23KL2LK2","email":"mhashimoto@example.com","name":"Mork Hashimoto","postal_code":"98052"}`)
+	}), func() {
+		user, err := p.FetchUser(&amazon.Session{AccessToken: "token"})
+		a.NoError(err)
+		a.Equal("amzn1.account.K2LI23KL2LK2", user.UserID)
+		a.Equal("98052", user.Location)
+	})
+}
+
 func provider() *amazon.Provider {
 	return amazon.New(os.Getenv("AMAZON_KEY"), os.Getenv("AMAZON_SECRET"), "/foo")
 }
+
+// withMockServer points the HTTP client of the provider to a server running the
+// handler, whatever the host it calls.
+func withMockServer(p *amazon.Provider, handler http.Handler, fn func()) {
+	server := httptest.NewTLSServer(handler)
+	defer server.Close()
+	p.HTTPClient = &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return net.Dial(network, server.Listener.Addr().String())
+			},
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		},
+	}
+	fn()
+}
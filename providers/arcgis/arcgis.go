@@ -0,0 +1,218 @@
+// Package arcgis implements the OAuth2 protocol for authenticating users through ArcGIS Online
+// and ArcGIS Enterprise.
+//
+// ArcGIS access tokens expire after expires_in seconds (30 minutes by default). The
+// refresh token lives for two weeks unless another lifetime is requested, and refreshing
+// does not issue a new refresh token, so keep using the one from the first exchange.
+package arcgis
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+)
+
+// OnlinePortalURL is the portal URL of ArcGIS Online.
+const OnlinePortalURL = "https://www.arcgis.com"
+
+// Provider is the implementation of `goth.Provider` for accessing ArcGIS.
+type Provider struct {
+	ClientKey   string
+	Secret      string
+	CallbackURL string
+	// RefreshTokenLifetime is requested as the lifetime of the refresh token, when set.
+	// ArcGIS expects it in whole minutes.
+	RefreshTokenLifetime time.Duration
+	HTTPClient           *http.Client
+	config               *oauth2.Config
+	providerName         string
+	portalURL            string
+}
+
+// New creates a new ArcGIS Online provider and sets up important connection details.
+// You should always call `arcgis.New` to get a new provider.  Never try to
+// create one manually.
+func New(clientKey, secret, callbackURL string) *Provider {
+	return NewCustomisedPortal(clientKey, secret, callbackURL, OnlinePortalURL)
+}
+
+// NewCustomisedPortal is similar to New(...) but can be used to set the portal of an
+// ArcGIS Enterprise installation, e.g. "https://gis.example.com/portal".
+func NewCustomisedPortal(clientKey, secret, callbackURL, portalURL string) *Provider {
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "arcgis",
+		portalURL:    strings.TrimSuffix(portalURL, "/"),
+	}
+	p.config = newConfig(p)
+	return p
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns an HTTP client to be used in all fetch operations.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// Debug is a no-op for the arcgis package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks ArcGIS for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	opts := []oauth2.AuthCodeOption{}
+	if p.RefreshTokenLifetime > 0 {
+		minutes := int64(p.RefreshTokenLifetime / time.Minute)
+		opts = append(opts, oauth2.SetAuthURLParam("expiration", strconv.FormatInt(minutes, 10)))
+	}
+	return &Session{
+		AuthURL: p.config.AuthCodeURL(state, opts...),
+	}, nil
+}
+
+// FetchUser will go to the community/self endpoint of the portal and access basic
+// information about the user. The ArcGIS username is used as UserID and NickName,
+// the organization is available in RawData as "orgId".
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	req, err := http.NewRequest("GET", p.portalURL+"/sharing/rest/community/self?f=json", nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+
+	response, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+	}
+
+	bits, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return user, err
+	}
+
+	err = json.NewDecoder(bytes.NewReader(bits)).Decode(&user.RawData)
+	if err != nil {
+		return user, err
+	}
+
+	err = p.userFromReader(bytes.NewReader(bits), &user)
+	return user, err
+}
+
+// userFromReader reads the user of the community/self response. ArcGIS reports errors
+// with a 200 status and an "error" object, which is turned into an error here.
+func (p *Provider) userFromReader(r io.Reader, user *goth.User) error {
+	u := struct {
+		Error *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+		Username    string `json:"username"`
+		FullName    string `json:"fullName"`
+		FirstName   string `json:"firstName"`
+		LastName    string `json:"lastName"`
+		Email       string `json:"email"`
+		Description string `json:"description"`
+		Thumbnail   string `json:"thumbnail"`
+		Region      string `json:"region"`
+	}{}
+	err := json.NewDecoder(r).Decode(&u)
+	if err != nil {
+		return err
+	}
+	if u.Error != nil {
+		return fmt.Errorf("%s responded with a %d trying to fetch user information: %s", p.providerName, u.Error.Code, u.Error.Message)
+	}
+	if u.Username == "" {
+		return fmt.Errorf("%s did not return a user", p.providerName)
+	}
+	user.UserID = u.Username
+	user.NickName = u.Username
+	user.Name = u.FullName
+	user.FirstName = u.FirstName
+	user.LastName = u.LastName
+	user.Email = u.Email
+	user.Description = u.Description
+	user.Location = u.Region
+	if u.Thumbnail != "" {
+		user.AvatarURL = p.portalURL + "/sharing/rest/community/users/" + url.PathEscape(u.Username) + "/info/" + u.Thumbnail
+	}
+	return nil
+}
+
+func newConfig(provider *Provider) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   provider.portalURL + "/sharing/rest/oauth2/authorize",
+			TokenURL:  provider.portalURL + "/sharing/rest/oauth2/token",
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+	}
+}
+
+// refreshTokenExpiry returns when the refresh token of the token expires, as reported by
+// the refresh_token_expires_in field of the token response.
+func refreshTokenExpiry(token *oauth2.Token) time.Time {
+	if secs, ok := token.Extra("refresh_token_expires_in").(float64); ok && secs > 0 {
+		return time.Now().Add(time.Duration(secs) * time.Second)
+	}
+	return time.Time{}
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token.
+// ArcGIS does not return a new refresh token, the returned token carries the one passed in.
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	token := &oauth2.Token{RefreshToken: refreshToken}
+	ts := p.config.TokenSource(goth.ContextForClient(p.Client()), token)
+	newToken, err := ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	return newToken, err
+}
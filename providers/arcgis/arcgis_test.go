@@ -0,0 +1,156 @@
+package arcgis_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/arcgis"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("ARCGIS_KEY"))
+	a.Equal(p.Secret, os.Getenv("ARCGIS_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	session, err := p.BeginAuth("test_state")
+	s := session.(*arcgis.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://www.arcgis.com/sharing/rest/oauth2/authorize")
+	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("ARCGIS_KEY")))
+	a.Contains(s.AuthURL, "state=test_state")
+	a.NotContains(s.AuthURL, "expiration=")
+}
+
+func Test_BeginAuthWithRefreshTokenLifetime(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := arcgis.NewCustomisedPortal(os.Getenv("ARCGIS_KEY"), os.Getenv("ARCGIS_SECRET"), "/foo", "https://gis.example.com/portal/")
+	p.RefreshTokenLifetime = 24 * time.Hour
+	session, err := p.BeginAuth("test_state")
+	s := session.(*arcgis.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://gis.example.com/portal/sharing/rest/oauth2/authorize")
+	a.Contains(s.AuthURL, "expiration=1440")
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://www.arcgis.com/sharing/rest/oauth2/authorize","AccessToken":"1234567890","Username":"jdoe_acme"}`)
+	a.NoError(err)
+
+	s := session.(*arcgis.Session)
+	a.Equal(s.AuthURL, "https://www.arcgis.com/sharing/rest/oauth2/authorize")
+	a.Equal(s.AccessToken, "1234567890")
+	a.Equal(s.Username, "jdoe_acme")
+}
+
+func Test_Authorize(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/sharing/rest/oauth2/token", r.URL.Path)
+		a.NoError(r.ParseForm())
+		a.Equal("authorization_code", r.Form.Get("grant_type"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"abc","expires_in":1800,"username":"jdoe_acme","ssl":true,"refresh_token":"def","refresh_token_expires_in":1209600}`))
+	}))
+	defer ts.Close()
+
+	p := arcgis.NewCustomisedPortal(os.Getenv("ARCGIS_KEY"), os.Getenv("ARCGIS_SECRET"), "/foo", ts.URL)
+	s := &arcgis.Session{}
+	token, err := s.Authorize(p, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("abc", token)
+	a.Equal("def", s.RefreshToken)
+	a.Equal("jdoe_acme", s.Username)
+	a.WithinDuration(time.Now().Add(1800*time.Second), s.ExpiresAt, time.Minute)
+	a.WithinDuration(time.Now().Add(14*24*time.Hour), s.RefreshTokenExpiresAt, time.Minute)
+}
+
+func Test_FetchUser(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/sharing/rest/community/self", r.URL.Path)
+		a.Equal("json", r.URL.Query().Get("f"))
+		a.Equal("Bearer 1234567890", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"username":"jdoe_acme","id":"9a8b7c6d5e4f","fullName":"Jane Doe","firstName":"Jane","lastName":"Doe","email":"jane@example.com","description":"GIS analyst","thumbnail":"jane.png","region":"US","orgId":"Xj56SBi2udA78cC9","role":"org_admin"}`))
+	}))
+	defer ts.Close()
+
+	p := arcgis.NewCustomisedPortal(os.Getenv("ARCGIS_KEY"), os.Getenv("ARCGIS_SECRET"), "/foo", ts.URL)
+	user, err := p.FetchUser(&arcgis.Session{AccessToken: "1234567890"})
+	a.NoError(err)
+	a.Equal("jdoe_acme", user.UserID)
+	a.Equal("jdoe_acme", user.NickName)
+	a.Equal("Jane Doe", user.Name)
+	a.Equal("jane@example.com", user.Email)
+	a.Equal(ts.URL+"/sharing/rest/community/users/jdoe_acme/info/jane.png", user.AvatarURL)
+	a.Equal("Xj56SBi2udA78cC9", user.RawData["orgId"])
+}
+
+func Test_FetchUserError(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"error":{"code":498,"message":"Invalid token.","details":[]}}`))
+	}))
+	defer ts.Close()
+
+	p := arcgis.NewCustomisedPortal(os.Getenv("ARCGIS_KEY"), os.Getenv("ARCGIS_SECRET"), "/foo", ts.URL)
+	_, err := p.FetchUser(&arcgis.Session{AccessToken: "1234567890"})
+	a.Error(err)
+	a.Contains(err.Error(), "498")
+}
+
+func Test_RefreshToken(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.NoError(r.ParseForm())
+		a.Equal("refresh_token", r.Form.Get("grant_type"))
+		a.Equal("def", r.Form.Get("refresh_token"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"new","expires_in":1800,"username":"jdoe_acme"}`))
+	}))
+	defer ts.Close()
+
+	p := arcgis.NewCustomisedPortal(os.Getenv("ARCGIS_KEY"), os.Getenv("ARCGIS_SECRET"), "/foo", ts.URL)
+	token, err := p.RefreshToken("def")
+	a.NoError(err)
+	a.Equal("new", token.AccessToken)
+	a.Equal("def", token.RefreshToken)
+}
+
+func provider() *arcgis.Provider {
+	return arcgis.New(os.Getenv("ARCGIS_KEY"), os.Getenv("ARCGIS_SECRET"), "/foo")
+}
@@ -0,0 +1,240 @@
+// Package atlassian implements the OAuth2 (3LO) protocol for authenticating users
+// through Atlassian cloud products such as Jira and Confluence.
+package atlassian
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+)
+
+// These vars define the Authentication, Token, Profile and Accessible Resources URLS for Atlassian.
+var (
+	AuthURL                = "https://auth.atlassian.com/authorize"
+	TokenURL               = "https://auth.atlassian.com/oauth/token"
+	ProfileURL             = "https://api.atlassian.com/me"
+	AccessibleResourcesURL = "https://api.atlassian.com/oauth/token/accessible-resources"
+)
+
+const (
+	// Audience is the audience parameter Atlassian requires on every authorization request.
+	Audience = "api.atlassian.com"
+
+	// ScopeReadMe allows the app to read the user's profile.
+	ScopeReadMe = "read:me"
+	// ScopeOfflineAccess makes Atlassian issue a refresh token.
+	ScopeOfflineAccess = "offline_access"
+)
+
+// Provider is the implementation of `goth.Provider` for accessing Atlassian.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	HTTPClient   *http.Client
+	config       *oauth2.Config
+	providerName string
+}
+
+// New creates a new Atlassian provider and sets up important connection details.
+// You should always call `atlassian.New` to get a new provider.  Never try to
+// create one manually.
+func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "atlassian",
+	}
+	p.config = newConfig(p, scopes)
+	return p
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns an HTTP client to be used in all fetch operations.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// Debug is a no-op for the atlassian package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks Atlassian for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	url := p.config.AuthCodeURL(state,
+		oauth2.SetAuthURLParam("audience", Audience),
+		oauth2.SetAuthURLParam("prompt", "consent"),
+	)
+	return &Session{
+		AuthURL: url,
+	}, nil
+}
+
+// FetchUser will go to Atlassian and access basic information about the user,
+// along with the cloud sites (resources) the user granted the app access to.
+// The sites are stored in RawData under the "accessible_resources" key and their
+// IDs under "cloud_ids".
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	bits, err := p.get(ProfileURL, sess.AccessToken)
+	if err != nil {
+		return user, err
+	}
+
+	err = json.NewDecoder(bytes.NewReader(bits)).Decode(&user.RawData)
+	if err != nil {
+		return user, err
+	}
+
+	err = userFromReader(bytes.NewReader(bits), &user)
+	if err != nil {
+		return user, err
+	}
+
+	bits, err = p.get(AccessibleResourcesURL, sess.AccessToken)
+	if err != nil {
+		return user, err
+	}
+
+	resources := []Resource{}
+	err = json.NewDecoder(bytes.NewReader(bits)).Decode(&resources)
+	if err != nil {
+		return user, err
+	}
+
+	cloudIDs := make([]string, 0, len(resources))
+	for _, r := range resources {
+		cloudIDs = append(cloudIDs, r.ID)
+	}
+	user.RawData["accessible_resources"] = resources
+	user.RawData["cloud_ids"] = cloudIDs
+
+	return user, nil
+}
+
+// Resource is a cloud site the user granted the app access to. Its ID is the
+// cloud ID used to build Jira and Confluence API URLs, e.g.
+// https://api.atlassian.com/ex/jira/{cloudid}/rest/api/3/myself
+type Resource struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	URL       string   `json:"url"`
+	Scopes    []string `json:"scopes"`
+	AvatarURL string   `json:"avatarUrl"`
+}
+
+func (p *Provider) get(endpoint, accessToken string) ([]byte, error) {
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Authorization", "Bearer "+accessToken)
+	req.Header.Add("Accept", "application/json")
+
+	response, err := p.Client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+	}
+
+	return ioutil.ReadAll(response.Body)
+}
+
+func userFromReader(r io.Reader, user *goth.User) error {
+	u := struct {
+		AccountID string `json:"account_id"`
+		Email     string `json:"email"`
+		Name      string `json:"name"`
+		NickName  string `json:"nickname"`
+		Picture   string `json:"picture"`
+		Locale    string `json:"locale"`
+		Extended  struct {
+			Location string `json:"location"`
+			JobTitle string `json:"job_title"`
+		} `json:"extended_profile"`
+	}{}
+	err := json.NewDecoder(r).Decode(&u)
+	if err != nil {
+		return err
+	}
+	user.UserID = u.AccountID
+	user.Email = u.Email
+	user.Name = u.Name
+	user.NickName = u.NickName
+	user.AvatarURL = u.Picture
+	user.Location = u.Extended.Location
+	user.Description = u.Extended.JobTitle
+	return nil
+}
+
+func newConfig(provider *Provider, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  AuthURL,
+			TokenURL: TokenURL,
+		},
+		Scopes: []string{},
+	}
+
+	if len(scopes) > 0 {
+		for _, scope := range scopes {
+			c.Scopes = append(c.Scopes, scope)
+		}
+	} else {
+		c.Scopes = []string{ScopeReadMe, ScopeOfflineAccess}
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not.
+// Atlassian only issues refresh tokens when the offline_access scope is requested.
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token.
+// Atlassian rotates refresh tokens, so the returned token carries a new refresh token
+// which must replace the old one.
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	token := &oauth2.Token{RefreshToken: refreshToken}
+	ts := p.config.TokenSource(goth.ContextForClient(p.Client()), token)
+	newToken, err := ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	return newToken, err
+}
@@ -0,0 +1,97 @@
+package atlassian_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/atlassian"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("ATLASSIAN_KEY"))
+	a.Equal(p.Secret, os.Getenv("ATLASSIAN_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	session, err := p.BeginAuth("test_state")
+	s := session.(*atlassian.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "auth.atlassian.com/authorize")
+	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("ATLASSIAN_KEY")))
+	a.Contains(s.AuthURL, "audience=api.atlassian.com")
+	a.Contains(s.AuthURL, "prompt=consent")
+	a.Contains(s.AuthURL, "scope=read%3Ame+offline_access")
+	a.Contains(s.AuthURL, "state=test_state")
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://auth.atlassian.com/authorize","AccessToken":"1234567890"}`)
+	a.NoError(err)
+
+	s := session.(*atlassian.Session)
+	a.Equal(s.AuthURL, "https://auth.atlassian.com/authorize")
+	a.Equal(s.AccessToken, "1234567890")
+}
+
+func Test_FetchUser(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("Bearer 1234567890", r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/me":
+			w.Write([]byte(`{"account_id":"557058:abcd","email":"wash@serenity.now","name":"Hoban Washburne","nickname":"wash","picture":"http://avatarURL","extended_profile":{"job_title":"Pilot","location":"Serenity"}}`))
+		case "/accessible-resources":
+			w.Write([]byte(`[{"id":"1324a887-45db-1bf4-1e99-ef0ff456d421","name":"serenity","url":"https://serenity.atlassian.net","scopes":["read:jira-work"],"avatarUrl":"https://site-admin-avatar-cdn.prod.public.atl-paas.net/avatars/240/flag.png"}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	originalProfileURL, originalResourcesURL := atlassian.ProfileURL, atlassian.AccessibleResourcesURL
+	atlassian.ProfileURL = ts.URL + "/me"
+	atlassian.AccessibleResourcesURL = ts.URL + "/accessible-resources"
+	defer func() {
+		atlassian.ProfileURL, atlassian.AccessibleResourcesURL = originalProfileURL, originalResourcesURL
+	}()
+
+	p := provider()
+	user, err := p.FetchUser(&atlassian.Session{AccessToken: "1234567890"})
+	a.NoError(err)
+	a.Equal("557058:abcd", user.UserID)
+	a.Equal("wash@serenity.now", user.Email)
+	a.Equal("Hoban Washburne", user.Name)
+	a.Equal("wash", user.NickName)
+	a.Equal("http://avatarURL", user.AvatarURL)
+	a.Equal("Serenity", user.Location)
+	a.Equal("Pilot", user.Description)
+	a.Equal([]string{"1324a887-45db-1bf4-1e99-ef0ff456d421"}, user.RawData["cloud_ids"])
+}
+
+func provider() *atlassian.Provider {
+	return atlassian.New(os.Getenv("ATLASSIAN_KEY"), os.Getenv("ATLASSIAN_SECRET"), "/foo")
+}
@@ -7,9 +7,11 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"strings"
 
 	"fmt"
 
+	"github.com/dgrijalva/jwt-go"
 	"github.com/markbates/goth"
 	"golang.org/x/oauth2"
 )
@@ -23,13 +25,18 @@ const (
 
 // Provider is the implementation of `goth.Provider` for accessing Auth0.
 type Provider struct {
-	ClientKey    string
-	Secret       string
-	CallbackURL  string
+	ClientKey   string
+	Secret      string
+	CallbackURL string
+	// Domain is the domain of the Auth0 tenant, e.g. "example.eu.auth0.com", or its
+	// custom domain, e.g. "login.example.com". A URL such as "https://login.example.com/"
+	// is accepted too.
 	Domain       string
 	HTTPClient   *http.Client
 	config       *oauth2.Config
 	providerName string
+	organization string
+	connection   string
 }
 
 type auth0UserResp struct {
@@ -55,6 +62,20 @@ func New(clientKey, secret, callbackURL string, auth0Domain string, scopes ...st
 	return p
 }
 
+// WithOrganization makes the users log in to the Auth0 Organization, given by its ID
+// ("org_…") or its name. The organization of the ID token is checked against it.
+func (p *Provider) WithOrganization(organization string) *Provider {
+	p.organization = organization
+	return p
+}
+
+// WithConnection sends the users straight to the connection, e.g. "google-oauth2" or
+// an enterprise connection, instead of the login page of Auth0.
+func (p *Provider) WithConnection(connection string) *Provider {
+	p.connection = connection
+	return p
+}
+
 // Name is the name used to retrieve this provider later.
 func (p *Provider) Name() string {
 	return p.providerName
@@ -74,8 +95,24 @@ func (p *Provider) Debug(debug bool) {}
 
 // BeginAuth asks Auth0 for an authentication end-point.
 func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	return p.BeginAuthForInvitation(state, p.organization, "")
+}
+
+// BeginAuthForInvitation asks Auth0 for an authentication end-point to accept the
+// invitation to the organization, both given in the query of the invitation link.
+func (p *Provider) BeginAuthForInvitation(state, organization, invitation string) (goth.Session, error) {
+	opts := []oauth2.AuthCodeOption{}
+	if organization != "" {
+		opts = append(opts, oauth2.SetAuthURLParam("organization", organization))
+	}
+	if invitation != "" {
+		opts = append(opts, oauth2.SetAuthURLParam("invitation", invitation))
+	}
+	if p.connection != "" {
+		opts = append(opts, oauth2.SetAuthURLParam("connection", p.connection))
+	}
 	return &Session{
-		AuthURL: p.config.AuthCodeURL(state),
+		AuthURL: p.config.AuthCodeURL(state, opts...),
 	}, nil
 }
 
@@ -90,6 +127,7 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 		Provider:     p.Name(),
 		RefreshToken: s.RefreshToken,
 		ExpiresAt:    s.ExpiresAt,
+		IDToken:      s.IDToken,
 	}
 
 	if user.AccessToken == "" {
@@ -97,7 +135,7 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
 	}
 
-	userProfileURL := protocol + p.Domain + endpointProfile
+	userProfileURL := p.baseURL() + endpointProfile
 	req, err := http.NewRequest("GET", userProfileURL, nil)
 	if err != nil {
 		return user, err
@@ -117,17 +155,57 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 	}
 
 	err = userFromReader(resp.Body, &user)
+	if err == nil && s.OrganizationID != "" {
+		user.RawData["org_id"] = s.OrganizationID
+	}
 	return user, err
 }
 
+// baseURL is the URL of the Auth0 tenant, from its domain.
+func (p *Provider) baseURL() string {
+	domain := strings.TrimSuffix(p.Domain, "/")
+	if !strings.Contains(domain, "://") {
+		domain = protocol + domain
+	}
+	return domain
+}
+
+// checkOrganization checks the organization of the ID token is the one of the
+// provider, if any, and returns the ID of the organization.
+func (p *Provider) checkOrganization(idToken string) (string, error) {
+	claims := struct {
+		jwt.StandardClaims
+		OrganizationID   string `json:"org_id"`
+		OrganizationName string `json:"org_name"`
+	}{}
+	// the ID token comes straight from the token endpoint over TLS, so its signature
+	// does not need to be checked
+	_, _, err := new(jwt.Parser).ParseUnverified(idToken, &claims)
+	if err != nil {
+		return "", err
+	}
+
+	if p.organization == "" {
+		return claims.OrganizationID, nil
+	}
+	if strings.HasPrefix(p.organization, "org_") {
+		if claims.OrganizationID != p.organization {
+			return "", fmt.Errorf("%s returned an ID token for the organization %q instead of %q", p.providerName, claims.OrganizationID, p.organization)
+		}
+	} else if !strings.EqualFold(claims.OrganizationName, p.organization) {
+		return "", fmt.Errorf("%s returned an ID token for the organization %q instead of %q", p.providerName, claims.OrganizationName, p.organization)
+	}
+	return claims.OrganizationID, nil
+}
+
 func newConfig(provider *Provider, scopes []string) *oauth2.Config {
 	c := &oauth2.Config{
 		ClientID:     provider.ClientKey,
 		ClientSecret: provider.Secret,
 		RedirectURL:  provider.CallbackURL,
 		Endpoint: oauth2.Endpoint{
-			AuthURL:  protocol + provider.Domain + authEndpoint,
-			TokenURL: protocol + provider.Domain + tokenEndpoint,
+			AuthURL:  provider.baseURL() + authEndpoint,
+			TokenURL: provider.baseURL() + tokenEndpoint,
 		},
 		Scopes: []string{},
 	}
@@ -1,9 +1,11 @@
 package auth0_test
 
 import (
+	"net/url"
 	"os"
 	"testing"
 
+	"github.com/dgrijalva/jwt-go"
 	"github.com/jarcoal/httpmock"
 	"github.com/markbates/goth"
 	"github.com/markbates/goth/providers/auth0"
@@ -96,6 +98,80 @@ func Test_FetchUser(t *testing.T) {
 
 }
 
+func Test_CustomDomain(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	for _, domain := range []string{"login.example.com", "https://login.example.com/"} {
+		p := auth0.New("key", "secret", "/foo", domain)
+		session, err := p.BeginAuth("test_state")
+		a.NoError(err)
+		a.Contains(session.(*auth0.Session).AuthURL, "https://login.example.com/authorize?")
+	}
+}
+
+func Test_BeginAuth_Organization(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := auth0.New("key", "secret", "/foo", "example.auth0.com").WithOrganization("org_123").WithConnection("google-oauth2")
+	session, err := p.BeginAuth("test_state")
+	a.NoError(err)
+	u, err := url.Parse(session.(*auth0.Session).AuthURL)
+	a.NoError(err)
+	a.Equal("org_123", u.Query().Get("organization"))
+	a.Equal("google-oauth2", u.Query().Get("connection"))
+	a.Empty(u.Query().Get("invitation"))
+
+	session, err = p.BeginAuthForInvitation("test_state", "org_456", "inv_789")
+	a.NoError(err)
+	u, err = url.Parse(session.(*auth0.Session).AuthURL)
+	a.NoError(err)
+	a.Equal("org_456", u.Query().Get("organization"))
+	a.Equal("inv_789", u.Query().Get("invitation"))
+}
+
+func Test_Authorize_Organization(t *testing.T) {
+	//t.Parallel()
+	a := assert.New(t)
+
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	idToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":      "auth0|58454",
+		"org_id":   "org_123",
+		"org_name": "acme",
+	}).SignedString([]byte("secret"))
+	a.NoError(err)
+	tokenResponder, err := httpmock.NewJsonResponder(200, map[string]interface{}{
+		"access_token": "token",
+		"id_token":     idToken,
+		"token_type":   "Bearer",
+		"expires_in":   86400,
+	})
+	a.NoError(err)
+	httpmock.RegisterResponder("POST", "https://example.auth0.com/oauth/token", tokenResponder)
+	httpmock.RegisterResponder("GET", "https://example.auth0.com/userinfo", httpmock.NewStringResponder(200, `{"sub":"auth0|58454"}`))
+
+	for _, organization := range []string{"", "org_123", "ACME"} {
+		p := auth0.New("key", "secret", "/foo", "example.auth0.com").WithOrganization(organization)
+		s := &auth0.Session{}
+		_, err = s.Authorize(p, url.Values{"code": {"code"}})
+		a.NoError(err)
+		a.Equal("org_123", s.OrganizationID)
+		a.Equal(idToken, s.IDToken)
+
+		u, err := p.FetchUser(s)
+		a.NoError(err)
+		a.Equal("org_123", u.RawData["org_id"])
+	}
+
+	p := auth0.New("key", "secret", "/foo", "example.auth0.com").WithOrganization("org_456")
+	_, err = (&auth0.Session{}).Authorize(p, url.Values{"code": {"code"}})
+	a.EqualError(err, `auth0 returned an ID token for the organization "org_123" instead of "org_456"`)
+}
+
 func provider() *auth0.Provider {
 	return auth0.New(os.Getenv("AUTH0_KEY"), os.Getenv("AUTH0_SECRET"), "/foo", os.Getenv("AUTH0_DOMAIN"))
 }
@@ -3,11 +3,11 @@ package auth0
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 
 	"github.com/markbates/goth"
-	"golang.org/x/oauth2"
 )
 
 // Session stores data during the auth process with Auth0.
@@ -16,6 +16,10 @@ type Session struct {
 	AccessToken  string
 	RefreshToken string
 	ExpiresAt    time.Time
+	IDToken      string `json:",omitempty"`
+	// OrganizationID is the org_id of the ID token, when the user logged in to an
+	// Auth0 Organization.
+	OrganizationID string `json:",omitempty"`
 }
 
 var _ goth.Session = &Session{}
@@ -31,7 +35,7 @@ func (s Session) GetAuthURL() (string, error) {
 // Authorize the session with Auth0 and return the access token to be stored for future use.
 func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string, error) {
 	p := provider.(*Provider)
-	token, err := p.config.Exchange(oauth2.NoContext, params.Get("code"))
+	token, err := p.config.Exchange(goth.ContextForClient(p.Client()), params.Get("code"))
 	if err != nil {
 		return "", err
 	}
@@ -43,6 +47,16 @@ func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string,
 	s.AccessToken = token.AccessToken
 	s.RefreshToken = token.RefreshToken
 	s.ExpiresAt = token.Expiry
+
+	if idToken, ok := token.Extra("id_token").(string); ok {
+		s.OrganizationID, err = p.checkOrganization(idToken)
+		if err != nil {
+			return "", err
+		}
+		s.IDToken = idToken
+	} else if p.organization != "" {
+		return "", fmt.Errorf("%s did not return an ID token to check the organization", p.providerName)
+	}
 	return token.AccessToken, err
 }
 
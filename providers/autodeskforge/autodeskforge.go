@@ -1,9 +1,13 @@
 // Package autodeskforge implements the OAuth2 protocol for authenticating users through forge.autodesk.com.
 // This package can be used as a reference implementation of an OAuth2 provider for Goth.
+//
+// The provider uses the Authentication v2 API of Autodesk, NewV1 keeps the deprecated
+// v1 API available while applications migrate.
 package autodeskforge
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"io/ioutil"
 	"net/http"
@@ -12,22 +16,45 @@ import (
 
 	"github.com/markbates/goth"
 	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
 )
 
 const (
-	baseAuthURL  string = "https://developer.api.autodesk.com/authentication/v1/authorize"
-	tokenURL     string = "https://developer.api.autodesk.com/authentication/v1/gettoken"
-	endpointUser string = "https://developer.api.autodesk.com/userprofile/v1/users/@me"
+	baseAuthURL  string = "https://developer.api.autodesk.com/authentication/v2/authorize"
+	tokenURL     string = "https://developer.api.autodesk.com/authentication/v2/token"
+	endpointUser string = "https://api.userprofile.autodesk.com/userinfo"
+	endpointHubs string = "https://developer.api.autodesk.com/project/v1/hubs"
+
+	baseAuthURLV1  string = "https://developer.api.autodesk.com/authentication/v1/authorize"
+	tokenURLV1     string = "https://developer.api.autodesk.com/authentication/v1/gettoken"
+	endpointUserV1 string = "https://developer.api.autodesk.com/userprofile/v1/users/@me"
+)
+
+const (
+	// ScopeUserProfileRead allows to read the user's profile, it is required by FetchUser.
+	ScopeUserProfileRead = "user-profile:read"
+	// ScopeDataRead allows to read the user's data, e.g. hubs and projects.
+	ScopeDataRead = "data:read"
+	// ScopeDataWrite allows to modify the user's data.
+	ScopeDataWrite = "data:write"
+	// ScopeDataCreate allows to create data for the user.
+	ScopeDataCreate = "data:create"
+	// ScopeAccountRead allows to read the account of the user.
+	ScopeAccountRead = "account:read"
 )
 
 // Provider is the implementation of `goth.Provider` for accessing forge.autodesk.com.
 type Provider struct {
-	ClientKey    string
-	Secret       string
-	CallbackURL  string
+	ClientKey   string
+	Secret      string
+	CallbackURL string
+	// FetchHubs makes FetchUser attach the hubs (BIM 360 and ACC accounts) the user can
+	// access to RawData under "hubs". It requires the ScopeDataRead scope.
+	FetchHubs    bool
 	HTTPClient   *http.Client
 	config       *oauth2.Config
 	providerName string
+	v1           bool
 }
 
 // New creates a new AutodeskForge provider and sets up important connection details.
@@ -44,6 +71,21 @@ func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
 	return p
 }
 
+// NewV1 is similar to New(...) but uses the Authentication v1 API of Autodesk.
+//
+// Deprecated: Autodesk is retiring the v1 API, use New instead.
+func NewV1(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "autodeskforge",
+		v1:           true,
+	}
+	p.config = newConfig(p, scopes)
+	return p
+}
+
 // Name is the name used to retrieve this provider later.
 func (p *Provider) Name() string {
 	return p.providerName
@@ -84,14 +126,91 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
 	}
 
+	var err error
+	if p.v1 {
+		user, err = p.fetchUserV1(sess.AccessToken, user)
+	} else {
+		user, err = p.fetchUser(sess.AccessToken, user)
+	}
+	if err != nil || !p.FetchHubs {
+		return user, err
+	}
+
+	hubs, err := p.hubs(sess.AccessToken)
+	if err != nil {
+		return user, err
+	}
+	if user.RawData == nil {
+		user.RawData = map[string]interface{}{}
+	}
+	user.RawData["hubs"] = hubs
+	return user, nil
+}
+
+// fetchUser gets the user from the userinfo endpoint of Autodesk.
+func (p *Provider) fetchUser(accessToken string, user goth.User) (goth.User, error) {
+	req, err := http.NewRequest("GET", endpointUser, nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Bearer "+accessToken)
+
+	response, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+	}
+
+	bits, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return user, err
+	}
+
+	err = json.NewDecoder(bytes.NewReader(bits)).Decode(&user.RawData)
+	if err != nil {
+		return user, err
+	}
+
+	u := struct {
+		Sub               string `json:"sub"`
+		Name              string `json:"name"`
+		GivenName         string `json:"given_name"`
+		FamilyName        string `json:"family_name"`
+		PreferredUsername string `json:"preferred_username"`
+		Email             string `json:"email"`
+		Picture           string `json:"picture"`
+		Locale            string `json:"locale"`
+	}{}
+
+	if err = json.NewDecoder(bytes.NewReader(bits)).Decode(&u); err != nil {
+		return user, err
+	}
+
+	user.UserID = u.Sub
+	user.Name = u.Name
+	user.FirstName = u.GivenName
+	user.LastName = u.FamilyName
+	user.NickName = u.PreferredUsername
+	user.Email = u.Email
+	user.AvatarURL = u.Picture
+	user.Location = u.Locale
+	return user, err
+}
+
+// fetchUserV1 gets the user from the user profile API used with Authentication v1.
+func (p *Provider) fetchUserV1(accessToken string, user goth.User) (goth.User, error) {
 	// Get the userID, forge.autodesk needs userID in order to get user profile info
 	c := p.Client()
-	req, err := http.NewRequest("GET", endpointUser, nil)
+	req, err := http.NewRequest("GET", endpointUserV1, nil)
 	if err != nil {
 		return user, err
 	}
 
-	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+	req.Header.Add("Authorization", "Bearer "+accessToken)
 
 	response, err := c.Do(req)
 	if err != nil {
@@ -137,23 +256,75 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 	return user, err
 }
 
+// hubs lists the hubs the user can access with the Data Management API.
+func (p *Provider) hubs(accessToken string) ([]interface{}, error) {
+	req, err := http.NewRequest("GET", endpointHubs, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Authorization", "Bearer "+accessToken)
+
+	response, err := p.Client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s responded with a %d trying to fetch hubs", p.providerName, response.StatusCode)
+	}
+
+	hubs := struct {
+		Data []interface{} `json:"data"`
+	}{}
+	err = json.NewDecoder(response.Body).Decode(&hubs)
+	return hubs.Data, err
+}
+
+// AppToken gets a two-legged token of the application, using the client credentials
+// of the provider. App tokens are used for server-side calls, e.g. to the Model
+// Derivative API, that do not act on behalf of a user. ScopeDataRead is requested when
+// no scopes are given.
+func (p *Provider) AppToken(ctx context.Context, scopes ...string) (*oauth2.Token, error) {
+	if len(scopes) == 0 {
+		scopes = []string{ScopeDataRead}
+	}
+	c := &clientcredentials.Config{
+		ClientID:     p.ClientKey,
+		ClientSecret: p.Secret,
+		TokenURL:     p.config.Endpoint.TokenURL,
+		Scopes:       scopes,
+		AuthStyle:    p.config.Endpoint.AuthStyle,
+	}
+	return c.Token(context.WithValue(ctx, oauth2.HTTPClient, p.Client()))
+}
+
 func newConfig(provider *Provider, scopes []string) *oauth2.Config {
-	authURL := fmt.Sprintf("%s?response_type=code&client_id=%s&redirect_uri=%s&scope=data:read", baseAuthURL, provider.ClientKey, provider.CallbackURL)
+	endpoint := oauth2.Endpoint{
+		AuthURL:   baseAuthURL,
+		TokenURL:  tokenURL,
+		AuthStyle: oauth2.AuthStyleInHeader,
+	}
+	if provider.v1 {
+		endpoint = oauth2.Endpoint{
+			AuthURL:  baseAuthURLV1,
+			TokenURL: tokenURLV1,
+		}
+	}
 	c := &oauth2.Config{
 		ClientID:     provider.ClientKey,
 		ClientSecret: provider.Secret,
 		RedirectURL:  provider.CallbackURL,
-		Endpoint: oauth2.Endpoint{
-			AuthURL:  authURL,
-			TokenURL: tokenURL,
-		},
-		Scopes: []string{},
+		Endpoint:     endpoint,
+		Scopes:       []string{},
 	}
 
 	if len(scopes) > 0 {
 		for _, scope := range scopes {
 			c.Scopes = append(c.Scopes, scope)
 		}
+	} else {
+		c.Scopes = []string{ScopeUserProfileRead, ScopeDataRead}
 	}
 	return c
 }
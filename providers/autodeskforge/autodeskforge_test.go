@@ -1,6 +1,12 @@
 package autodeskforge_test
 
 import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"testing"
 
@@ -32,6 +38,31 @@ func Test_BeginAuth(t *testing.T) {
 	session, err := p.BeginAuth("test_state")
 	s := session.(*autodeskforge.Session)
 	a.NoError(err)
+	a.Contains(s.AuthURL, "https://developer.api.autodesk.com/authentication/v2/authorize")
+	a.Contains(s.AuthURL, "client_id="+os.Getenv("ADSK_FORGE_CLIENT_ID"))
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "scope=user-profile%3Aread+data%3Aread")
+}
+
+func Test_BeginAuthWithScopes(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := autodeskforge.New(os.Getenv("ADSK_FORGE_CLIENT_ID"), os.Getenv("ADSK_FORGE_CLIENT_SECRET"), "/foo", autodeskforge.ScopeDataWrite, autodeskforge.ScopeAccountRead)
+	session, err := p.BeginAuth("test_state")
+	s := session.(*autodeskforge.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "scope=data%3Awrite+account%3Aread")
+	a.NotContains(s.AuthURL, "data%3Aread")
+	a.NotContains(s.AuthURL, "data:read")
+}
+
+func Test_BeginAuthV1(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := autodeskforge.NewV1(os.Getenv("ADSK_FORGE_CLIENT_ID"), os.Getenv("ADSK_FORGE_CLIENT_SECRET"), "/foo")
+	session, err := p.BeginAuth("test_state")
+	s := session.(*autodeskforge.Session)
+	a.NoError(err)
 	a.Contains(s.AuthURL, "https://developer.api.autodesk.com/authentication/v1/authorize")
 }
 
@@ -48,6 +79,145 @@ func Test_SessionFromJSON(t *testing.T) {
 	a.Equal(s.AccessToken, "1234567890")
 }
 
+func Test_Authorize(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("developer.api.autodesk.com", r.Host)
+		a.Equal("/authentication/v2/token", r.URL.Path)
+		user, pass, ok := r.BasicAuth()
+		a.True(ok)
+		a.Equal("client-id", user)
+		a.Equal("client-secret", pass)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"1234567890","token_type":"Bearer","expires_in":3599,"refresh_token":"refresh"}`))
+	})
+
+	withMockServer(autodeskforge.New("client-id", "client-secret", "/foo"), handler, func(p *autodeskforge.Provider) {
+		s := &autodeskforge.Session{}
+		token, err := s.Authorize(p, url.Values{"code": {"code"}})
+		a.NoError(err)
+		a.Equal("1234567890", token)
+		a.Equal("refresh", s.RefreshToken)
+	})
+}
+
+func Test_FetchUser(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("api.userprofile.autodesk.com", r.Host)
+		a.Equal("/userinfo", r.URL.Path)
+		a.Equal("Bearer 1234567890", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"sub":"FDHGSX5L5ATC","name":"John Doe","given_name":"John","family_name":"Doe","preferred_username":"jdoe","email":"john.doe@example.com","email_verified":true,"picture":"https://images.profile.autodesk.com/jdoe.png","locale":"en-US"}`))
+	})
+
+	withMockServer(provider(), handler, func(p *autodeskforge.Provider) {
+		user, err := p.FetchUser(&autodeskforge.Session{AccessToken: "1234567890"})
+		a.NoError(err)
+		a.Equal("FDHGSX5L5ATC", user.UserID)
+		a.Equal("John Doe", user.Name)
+		a.Equal("jdoe", user.NickName)
+		a.Equal("john.doe@example.com", user.Email)
+		a.Equal(true, user.RawData["email_verified"])
+	})
+}
+
+func Test_FetchUserWithHubs(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("Bearer 1234567890", r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/userinfo":
+			w.Write([]byte(`{"sub":"FDHGSX5L5ATC","name":"John Doe","email":"john.doe@example.com"}`))
+		case "/project/v1/hubs":
+			a.Equal("developer.api.autodesk.com", r.Host)
+			w.Write([]byte(`{"jsonapi":{"version":"1.0"},"data":[{"type":"hubs","id":"b.8a331103-1b2d-4c2c-8a5f-0b1a5c1b4a6e","attributes":{"name":"Acme Construction","extension":{"type":"hubs:autodesk.bim360:Account"},"region":"US"}}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	p := provider()
+	p.FetchHubs = true
+	withMockServer(p, handler, func(p *autodeskforge.Provider) {
+		user, err := p.FetchUser(&autodeskforge.Session{AccessToken: "1234567890"})
+		a.NoError(err)
+		a.Equal("FDHGSX5L5ATC", user.UserID)
+		hubs := user.RawData["hubs"].([]interface{})
+		a.Len(hubs, 1)
+		a.Equal("b.8a331103-1b2d-4c2c-8a5f-0b1a5c1b4a6e", hubs[0].(map[string]interface{})["id"])
+	})
+}
+
+func Test_FetchUserV1(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("developer.api.autodesk.com", r.Host)
+		a.Equal("/userprofile/v1/users/@me", r.URL.Path)
+		w.Write([]byte(`{"userId":"FDHGSX5L5ATC","userName":"jdoe","emailId":"john.doe@example.com","firstName":"John","lastName":"Doe","countryCode":"US"}`))
+	})
+
+	p := autodeskforge.NewV1(os.Getenv("ADSK_FORGE_CLIENT_ID"), os.Getenv("ADSK_FORGE_CLIENT_SECRET"), "/foo")
+	withMockServer(p, handler, func(p *autodeskforge.Provider) {
+		user, err := p.FetchUser(&autodeskforge.Session{AccessToken: "1234567890"})
+		a.NoError(err)
+		a.Equal("FDHGSX5L5ATC", user.UserID)
+		a.Equal("jdoe", user.NickName)
+		a.Equal("US", user.Location)
+	})
+}
+
+func Test_AppToken(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/authentication/v2/token", r.URL.Path)
+		a.NoError(r.ParseForm())
+		a.Equal("client_credentials", r.Form.Get("grant_type"))
+		a.Equal("data:read bucket:read", r.Form.Get("scope"))
+		user, _, ok := r.BasicAuth()
+		a.True(ok)
+		a.Equal("client-id", user)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"app-token","token_type":"Bearer","expires_in":3599}`))
+	})
+
+	withMockServer(autodeskforge.New("client-id", "client-secret", "/foo"), handler, func(p *autodeskforge.Provider) {
+		token, err := p.AppToken(context.Background(), autodeskforge.ScopeDataRead, "bucket:read")
+		a.NoError(err)
+		a.Equal("app-token", token.AccessToken)
+		a.Empty(token.RefreshToken)
+	})
+}
+
 func provider() *autodeskforge.Provider {
 	return autodeskforge.New(os.Getenv("ADSK_FORGE_CLIENT_ID"), os.Getenv("ADSK_FORGE_CLIENT_SECRET"), "/foo")
 }
+
+func withMockServer(p *autodeskforge.Provider, handler http.Handler, fn func(p *autodeskforge.Provider)) {
+	server := httptest.NewTLSServer(handler)
+	defer server.Close()
+
+	httpClient := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return net.Dial(network, server.Listener.Addr().String())
+			},
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		},
+	}
+
+	p.HTTPClient = httpClient
+
+	fn(p)
+}
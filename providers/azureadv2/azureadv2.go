@@ -6,7 +6,9 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
+	"strings"
 
+	"github.com/dgrijalva/jwt-go"
 	"github.com/markbates/goth"
 	"golang.org/x/oauth2"
 )
@@ -16,6 +18,10 @@ const (
 	authURLTemplate  string = "https://login.microsoftonline.com/%s/oauth2/v2.0/authorize"
 	tokenURLTemplate string = "https://login.microsoftonline.com/%s/oauth2/v2.0/token"
 	graphAPIResource string = "https://graph.microsoft.com/v1.0/"
+	issuerTemplate   string = "https://login.microsoftonline.com/%s/v2.0"
+
+	// consumersTenantID is the tenant of personal Microsoft accounts.
+	consumersTenantID string = "9188040d-6c67-4c5b-b112-36a304b66dad"
 )
 
 type (
@@ -28,18 +34,29 @@ type (
 
 	// Provider is the implementation of `goth.Provider` for accessing AzureAD V2.
 	Provider struct {
-		ClientKey    string
-		Secret       string
-		CallbackURL  string
-		HTTPClient   *http.Client
-		config       *oauth2.Config
-		providerName string
+		ClientKey       string
+		Secret          string
+		CallbackURL     string
+		HTTPClient      *http.Client
+		config          *oauth2.Config
+		providerName    string
+		tenant          TenantType
+		allowedTenants  []string
+		groupsFromGraph bool
 	}
 
 	// ProviderOptions are the collection of optional configuration to provide when constructing a Provider
 	ProviderOptions struct {
 		Scopes []ScopeType
 		Tenant TenantType
+		// AllowedTenants are the IDs of the tenants whose users can sign in, when the Tenant
+		// is CommonTenant or OrganizationsTenant. All tenants are allowed when it is empty.
+		AllowedTenants []string
+		// GroupsFromGraph makes FetchUser read the groups of the users who are members of
+		// too many groups for the ID token from Microsoft Graph, which requires the
+		// GroupMemberReadAllScope scope. FetchUser then fails when they cannot be read.
+		// Otherwise their Groups are left empty.
+		GroupsFromGraph bool
 	}
 )
 
@@ -63,10 +80,16 @@ const (
 // one manually.
 func New(clientKey, secret, callbackURL string, opts ProviderOptions) *Provider {
 	p := &Provider{
-		ClientKey:    clientKey,
-		Secret:       secret,
-		CallbackURL:  callbackURL,
-		providerName: "azureadv2",
+		ClientKey:       clientKey,
+		Secret:          secret,
+		CallbackURL:     callbackURL,
+		providerName:    "azureadv2",
+		tenant:          opts.Tenant,
+		allowedTenants:  opts.AllowedTenants,
+		groupsFromGraph: opts.GroupsFromGraph,
+	}
+	if p.tenant == "" {
+		p.tenant = CommonTenant
 	}
 
 	p.config = newConfig(p, opts)
@@ -74,10 +97,7 @@ func New(clientKey, secret, callbackURL string, opts ProviderOptions) *Provider
 }
 
 func newConfig(provider *Provider, opts ProviderOptions) *oauth2.Config {
-	tenant := opts.Tenant
-	if tenant == "" {
-		tenant = CommonTenant
-	}
+	tenant := provider.tenant
 
 	c := &oauth2.Config{
 		ClientID:     provider.ClientKey,
@@ -128,12 +148,14 @@ func (p *Provider) BeginAuth(state string) (goth.Session, error) {
 }
 
 // FetchUser will go to AzureAD and access basic information about the user.
+// The groups and app roles of the user are read from the ID token into Groups and Roles.
 func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 	msSession := session.(*Session)
 	user := goth.User{
 		AccessToken: msSession.AccessToken,
 		Provider:    p.Name(),
 		ExpiresAt:   msSession.ExpiresAt,
+		IDToken:     msSession.IDToken,
 	}
 
 	if user.AccessToken == "" {
@@ -161,9 +183,154 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 	user.AccessToken = msSession.AccessToken
 	user.RefreshToken = msSession.RefreshToken
 	user.ExpiresAt = msSession.ExpiresAt
+	user.IDToken = msSession.IDToken
+	if err != nil || msSession.IDToken == "" {
+		return user, err
+	}
+	user.RawData["tid"] = msSession.TenantID
+
+	err = p.groupsAndRoles(msSession, &user)
 	return user, err
 }
 
+// verifyIssuer checks the issuer of the ID token is the tenant of the user, as given by
+// the tid claim, and that this tenant is allowed to sign in. It returns the tenant ID.
+//
+// The ID token comes straight from the token endpoint over TLS, so its signature does not
+// need to be checked.
+func (p *Provider) verifyIssuer(idToken string) (string, error) {
+	claims := struct {
+		jwt.StandardClaims
+		TenantID string `json:"tid"`
+	}{}
+	_, _, err := new(jwt.Parser).ParseUnverified(idToken, &claims)
+	if err != nil {
+		return "", err
+	}
+
+	if claims.TenantID == "" || claims.Issuer != fmt.Sprintf(issuerTemplate, claims.TenantID) {
+		return "", fmt.Errorf("%s returned an ID token with an unexpected issuer %q", p.providerName, claims.Issuer)
+	}
+
+	switch p.tenant {
+	case CommonTenant:
+	case OrganizationsTenant:
+		if claims.TenantID == consumersTenantID {
+			return "", fmt.Errorf("%s only allows work or school accounts", p.providerName)
+		}
+	case ConsumersTenant:
+		if claims.TenantID != consumersTenantID {
+			return "", fmt.Errorf("%s only allows personal Microsoft accounts", p.providerName)
+		}
+	default:
+		// a tenant ID or a domain name, the latter is resolved by Azure AD so the tenant
+		// can only be compared with an ID
+		if isTenantID(string(p.tenant)) && !strings.EqualFold(string(p.tenant), claims.TenantID) {
+			return "", fmt.Errorf("%s returned an ID token for another tenant %s", p.providerName, claims.TenantID)
+		}
+	}
+
+	if len(p.allowedTenants) > 0 {
+		allowed := false
+		for _, t := range p.allowedTenants {
+			allowed = allowed || strings.EqualFold(t, claims.TenantID)
+		}
+		if !allowed {
+			return "", fmt.Errorf("%s tenant %s is not allowed to sign in", p.providerName, claims.TenantID)
+		}
+	}
+	return claims.TenantID, nil
+}
+
+// isTenantID reports whether the tenant is a tenant ID, which is a GUID.
+func isTenantID(tenant string) bool {
+	parts := strings.Split(tenant, "-")
+	if len(parts) != 5 || len(tenant) != 36 {
+		return false
+	}
+	for _, c := range strings.Join(parts, "") {
+		if !strings.ContainsRune("0123456789abcdefABCDEF", c) {
+			return false
+		}
+	}
+	return true
+}
+
+// groupsAndRoles sets the groups and app roles of the user from the claims of the ID token.
+// When the user is a member of too many groups for the token, which then carries a
+// groups overage claim instead, the groups are read from Microsoft Graph if the provider
+// has GroupsFromGraph. Otherwise they are left empty, and the "groups_overage" of the
+// RawData of the user is true.
+//
+// The groups claim is only issued when the groupMembershipClaims of the app manifest
+// are configured, the roles claim when app roles are assigned to the user.
+func (p *Provider) groupsAndRoles(session *Session, user *goth.User) error {
+	claims := struct {
+		jwt.StandardClaims
+		Groups     []string               `json:"groups"`
+		Roles      []string               `json:"roles"`
+		HasGroups  bool                   `json:"hasgroups"`
+		ClaimNames map[string]interface{} `json:"_claim_names"`
+	}{}
+	// the ID token comes straight from the token endpoint over TLS, so its signature
+	// does not need to be checked
+	_, _, err := new(jwt.Parser).ParseUnverified(session.IDToken, &claims)
+	if err != nil {
+		return err
+	}
+
+	user.Roles = claims.Roles
+	user.Groups = claims.Groups
+	if _, overage := claims.ClaimNames["groups"]; overage || claims.HasGroups {
+		if !p.groupsFromGraph {
+			user.RawData["groups_overage"] = true
+			return nil
+		}
+		user.Groups, err = p.memberOf(session)
+	}
+	return err
+}
+
+// memberOf reads the ids of the groups the user is a member of from Microsoft Graph.
+func (p *Provider) memberOf(session *Session) ([]string, error) {
+	groups := []string{}
+	url := graphAPIResource + "me/memberOf/microsoft.graph.group?$select=id"
+	for url != "" {
+		req, err := http.NewRequest("GET", url, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set(authorizationHeader(session))
+
+		response, err := p.Client().Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if response.StatusCode != http.StatusOK {
+			response.Body.Close()
+			return nil, fmt.Errorf("%s responded with a %d trying to fetch user groups", p.providerName, response.StatusCode)
+		}
+
+		page := struct {
+			Value []struct {
+				ID string `json:"id"`
+			} `json:"value"`
+			NextLink string `json:"@odata.nextLink"`
+		}{}
+		err = json.NewDecoder(response.Body).Decode(&page)
+		response.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		for _, g := range page.Value {
+			groups = append(groups, g.ID)
+		}
+		url = page.NextLink
+	}
+	return groups, nil
+}
+
 //RefreshTokenAvailable refresh token is provided by auth provider or not
 func (p *Provider) RefreshTokenAvailable() bool {
 	return true
@@ -1,8 +1,15 @@
 package azureadv2_test
 
 import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"testing"
 
+	"github.com/dgrijalva/jwt-go"
 	"github.com/markbates/goth"
 	"github.com/markbates/goth/providers/azureadv2"
 	"github.com/stretchr/testify/assert"
@@ -57,6 +64,174 @@ func Test_SessionFromJSON(t *testing.T) {
 	a.Equal(s.AccessToken, "1234567890")
 }
 
+func Test_FetchUserWithGroupsAndRoles(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/v1.0/me", r.URL.Path)
+		w.Write([]byte(`{"id":"48d31887-5fad-4d73-a9f5-3c356e68a038","displayName":"Megan Bowen","mail":"MeganB@contoso.com"}`))
+	})
+
+	idToken := signedIDToken(t, jwt.MapClaims{
+		"oid":    "48d31887-5fad-4d73-a9f5-3c356e68a038",
+		"groups": []string{"62e90394-69f5-4237-9190-012177145e10", "f2ab1f2b-3ef1-4f64-a6b7-0c3a1a0e1f6d"},
+		"roles":  []string{"Admin"},
+	})
+	withMockServer(azureadProvider(), handler, func(p *azureadv2.Provider) {
+		user, err := p.FetchUser(&azureadv2.Session{AccessToken: "1234567890", IDToken: idToken})
+		a.NoError(err)
+		a.Equal("48d31887-5fad-4d73-a9f5-3c356e68a038", user.UserID)
+		a.Equal([]string{"62e90394-69f5-4237-9190-012177145e10", "f2ab1f2b-3ef1-4f64-a6b7-0c3a1a0e1f6d"}, user.Groups)
+		a.Equal([]string{"Admin"}, user.Roles)
+		a.Equal(idToken, user.IDToken)
+	})
+}
+
+func Test_FetchUserWithGroupsOverage(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("Bearer 1234567890", r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/v1.0/me":
+			w.Write([]byte(`{"id":"48d31887-5fad-4d73-a9f5-3c356e68a038","displayName":"Megan Bowen"}`))
+		case "/v1.0/me/memberOf/microsoft.graph.group":
+			if r.URL.Query().Get("$skiptoken") == "" {
+				w.Write([]byte(`{"value":[{"id":"62e90394-69f5-4237-9190-012177145e10"}],"@odata.nextLink":"https://graph.microsoft.com/v1.0/me/memberOf/microsoft.graph.group?$select=id&$skiptoken=next"}`))
+				return
+			}
+			w.Write([]byte(`{"value":[{"id":"f2ab1f2b-3ef1-4f64-a6b7-0c3a1a0e1f6d"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	idToken := signedIDToken(t, jwt.MapClaims{
+		"oid":          "48d31887-5fad-4d73-a9f5-3c356e68a038",
+		"_claim_names": map[string]string{"groups": "src1"},
+		"_claim_sources": map[string]interface{}{
+			"src1": map[string]string{"endpoint": "https://graph.windows.net/contoso.com/users/48d31887-5fad-4d73-a9f5-3c356e68a038/getMemberObjects"},
+		},
+	})
+	groupsFromGraph := azureadv2.New(applicationID, secret, redirectUri, azureadv2.ProviderOptions{GroupsFromGraph: true})
+	withMockServer(groupsFromGraph, handler, func(p *azureadv2.Provider) {
+		user, err := p.FetchUser(&azureadv2.Session{AccessToken: "1234567890", IDToken: idToken})
+		a.NoError(err)
+		a.Equal([]string{"62e90394-69f5-4237-9190-012177145e10", "f2ab1f2b-3ef1-4f64-a6b7-0c3a1a0e1f6d"}, user.Groups)
+		a.Nil(user.Roles)
+	})
+
+	// the groups are not read from Microsoft Graph by default
+	withMockServer(azureadProvider(), handler, func(p *azureadv2.Provider) {
+		user, err := p.FetchUser(&azureadv2.Session{AccessToken: "1234567890", IDToken: idToken})
+		a.NoError(err)
+		a.Empty(user.Groups)
+		a.Equal(true, user.RawData["groups_overage"])
+	})
+}
+
+func Test_FetchUserWithGroupsOverageAndGraphError(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1.0/me" {
+			w.Write([]byte(`{"id":"48d31887-5fad-4d73-a9f5-3c356e68a038","displayName":"Megan Bowen"}`))
+			return
+		}
+		w.WriteHeader(http.StatusForbidden)
+	})
+
+	idToken := signedIDToken(t, jwt.MapClaims{"oid": "48d31887-5fad-4d73-a9f5-3c356e68a038", "hasgroups": true})
+	withMockServer(azureadProvider(), handler, func(p *azureadv2.Provider) {
+		user, err := p.FetchUser(&azureadv2.Session{AccessToken: "1234567890", IDToken: idToken})
+		a.NoError(err)
+		a.Equal("48d31887-5fad-4d73-a9f5-3c356e68a038", user.UserID)
+		a.Empty(user.Groups)
+	})
+
+	groupsFromGraph := azureadv2.New(applicationID, secret, redirectUri, azureadv2.ProviderOptions{GroupsFromGraph: true})
+	withMockServer(groupsFromGraph, handler, func(p *azureadv2.Provider) {
+		_, err := p.FetchUser(&azureadv2.Session{AccessToken: "1234567890", IDToken: idToken})
+		a.Error(err)
+	})
+}
+
+func Test_AuthorizeMultiTenant(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	const tenantID = "72f988bf-86f1-41af-91ab-2d7cd011db47"
+	tokenHandler := func(claims jwt.MapClaims) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			a.Equal("/organizations/oauth2/v2.0/token", r.URL.Path)
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"access_token":"1234567890","token_type":"Bearer","expires_in":3600,"id_token":"` + signedIDToken(t, claims) + `"}`))
+		})
+	}
+	provider := func(allowed ...string) *azureadv2.Provider {
+		return azureadv2.New(applicationID, secret, redirectUri, azureadv2.ProviderOptions{
+			Tenant:         azureadv2.OrganizationsTenant,
+			AllowedTenants: allowed,
+		})
+	}
+
+	valid := jwt.MapClaims{"tid": tenantID, "iss": "https://login.microsoftonline.com/" + tenantID + "/v2.0"}
+	withMockServer(provider(tenantID), tokenHandler(valid), func(p *azureadv2.Provider) {
+		s := &azureadv2.Session{}
+		_, err := s.Authorize(p, url.Values{"code": {"code"}})
+		a.NoError(err)
+		a.Equal(tenantID, s.TenantID)
+	})
+
+	withMockServer(provider("f8cdef31-a31e-4b4a-93e4-5f571e91255a"), tokenHandler(valid), func(p *azureadv2.Provider) {
+		_, err := (&azureadv2.Session{}).Authorize(p, url.Values{"code": {"code"}})
+		a.EqualError(err, "azureadv2 tenant "+tenantID+" is not allowed to sign in")
+	})
+
+	forged := jwt.MapClaims{"tid": tenantID, "iss": "https://login.microsoftonline.com/f8cdef31-a31e-4b4a-93e4-5f571e91255a/v2.0"}
+	withMockServer(provider(), tokenHandler(forged), func(p *azureadv2.Provider) {
+		_, err := (&azureadv2.Session{}).Authorize(p, url.Values{"code": {"code"}})
+		a.Error(err)
+	})
+
+	consumer := jwt.MapClaims{"tid": "9188040d-6c67-4c5b-b112-36a304b66dad", "iss": "https://login.microsoftonline.com/9188040d-6c67-4c5b-b112-36a304b66dad/v2.0"}
+	withMockServer(provider(), tokenHandler(consumer), func(p *azureadv2.Provider) {
+		_, err := (&azureadv2.Session{}).Authorize(p, url.Values{"code": {"code"}})
+		a.EqualError(err, "azureadv2 only allows work or school accounts")
+	})
+}
+
+func signedIDToken(t *testing.T, claims jwt.MapClaims) string {
+	idToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return idToken
+}
+
+func withMockServer(p *azureadv2.Provider, handler http.Handler, fn func(p *azureadv2.Provider)) {
+	server := httptest.NewTLSServer(handler)
+	defer server.Close()
+
+	httpClient := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return net.Dial(network, server.Listener.Addr().String())
+			},
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		},
+	}
+
+	p.HTTPClient = httpClient
+
+	fn(p)
+}
+
 func azureadProvider() *azureadv2.Provider {
 	return azureadv2.New(applicationID, secret, redirectUri, azureadv2.ProviderOptions{})
 }
@@ -278,6 +278,11 @@ const (
 	// the signed-in user.  Additionally allows group owners to manage their groups and allows group members to update
 	// group content.
 	GroupReadWriteAllScope ScopeType = "Group.ReadWrite.All"
+
+	// GroupMemberReadAllScope allows the app to list groups, read basic group properties and read membership of all
+	// groups the signed-in user has access to. It is enough to read the groups of the signed-in user when their ID
+	// token has too many groups to list them.
+	GroupMemberReadAllScope ScopeType = "GroupMember.Read.All"
 )
 
 // Identity Risk Event Permissions
@@ -15,6 +15,9 @@ type Session struct {
 	AccessToken  string    `json:"at"`
 	RefreshToken string    `json:"rt"`
 	ExpiresAt    time.Time `json:"exp"`
+	IDToken      string    `json:"idt"`
+	// TenantID is the ID of the Azure AD tenant of the user, read from the ID token.
+	TenantID string `json:"tid"`
 }
 
 // GetAuthURL will return the URL set by calling the `BeginAuth` func
@@ -38,9 +41,20 @@ func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string,
 		return "", errors.New("invalid token received from provider")
 	}
 
+	idToken, _ := token.Extra("id_token").(string)
+	if idToken != "" {
+		s.TenantID, err = p.verifyIssuer(idToken)
+		if err != nil {
+			return "", err
+		}
+	} else if len(p.allowedTenants) > 0 {
+		return "", errors.New("no ID token received from provider to verify the tenant, request the openid scope")
+	}
+
 	s.AccessToken = token.AccessToken
 	s.RefreshToken = token.RefreshToken
 	s.ExpiresAt = token.Expiry
+	s.IDToken = idToken
 
 	return token.AccessToken, err
 }
@@ -0,0 +1,198 @@
+// Package bamboohr implements the OpenID Connect protocol for authenticating users through BambooHR.
+// Every BambooHR company has its own subdomain, which the provider is configured with.
+package bamboohr
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+)
+
+// These vars are formatted with the company subdomain to get the URLs of a BambooHR company.
+var (
+	BaseURLFormat = "https://%s.bamboohr.com"
+	APIURLFormat  = "https://api.bamboohr.com/api/gateway.php/%s"
+)
+
+const (
+	// ScopeOpenID is required to sign in with BambooHR.
+	ScopeOpenID = "openid"
+	// ScopeEmail grants access to the user's email address.
+	ScopeEmail = "email"
+)
+
+// employeeFields are the fields requested for the employee of the user.
+const employeeFields = "id,firstName,lastName,displayName,workEmail,jobTitle,photoUrl"
+
+// Provider is the implementation of `goth.Provider` for accessing BambooHR.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	Company      string
+	HTTPClient   *http.Client
+	config       *oauth2.Config
+	providerName string
+}
+
+// New creates a new BambooHR provider and sets up important connection details.
+// You should always call `bamboohr.New` to get a new provider.  Never try to
+// create one manually.
+// The company is the subdomain of the BambooHR account, e.g. "acme" for acme.bamboohr.com.
+func New(clientKey, secret, callbackURL, company string, scopes ...string) *Provider {
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		Company:      company,
+		providerName: "bamboohr",
+	}
+	p.config = newConfig(p, scopes)
+	return p
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns an HTTP client to be used in all fetch operations.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// Debug is a no-op for the bamboohr package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks BambooHR for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	return &Session{
+		AuthURL: p.config.AuthCodeURL(state),
+	}, nil
+}
+
+// FetchUser will go to BambooHR and access the employee record of the user.
+// The UserID is the BambooHR employee id.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+		IDToken:      sess.IDToken,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	// employee 0 is the employee of the authenticated user
+	employeeURL := fmt.Sprintf(APIURLFormat, url.PathEscape(p.Company)) + "/v1/employees/0?fields=" + url.QueryEscape(employeeFields)
+	req, err := http.NewRequest("GET", employeeURL, nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+	req.Header.Add("Accept", "application/json")
+
+	response, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+	}
+
+	bits, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return user, err
+	}
+
+	err = json.NewDecoder(bytes.NewReader(bits)).Decode(&user.RawData)
+	if err != nil {
+		return user, err
+	}
+
+	err = userFromReader(bytes.NewReader(bits), &user)
+	return user, err
+}
+
+func userFromReader(r io.Reader, user *goth.User) error {
+	u := struct {
+		ID          string `json:"id"`
+		FirstName   string `json:"firstName"`
+		LastName    string `json:"lastName"`
+		DisplayName string `json:"displayName"`
+		WorkEmail   string `json:"workEmail"`
+		JobTitle    string `json:"jobTitle"`
+		PhotoURL    string `json:"photoUrl"`
+	}{}
+	err := json.NewDecoder(r).Decode(&u)
+	if err != nil {
+		return err
+	}
+	user.UserID = u.ID
+	user.FirstName = u.FirstName
+	user.LastName = u.LastName
+	user.Name = u.DisplayName
+	user.Email = u.WorkEmail
+	user.Description = u.JobTitle
+	user.AvatarURL = u.PhotoURL
+	return nil
+}
+
+func newConfig(provider *Provider, scopes []string) *oauth2.Config {
+	base := fmt.Sprintf(BaseURLFormat, provider.Company)
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   base + "/authorize.php?request=authorize",
+			TokenURL:  base + "/token.php?request=token",
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+		Scopes: []string{},
+	}
+
+	if len(scopes) > 0 {
+		for _, scope := range scopes {
+			c.Scopes = append(c.Scopes, scope)
+		}
+	} else {
+		c.Scopes = []string{ScopeOpenID, ScopeEmail}
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	token := &oauth2.Token{RefreshToken: refreshToken}
+	ts := p.config.TokenSource(goth.ContextForClient(p.Client()), token)
+	newToken, err := ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	return newToken, err
+}
@@ -0,0 +1,87 @@
+package bamboohr_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/bamboohr"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("BAMBOOHR_KEY"))
+	a.Equal(p.Secret, os.Getenv("BAMBOOHR_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+	a.Equal(p.Company, "acme")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	session, err := p.BeginAuth("test_state")
+	s := session.(*bamboohr.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://acme.bamboohr.com/authorize.php?request=authorize&")
+	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("BAMBOOHR_KEY")))
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "scope=openid+email")
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://acme.bamboohr.com/authorize.php?request=authorize","AccessToken":"1234567890"}`)
+	a.NoError(err)
+
+	s := session.(*bamboohr.Session)
+	a.Equal(s.AuthURL, "https://acme.bamboohr.com/authorize.php?request=authorize")
+	a.Equal(s.AccessToken, "1234567890")
+}
+
+func Test_FetchUser(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/acme/v1/employees/0", r.URL.Path)
+		a.Contains(r.URL.Query().Get("fields"), "workEmail")
+		a.Equal("Bearer 1234567890", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"id":"123","firstName":"Charlotte","lastName":"Abbott","displayName":"Charlotte Abbott","workEmail":"cabbott@example.com","jobTitle":"Sr. HR Administrator","photoUrl":"https://example.com/photo.jpg"}`))
+	}))
+	defer ts.Close()
+
+	originalAPIURLFormat := bamboohr.APIURLFormat
+	bamboohr.APIURLFormat = ts.URL + "/%s"
+	defer func() { bamboohr.APIURLFormat = originalAPIURLFormat }()
+
+	user, err := provider().FetchUser(&bamboohr.Session{AccessToken: "1234567890", IDToken: "id-token"})
+	a.NoError(err)
+	a.Equal("123", user.UserID)
+	a.Equal("Charlotte", user.FirstName)
+	a.Equal("Abbott", user.LastName)
+	a.Equal("Charlotte Abbott", user.Name)
+	a.Equal("cabbott@example.com", user.Email)
+	a.Equal("Sr. HR Administrator", user.Description)
+	a.Equal("https://example.com/photo.jpg", user.AvatarURL)
+	a.Equal("id-token", user.IDToken)
+}
+
+func provider() *bamboohr.Provider {
+	return bamboohr.New(os.Getenv("BAMBOOHR_KEY"), os.Getenv("BAMBOOHR_SECRET"), "/foo", "acme")
+}
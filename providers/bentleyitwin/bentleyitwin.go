@@ -0,0 +1,195 @@
+// Package bentleyitwin implements the OAuth2 protocol for authenticating users through the Bentley iTwin Platform.
+package bentleyitwin
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+)
+
+// These vars define the Authentication, Token, and Profile URLS for Bentley iTwin.
+var (
+	AuthURL    = "https://ims.bentley.com/connect/authorize"
+	TokenURL   = "https://ims.bentley.com/connect/token"
+	ProfileURL = "https://api.bentley.com/users/me"
+)
+
+const (
+	// ScopeUsersRead allows to read the profile of the user, it is required by FetchUser.
+	ScopeUsersRead = "users:read"
+	// ScopeOfflineAccess is required to get a refresh token.
+	ScopeOfflineAccess = "offline_access"
+	// ScopeITwinsRead allows to read the iTwins the user has access to.
+	ScopeITwinsRead = "itwins:read"
+)
+
+// Provider is the implementation of `goth.Provider` for accessing Bentley iTwin.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	HTTPClient   *http.Client
+	config       *oauth2.Config
+	providerName string
+}
+
+// New creates a new Bentley iTwin provider and sets up important connection details.
+// You should always call `bentleyitwin.New` to get a new provider.  Never try to
+// create one manually.
+func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "bentleyitwin",
+	}
+	p.config = newConfig(p, scopes)
+	return p
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns an HTTP client to be used in all fetch operations.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// Debug is a no-op for the bentleyitwin package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks Bentley iTwin for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	return &Session{
+		AuthURL: p.config.AuthCodeURL(state),
+	}, nil
+}
+
+// FetchUser will go to Bentley iTwin and access basic information about the user.
+// The organization of the user is available in RawData as "organizationId" and "organizationName".
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	req, err := http.NewRequest("GET", ProfileURL, nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+	req.Header.Add("Accept", "application/vnd.bentley.itwin-platform.v1+json")
+
+	response, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+	}
+
+	bits, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return user, err
+	}
+
+	err = userFromReader(bytes.NewReader(bits), &user)
+	return user, err
+}
+
+// userFromReader reads the user from the "user" envelope iTwin wraps it in,
+// the content of the envelope is used as RawData.
+func userFromReader(r io.Reader, user *goth.User) error {
+	envelope := struct {
+		User json.RawMessage `json:"user"`
+	}{}
+	err := json.NewDecoder(r).Decode(&envelope)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(envelope.User, &user.RawData)
+	if err != nil {
+		return err
+	}
+
+	u := struct {
+		ID          string `json:"id"`
+		DisplayName string `json:"displayName"`
+		GivenName   string `json:"givenName"`
+		Surname     string `json:"surname"`
+		Email       string `json:"email"`
+	}{}
+	err = json.Unmarshal(envelope.User, &u)
+	if err != nil {
+		return err
+	}
+	user.UserID = u.ID
+	user.Name = u.DisplayName
+	user.FirstName = u.GivenName
+	user.LastName = u.Surname
+	user.Email = u.Email
+	return nil
+}
+
+func newConfig(provider *Provider, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   AuthURL,
+			TokenURL:  TokenURL,
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+		Scopes: []string{},
+	}
+
+	if len(scopes) > 0 {
+		for _, scope := range scopes {
+			c.Scopes = append(c.Scopes, scope)
+		}
+	} else {
+		c.Scopes = []string{ScopeUsersRead, ScopeOfflineAccess}
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	token := &oauth2.Token{RefreshToken: refreshToken}
+	ts := p.config.TokenSource(goth.ContextForClient(p.Client()), token)
+	newToken, err := ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	return newToken, err
+}
@@ -0,0 +1,82 @@
+package bentleyitwin_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/bentleyitwin"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("BENTLEY_ITWIN_KEY"))
+	a.Equal(p.Secret, os.Getenv("BENTLEY_ITWIN_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	session, err := p.BeginAuth("test_state")
+	s := session.(*bentleyitwin.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "ims.bentley.com/connect/authorize")
+	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("BENTLEY_ITWIN_KEY")))
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "scope=users%3Aread+offline_access")
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://ims.bentley.com/connect/authorize","AccessToken":"1234567890"}`)
+	a.NoError(err)
+
+	s := session.(*bentleyitwin.Session)
+	a.Equal(s.AuthURL, "https://ims.bentley.com/connect/authorize")
+	a.Equal(s.AccessToken, "1234567890")
+}
+
+func Test_FetchUser(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("Bearer 1234567890", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"user":{"id":"0f8b4a0a-6d5b-4e1a-9b3c-3a2f1e0d9c8b","displayName":"Jane Doe","givenName":"Jane","surname":"Doe","email":"jane@example.com","organizationName":"Acme Engineering","organizationId":"e1d2c3b4-a596-4877-8899-aabbccddeeff"}}`))
+	}))
+	defer ts.Close()
+
+	originalProfileURL := bentleyitwin.ProfileURL
+	bentleyitwin.ProfileURL = ts.URL
+	defer func() { bentleyitwin.ProfileURL = originalProfileURL }()
+
+	user, err := provider().FetchUser(&bentleyitwin.Session{AccessToken: "1234567890"})
+	a.NoError(err)
+	a.Equal("0f8b4a0a-6d5b-4e1a-9b3c-3a2f1e0d9c8b", user.UserID)
+	a.Equal("Jane Doe", user.Name)
+	a.Equal("Jane", user.FirstName)
+	a.Equal("Doe", user.LastName)
+	a.Equal("jane@example.com", user.Email)
+	a.Equal("Acme Engineering", user.RawData["organizationName"])
+}
+
+func provider() *bentleyitwin.Provider {
+	return bentleyitwin.New(os.Getenv("BENTLEY_ITWIN_KEY"), os.Getenv("BENTLEY_ITWIN_SECRET"), "/foo")
+}
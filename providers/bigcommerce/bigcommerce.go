@@ -0,0 +1,135 @@
+// Package bigcommerce implements the BigCommerce app installation OAuth flow.
+//
+// Installations are usually started from the store's control panel, which sends the
+// merchant straight to the app's auth callback, without a BeginAuth round trip. In that
+// callback create an empty Session, call Authorize with the callback's query parameters
+// (code, scope and context) and then FetchUser:
+//
+//	p, _ := goth.GetProvider("bigcommerce")
+//	sess := &bigcommerce.Session{}
+//	_, err := sess.Authorize(p, req.URL.Query())
+//	user, err := p.FetchUser(sess)
+//
+// BigCommerce access tokens do not expire, so there are no refresh tokens.
+package bigcommerce
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+)
+
+// These vars define the Installation and Token URLS for BigCommerce.
+var (
+	InstallURLFormat = "https://login.bigcommerce.com/app/%s/install"
+	TokenURL         = "https://login.bigcommerce.com/oauth2/token"
+)
+
+// Provider is the implementation of `goth.Provider` for accessing BigCommerce.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	HTTPClient   *http.Client
+	config       *oauth2.Config
+	providerName string
+}
+
+// New creates a new BigCommerce provider and sets up important connection details.
+// You should always call `bigcommerce.New` to get a new provider.  Never try to
+// create one manually.
+// The scopes are configured on the app in the BigCommerce developer portal.
+func New(clientKey, secret, callbackURL string) *Provider {
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "bigcommerce",
+	}
+	p.config = newConfig(p)
+	return p
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns an HTTP client to be used in all fetch operations.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// Debug is a no-op for the bigcommerce package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth returns a session pointing at the app's external install URL, which can be
+// used to start an installation from outside the BigCommerce control panel.
+// BigCommerce does not send the state back, so it is not part of the URL.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	return &Session{
+		AuthURL: fmt.Sprintf(InstallURLFormat, url.PathEscape(p.ClientKey)),
+	}, nil
+}
+
+// FetchUser returns the user that installed the app, as reported in the token response,
+// no request is made to BigCommerce.
+// The store is available in RawData as "store_hash" and "context", the owner of the
+// store as "owner_id" and "owner_email".
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken: sess.AccessToken,
+		Provider:    p.Name(),
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	user.UserID = sess.UserID
+	user.Email = sess.UserEmail
+	user.NickName = sess.Username
+	user.Name = sess.Username
+	user.RawData = map[string]interface{}{
+		"store_hash":   sess.StoreHash,
+		"context":      sess.Context,
+		"scope":        sess.Scope,
+		"owner_id":     sess.OwnerID,
+		"owner_email":  sess.OwnerEmail,
+		"account_uuid": sess.AccountUUID,
+	}
+	return user, nil
+}
+
+func newConfig(provider *Provider) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			TokenURL:  TokenURL,
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+	}
+}
+
+// RefreshTokenAvailable refresh token is not provided by BigCommerce
+func (p *Provider) RefreshTokenAvailable() bool {
+	return false
+}
+
+// RefreshToken refresh token is not provided by BigCommerce
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	return nil, errors.New("Refresh token is not provided by BigCommerce")
+}
@@ -0,0 +1,119 @@
+package bigcommerce_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/bigcommerce"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("BIGCOMMERCE_KEY"))
+	a.Equal(p.Secret, os.Getenv("BIGCOMMERCE_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := bigcommerce.New("abc123", "secret", "/foo")
+	session, err := p.BeginAuth("test_state")
+	s := session.(*bigcommerce.Session)
+	a.NoError(err)
+	a.Equal("https://login.bigcommerce.com/app/abc123/install", s.AuthURL)
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://login.bigcommerce.com/app/abc123/install","AccessToken":"1234567890","StoreHash":"z4zn3wo"}`)
+	a.NoError(err)
+
+	s := session.(*bigcommerce.Session)
+	a.Equal(s.AuthURL, "https://login.bigcommerce.com/app/abc123/install")
+	a.Equal(s.AccessToken, "1234567890")
+	a.Equal(s.StoreHash, "z4zn3wo")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.NoError(r.ParseForm())
+		a.Equal("authorization_code", r.Form.Get("grant_type"))
+		a.Equal("qr6h3thvbvag2ffq", r.Form.Get("code"))
+		a.Equal("store_v2_orders store_v2_products", r.Form.Get("scope"))
+		a.Equal("stores/z4zn3wo", r.Form.Get("context"))
+		a.Equal(os.Getenv("BIGCOMMERCE_SECRET"), r.Form.Get("client_secret"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"g3y3ab5mns36zhrpgtllr9o4i4gi21l","scope":"store_v2_orders store_v2_products","user":{"id":24654,"username":"merchant","email":"merchant@example.com"},"owner":{"id":24653,"username":"owner","email":"owner@example.com"},"context":"stores/z4zn3wo","account_uuid":"ffffffff-fffff-ffff-ffff-ffffffffffff"}`))
+	}))
+	defer ts.Close()
+
+	originalTokenURL := bigcommerce.TokenURL
+	bigcommerce.TokenURL = ts.URL
+	defer func() { bigcommerce.TokenURL = originalTokenURL }()
+
+	s := &bigcommerce.Session{}
+	token, err := s.Authorize(provider(), url.Values{
+		"code":    {"qr6h3thvbvag2ffq"},
+		"scope":   {"store_v2_orders store_v2_products"},
+		"context": {"stores/z4zn3wo"},
+	})
+	a.NoError(err)
+	a.Equal("g3y3ab5mns36zhrpgtllr9o4i4gi21l", token)
+	a.Equal("z4zn3wo", s.StoreHash)
+	a.Equal("24654", s.UserID)
+	a.Equal("merchant@example.com", s.UserEmail)
+	a.Equal("24653", s.OwnerID)
+	a.Equal("owner@example.com", s.OwnerEmail)
+}
+
+func Test_FetchUser(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	_, err := p.FetchUser(&bigcommerce.Session{})
+	a.Error(err)
+
+	user, err := p.FetchUser(&bigcommerce.Session{
+		AccessToken: "1234567890",
+		Context:     "stores/z4zn3wo",
+		StoreHash:   "z4zn3wo",
+		UserID:      "24654",
+		Username:    "merchant",
+		UserEmail:   "merchant@example.com",
+		OwnerID:     "24653",
+		OwnerEmail:  "owner@example.com",
+	})
+	a.NoError(err)
+	a.Equal("24654", user.UserID)
+	a.Equal("merchant@example.com", user.Email)
+	a.Equal("merchant", user.NickName)
+	a.Equal("z4zn3wo", user.RawData["store_hash"])
+	a.Equal("24653", user.RawData["owner_id"])
+	a.Equal("owner@example.com", user.RawData["owner_email"])
+	a.Equal("bigcommerce", user.Provider)
+}
+
+func provider() *bigcommerce.Provider {
+	return bigcommerce.New(os.Getenv("BIGCOMMERCE_KEY"), os.Getenv("BIGCOMMERCE_SECRET"), "/foo")
+}
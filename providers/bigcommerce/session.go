@@ -0,0 +1,91 @@
+package bigcommerce
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+)
+
+// Session stores data during the auth process with BigCommerce.
+type Session struct {
+	AuthURL     string
+	AccessToken string
+	Scope       string
+	Context     string
+	StoreHash   string
+	UserID      string
+	Username    string
+	UserEmail   string
+	OwnerID     string
+	OwnerEmail  string
+	AccountUUID string
+}
+
+var _ goth.Session = &Session{}
+
+// GetAuthURL will return the URL set by calling the `BeginAuth` function on the BigCommerce provider.
+func (s Session) GetAuthURL() (string, error) {
+	if s.AuthURL == "" {
+		return "", errors.New(goth.NoAuthUrlErrorMessage)
+	}
+	return s.AuthURL, nil
+}
+
+// Authorize the session with BigCommerce and return the access token to be stored for future use.
+// The params are the query parameters of the auth callback, BigCommerce requires the
+// scope and context it sent to be part of the token request.
+func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string, error) {
+	p := provider.(*Provider)
+	token, err := p.config.Exchange(goth.ContextForClient(p.Client()), params.Get("code"),
+		oauth2.SetAuthURLParam("scope", params.Get("scope")),
+		oauth2.SetAuthURLParam("context", params.Get("context")),
+	)
+	if err != nil {
+		return "", err
+	}
+
+	if !token.Valid() {
+		return "", errors.New("Invalid token received from provider")
+	}
+
+	s.AccessToken = token.AccessToken
+	s.Scope, _ = token.Extra("scope").(string)
+	s.Context, _ = token.Extra("context").(string)
+	s.StoreHash = strings.TrimPrefix(s.Context, "stores/")
+	s.AccountUUID, _ = token.Extra("account_uuid").(string)
+	s.UserID, s.Username, s.UserEmail = account(token.Extra("user"))
+	s.OwnerID, _, s.OwnerEmail = account(token.Extra("owner"))
+	return token.AccessToken, err
+}
+
+// account reads the id, username and email of a user or owner object of the token response.
+func account(v interface{}) (id, username, email string) {
+	m, _ := v.(map[string]interface{})
+	if n, ok := m["id"].(float64); ok {
+		id = fmt.Sprintf("%.0f", n)
+	}
+	username, _ = m["username"].(string)
+	email, _ = m["email"].(string)
+	return id, username, email
+}
+
+// Marshal the session into a string
+func (s Session) Marshal() string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+func (s Session) String() string {
+	return s.Marshal()
+}
+
+// UnmarshalSession will unmarshal a JSON string into a session.
+func (p *Provider) UnmarshalSession(data string) (goth.Session, error) {
+	s := &Session{}
+	err := json.NewDecoder(strings.NewReader(data)).Decode(s)
+	return s, err
+}
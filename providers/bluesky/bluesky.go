@@ -0,0 +1,303 @@
+// Package bluesky implements the AT Protocol OAuth profile for authenticating users
+// through Bluesky and any other atproto Personal Data Server.
+//
+// atproto OAuth differs from plain OAuth2 in a few ways that this package handles:
+// the client is identified by the URL of its metadata document (see ClientMetadata),
+// the authorization request is pushed to the server (PAR) and the tokens are bound
+// to a DPoP key held by the provider.
+// See https://atproto.com/specs/oauth
+package bluesky
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+)
+
+// DefaultServerURL is the entryway used when the user's handle is not known up front.
+var DefaultServerURL = "https://bsky.social"
+
+const (
+	// ScopeAtproto is required for every atproto OAuth session.
+	ScopeAtproto = "atproto"
+	// ScopeTransitionGeneric grants access comparable to an app password.
+	ScopeTransitionGeneric = "transition:generic"
+)
+
+// Provider is the implementation of `goth.Provider` for accessing Bluesky.
+type Provider struct {
+	// ClientKey is the client_id, which for atproto is the HTTPS URL where the
+	// document returned by ClientMetadata is served.
+	ClientKey   string
+	CallbackURL string
+	// ServerURL is the PDS or entryway used when BeginAuth is called without a handle.
+	ServerURL  string
+	HTTPClient *http.Client
+	// DPoPKey signs the DPoP proofs tokens are bound to. Refresh tokens can only be
+	// used with the key they were issued for, so set the same key on every instance
+	// of the application.
+	DPoPKey      *ecdsa.PrivateKey
+	scopes       []string
+	providerName string
+}
+
+// New creates a new Bluesky provider and sets up important connection details.
+// You should always call `bluesky.New` to get a new provider.  Never try to
+// create one manually.
+// A DPoP key is generated if dpopKey is nil.
+func New(clientID, callbackURL string, dpopKey *ecdsa.PrivateKey, scopes ...string) (*Provider, error) {
+	if dpopKey == nil {
+		var err error
+		dpopKey, err = GenerateDPoPKey()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	p := &Provider{
+		ClientKey:    clientID,
+		CallbackURL:  callbackURL,
+		ServerURL:    DefaultServerURL,
+		DPoPKey:      dpopKey,
+		scopes:       []string{ScopeAtproto},
+		providerName: "bluesky",
+	}
+	for _, scope := range scopes {
+		if scope != ScopeAtproto {
+			p.scopes = append(p.scopes, scope)
+		}
+	}
+	return p, nil
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns an HTTP client to be used in all fetch operations.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// Debug is a no-op for the bluesky package.
+func (p *Provider) Debug(debug bool) {}
+
+// ClientMetadata returns the client metadata document which must be served as JSON
+// at the ClientKey URL.
+func (p *Provider) ClientMetadata(clientName string) map[string]interface{} {
+	return map[string]interface{}{
+		"client_id":                  p.ClientKey,
+		"client_name":                clientName,
+		"application_type":           "web",
+		"grant_types":                []string{"authorization_code", "refresh_token"},
+		"response_types":             []string{"code"},
+		"scope":                      strings.Join(p.scopes, " "),
+		"redirect_uris":              []string{p.CallbackURL},
+		"token_endpoint_auth_method": "none",
+		"dpop_bound_access_tokens":   true,
+	}
+}
+
+// BeginAuth starts the authorization at the configured ServerURL, letting the user
+// enter their account there.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	return p.BeginAuthWithHandle(state, "")
+}
+
+// BeginAuthWithHandle resolves the handle (or DID) to the user's PDS, pushes the
+// authorization request to the authorization server protecting it and returns a
+// session pointing at the authorization endpoint.
+func (p *Provider) BeginAuthWithHandle(state, handle string) (goth.Session, error) {
+	session := &Session{State: state}
+
+	serverURL := p.ServerURL
+	if handle != "" {
+		did := handle
+		if !strings.HasPrefix(did, "did:") {
+			var err error
+			did, err = p.ResolveHandle(handle)
+			if err != nil {
+				return nil, err
+			}
+		}
+		pds, err := p.ResolvePDS(did)
+		if err != nil {
+			return nil, err
+		}
+		serverURL = pds
+		session.DID = did
+	}
+
+	as, err := p.ResolveAuthServer(serverURL)
+	if err != nil {
+		return nil, err
+	}
+
+	session.CodeVerifier, err = goth.GenerateCodeVerifier()
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"client_id":             {p.ClientKey},
+		"response_type":         {"code"},
+		"redirect_uri":          {p.CallbackURL},
+		"scope":                 {strings.Join(p.scopes, " ")},
+		"state":                 {state},
+		"code_challenge":        {goth.S256CodeChallenge(session.CodeVerifier)},
+		"code_challenge_method": {"S256"},
+	}
+	if handle != "" {
+		form.Set("login_hint", handle)
+	}
+
+	par := struct {
+		RequestURI string `json:"request_uri"`
+	}{}
+	session.DPoPNonce, err = p.postForm(as.PushedAuthorizationRequestEndpoint, form, "", &par)
+	if err != nil {
+		return nil, err
+	}
+
+	authURL, err := url.Parse(as.AuthorizationEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	query := authURL.Query()
+	query.Set("client_id", p.ClientKey)
+	query.Set("request_uri", par.RequestURI)
+	authURL.RawQuery = query.Encode()
+
+	session.AuthURL = authURL.String()
+	session.Issuer = as.Issuer
+	session.TokenEndpoint = as.TokenEndpoint
+	return session, nil
+}
+
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+	Scope        string `json:"scope"`
+	Sub          string `json:"sub"`
+}
+
+func (t tokenResponse) expiry() time.Time {
+	if t.ExpiresIn == 0 {
+		return time.Time{}
+	}
+	return time.Now().Add(time.Duration(t.ExpiresIn) * time.Second)
+}
+
+// FetchUser will go to the Bluesky AppView and access the public profile of the user.
+// The user's DID is used as UserID.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+		UserID:       sess.DID,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	bits, err := p.get(strings.TrimSuffix(AppViewURL, "/") + "/xrpc/app.bsky.actor.getProfile?actor=" + url.QueryEscape(sess.DID))
+	if err != nil {
+		return user, err
+	}
+
+	err = json.Unmarshal(bits, &user.RawData)
+	if err != nil {
+		return user, err
+	}
+
+	u := struct {
+		DID         string `json:"did"`
+		Handle      string `json:"handle"`
+		DisplayName string `json:"displayName"`
+		Description string `json:"description"`
+		Avatar      string `json:"avatar"`
+	}{}
+	err = json.Unmarshal(bits, &u)
+	if err != nil {
+		return user, err
+	}
+	if u.DID != sess.DID {
+		return user, fmt.Errorf("%s returned the profile of %s instead of %s", p.providerName, u.DID, sess.DID)
+	}
+
+	user.NickName = u.Handle
+	user.Name = u.DisplayName
+	if user.Name == "" {
+		user.Name = u.Handle
+	}
+	user.Description = u.Description
+	user.AvatarURL = u.Avatar
+	return user, nil
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token.
+// The token is refreshed at the authorization server of ServerURL, which is where
+// users of bsky.social-hosted accounts are authorized; sessions authorized elsewhere
+// should use Session.Refresh instead.
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	as, err := p.ResolveAuthServer(p.ServerURL)
+	if err != nil {
+		return nil, err
+	}
+	t, _, err := p.refresh(as.TokenEndpoint, refreshToken, "")
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (p *Provider) refresh(tokenEndpoint, refreshToken, nonce string) (*oauth2.Token, string, error) {
+	form := url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+		"client_id":     {p.ClientKey},
+	}
+
+	tr := tokenResponse{}
+	nonce, err := p.postForm(tokenEndpoint, form, nonce, &tr)
+	if err != nil {
+		return nil, nonce, err
+	}
+	if tr.AccessToken == "" {
+		return nil, nonce, errors.New("Invalid token received from provider")
+	}
+
+	t := &oauth2.Token{
+		AccessToken:  tr.AccessToken,
+		TokenType:    tr.TokenType,
+		RefreshToken: tr.RefreshToken,
+		Expiry:       tr.expiry(),
+	}
+	return t.WithExtra(map[string]interface{}{"sub": tr.Sub, "scope": tr.Scope}), nonce, nil
+}
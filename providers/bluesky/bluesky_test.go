@@ -0,0 +1,170 @@
+package bluesky_test
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/bluesky"
+	"github.com/stretchr/testify/assert"
+)
+
+const did = "did:plc:ewvi7nxzyoun6zhxrhs64oiz"
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, "https://app.example.com/client-metadata.json")
+	a.Equal(p.CallbackURL, "/foo")
+	a.NotNil(p.DPoPKey)
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_ClientMetadata(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	m := p.ClientMetadata("goth")
+	a.Equal("https://app.example.com/client-metadata.json", m["client_id"])
+	a.Equal("atproto transition:generic", m["scope"])
+	a.Equal(true, m["dpop_bound_access_tokens"])
+	a.Equal([]string{"/foo"}, m["redirect_uris"])
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://bsky.social/oauth/authorize","AccessToken":"1234567890","DID":"did:plc:abc"}`)
+	a.NoError(err)
+
+	s := session.(*bluesky.Session)
+	a.Equal(s.AuthURL, "https://bsky.social/oauth/authorize")
+	a.Equal(s.AccessToken, "1234567890")
+	a.Equal(s.DID, "did:plc:abc")
+}
+
+func Test_AuthFlow(t *testing.T) {
+	a := assert.New(t)
+
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/" + did:
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"id": did,
+				"service": []map[string]string{
+					{"id": "#atproto_pds", "type": "AtprotoPersonalDataServer", "serviceEndpoint": ts.URL},
+				},
+			})
+		case "/.well-known/oauth-protected-resource":
+			json.NewEncoder(w).Encode(map[string]interface{}{"authorization_servers": []string{ts.URL}})
+		case "/.well-known/oauth-authorization-server":
+			json.NewEncoder(w).Encode(map[string]string{
+				"issuer":                                ts.URL,
+				"authorization_endpoint":                ts.URL + "/oauth/authorize",
+				"token_endpoint":                        ts.URL + "/oauth/token",
+				"pushed_authorization_request_endpoint": ts.URL + "/oauth/par",
+			})
+		case "/oauth/par":
+			a.NotEmpty(r.Header.Get("DPoP"))
+			if !strings.Contains(r.Header.Get("DPoP"), ".") || r.FormValue("login_hint") != did {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if !dpopHasNonce(r) {
+				w.Header().Set("DPoP-Nonce", "nonce-1")
+				w.WriteHeader(http.StatusBadRequest)
+				w.Write([]byte(`{"error":"use_dpop_nonce"}`))
+				return
+			}
+			a.Equal("S256", r.FormValue("code_challenge_method"))
+			a.Equal("test_state", r.FormValue("state"))
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"request_uri":"urn:ietf:params:oauth:request_uri:req-1","expires_in":299}`))
+		case "/oauth/token":
+			a.True(dpopHasNonce(r))
+			a.Equal("authorization_code", r.FormValue("grant_type"))
+			a.Equal("code-1", r.FormValue("code"))
+			a.NotEmpty(r.FormValue("code_verifier"))
+			w.Write([]byte(`{"access_token":"1234567890","token_type":"DPoP","refresh_token":"refresh","expires_in":3600,"scope":"atproto","sub":"` + did + `"}`))
+		case "/xrpc/app.bsky.actor.getProfile":
+			a.Equal(did, r.URL.Query().Get("actor"))
+			w.Write([]byte(`{"did":"` + did + `","handle":"wash.bsky.social","displayName":"Hoban Washburne","description":"Pilot","avatar":"http://avatarURL"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	originalPLC, originalAppView := bluesky.PLCDirectoryURL, bluesky.AppViewURL
+	bluesky.PLCDirectoryURL, bluesky.AppViewURL = ts.URL, ts.URL
+	defer func() {
+		bluesky.PLCDirectoryURL, bluesky.AppViewURL = originalPLC, originalAppView
+	}()
+
+	p := provider()
+	session, err := p.BeginAuthWithHandle("test_state", did)
+	a.NoError(err)
+	s := session.(*bluesky.Session)
+	a.Contains(s.AuthURL, ts.URL+"/oauth/authorize?")
+	a.Contains(s.AuthURL, "request_uri=urn%3Aietf%3Aparams%3Aoauth%3Arequest_uri%3Areq-1")
+	a.Equal(ts.URL, s.Issuer)
+
+	_, err = s.Authorize(p, url.Values{"code": {"code-1"}, "state": {"other"}})
+	a.Error(err)
+
+	token, err := s.Authorize(p, url.Values{"code": {"code-1"}, "state": {"test_state"}, "iss": {ts.URL}})
+	a.NoError(err)
+	a.Equal("1234567890", token)
+	a.Equal(did, s.DID)
+
+	user, err := p.FetchUser(s)
+	a.NoError(err)
+	a.Equal(did, user.UserID)
+	a.Equal("wash.bsky.social", user.NickName)
+	a.Equal("Hoban Washburne", user.Name)
+	a.Equal("Pilot", user.Description)
+	a.Equal("http://avatarURL", user.AvatarURL)
+	a.Equal("refresh", user.RefreshToken)
+}
+
+func dpopHasNonce(r *http.Request) bool {
+	parts := strings.Split(r.Header.Get("DPoP"), ".")
+	if len(parts) != 3 {
+		return false
+	}
+	claims, err := decodeSegment(parts[1])
+	if err != nil {
+		return false
+	}
+	return claims["nonce"] == "nonce-1" && claims["htm"] == "POST"
+}
+
+func decodeSegment(seg string) (map[string]interface{}, error) {
+	bits, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return nil, err
+	}
+	m := map[string]interface{}{}
+	return m, json.Unmarshal(bits, &m)
+}
+
+func provider() *bluesky.Provider {
+	p, _ := bluesky.New("https://app.example.com/client-metadata.json", "/foo", nil, bluesky.ScopeTransitionGeneric)
+	return p
+}
@@ -0,0 +1,114 @@
+package bluesky
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// GenerateDPoPKey creates a new ES256 key suitable to sign DPoP proofs.
+func GenerateDPoPKey() (*ecdsa.PrivateKey, error) {
+	return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+}
+
+// dpopProof creates a DPoP proof JWT for a request, as described in
+// https://datatracker.ietf.org/doc/html/rfc9449#section-4
+func dpopProof(key *ecdsa.PrivateKey, method, target, nonce string) (string, error) {
+	jti := make([]byte, 16)
+	if _, err := io.ReadFull(rand.Reader, jti); err != nil {
+		return "", err
+	}
+
+	claims := jwt.MapClaims{
+		"jti": base64.RawURLEncoding.EncodeToString(jti),
+		"htm": method,
+		"htu": target,
+		"iat": time.Now().Unix(),
+	}
+	if nonce != "" {
+		claims["nonce"] = nonce
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["typ"] = "dpop+jwt"
+	token.Header["jwk"] = map[string]string{
+		"kty": "EC",
+		"crv": "P-256",
+		"x":   base64.RawURLEncoding.EncodeToString(padded(key.X.Bytes())),
+		"y":   base64.RawURLEncoding.EncodeToString(padded(key.Y.Bytes())),
+	}
+	return token.SignedString(key)
+}
+
+func padded(b []byte) []byte {
+	if len(b) >= 32 {
+		return b
+	}
+	return append(make([]byte, 32-len(b)), b...)
+}
+
+// oauthError is the error body returned by the authorization server.
+type oauthError struct {
+	Error       string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+// postForm sends a DPoP-bound form POST to the authorization server, retrying once
+// with the server-provided nonce when it asks for one. It returns the latest nonce
+// so it can be reused for subsequent requests.
+func (p *Provider) postForm(endpoint string, form url.Values, nonce string, v interface{}) (string, error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		proof, err := dpopProof(p.DPoPKey, "POST", endpoint, nonce)
+		if err != nil {
+			return nonce, err
+		}
+
+		req, err := http.NewRequest("POST", endpoint, strings.NewReader(form.Encode()))
+		if err != nil {
+			return nonce, err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("Accept", "application/json")
+		req.Header.Set("DPoP", proof)
+
+		response, err := p.Client().Do(req)
+		if err != nil {
+			return nonce, err
+		}
+		bits, err := ioutil.ReadAll(response.Body)
+		response.Body.Close()
+		if err != nil {
+			return nonce, err
+		}
+
+		if n := response.Header.Get("DPoP-Nonce"); n != "" {
+			nonce = n
+		}
+
+		if response.StatusCode >= 200 && response.StatusCode < 300 {
+			return nonce, json.Unmarshal(bits, v)
+		}
+
+		oerr := oauthError{}
+		json.Unmarshal(bits, &oerr)
+		if oerr.Error == "use_dpop_nonce" && attempt == 0 {
+			continue
+		}
+		if oerr.Error != "" {
+			return nonce, fmt.Errorf("%s responded with %s: %s", p.providerName, oerr.Error, oerr.Description)
+		}
+		return nonce, fmt.Errorf("%s responded with a %d calling %s", p.providerName, response.StatusCode, endpoint)
+	}
+	return nonce, fmt.Errorf("%s kept rejecting the DPoP nonce", p.providerName)
+}
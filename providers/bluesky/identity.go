@@ -0,0 +1,152 @@
+package bluesky
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// These vars define the services used to resolve identities and profiles.
+var (
+	// PLCDirectoryURL resolves did:plc identifiers to DID documents.
+	PLCDirectoryURL = "https://plc.directory"
+	// AppViewURL serves public profiles.
+	AppViewURL = "https://public.api.bsky.app"
+)
+
+// lookupTXT is swapped in tests.
+var lookupTXT = net.LookupTXT
+
+// AuthServer describes the OAuth authorization server metadata of a PDS.
+// See https://atproto.com/specs/oauth#authorization-servers
+type AuthServer struct {
+	Issuer                             string `json:"issuer"`
+	AuthorizationEndpoint              string `json:"authorization_endpoint"`
+	TokenEndpoint                      string `json:"token_endpoint"`
+	PushedAuthorizationRequestEndpoint string `json:"pushed_authorization_request_endpoint"`
+}
+
+// ResolveHandle resolves a handle (e.g. "alice.bsky.social") to a DID, first through
+// the _atproto DNS TXT record and then through the /.well-known/atproto-did endpoint.
+func (p *Provider) ResolveHandle(handle string) (string, error) {
+	handle = strings.ToLower(strings.TrimPrefix(handle, "@"))
+
+	if records, err := lookupTXT("_atproto." + handle); err == nil {
+		for _, r := range records {
+			if strings.HasPrefix(r, "did=") {
+				return strings.TrimPrefix(r, "did="), nil
+			}
+		}
+	}
+
+	bits, err := p.get("https://" + handle + "/.well-known/atproto-did")
+	if err != nil {
+		return "", fmt.Errorf("%s cannot resolve handle %s: %v", p.providerName, handle, err)
+	}
+	did := strings.TrimSpace(string(bits))
+	if !strings.HasPrefix(did, "did:") {
+		return "", fmt.Errorf("%s cannot resolve handle %s", p.providerName, handle)
+	}
+	return did, nil
+}
+
+// ResolvePDS resolves a DID to the URL of the user's Personal Data Server.
+func (p *Provider) ResolvePDS(did string) (string, error) {
+	var docURL string
+	switch {
+	case strings.HasPrefix(did, "did:plc:"):
+		docURL = strings.TrimSuffix(PLCDirectoryURL, "/") + "/" + did
+	case strings.HasPrefix(did, "did:web:"):
+		docURL = "https://" + strings.TrimPrefix(did, "did:web:") + "/.well-known/did.json"
+	default:
+		return "", fmt.Errorf("%s does not support the DID method of %s", p.providerName, did)
+	}
+
+	bits, err := p.get(docURL)
+	if err != nil {
+		return "", err
+	}
+
+	doc := struct {
+		ID      string `json:"id"`
+		Service []struct {
+			ID              string `json:"id"`
+			Type            string `json:"type"`
+			ServiceEndpoint string `json:"serviceEndpoint"`
+		} `json:"service"`
+	}{}
+	if err := json.Unmarshal(bits, &doc); err != nil {
+		return "", err
+	}
+	if doc.ID != did {
+		return "", fmt.Errorf("%s received a DID document for %s instead of %s", p.providerName, doc.ID, did)
+	}
+
+	for _, s := range doc.Service {
+		if strings.HasSuffix(s.ID, "#atproto_pds") && s.Type == "AtprotoPersonalDataServer" {
+			return strings.TrimSuffix(s.ServiceEndpoint, "/"), nil
+		}
+	}
+	return "", fmt.Errorf("%s found no PDS in the DID document of %s", p.providerName, did)
+}
+
+// ResolveAuthServer finds the authorization server protecting the given PDS
+// (or entryway) and fetches its metadata.
+func (p *Provider) ResolveAuthServer(pdsURL string) (*AuthServer, error) {
+	pdsURL = strings.TrimSuffix(pdsURL, "/")
+
+	issuer := pdsURL
+	bits, err := p.get(pdsURL + "/.well-known/oauth-protected-resource")
+	if err == nil {
+		resource := struct {
+			AuthorizationServers []string `json:"authorization_servers"`
+		}{}
+		if err := json.Unmarshal(bits, &resource); err != nil {
+			return nil, err
+		}
+		if len(resource.AuthorizationServers) > 0 {
+			issuer = strings.TrimSuffix(resource.AuthorizationServers[0], "/")
+		}
+	}
+
+	bits, err = p.get(issuer + "/.well-known/oauth-authorization-server")
+	if err != nil {
+		return nil, err
+	}
+
+	as := &AuthServer{}
+	if err := json.Unmarshal(bits, as); err != nil {
+		return nil, err
+	}
+	if as.Issuer != issuer {
+		return nil, fmt.Errorf("%s received metadata for issuer %s instead of %s", p.providerName, as.Issuer, issuer)
+	}
+	if as.PushedAuthorizationRequestEndpoint == "" {
+		return nil, errors.New("authorization server does not support pushed authorization requests")
+	}
+	return as, nil
+}
+
+func (p *Provider) get(url string) ([]byte, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Accept", "application/json")
+
+	response, err := p.Client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s responded with a %d trying to fetch %s", p.providerName, response.StatusCode, url)
+	}
+
+	return ioutil.ReadAll(response.Body)
+}
@@ -0,0 +1,132 @@
+package bluesky
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/markbates/goth"
+)
+
+// Session stores data during the auth process with Bluesky.
+type Session struct {
+	AuthURL       string
+	State         string
+	CodeVerifier  string
+	Issuer        string
+	TokenEndpoint string
+	DPoPNonce     string
+	DID           string
+	AccessToken   string
+	RefreshToken  string
+	ExpiresAt     time.Time
+}
+
+var _ goth.Session = &Session{}
+
+// GetAuthURL will return the URL set by calling the `BeginAuth` function on the Bluesky provider.
+func (s Session) GetAuthURL() (string, error) {
+	if s.AuthURL == "" {
+		return "", errors.New(goth.NoAuthUrlErrorMessage)
+	}
+	return s.AuthURL, nil
+}
+
+// Authorize the session with Bluesky and return the access token to be stored for future use.
+// Since the state is not part of the pushed AuthURL, it is checked here, together with
+// the issuer and the account (DID) the token was issued for.
+func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string, error) {
+	p := provider.(*Provider)
+
+	if params.Get("state") != s.State {
+		return "", errors.New("state token mismatch")
+	}
+	if iss := params.Get("iss"); iss != "" && iss != s.Issuer {
+		return "", fmt.Errorf("%s: unexpected issuer %s", p.providerName, iss)
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {params.Get("code")},
+		"redirect_uri":  {p.CallbackURL},
+		"client_id":     {p.ClientKey},
+		"code_verifier": {s.CodeVerifier},
+	}
+
+	tr := tokenResponse{}
+	nonce, err := p.postForm(s.TokenEndpoint, form, s.DPoPNonce, &tr)
+	s.DPoPNonce = nonce
+	if err != nil {
+		return "", err
+	}
+
+	if tr.AccessToken == "" || !strings.EqualFold(tr.TokenType, "DPoP") {
+		return "", errors.New("Invalid token received from provider")
+	}
+	if !strings.HasPrefix(tr.Sub, "did:") {
+		return "", fmt.Errorf("%s: invalid subject %q", p.providerName, tr.Sub)
+	}
+
+	if s.DID != "" {
+		if s.DID != tr.Sub {
+			return "", fmt.Errorf("%s: token issued for %s instead of %s", p.providerName, tr.Sub, s.DID)
+		}
+	} else {
+		// the account was picked at the authorization server, make sure it is
+		// authoritative for it
+		pds, err := p.ResolvePDS(tr.Sub)
+		if err != nil {
+			return "", err
+		}
+		as, err := p.ResolveAuthServer(pds)
+		if err != nil {
+			return "", err
+		}
+		if as.Issuer != s.Issuer {
+			return "", fmt.Errorf("%s: %s is not authoritative for %s", p.providerName, s.Issuer, tr.Sub)
+		}
+	}
+
+	s.DID = tr.Sub
+	s.AccessToken = tr.AccessToken
+	s.RefreshToken = tr.RefreshToken
+	s.ExpiresAt = tr.expiry()
+	return s.AccessToken, nil
+}
+
+// Refresh gets a new access token from the authorization server that issued the
+// session's tokens.
+func (s *Session) Refresh(p *Provider) error {
+	t, nonce, err := p.refresh(s.TokenEndpoint, s.RefreshToken, s.DPoPNonce)
+	s.DPoPNonce = nonce
+	if err != nil {
+		return err
+	}
+	if sub, _ := t.Extra("sub").(string); sub != s.DID {
+		return fmt.Errorf("%s: token issued for %s instead of %s", p.providerName, sub, s.DID)
+	}
+	s.AccessToken = t.AccessToken
+	s.RefreshToken = t.RefreshToken
+	s.ExpiresAt = t.Expiry
+	return nil
+}
+
+// Marshal the session into a string
+func (s Session) Marshal() string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+func (s Session) String() string {
+	return s.Marshal()
+}
+
+// UnmarshalSession will unmarshal a JSON string into a session.
+func (p *Provider) UnmarshalSession(data string) (goth.Session, error) {
+	s := &Session{}
+	err := json.NewDecoder(strings.NewReader(data)).Decode(s)
+	return s, err
+}
@@ -0,0 +1,47 @@
+package bluesky_test
+
+import (
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/bluesky"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func Test_Implements_Session(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	s := &bluesky.Session{}
+
+	a.Implements((*goth.Session)(nil), s)
+}
+
+func Test_GetAuthURL(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	s := &bluesky.Session{}
+
+	_, err := s.GetAuthURL()
+	a.Error(err)
+
+	s.AuthURL = "/foo"
+
+	url, _ := s.GetAuthURL()
+	a.Equal(url, "/foo")
+}
+
+func Test_ToJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	s := &bluesky.Session{}
+
+	data := s.Marshal()
+	a.Equal(data, `{"AuthURL":"","State":"","CodeVerifier":"","Issuer":"","TokenEndpoint":"","DPoPNonce":"","DID":"","AccessToken":"","RefreshToken":"","ExpiresAt":"0001-01-01T00:00:00Z"}`)
+}
+
+func Test_String(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	s := &bluesky.Session{}
+
+	a.Equal(s.String(), s.Marshal())
+}
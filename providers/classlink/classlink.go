@@ -0,0 +1,191 @@
+// Package classlink implements the OAuth2 protocol for authenticating users through ClassLink.
+//
+// ClassLink users belong to a tenant, usually a school district. The tenant and the role
+// of the user (e.g. "Student" or "Teacher") are available in RawData under "TenantId"
+// and "Role".
+package classlink
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+)
+
+// These vars define the Authentication, Token, and API URLS for ClassLink.
+var (
+	AuthURL  = "https://launchpad.classlink.com/oauth2/v2/auth"
+	TokenURL = "https://launchpad.classlink.com/oauth2/v2/token"
+	APIURL   = "https://nodeapi.classlink.com"
+)
+
+const (
+	// ScopeProfile grants access to the user's profile.
+	ScopeProfile = "profile"
+	// ScopeOneRoster grants access to the user's OneRoster data, e.g. classes.
+	ScopeOneRoster = "oneroster"
+	// ScopeFull grants access to all the data of the user.
+	ScopeFull = "full"
+)
+
+// Provider is the implementation of `goth.Provider` for accessing ClassLink.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	HTTPClient   *http.Client
+	config       *oauth2.Config
+	providerName string
+}
+
+// New creates a new ClassLink provider and sets up important connection details.
+// You should always call `classlink.New` to get a new provider.  Never try to
+// create one manually.
+func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "classlink",
+	}
+	p.config = newConfig(p, scopes)
+	return p
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns an HTTP client to be used in all fetch operations.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// Debug is a no-op for the classlink package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks ClassLink for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	return &Session{
+		AuthURL: p.config.AuthCodeURL(state),
+	}, nil
+}
+
+// FetchUser will go to ClassLink and access basic information about the user.
+// The OneRoster id of the user is available in RawData under "SourcedId".
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken: sess.AccessToken,
+		Provider:    p.Name(),
+		ExpiresAt:   sess.ExpiresAt,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	req, err := http.NewRequest("GET", APIURL+"/v2/my/info", nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+
+	response, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+	}
+
+	bits, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return user, err
+	}
+
+	err = json.NewDecoder(bytes.NewReader(bits)).Decode(&user.RawData)
+	if err != nil {
+		return user, err
+	}
+
+	err = userFromReader(bytes.NewReader(bits), &user)
+	return user, err
+}
+
+func userFromReader(r io.Reader, user *goth.User) error {
+	u := struct {
+		UserID      int64  `json:"UserId"`
+		LoginID     string `json:"LoginId"`
+		DisplayName string `json:"DisplayName"`
+		FirstName   string `json:"FirstName"`
+		LastName    string `json:"LastName"`
+		Email       string `json:"Email"`
+		ImagePath   string `json:"ImagePath"`
+		Tenant      string `json:"Tenant"`
+		Role        string `json:"Role"`
+	}{}
+	err := json.NewDecoder(r).Decode(&u)
+	if err != nil {
+		return err
+	}
+	user.UserID = strconv.FormatInt(u.UserID, 10)
+	user.NickName = u.LoginID
+	user.Name = u.DisplayName
+	user.FirstName = u.FirstName
+	user.LastName = u.LastName
+	user.Email = u.Email
+	user.AvatarURL = u.ImagePath
+	user.Description = u.Role
+	user.Location = u.Tenant
+	return nil
+}
+
+func newConfig(provider *Provider, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   AuthURL,
+			TokenURL:  TokenURL,
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+		Scopes: []string{},
+	}
+
+	if len(scopes) > 0 {
+		for _, scope := range scopes {
+			c.Scopes = append(c.Scopes, scope)
+		}
+	} else {
+		c.Scopes = []string{ScopeProfile}
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is not provided by ClassLink
+func (p *Provider) RefreshTokenAvailable() bool {
+	return false
+}
+
+// RefreshToken refresh token is not provided by ClassLink
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	return nil, errors.New("Refresh token is not provided by ClassLink")
+}
@@ -0,0 +1,86 @@
+package classlink_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/classlink"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("CLASSLINK_KEY"))
+	a.Equal(p.Secret, os.Getenv("CLASSLINK_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	session, err := p.BeginAuth("test_state")
+	s := session.(*classlink.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://launchpad.classlink.com/oauth2/v2/auth")
+	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("CLASSLINK_KEY")))
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "scope=profile")
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://launchpad.classlink.com/oauth2/v2/auth","AccessToken":"1234567890"}`)
+	a.NoError(err)
+
+	s := session.(*classlink.Session)
+	a.Equal(s.AuthURL, "https://launchpad.classlink.com/oauth2/v2/auth")
+	a.Equal(s.AccessToken, "1234567890")
+}
+
+func Test_FetchUser(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/v2/my/info", r.URL.Path)
+		a.Equal("Bearer 1234567890", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"UserId":1234567,"LoginId":"jdoe","TenantId":2001,"BuildingId":5,"AuthenticationType":1,"DisplayName":"Jane Doe","FirstName":"Jane","LastName":"Doe","Email":"jdoe@example.org","ImagePath":"https://filesusr.classlink.com/jdoe.png","LanguageId":1,"Language":"English","DefaultTimeZoneId":1,"ProfileId":3,"RoleLevel":1,"Tenant":"Demo District","Role":"Teacher","SourcedId":"t-123"}`))
+	}))
+	defer ts.Close()
+
+	originalAPIURL := classlink.APIURL
+	classlink.APIURL = ts.URL
+	defer func() { classlink.APIURL = originalAPIURL }()
+
+	p := provider()
+	user, err := p.FetchUser(&classlink.Session{AccessToken: "1234567890"})
+	a.NoError(err)
+	a.Equal("1234567", user.UserID)
+	a.Equal("jdoe", user.NickName)
+	a.Equal("Jane Doe", user.Name)
+	a.Equal("jdoe@example.org", user.Email)
+	a.Equal("Teacher", user.Description)
+	a.Equal("Demo District", user.Location)
+	a.Equal(float64(2001), user.RawData["TenantId"])
+	a.Equal("Teacher", user.RawData["Role"])
+}
+
+func provider() *classlink.Provider {
+	return classlink.New(os.Getenv("CLASSLINK_KEY"), os.Getenv("CLASSLINK_SECRET"), "/foo")
+}
@@ -0,0 +1,324 @@
+// Package clever implements the OAuth2 protocol for authenticating users through Clever.
+//
+// Clever users are students, teachers, staff or district admins of a school district.
+// FetchUser resolves which of them the user is, the type is available in RawData under
+// "user_type" and the district under "district".
+package clever
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+)
+
+// These vars define the Authentication, Token, and API URLS for Clever.
+var (
+	AuthURL  = "https://clever.com/oauth/authorize"
+	TokenURL = "https://clever.com/oauth/tokens"
+	APIURL   = "https://api.clever.com/v3.0"
+)
+
+// These are the user types of Clever, in the order they are resolved in when a user
+// has several roles.
+const (
+	UserTypeDistrictAdmin = "district_admin"
+	UserTypeStaff         = "staff"
+	UserTypeTeacher       = "teacher"
+	UserTypeStudent       = "student"
+)
+
+var userTypes = []string{UserTypeDistrictAdmin, UserTypeStaff, UserTypeTeacher, UserTypeStudent}
+
+// Provider is the implementation of `goth.Provider` for accessing Clever.
+type Provider struct {
+	ClientKey   string
+	Secret      string
+	CallbackURL string
+	// DistrictID, when set, restricts the login to the users of the given district,
+	// and skips the district picker of Clever.
+	DistrictID   string
+	HTTPClient   *http.Client
+	config       *oauth2.Config
+	providerName string
+}
+
+// New creates a new Clever provider and sets up important connection details.
+// You should always call `clever.New` to get a new provider.  Never try to
+// create one manually.
+// The scopes are configured on the app in the Clever dashboard.
+func New(clientKey, secret, callbackURL string) *Provider {
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "clever",
+	}
+	p.config = newConfig(p)
+	return p
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns an HTTP client to be used in all fetch operations.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// Debug is a no-op for the clever package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks Clever for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	opts := []oauth2.AuthCodeOption{}
+	if p.DistrictID != "" {
+		opts = append(opts, oauth2.SetAuthURLParam("district_id", p.DistrictID))
+	}
+	return &Session{
+		AuthURL: p.config.AuthCodeURL(state, opts...),
+	}, nil
+}
+
+// FetchUser will go to Clever and access basic information about the user.
+// The roles of the user are available in RawData under "roles".
+// When the session holds a district token, see DistrictTokens, the district is returned
+// as the user and "user_type" is "district".
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken: sess.AccessToken,
+		Provider:    p.Name(),
+		ExpiresAt:   sess.ExpiresAt,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	me := struct {
+		Type string `json:"type"`
+		Data struct {
+			ID       string `json:"id"`
+			District string `json:"district"`
+		} `json:"data"`
+	}{}
+	bits, err := p.get(sess.AccessToken, "/me")
+	if err != nil {
+		return user, err
+	}
+	err = json.Unmarshal(bits, &me)
+	if err != nil {
+		return user, err
+	}
+	if me.Data.ID == "" {
+		return user, fmt.Errorf("%s did not return the user of the token", p.providerName)
+	}
+
+	if me.Type == "district" {
+		// district tokens are not bound to a user, the district is used instead
+		bits, err = p.get(sess.AccessToken, "/districts/"+url.PathEscape(me.Data.ID))
+		if err != nil {
+			return user, err
+		}
+		err = districtFromReader(bytes.NewReader(bits), &user)
+		return user, err
+	}
+
+	bits, err = p.get(sess.AccessToken, "/users/"+url.PathEscape(me.Data.ID))
+	if err != nil {
+		return user, err
+	}
+
+	err = userFromReader(bytes.NewReader(bits), &user)
+	if err != nil {
+		return user, err
+	}
+	if _, ok := user.RawData["district"]; !ok {
+		user.RawData["district"] = me.Data.District
+	}
+	return user, nil
+}
+
+// get reads a resource of the Clever API with the access token.
+func (p *Provider) get(accessToken, path string) ([]byte, error) {
+	req, err := http.NewRequest("GET", APIURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Authorization", "Bearer "+accessToken)
+
+	response, err := p.Client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+	}
+
+	return ioutil.ReadAll(response.Body)
+}
+
+// userFromReader reads the user from the "data" envelope Clever wraps it in, the
+// content of the envelope is used as RawData.
+func userFromReader(r io.Reader, user *goth.User) error {
+	envelope := struct {
+		Data json.RawMessage `json:"data"`
+	}{}
+	err := json.NewDecoder(r).Decode(&envelope)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(envelope.Data, &user.RawData)
+	if err != nil {
+		return err
+	}
+
+	u := struct {
+		ID    string `json:"id"`
+		Email string `json:"email"`
+		Name  struct {
+			First string `json:"first"`
+			Last  string `json:"last"`
+		} `json:"name"`
+		Roles map[string]json.RawMessage `json:"roles"`
+	}{}
+	err = json.Unmarshal(envelope.Data, &u)
+	if err != nil {
+		return err
+	}
+	if user.RawData == nil {
+		return errors.New("Invalid user received from provider")
+	}
+
+	user.UserID = u.ID
+	user.Email = u.Email
+	user.FirstName = u.Name.First
+	user.LastName = u.Name.Last
+	user.Name = strings.TrimSpace(u.Name.First + " " + u.Name.Last)
+	user.RawData["user_type"] = userType(u.Roles)
+	return nil
+}
+
+// districtFromReader reads a district from the "data" envelope Clever wraps it in.
+func districtFromReader(r io.Reader, user *goth.User) error {
+	envelope := struct {
+		Data json.RawMessage `json:"data"`
+	}{}
+	err := json.NewDecoder(r).Decode(&envelope)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(envelope.Data, &user.RawData)
+	if err != nil {
+		return err
+	}
+	if user.RawData == nil {
+		return errors.New("Invalid district received from provider")
+	}
+
+	d := struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}{}
+	err = json.Unmarshal(envelope.Data, &d)
+	if err != nil {
+		return err
+	}
+	user.UserID = d.ID
+	user.Name = d.Name
+	user.RawData["district"] = d.ID
+	user.RawData["user_type"] = "district"
+	return nil
+}
+
+// userType resolves the type of a user from its roles, favoring the roles with the most
+// privileges.
+func userType(roles map[string]json.RawMessage) string {
+	for _, t := range userTypes {
+		if _, ok := roles[t]; ok {
+			return t
+		}
+	}
+	return ""
+}
+
+// DistrictToken is the token of a district that authorized the app.
+type DistrictToken struct {
+	ID          string   `json:"id"`
+	AccessToken string   `json:"access_token"`
+	Scopes      []string `json:"scopes"`
+	Owner       struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+	} `json:"owner"`
+}
+
+// DistrictTokens lists the tokens of the districts that authorized the app, they give
+// access to the data of a whole district rather than of a single user.
+func (p *Provider) DistrictTokens() ([]DistrictToken, error) {
+	req, err := http.NewRequest("GET", TokenURL+"?owner_type=district", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(p.ClientKey, p.Secret)
+
+	response, err := p.Client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s responded with a %d trying to list district tokens", p.providerName, response.StatusCode)
+	}
+
+	tokens := struct {
+		Data []DistrictToken `json:"data"`
+	}{}
+	err = json.NewDecoder(response.Body).Decode(&tokens)
+	return tokens.Data, err
+}
+
+func newConfig(provider *Provider) *oauth2.Config {
+	return &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   AuthURL,
+			TokenURL:  TokenURL,
+			AuthStyle: oauth2.AuthStyleInHeader,
+		},
+	}
+}
+
+// RefreshTokenAvailable refresh token is not provided by Clever
+func (p *Provider) RefreshTokenAvailable() bool {
+	return false
+}
+
+// RefreshToken refresh token is not provided by Clever
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	return nil, errors.New("Refresh token is not provided by Clever")
+}
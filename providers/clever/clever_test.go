@@ -0,0 +1,175 @@
+package clever_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/clever"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("CLEVER_KEY"))
+	a.Equal(p.Secret, os.Getenv("CLEVER_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	session, err := p.BeginAuth("test_state")
+	s := session.(*clever.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://clever.com/oauth/authorize")
+	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("CLEVER_KEY")))
+	a.Contains(s.AuthURL, "state=test_state")
+	a.NotContains(s.AuthURL, "district_id=")
+}
+
+func Test_BeginAuthWithDistrict(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	p.DistrictID = "5327a245c79f90670e001b78"
+	session, err := p.BeginAuth("test_state")
+	s := session.(*clever.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "district_id=5327a245c79f90670e001b78")
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://clever.com/oauth/authorize","AccessToken":"1234567890"}`)
+	a.NoError(err)
+
+	s := session.(*clever.Session)
+	a.Equal(s.AuthURL, "https://clever.com/oauth/authorize")
+	a.Equal(s.AccessToken, "1234567890")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, pass, ok := r.BasicAuth()
+		a.True(ok)
+		a.Equal("client-id", user)
+		a.Equal("client-secret", pass)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"il_1234567890","token_type":"bearer"}`))
+	}))
+	defer ts.Close()
+
+	originalTokenURL := clever.TokenURL
+	clever.TokenURL = ts.URL
+	defer func() { clever.TokenURL = originalTokenURL }()
+
+	p := clever.New("client-id", "client-secret", "/foo")
+	s := &clever.Session{}
+	token, err := s.Authorize(p, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("il_1234567890", token)
+}
+
+func Test_FetchUser(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("Bearer 1234567890", r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/me":
+			w.Write([]byte(`{"type":"user","data":{"id":"5b2ad81a709e300001e2cd7a","district":"5b2ad81a709e300001e2cd7b","type":"user","authorized_by":"district"}}`))
+		case "/users/5b2ad81a709e300001e2cd7a":
+			w.Write([]byte(`{"data":{"id":"5b2ad81a709e300001e2cd7a","district":"5b2ad81a709e300001e2cd7b","email":"jane.doe@example.com","name":{"first":"Jane","last":"Doe"},"roles":{"teacher":{"district":"5b2ad81a709e300001e2cd7b"},"student":{"district":"5b2ad81a709e300001e2cd7b"}}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	originalAPIURL := clever.APIURL
+	clever.APIURL = ts.URL
+	defer func() { clever.APIURL = originalAPIURL }()
+
+	p := provider()
+	user, err := p.FetchUser(&clever.Session{AccessToken: "1234567890"})
+	a.NoError(err)
+	a.Equal("5b2ad81a709e300001e2cd7a", user.UserID)
+	a.Equal("Jane Doe", user.Name)
+	a.Equal("jane.doe@example.com", user.Email)
+	a.Equal(clever.UserTypeTeacher, user.RawData["user_type"])
+	a.Equal("5b2ad81a709e300001e2cd7b", user.RawData["district"])
+}
+
+func Test_FetchUserWithDistrictToken(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/me":
+			w.Write([]byte(`{"type":"district","data":{"id":"5b2ad81a709e300001e2cd7b","type":"district"}}`))
+		case "/districts/5b2ad81a709e300001e2cd7b":
+			w.Write([]byte(`{"data":{"id":"5b2ad81a709e300001e2cd7b","name":"Demo District"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	originalAPIURL := clever.APIURL
+	clever.APIURL = ts.URL
+	defer func() { clever.APIURL = originalAPIURL }()
+
+	p := provider()
+	user, err := p.FetchUser(&clever.Session{AccessToken: "1234567890"})
+	a.NoError(err)
+	a.Equal("5b2ad81a709e300001e2cd7b", user.UserID)
+	a.Equal("Demo District", user.Name)
+	a.Equal("district", user.RawData["user_type"])
+}
+
+func Test_DistrictTokens(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("district", r.URL.Query().Get("owner_type"))
+		user, _, _ := r.BasicAuth()
+		a.Equal("client-id", user)
+		w.Write([]byte(`{"data":[{"id":"58939ac0a206f40316fc4e1b","created":"2017-02-02T20:49:36.091Z","owner":{"type":"district","id":"5b2ad81a709e300001e2cd7b"},"access_token":"district-token","scopes":["read:district_admins"]}]}`))
+	}))
+	defer ts.Close()
+
+	originalTokenURL := clever.TokenURL
+	clever.TokenURL = ts.URL
+	defer func() { clever.TokenURL = originalTokenURL }()
+
+	p := clever.New("client-id", "client-secret", "/foo")
+	tokens, err := p.DistrictTokens()
+	a.NoError(err)
+	a.Len(tokens, 1)
+	a.Equal("district-token", tokens[0].AccessToken)
+	a.Equal("5b2ad81a709e300001e2cd7b", tokens[0].Owner.ID)
+}
+
+func provider() *clever.Provider {
+	return clever.New(os.Getenv("CLEVER_KEY"), os.Getenv("CLEVER_SECRET"), "/foo")
+}
@@ -5,6 +5,7 @@ package discord
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"io"
 	"io/ioutil"
 
@@ -19,6 +20,10 @@ const (
 	authURL      string = "https://discord.com/api/oauth2/authorize"
 	tokenURL     string = "https://discord.com/api/oauth2/token"
 	userEndpoint string = "https://discord.com/api/users/@me"
+
+	guildsEndpoint string = "https://discord.com/api/users/@me/guilds"
+	// memberEndpoint is the guild member endpoint, formatted with the guild and user IDs
+	memberEndpoint string = "https://discord.com/api/guilds/%s/members/%s"
 )
 
 const (
@@ -62,6 +67,33 @@ type Provider struct {
 	HTTPClient   *http.Client
 	config       *oauth2.Config
 	providerName string
+
+	fetchGuilds   bool
+	allowedGuilds []string
+	roleGuildID   string
+	botToken      string
+}
+
+// WithGuilds makes FetchUser get the guilds of the user, their IDs are set in the Groups
+// of the user and the guilds are available in RawData under "guilds". It requires the
+// guilds scope.
+//
+// When guild IDs are given, the provider is restricted to the members of these guilds,
+// FetchUser returns a GuildError for other users.
+func (p *Provider) WithGuilds(allowed ...string) *Provider {
+	p.fetchGuilds = true
+	p.allowedGuilds = append(p.allowedGuilds, allowed...)
+	return p
+}
+
+// WithGuildRoles makes FetchUser get the member of the user in the given guild with the
+// token of a bot added to this guild, the IDs of its roles are set in the Roles of the
+// user and the member is available in RawData under "member". Users who are not members
+// of the guild have no roles.
+func (p *Provider) WithGuildRoles(guildID, botToken string) *Provider {
+	p.roleGuildID = guildID
+	p.botToken = botToken
+	return p
 }
 
 // Name gets the name used to retrieve this provider.
@@ -143,9 +175,92 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 		return user, err
 	}
 
+	if p.fetchGuilds {
+		err = p.guilds(&user)
+		if err != nil {
+			return user, err
+		}
+	}
+
+	if p.roleGuildID != "" {
+		err = p.guildRoles(&user)
+	}
+
 	return user, err
 }
 
+// guilds sets the guilds of the user and checks the user is a member of one of the
+// allowed guilds.
+func (p *Provider) guilds(user *goth.User) error {
+	guilds := []map[string]interface{}{}
+	err := p.get(guildsEndpoint, "Bearer "+user.AccessToken, &guilds)
+	if err != nil {
+		return err
+	}
+
+	user.RawData["guilds"] = guilds
+	allowed := len(p.allowedGuilds) == 0
+	for _, guild := range guilds {
+		id, _ := guild["id"].(string)
+		user.Groups = append(user.Groups, id)
+		for _, a := range p.allowedGuilds {
+			allowed = allowed || a == id
+		}
+	}
+	if !allowed {
+		return GuildError{UserID: user.UserID, Guilds: p.allowedGuilds}
+	}
+	return nil
+}
+
+// guildRoles sets the roles of the user in the guild configured with WithGuildRoles.
+func (p *Provider) guildRoles(user *goth.User) error {
+	member := map[string]interface{}{}
+	url := fmt.Sprintf(memberEndpoint, p.roleGuildID, user.UserID)
+	err := p.get(url, "Bot "+p.botToken, &member)
+	if err == errNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	user.RawData["member"] = member
+	roles, _ := member["roles"].([]interface{})
+	for _, role := range roles {
+		if id, ok := role.(string); ok {
+			user.Roles = append(user.Roles, id)
+		}
+	}
+	return nil
+}
+
+var errNotFound = errors.New("not found")
+
+// get decodes the response of a GET request to the Discord API authorized with the
+// given Authorization header. It returns errNotFound on a 404.
+func (p *Provider) get(url, authorization string, v interface{}) error {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", authorization)
+	resp, err := p.Client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return errNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s responded with a %d trying to fetch %s", p.providerName, resp.StatusCode, url)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
 func userFromReader(r io.Reader, user *goth.User) error {
 	u := struct {
 		Name          string `json:"username"`
@@ -1,6 +1,11 @@
 package discord
 
 import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 
@@ -52,3 +57,64 @@ func Test_SessionFromJSON(t *testing.T) {
 	a.Equal(s.AuthURL, "https://discord.com/api/oauth2/authorize")
 	a.Equal(s.AccessToken, "1234567890")
 }
+
+func Test_FetchUserWithGuilds(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/users/@me":
+			a.Equal("Bearer TOKEN", r.Header.Get("Authorization"))
+			w.Write([]byte(`{"id":"80351110224678912","username":"Nelly"}`))
+		case "/api/users/@me/guilds":
+			a.Equal("Bearer TOKEN", r.Header.Get("Authorization"))
+			w.Write([]byte(`[{"id":"197038439483310086","name":"Discord Testers"},{"id":"81384788765712384","name":"Discord API"}]`))
+		case "/api/guilds/81384788765712384/members/80351110224678912":
+			a.Equal("Bot BOT_TOKEN", r.Header.Get("Authorization"))
+			w.Write([]byte(`{"nick":"nelly","roles":["41771983423143936","41771983423143937"]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	p := provider().WithGuilds("81384788765712384").WithGuildRoles("81384788765712384", "BOT_TOKEN")
+	withMockServer(p, handler, func(p *Provider) {
+		user, err := p.FetchUser(&Session{AccessToken: "TOKEN"})
+		a.NoError(err)
+		a.Equal([]string{"197038439483310086", "81384788765712384"}, user.Groups)
+		a.Equal([]string{"41771983423143936", "41771983423143937"}, user.Roles)
+	})
+
+	p = provider().WithGuilds("613425648685547541")
+	withMockServer(p, handler, func(p *Provider) {
+		_, err := p.FetchUser(&Session{AccessToken: "TOKEN"})
+		a.Equal(GuildError{UserID: "80351110224678912", Guilds: []string{"613425648685547541"}}, err)
+	})
+
+	p = provider().WithGuildRoles("613425648685547541", "BOT_TOKEN")
+	withMockServer(p, handler, func(p *Provider) {
+		user, err := p.FetchUser(&Session{AccessToken: "TOKEN"})
+		a.NoError(err)
+		a.Nil(user.Groups)
+		a.Nil(user.Roles)
+	})
+}
+
+func withMockServer(p *Provider, handler http.Handler, fn func(p *Provider)) {
+	server := httptest.NewTLSServer(handler)
+	defer server.Close()
+
+	p.HTTPClient = &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return net.Dial(network, server.Listener.Addr().String())
+			},
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		},
+	}
+
+	fn(p)
+}
@@ -0,0 +1,17 @@
+package discord
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GuildError is returned by FetchUser when the user is not a member of any of the
+// guilds the provider is restricted to.
+type GuildError struct {
+	UserID string
+	Guilds []string
+}
+
+func (e GuildError) Error() string {
+	return fmt.Sprintf("Discord user %s is not a member of %s", e.UserID, strings.Join(e.Guilds, ", "))
+}
@@ -0,0 +1,230 @@
+// Package docusign implements the OAuth2 protocol for authenticating users through DocuSign.
+//
+// DocuSign uses separate account servers for its developer (demo) and production
+// environments, use New for production and NewDemo for the demo environment.
+package docusign
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+)
+
+// These are the account servers of the DocuSign environments.
+const (
+	ProductionAccountServer = "https://account.docusign.com"
+	DemoAccountServer       = "https://account-d.docusign.com"
+)
+
+const (
+	// ScopeSignature allows to call the eSignature REST API on behalf of the user.
+	ScopeSignature = "signature"
+	// ScopeExtended issues refresh tokens that do not expire as long as they are used.
+	ScopeExtended = "extended"
+	// ScopeImpersonation is needed to use the JWT grant.
+	ScopeImpersonation = "impersonation"
+)
+
+// Account is one of the DocuSign accounts the user has access to.
+// API calls for an account have to be made against its BaseURI.
+type Account struct {
+	AccountID   string `json:"account_id"`
+	IsDefault   bool   `json:"is_default"`
+	AccountName string `json:"account_name"`
+	BaseURI     string `json:"base_uri"`
+}
+
+// Provider is the implementation of `goth.Provider` for accessing DocuSign.
+type Provider struct {
+	ClientKey     string
+	Secret        string
+	CallbackURL   string
+	HTTPClient    *http.Client
+	config        *oauth2.Config
+	providerName  string
+	accountServer string
+}
+
+// New creates a new DocuSign provider for the production environment and sets up
+// important connection details.
+// You should always call `docusign.New` to get a new provider.  Never try to
+// create one manually.
+func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	return NewCustomisedAccountServer(clientKey, secret, callbackURL, ProductionAccountServer, scopes...)
+}
+
+// NewDemo is similar to New(...) but uses the demo (developer) environment.
+func NewDemo(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	return NewCustomisedAccountServer(clientKey, secret, callbackURL, DemoAccountServer, scopes...)
+}
+
+// NewCustomisedAccountServer is similar to New(...) but can be used to set a custom account server.
+func NewCustomisedAccountServer(clientKey, secret, callbackURL, accountServer string, scopes ...string) *Provider {
+	p := &Provider{
+		ClientKey:     clientKey,
+		Secret:        secret,
+		CallbackURL:   callbackURL,
+		providerName:  "docusign",
+		accountServer: strings.TrimSuffix(accountServer, "/"),
+	}
+	p.config = newConfig(p, scopes)
+	return p
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns an HTTP client to be used in all fetch operations.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// Debug is a no-op for the docusign package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks DocuSign for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	return &Session{
+		AuthURL: p.config.AuthCodeURL(state),
+	}, nil
+}
+
+// FetchUser will go to DocuSign and access basic information about the user.
+// The accounts of the user are available in RawData as "accounts"; the default
+// account's "account_id" and "base_uri", which API calls have to be made against,
+// are also copied to the top level of RawData.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	req, err := http.NewRequest("GET", p.accountServer+"/oauth/userinfo", nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+
+	response, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+	}
+
+	bits, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return user, err
+	}
+
+	err = json.NewDecoder(bytes.NewReader(bits)).Decode(&user.RawData)
+	if err != nil {
+		return user, err
+	}
+
+	err = userFromReader(bytes.NewReader(bits), &user)
+	return user, err
+}
+
+func userFromReader(r io.Reader, user *goth.User) error {
+	u := struct {
+		Sub        string    `json:"sub"`
+		Name       string    `json:"name"`
+		GivenName  string    `json:"given_name"`
+		FamilyName string    `json:"family_name"`
+		Email      string    `json:"email"`
+		Accounts   []Account `json:"accounts"`
+	}{}
+	err := json.NewDecoder(r).Decode(&u)
+	if err != nil {
+		return err
+	}
+	user.UserID = u.Sub
+	user.Name = u.Name
+	user.FirstName = u.GivenName
+	user.LastName = u.FamilyName
+	user.Email = u.Email
+
+	if account, ok := DefaultAccount(u.Accounts); ok {
+		user.RawData["account_id"] = account.AccountID
+		user.RawData["base_uri"] = account.BaseURI
+	}
+	return nil
+}
+
+// DefaultAccount returns the account flagged as default, or the first one if none is.
+func DefaultAccount(accounts []Account) (Account, bool) {
+	for _, account := range accounts {
+		if account.IsDefault {
+			return account, true
+		}
+	}
+	if len(accounts) > 0 {
+		return accounts[0], true
+	}
+	return Account{}, false
+}
+
+func newConfig(provider *Provider, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   provider.accountServer + "/oauth/auth",
+			TokenURL:  provider.accountServer + "/oauth/token",
+			AuthStyle: oauth2.AuthStyleInHeader,
+		},
+		Scopes: []string{},
+	}
+
+	if len(scopes) > 0 {
+		for _, scope := range scopes {
+			c.Scopes = append(c.Scopes, scope)
+		}
+	} else {
+		c.Scopes = []string{ScopeSignature}
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	token := &oauth2.Token{RefreshToken: refreshToken}
+	ts := p.config.TokenSource(goth.ContextForClient(p.Client()), token)
+	newToken, err := ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	return newToken, err
+}
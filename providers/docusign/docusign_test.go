@@ -0,0 +1,105 @@
+package docusign_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/docusign"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("DOCUSIGN_KEY"))
+	a.Equal(p.Secret, os.Getenv("DOCUSIGN_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+}
+
+func Test_NewDemo(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := docusign.NewDemo(os.Getenv("DOCUSIGN_KEY"), os.Getenv("DOCUSIGN_SECRET"), "/foo")
+	session, err := p.BeginAuth("test_state")
+	s := session.(*docusign.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://account-d.docusign.com/oauth/auth")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	session, err := p.BeginAuth("test_state")
+	s := session.(*docusign.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://account.docusign.com/oauth/auth")
+	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("DOCUSIGN_KEY")))
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "scope=signature")
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://account.docusign.com/oauth/auth","AccessToken":"1234567890"}`)
+	a.NoError(err)
+
+	s := session.(*docusign.Session)
+	a.Equal(s.AuthURL, "https://account.docusign.com/oauth/auth")
+	a.Equal(s.AccessToken, "1234567890")
+}
+
+func Test_FetchUser(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/oauth/userinfo", r.URL.Path)
+		a.Equal("Bearer 1234567890", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"sub":"4799e5e9-1559-4915-9862-cf4713bbcacc","name":"Susan Smart","given_name":"Susan","family_name":"Smart","email":"susan.smart@example.com","accounts":[{"account_id":"a4ec37d6","is_default":false,"account_name":"Other","base_uri":"https://eu.docusign.net"},{"account_id":"18b4799a","is_default":true,"account_name":"Susan's Company","base_uri":"https://demo.docusign.net"}]}`))
+	}))
+	defer ts.Close()
+
+	p := docusign.NewCustomisedAccountServer(os.Getenv("DOCUSIGN_KEY"), os.Getenv("DOCUSIGN_SECRET"), "/foo", ts.URL+"/")
+	user, err := p.FetchUser(&docusign.Session{AccessToken: "1234567890"})
+	a.NoError(err)
+	a.Equal("4799e5e9-1559-4915-9862-cf4713bbcacc", user.UserID)
+	a.Equal("Susan Smart", user.Name)
+	a.Equal("Susan", user.FirstName)
+	a.Equal("Smart", user.LastName)
+	a.Equal("susan.smart@example.com", user.Email)
+	a.Equal("18b4799a", user.RawData["account_id"])
+	a.Equal("https://demo.docusign.net", user.RawData["base_uri"])
+	a.Len(user.RawData["accounts"], 2)
+}
+
+func Test_DefaultAccount(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	_, ok := docusign.DefaultAccount(nil)
+	a.False(ok)
+
+	account, ok := docusign.DefaultAccount([]docusign.Account{{AccountID: "1"}, {AccountID: "2"}})
+	a.True(ok)
+	a.Equal("1", account.AccountID)
+}
+
+func provider() *docusign.Provider {
+	return docusign.New(os.Getenv("DOCUSIGN_KEY"), os.Getenv("DOCUSIGN_SECRET"), "/foo")
+}
@@ -9,6 +9,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"strings"
+	"time"
 
 	"fmt"
 
@@ -35,13 +36,19 @@ type Provider struct {
 
 // Session stores data during the auth process with Dropbox.
 type Session struct {
-	AuthURL string
-	Token   string
+	AuthURL      string
+	Token        string
+	RefreshToken string
+	ExpiresAt    time.Time
 }
 
 // New creates a new Dropbox provider and sets up important connection details.
 // You should always call `dropbox.New` to get a new provider.  Never try to
 // create one manually.
+//
+// The scopes are only used by scoped apps, all the scopes of the app are granted when
+// none are given. Dropbox access tokens expire after four hours, a refresh token is
+// requested to get new ones.
 func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
 	p := &Provider{
 		ClientKey:    clientKey,
@@ -74,7 +81,7 @@ func (p *Provider) Debug(debug bool) {}
 // BeginAuth asks Dropbox for an authentication end-point.
 func (p *Provider) BeginAuth(state string) (goth.Session, error) {
 	return &Session{
-		AuthURL: p.config.AuthCodeURL(state),
+		AuthURL: p.config.AuthCodeURL(state, oauth2.SetAuthURLParam("token_access_type", "offline")),
 	}, nil
 }
 
@@ -82,8 +89,10 @@ func (p *Provider) BeginAuth(state string) (goth.Session, error) {
 func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 	s := session.(*Session)
 	user := goth.User{
-		AccessToken: s.Token,
-		Provider:    p.Name(),
+		AccessToken:  s.Token,
+		Provider:     p.Name(),
+		RefreshToken: s.RefreshToken,
+		ExpiresAt:    s.ExpiresAt,
 	}
 
 	if user.AccessToken == "" {
@@ -148,6 +157,8 @@ func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string,
 	}
 
 	s.Token = token.AccessToken
+	s.RefreshToken = token.RefreshToken
+	s.ExpiresAt = token.Expiry
 	return token.AccessToken, nil
 }
 
@@ -170,6 +181,7 @@ func newConfig(p *Provider, scopes []string) *oauth2.Config {
 			AuthURL:  authURL,
 			TokenURL: tokenURL,
 		},
+		Scopes: scopes,
 	}
 	return c
 }
@@ -185,6 +197,9 @@ func userFromReader(r io.Reader, user *goth.User) error {
 		Country         string `json:"country"`
 		Email           string `json:"email"`
 		ProfilePhotoURL string `json:"profile_photo_url"`
+		Team            struct {
+			ID string `json:"id"`
+		} `json:"team"`
 	}{}
 	err := json.NewDecoder(r).Decode(&u)
 	if err != nil {
@@ -199,15 +214,26 @@ func userFromReader(r io.Reader, user *goth.User) error {
 	user.NickName = u.Email // Email is the dropbox username
 	user.Location = u.Country
 	user.AvatarURL = u.ProfilePhotoURL // May be blank
+	if u.Team.ID != "" {
+		// Dropbox Business users are members of a team, available in RawData under "team"
+		user.Groups = []string{u.Team.ID}
+	}
 	return nil
 }
 
-//RefreshToken refresh token is not provided by dropbox
+//RefreshToken get new access token based on the refresh token, Dropbox does not return
+//a new refresh token
 func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
-	return nil, errors.New("Refresh token is not provided by dropbox")
+	token := &oauth2.Token{RefreshToken: refreshToken}
+	ts := p.config.TokenSource(goth.ContextForClient(p.Client()), token)
+	newToken, err := ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	return newToken, err
 }
 
-//RefreshTokenAvailable refresh token is not provided by dropbox
+//RefreshTokenAvailable refresh token is provided by auth provider or not
 func (p *Provider) RefreshTokenAvailable() bool {
-	return false
+	return true
 }
@@ -1,10 +1,14 @@
 package dropbox
 
 import (
+	"context"
+	"crypto/tls"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/markbates/goth"
 	"github.com/stretchr/testify/assert"
@@ -45,6 +49,7 @@ func Test_BeginAuth(t *testing.T) {
 	s := session.(*Session)
 	a.NoError(err)
 	a.Contains(s.AuthURL, "www.dropbox.com/oauth2/authorize")
+	a.Contains(s.AuthURL, "token_access_type=offline")
 }
 
 func Test_FetchUser(t *testing.T) {
@@ -79,9 +84,43 @@ func Test_FetchUser(t *testing.T) {
 	a.Equal(user.AccessTokenSecret, "")
 	a.Equal(user.AvatarURL, "https://dl-web.dropbox.com/account_photo/get/dbid%3AAAH4f99T0taONIb-OurWxbNQ6ywGRopQngc?vers=1453416673259\u0026size=128x128")
 	a.Equal(user.Provider, "dropbox")
+	a.Equal([]string{"dbtid:AAFdgehTzw7WlXhZJsbGCLePe8RvQGYDr-I"}, user.Groups)
 	a.Len(user.RawData, 14)
 }
 
+func Test_RefreshToken(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ts := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/oauth2/token", r.URL.Path)
+		a.Equal("refresh_token", r.FormValue("grant_type"))
+		a.Equal("REFRESH", r.FormValue("refresh_token"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"sl.NEW","token_type":"bearer","expires_in":14400}`))
+	}))
+	defer ts.Close()
+
+	p := provider()
+	p.HTTPClient = &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return net.Dial(network, ts.Listener.Addr().String())
+			},
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		},
+	}
+
+	a.True(p.RefreshTokenAvailable())
+	token, err := p.RefreshToken("REFRESH")
+	a.NoError(err)
+	a.Equal("sl.NEW", token.AccessToken)
+	a.Equal("REFRESH", token.RefreshToken)
+	a.WithinDuration(time.Now().Add(4*time.Hour), token.Expiry, time.Minute)
+}
+
 func Test_SessionFromJSON(t *testing.T) {
 	t.Parallel()
 	a := assert.New(t)
@@ -101,7 +140,7 @@ func Test_SessionToJSON(t *testing.T) {
 	s := &Session{}
 
 	data := s.Marshal()
-	a.Equal(data, `{"AuthURL":"","Token":""}`)
+	a.Equal(data, `{"AuthURL":"","Token":"","RefreshToken":"","ExpiresAt":"0001-01-01T00:00:00Z"}`)
 }
 
 func Test_GetAuthURL(t *testing.T) {
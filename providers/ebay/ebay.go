@@ -0,0 +1,223 @@
+// Package ebay implements the OAuth2 protocol for authenticating users through eBay.
+//
+// eBay does not redirect to a callback URL directly, the redirect_uri is the RuName
+// (eBay Redirect URL name) of the application, which is configured with the URL users
+// are sent back to. Pass the RuName as callbackURL.
+package ebay
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+)
+
+// These are the endpoints of the eBay environments.
+const (
+	authURLProduction     = "https://auth.ebay.com/oauth2/authorize"
+	tokenURLProduction    = "https://api.ebay.com/identity/v1/oauth2/token"
+	identityURLProduction = "https://apiz.ebay.com/commerce/identity/v1/user/"
+
+	authURLSandbox     = "https://auth.sandbox.ebay.com/oauth2/authorize"
+	tokenURLSandbox    = "https://api.sandbox.ebay.com/identity/v1/oauth2/token"
+	identityURLSandbox = "https://apiz.sandbox.ebay.com/commerce/identity/v1/user/"
+)
+
+const (
+	// ScopeAPI gives access to the public eBay APIs.
+	ScopeAPI = "https://api.ebay.com/oauth/api_scope"
+	// ScopeIdentityRead allows to read the user's identity, it is required by FetchUser.
+	ScopeIdentityRead = "https://api.ebay.com/oauth/api_scope/commerce.identity.readonly"
+)
+
+// Provider is the implementation of `goth.Provider` for accessing eBay.
+type Provider struct {
+	ClientKey string
+	Secret    string
+	// CallbackURL is the RuName of the application.
+	CallbackURL  string
+	HTTPClient   *http.Client
+	config       *oauth2.Config
+	providerName string
+	identityURL  string
+}
+
+// New creates a new eBay provider for the production environment and sets up
+// important connection details.
+// You should always call `ebay.New` to get a new provider.  Never try to
+// create one manually.
+func New(clientKey, secret, ruName string, scopes ...string) *Provider {
+	return NewCustomisedURL(clientKey, secret, ruName, authURLProduction, tokenURLProduction, identityURLProduction, scopes...)
+}
+
+// NewSandbox is similar to New(...) but uses the sandbox environment.
+func NewSandbox(clientKey, secret, ruName string, scopes ...string) *Provider {
+	return NewCustomisedURL(clientKey, secret, ruName, authURLSandbox, tokenURLSandbox, identityURLSandbox, scopes...)
+}
+
+// NewCustomisedURL is similar to New(...) but can be used to set custom URLs to connect to
+func NewCustomisedURL(clientKey, secret, ruName, authURL, tokenURL, identityURL string, scopes ...string) *Provider {
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  ruName,
+		providerName: "ebay",
+		identityURL:  identityURL,
+	}
+	p.config = newConfig(p, authURL, tokenURL, scopes)
+	return p
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns an HTTP client to be used in all fetch operations.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// Debug is a no-op for the ebay package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks eBay for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	return &Session{
+		AuthURL: p.config.AuthCodeURL(state),
+	}, nil
+}
+
+// FetchUser will go to the eBay identity API and access basic information about the user.
+// The eBay username is used as NickName.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	req, err := http.NewRequest("GET", p.identityURL, nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+
+	response, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+	}
+
+	bits, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return user, err
+	}
+
+	err = json.NewDecoder(bytes.NewReader(bits)).Decode(&user.RawData)
+	if err != nil {
+		return user, err
+	}
+
+	err = userFromReader(bytes.NewReader(bits), &user)
+	return user, err
+}
+
+func userFromReader(r io.Reader, user *goth.User) error {
+	u := struct {
+		UserID            string `json:"userId"`
+		Username          string `json:"username"`
+		IndividualAccount *struct {
+			FirstName string `json:"firstName"`
+			LastName  string `json:"lastName"`
+			Email     string `json:"email"`
+		} `json:"individualAccount"`
+		BusinessAccount *struct {
+			Name  string `json:"name"`
+			Email string `json:"email"`
+		} `json:"businessAccount"`
+	}{}
+	err := json.NewDecoder(r).Decode(&u)
+	if err != nil {
+		return err
+	}
+	user.UserID = u.UserID
+	user.NickName = u.Username
+	user.Name = u.Username
+	if a := u.IndividualAccount; a != nil {
+		user.FirstName = a.FirstName
+		user.LastName = a.LastName
+		user.Email = a.Email
+		if name := strings.TrimSpace(a.FirstName + " " + a.LastName); name != "" {
+			user.Name = name
+		}
+	}
+	if a := u.BusinessAccount; a != nil {
+		user.Email = a.Email
+		if a.Name != "" {
+			user.Name = a.Name
+		}
+	}
+	return nil
+}
+
+func newConfig(provider *Provider, authURL, tokenURL string, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   authURL,
+			TokenURL:  tokenURL,
+			AuthStyle: oauth2.AuthStyleInHeader,
+		},
+		Scopes: []string{},
+	}
+
+	if len(scopes) > 0 {
+		for _, scope := range scopes {
+			c.Scopes = append(c.Scopes, scope)
+		}
+	} else {
+		c.Scopes = []string{ScopeIdentityRead}
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	token := &oauth2.Token{RefreshToken: refreshToken}
+	ts := p.config.TokenSource(goth.ContextForClient(p.Client()), token)
+	newToken, err := ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	return newToken, err
+}
@@ -0,0 +1,115 @@
+package ebay_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/ebay"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("EBAY_KEY"))
+	a.Equal(p.Secret, os.Getenv("EBAY_SECRET"))
+	a.Equal(p.CallbackURL, "Acme_Inc-AcmeApp-PRD-a1b2c3d4e-12345678")
+}
+
+func Test_NewSandbox(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := ebay.NewSandbox(os.Getenv("EBAY_KEY"), os.Getenv("EBAY_SECRET"), "Acme_Inc-AcmeApp-SBX-a1b2c3d4e-12345678")
+	session, err := p.BeginAuth("test_state")
+	s := session.(*ebay.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://auth.sandbox.ebay.com/oauth2/authorize")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	session, err := p.BeginAuth("test_state")
+	s := session.(*ebay.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://auth.ebay.com/oauth2/authorize")
+	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("EBAY_KEY")))
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "redirect_uri=Acme_Inc-AcmeApp-PRD-a1b2c3d4e-12345678")
+	a.Contains(s.AuthURL, "scope="+url.QueryEscape(ebay.ScopeIdentityRead))
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://auth.ebay.com/oauth2/authorize","AccessToken":"1234567890"}`)
+	a.NoError(err)
+
+	s := session.(*ebay.Session)
+	a.Equal(s.AuthURL, "https://auth.ebay.com/oauth2/authorize")
+	a.Equal(s.AccessToken, "1234567890")
+}
+
+func Test_Authorize(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _, ok := r.BasicAuth()
+		a.True(ok)
+		a.NoError(r.ParseForm())
+		a.Equal("Acme_Inc-AcmeApp-PRD-a1b2c3d4e-12345678", r.Form.Get("redirect_uri"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"v^1.1#i^1","expires_in":7200,"refresh_token":"v^1.1#r^1","refresh_token_expires_in":47304000,"token_type":"User Access Token"}`))
+	}))
+	defer ts.Close()
+
+	p := ebay.NewCustomisedURL(os.Getenv("EBAY_KEY"), os.Getenv("EBAY_SECRET"), "Acme_Inc-AcmeApp-PRD-a1b2c3d4e-12345678", ts.URL, ts.URL, ts.URL)
+	s := &ebay.Session{}
+	token, err := s.Authorize(p, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("v^1.1#i^1", token)
+	a.Equal("v^1.1#r^1", s.RefreshToken)
+}
+
+func Test_FetchUser(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("Bearer 1234567890", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"userId":"9Ab8Cd","username":"vintage_finds","accountType":"INDIVIDUAL","registrationMarketplaceId":"EBAY_US","individualAccount":{"firstName":"Jane","lastName":"Doe","email":"jane@example.com"}}`))
+	}))
+	defer ts.Close()
+
+	p := ebay.NewCustomisedURL(os.Getenv("EBAY_KEY"), os.Getenv("EBAY_SECRET"), "RuName", ts.URL, ts.URL, ts.URL)
+	user, err := p.FetchUser(&ebay.Session{AccessToken: "1234567890"})
+	a.NoError(err)
+	a.Equal("9Ab8Cd", user.UserID)
+	a.Equal("vintage_finds", user.NickName)
+	a.Equal("Jane Doe", user.Name)
+	a.Equal("Jane", user.FirstName)
+	a.Equal("Doe", user.LastName)
+	a.Equal("jane@example.com", user.Email)
+	a.Equal("EBAY_US", user.RawData["registrationMarketplaceId"])
+}
+
+func provider() *ebay.Provider {
+	return ebay.New(os.Getenv("EBAY_KEY"), os.Getenv("EBAY_SECRET"), "Acme_Inc-AcmeApp-PRD-a1b2c3d4e-12345678")
+}
@@ -0,0 +1,187 @@
+// Package epicgames implements the OAuth2 protocol for authenticating users through Epic Games (Epic Account Services).
+package epicgames
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+)
+
+// These vars define the Authentication, Token, and Profile URLS for Epic Games.
+var (
+	AuthURL    = "https://www.epicgames.com/id/authorize"
+	TokenURL   = "https://api.epicgames.dev/epic/oauth/v2/token"
+	ProfileURL = "https://api.epicgames.dev/epic/id/v2/accounts"
+)
+
+const (
+	// ScopeBasicProfile grants access to the account ID and display name.
+	ScopeBasicProfile = "basic_profile"
+	// ScopeFriendsList grants access to the user's friends.
+	ScopeFriendsList = "friends_list"
+	// ScopePresence grants access to the user's online presence.
+	ScopePresence = "presence"
+	// ScopeCountry grants access to the user's country.
+	ScopeCountry = "country"
+)
+
+// Provider is the implementation of `goth.Provider` for accessing Epic Games.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	HTTPClient   *http.Client
+	config       *oauth2.Config
+	providerName string
+}
+
+// New creates a new Epic Games provider and sets up important connection details.
+// You should always call `epicgames.New` to get a new provider.  Never try to
+// create one manually.
+func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "epicgames",
+	}
+	p.config = newConfig(p, scopes)
+	return p
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns an HTTP client to be used in all fetch operations.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// Debug is a no-op for the epicgames package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks Epic Games for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	return &Session{
+		AuthURL: p.config.AuthCodeURL(state),
+	}, nil
+}
+
+// FetchUser will go to the Epic Games account service and access basic information about the user.
+// The account ID is taken from the token response, since the account service has no "me" endpoint.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	if sess.AccountID == "" {
+		return user, errors.New("epicgames: the token response did not include an account_id")
+	}
+	user.UserID = sess.AccountID
+
+	req, err := http.NewRequest("GET", ProfileURL+"?accountId="+url.QueryEscape(sess.AccountID), nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+
+	response, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+	}
+
+	bits, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return user, err
+	}
+
+	return user, userFromReader(bytes.NewReader(bits), &user)
+}
+
+// userFromReader reads the account service response, which is a list of accounts,
+// and copies the one matching user.UserID into the user.
+func userFromReader(r io.Reader, user *goth.User) error {
+	accounts := []map[string]interface{}{}
+	err := json.NewDecoder(r).Decode(&accounts)
+	if err != nil {
+		return err
+	}
+	for _, account := range accounts {
+		if id, _ := account["accountId"].(string); id != user.UserID {
+			continue
+		}
+		user.RawData = account
+		user.Name, _ = account["displayName"].(string)
+		user.NickName = user.Name
+		return nil
+	}
+	return fmt.Errorf("epicgames: account %s not found", user.UserID)
+}
+
+func newConfig(provider *Provider, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   AuthURL,
+			TokenURL:  TokenURL,
+			AuthStyle: oauth2.AuthStyleInHeader,
+		},
+		Scopes: []string{},
+	}
+
+	if len(scopes) > 0 {
+		for _, scope := range scopes {
+			c.Scopes = append(c.Scopes, scope)
+		}
+	} else {
+		c.Scopes = []string{ScopeBasicProfile}
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	token := &oauth2.Token{RefreshToken: refreshToken}
+	ts := p.config.TokenSource(goth.ContextForClient(p.Client()), token)
+	newToken, err := ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	return newToken, err
+}
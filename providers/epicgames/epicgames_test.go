@@ -0,0 +1,89 @@
+package epicgames_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/epicgames"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("EPICGAMES_KEY"))
+	a.Equal(p.Secret, os.Getenv("EPICGAMES_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	session, err := p.BeginAuth("test_state")
+	s := session.(*epicgames.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "www.epicgames.com/id/authorize")
+	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("EPICGAMES_KEY")))
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "scope=basic_profile")
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://www.epicgames.com/id/authorize","AccessToken":"1234567890"}`)
+	a.NoError(err)
+
+	s := session.(*epicgames.Session)
+	a.Equal(s.AuthURL, "https://www.epicgames.com/id/authorize")
+	a.Equal(s.AccessToken, "1234567890")
+}
+
+func Test_FetchUser(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("Bearer 1234567890", r.Header.Get("Authorization"))
+		a.Equal("e8a7b2d3c4f5", r.URL.Query().Get("accountId"))
+		w.Write([]byte(`[{"accountId":"e8a7b2d3c4f5","displayName":"Wash","preferredLanguage":"en"}]`))
+	}))
+	defer ts.Close()
+
+	originalProfileURL := epicgames.ProfileURL
+	epicgames.ProfileURL = ts.URL
+	defer func() { epicgames.ProfileURL = originalProfileURL }()
+
+	user, err := provider().FetchUser(&epicgames.Session{AccessToken: "1234567890", AccountID: "e8a7b2d3c4f5"})
+	a.NoError(err)
+	a.Equal("e8a7b2d3c4f5", user.UserID)
+	a.Equal("Wash", user.Name)
+	a.Equal("Wash", user.NickName)
+	a.Equal("en", user.RawData["preferredLanguage"])
+}
+
+func Test_FetchUser_WithoutAccountID(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	_, err := provider().FetchUser(&epicgames.Session{AccessToken: "1234567890"})
+	a.Error(err)
+}
+
+func provider() *epicgames.Provider {
+	return epicgames.New(os.Getenv("EPICGAMES_KEY"), os.Getenv("EPICGAMES_SECRET"), "/foo")
+}
@@ -0,0 +1,227 @@
+// Package etsy implements the OAuth 2.0 protocol (with the required PKCE) for authenticating users through Etsy.
+package etsy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+)
+
+// These vars define the Authentication, Token, and User URLS for Etsy.
+var (
+	AuthURL  = "https://www.etsy.com/oauth/connect"
+	TokenURL = "https://api.etsy.com/v3/public/oauth/token"
+	UserURL  = "https://openapi.etsy.com/v3/application/users/"
+)
+
+const (
+	// ScopeEmailRead allows to read the user's email address.
+	ScopeEmailRead = "email_r"
+	// ScopeProfileRead allows to read the user's profile.
+	ScopeProfileRead = "profile_r"
+	// ScopeShopsRead allows to read the user's shops.
+	ScopeShopsRead = "shops_r"
+	// ScopeListingsRead allows to read the user's listings, including inactive ones.
+	ScopeListingsRead = "listings_r"
+	// ScopeTransactionsRead allows to read the user's orders and payments.
+	ScopeTransactionsRead = "transactions_r"
+)
+
+// Provider is the implementation of `goth.Provider` for accessing Etsy.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	HTTPClient   *http.Client
+	config       *oauth2.Config
+	providerName string
+}
+
+// New creates a new Etsy provider and sets up important connection details.
+// You should always call `etsy.New` to get a new provider.  Never try to
+// create one manually.
+func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "etsy",
+	}
+	p.config = newConfig(p, scopes)
+	return p
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns an HTTP client to be used in all fetch operations.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// Debug is a no-op for the etsy package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks Etsy for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	verifier, err := goth.GenerateCodeVerifier()
+	if err != nil {
+		return nil, err
+	}
+	return &Session{
+		AuthURL:      p.config.AuthCodeURL(state, goth.PKCEChallengeOptions(verifier)...),
+		CodeVerifier: verifier,
+	}, nil
+}
+
+// FetchUser will go to Etsy and access basic information about the user.
+// Etsy prefixes access tokens with the id of the user they were issued for, which is
+// used to look the user up.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	userID, err := UserIDFromToken(sess.AccessToken)
+	if err != nil {
+		return user, err
+	}
+
+	req, err := http.NewRequest("GET", UserURL+url.PathEscape(userID), nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+	req.Header.Add("x-api-key", p.apiKey())
+
+	response, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+	}
+
+	bits, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return user, err
+	}
+
+	err = json.NewDecoder(bytes.NewReader(bits)).Decode(&user.RawData)
+	if err != nil {
+		return user, err
+	}
+
+	err = userFromReader(bytes.NewReader(bits), &user)
+	return user, err
+}
+
+// UserIDFromToken returns the id of the user an Etsy access token was issued for.
+func UserIDFromToken(accessToken string) (string, error) {
+	i := strings.Index(accessToken, ".")
+	if i <= 0 {
+		return "", errors.New("etsy: access token is not prefixed with a user id")
+	}
+	if _, err := strconv.ParseInt(accessToken[:i], 10, 64); err != nil {
+		return "", errors.New("etsy: access token is not prefixed with a user id")
+	}
+	return accessToken[:i], nil
+}
+
+// apiKey returns the value of the x-api-key header, which is the keystring, joined
+// with the shared secret when there is one.
+func (p *Provider) apiKey() string {
+	if p.Secret == "" {
+		return p.ClientKey
+	}
+	return p.ClientKey + ":" + p.Secret
+}
+
+func userFromReader(r io.Reader, user *goth.User) error {
+	u := struct {
+		UserID       int64  `json:"user_id"`
+		PrimaryEmail string `json:"primary_email"`
+		FirstName    string `json:"first_name"`
+		LastName     string `json:"last_name"`
+		ImageURL     string `json:"image_url_75x75"`
+	}{}
+	err := json.NewDecoder(r).Decode(&u)
+	if err != nil {
+		return err
+	}
+	user.UserID = strconv.FormatInt(u.UserID, 10)
+	user.Email = u.PrimaryEmail
+	user.FirstName = u.FirstName
+	user.LastName = u.LastName
+	user.Name = strings.TrimSpace(u.FirstName + " " + u.LastName)
+	user.AvatarURL = u.ImageURL
+	return nil
+}
+
+func newConfig(provider *Provider, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		// the token endpoint only takes the client_id and the PKCE verifier, the shared
+		// secret is only sent with the x-api-key header
+		ClientID:    provider.ClientKey,
+		RedirectURL: provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   AuthURL,
+			TokenURL:  TokenURL,
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+		Scopes: []string{},
+	}
+
+	if len(scopes) > 0 {
+		for _, scope := range scopes {
+			c.Scopes = append(c.Scopes, scope)
+		}
+	} else {
+		c.Scopes = []string{ScopeEmailRead, ScopeProfileRead}
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	token := &oauth2.Token{RefreshToken: refreshToken}
+	ts := p.config.TokenSource(goth.ContextForClient(p.Client()), token)
+	newToken, err := ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	return newToken, err
+}
@@ -0,0 +1,102 @@
+package etsy_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/etsy"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("ETSY_KEY"))
+	a.Equal(p.Secret, os.Getenv("ETSY_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	session, err := p.BeginAuth("test_state")
+	s := session.(*etsy.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "www.etsy.com/oauth/connect")
+	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("ETSY_KEY")))
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "scope=email_r+profile_r")
+	a.Contains(s.AuthURL, "code_challenge="+goth.S256CodeChallenge(s.CodeVerifier))
+	a.Contains(s.AuthURL, "code_challenge_method=S256")
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://www.etsy.com/oauth/connect","AccessToken":"1234567890"}`)
+	a.NoError(err)
+
+	s := session.(*etsy.Session)
+	a.Equal(s.AuthURL, "https://www.etsy.com/oauth/connect")
+	a.Equal(s.AccessToken, "1234567890")
+}
+
+func Test_FetchUser(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/12345678", r.URL.Path)
+		a.Equal("Bearer 12345678.O1zLuwveeKjpIqCQFfmR-PaMMpBmagH6DljRAkK9qt05OtRKiANJOyZlMx3WQ_o2FdComQGuoiAWy3dxyGI4Ke_76PR", r.Header.Get("Authorization"))
+		a.Equal("keystring:shared-secret", r.Header.Get("x-api-key"))
+		w.Write([]byte(`{"user_id":12345678,"primary_email":"seller@example.com","first_name":"Ada","last_name":"Lovelace","image_url_75x75":"https://i.etsystatic.com/75x75.jpg"}`))
+	}))
+	defer ts.Close()
+
+	originalUserURL := etsy.UserURL
+	etsy.UserURL = ts.URL + "/"
+	defer func() { etsy.UserURL = originalUserURL }()
+
+	p := etsy.New("keystring", "shared-secret", "/foo")
+	user, err := p.FetchUser(&etsy.Session{AccessToken: "12345678.O1zLuwveeKjpIqCQFfmR-PaMMpBmagH6DljRAkK9qt05OtRKiANJOyZlMx3WQ_o2FdComQGuoiAWy3dxyGI4Ke_76PR"})
+	a.NoError(err)
+	a.Equal("12345678", user.UserID)
+	a.Equal("seller@example.com", user.Email)
+	a.Equal("Ada", user.FirstName)
+	a.Equal("Lovelace", user.LastName)
+	a.Equal("Ada Lovelace", user.Name)
+	a.Equal("https://i.etsystatic.com/75x75.jpg", user.AvatarURL)
+}
+
+func Test_UserIDFromToken(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	id, err := etsy.UserIDFromToken("12345678.abcdef")
+	a.NoError(err)
+	a.Equal("12345678", id)
+
+	_, err = etsy.UserIDFromToken("abcdef")
+	a.Error(err)
+
+	_, err = etsy.UserIDFromToken("abc.def")
+	a.Error(err)
+}
+
+func provider() *etsy.Provider {
+	return etsy.New(os.Getenv("ETSY_KEY"), os.Getenv("ETSY_SECRET"), "/foo")
+}
@@ -15,8 +15,10 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/markbates/goth"
+	"github.com/markbates/goth/internal/jwks"
 	"golang.org/x/oauth2"
 )
 
@@ -50,6 +52,8 @@ type Provider struct {
 	Fields       string
 	config       *oauth2.Config
 	providerName string
+	// limitedLoginKeys are the keys verifying the Limited Login ID tokens.
+	limitedLoginKeys jwks.Cache
 }
 
 // Name is the name used to retrieve this provider later.
@@ -97,6 +101,10 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 		ExpiresAt:   sess.ExpiresAt,
 	}
 
+	if user.AccessToken == "" && sess.IDToken != "" {
+		return p.fetchLimitedLoginUser(sess)
+	}
+
 	if user.AccessToken == "" {
 		// data is not yet retrieved since accessToken is still empty
 		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
@@ -141,6 +149,52 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 	return user, err
 }
 
+// ExchangeToken exchanges the short-lived access token of the session, which expires after
+// a couple of hours, for a long-lived one that lasts about 60 days, and updates the session.
+// Facebook does not provide refresh tokens, so this is how to keep users signed in.
+//
+// See https://developers.facebook.com/docs/facebook-login/guides/access-tokens/get-long-lived
+func (p *Provider) ExchangeToken(session goth.Session) error {
+	sess := session.(*Session)
+	if sess.AccessToken == "" {
+		return fmt.Errorf("%s cannot exchange a token without accessToken", p.providerName)
+	}
+
+	params := url.Values{
+		"grant_type":        {"fb_exchange_token"},
+		"client_id":         {p.ClientKey},
+		"client_secret":     {p.Secret},
+		"fb_exchange_token": {sess.AccessToken},
+	}
+	response, err := p.Client().Get(tokenURL + "?" + params.Encode())
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s responded with a %d trying to exchange the token", p.providerName, response.StatusCode)
+	}
+
+	token := struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}{}
+	if err := json.NewDecoder(response.Body).Decode(&token); err != nil {
+		return err
+	}
+	if token.AccessToken == "" {
+		return errors.New("Invalid token received from provider")
+	}
+
+	sess.AccessToken = token.AccessToken
+	sess.ExpiresAt = time.Time{}
+	if token.ExpiresIn > 0 {
+		sess.ExpiresAt = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	}
+	return nil
+}
+
 func userFromReader(reader io.Reader, user *goth.User) error {
 	u := struct {
 		ID        string `json:"id"`
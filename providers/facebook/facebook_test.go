@@ -1,11 +1,22 @@
 package facebook_test
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"encoding/json"
 	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/dgrijalva/jwt-go"
+	"github.com/lestrrat-go/jwx/jwk"
 	"github.com/markbates/goth"
 	"github.com/markbates/goth/providers/facebook"
 	"github.com/stretchr/testify/assert"
@@ -67,6 +78,109 @@ func Test_SetCustomFields(t *testing.T) {
 	a.Equal(provider.Fields, strings.Join(cf, ","))
 }
 
+func Test_ExchangeToken(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/oauth/access_token", r.URL.Path)
+		a.Equal("fb_exchange_token", r.URL.Query().Get("grant_type"))
+		a.Equal("short-lived", r.URL.Query().Get("fb_exchange_token"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"long-lived","token_type":"bearer","expires_in":5183944}`))
+	})
+
+	withMockServer(facebookProvider(), handler, func(p *facebook.Provider) {
+		session := &facebook.Session{AccessToken: "short-lived", ExpiresAt: time.Now().Add(time.Hour)}
+		a.NoError(p.ExchangeToken(session))
+		a.Equal("long-lived", session.AccessToken)
+		a.True(session.ExpiresAt.After(time.Now().Add(59 * 24 * time.Hour)))
+	})
+}
+
+func Test_LimitedLogin(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fetches := 0
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/.well-known/oauth/openid/jwks/", r.URL.Path)
+		fetches++
+		k, err := jwk.New(&key.PublicKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		k.Set(jwk.KeyIDKey, "key-1")
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(jwk.Set{Keys: []jwk.Key{k}})
+	})
+	idToken := func(claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = "key-1"
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+	claims := func(aud string) jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":         "https://www.facebook.com",
+			"aud":         aud,
+			"sub":         "10158000000000000",
+			"exp":         time.Now().Add(time.Hour).Unix(),
+			"nonce":       "abc",
+			"name":        "Jane Doe",
+			"given_name":  "Jane",
+			"family_name": "Doe",
+			"email":       "jane@example.com",
+			"picture":     "https://platform-lookaside.fbsbx.com/picture",
+		}
+	}
+
+	withMockServer(facebook.New("app-id", "secret", "/foo"), handler, func(p *facebook.Provider) {
+		user, err := p.FetchUser(p.LimitedLoginSession(idToken(claims("app-id")), "abc"))
+		a.NoError(err)
+		a.Equal("10158000000000000", user.UserID)
+		a.Equal("Jane Doe", user.Name)
+		a.Equal("Jane", user.FirstName)
+		a.Equal("Doe", user.LastName)
+		a.Equal("jane@example.com", user.Email)
+		a.Equal("https://platform-lookaside.fbsbx.com/picture", user.AvatarURL)
+
+		_, err = p.FetchUser(p.LimitedLoginSession(idToken(claims("app-id")), "other"))
+		a.EqualError(err, "facebook returned an ID token with an unexpected nonce")
+
+		_, err = p.FetchUser(p.LimitedLoginSession(idToken(claims("other-app")), ""))
+		a.EqualError(err, "facebook returned an ID token for another audience")
+
+		// the keys are only fetched once
+		a.Equal(1, fetches)
+	})
+}
+
+func withMockServer(p *facebook.Provider, handler http.Handler, fn func(p *facebook.Provider)) {
+	server := httptest.NewTLSServer(handler)
+	defer server.Close()
+
+	p.HTTPClient = &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return net.Dial(network, server.Listener.Addr().String())
+			},
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		},
+	}
+
+	fn(p)
+}
+
 func facebookProvider() *facebook.Provider {
 	return facebook.New(os.Getenv("FACEBOOK_KEY"), os.Getenv("FACEBOOK_SECRET"), "/foo", "email")
 }
@@ -0,0 +1,85 @@
+package facebook
+
+import (
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/markbates/goth"
+)
+
+const (
+	limitedLoginIssuer  string = "https://www.facebook.com"
+	limitedLoginJWKSURL string = "https://limited.facebook.com/.well-known/oauth/openid/jwks/"
+)
+
+// LimitedLoginSession creates a session from the OIDC ID token an app received from
+// Facebook Limited Login, which gives no access token. The nonce is the one the app
+// passed to the Facebook SDK, it is checked when not empty. FetchUser verifies the
+// ID token and builds the user from its claims.
+//
+// See https://developers.facebook.com/docs/facebook-login/limited-login
+func (p *Provider) LimitedLoginSession(idToken, nonce string) *Session {
+	return &Session{
+		IDToken: idToken,
+		Nonce:   nonce,
+	}
+}
+
+// fetchLimitedLoginUser builds the user from the claims of the Limited Login ID token
+// of the session, once its signature, issuer, audience and nonce are verified.
+func (p *Provider) fetchLimitedLoginUser(sess *Session) (goth.User, error) {
+	user := goth.User{
+		Provider: p.Name(),
+		IDToken:  sess.IDToken,
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(sess.IDToken, claims, func(token *jwt.Token) (interface{}, error) {
+		if token.Method != jwt.SigningMethodRS256 {
+			return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+		}
+		kid, _ := token.Header["kid"].(string)
+		return p.limitedLoginKey(kid)
+	})
+	if err != nil {
+		return user, fmt.Errorf("%s returned an invalid ID token: %v", p.providerName, err)
+	}
+	if !claims.VerifyIssuer(limitedLoginIssuer, true) {
+		return user, fmt.Errorf("%s returned an ID token with an unexpected issuer", p.providerName)
+	}
+	if !claims.VerifyAudience(p.ClientKey, true) {
+		return user, fmt.Errorf("%s returned an ID token for another audience", p.providerName)
+	}
+	if sess.Nonce != "" && claims["nonce"] != sess.Nonce {
+		return user, fmt.Errorf("%s returned an ID token with an unexpected nonce", p.providerName)
+	}
+
+	user.RawData = claims
+	user.UserID, _ = claims["sub"].(string)
+	user.Name, _ = claims["name"].(string)
+	user.NickName = user.Name
+	user.FirstName, _ = claims["given_name"].(string)
+	user.LastName, _ = claims["family_name"].(string)
+	user.Email, _ = claims["email"].(string)
+	user.AvatarURL, _ = claims["picture"].(string)
+	if exp, ok := claims["exp"].(float64); ok {
+		user.ExpiresAt = time.Unix(int64(exp), 0)
+	}
+	return user, nil
+}
+
+// limitedLoginKey gets the key with the given id from the keys published by Facebook
+// for Limited Login, which are cached.
+func (p *Provider) limitedLoginKey(kid string) (interface{}, error) {
+	key, err := p.limitedLoginKeys.Key(p.Client(), limitedLoginJWKSURL, kid)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := key.(*rsa.PublicKey); !ok {
+		return nil, errors.New("unexpected key type")
+	}
+	return key, nil
+}
@@ -14,6 +14,9 @@ type Session struct {
 	AuthURL     string
 	AccessToken string
 	ExpiresAt   time.Time
+	// IDToken and Nonce are set for Limited Login, see LimitedLoginSession.
+	IDToken string `json:",omitempty"`
+	Nonce   string `json:",omitempty"`
 }
 
 // GetAuthURL will return the URL set by calling the `BeginAuth` function on the Facebook provider.
@@ -0,0 +1,17 @@
+package fitbit
+
+import (
+	"fmt"
+	"time"
+)
+
+// RateLimitError is returned when Fitbit answers with a 429 because the rate limit of
+// the application for the user is exhausted. RetryAfter is the time to wait before
+// calling the API again, as given by the Retry-After header (zero if it is missing).
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e RateLimitError) Error() string {
+	return fmt.Sprintf("Fitbit rate limit exceeded, retry after %s", e.RetryAfter)
+}
@@ -4,10 +4,13 @@ package fitbit
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"sync"
+	"time"
 
-	"fmt"
 	"github.com/markbates/goth"
 	"golang.org/x/oauth2"
 )
@@ -61,8 +64,24 @@ type Provider struct {
 	HTTPClient   *http.Client
 	config       *oauth2.Config
 	providerName string
+
+	refreshMu sync.Mutex
+	refreshes map[string]*refresh
 }
 
+// refresh is a refresh of an access token, shared by the concurrent callers
+// refreshing the same refresh token.
+type refresh struct {
+	done     chan struct{}
+	token    *oauth2.Token
+	err      error
+	finished time.Time
+}
+
+// refreshReuseWindow is how long the result of a refresh is given to the callers
+// refreshing the same refresh token, which Fitbit invalidated.
+var refreshReuseWindow = time.Minute
+
 // Name is the name used to retrieve this provider later.
 func (p *Provider) Name() string {
 	return p.providerName
@@ -119,6 +138,10 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return user, RateLimitError{RetryAfter: retryAfter(resp.Header.Get("Retry-After"))}
+	}
+
 	if resp.StatusCode != http.StatusOK {
 		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, resp.StatusCode)
 	}
@@ -128,6 +151,18 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 	return user, err
 }
 
+// retryAfter parses the value of a Retry-After header, either a number of seconds or
+// an HTTP date.
+func retryAfter(value string) time.Duration {
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil && time.Until(date) > 0 {
+		return time.Until(date)
+	}
+	return 0
+}
+
 func userFromReader(reader io.Reader, user *goth.User) error {
 	u := struct {
 		User struct {
@@ -178,15 +213,54 @@ func newConfig(provider *Provider, scopes []string) *oauth2.Config {
 	return c
 }
 
-//RefreshToken get new access token based on the refresh token
+// RefreshToken get new access token based on the refresh token.
+//
+// Fitbit invalidates the refresh token once it is used, so the concurrent calls with the
+// same refresh token share a single refresh, and the calls made shortly after it get its
+// result instead of failing with the invalidated token.
 func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
-	token := &oauth2.Token{RefreshToken: refreshToken}
-	ts := p.config.TokenSource(oauth2.NoContext, token)
-	newToken, err := ts.Token()
-	if err != nil {
-		return nil, err
+	p.refreshMu.Lock()
+	if p.refreshes == nil {
+		p.refreshes = map[string]*refresh{}
+	}
+	for key, r := range p.refreshes {
+		if !r.finished.IsZero() && time.Since(r.finished) > refreshReuseWindow {
+			delete(p.refreshes, key)
+		}
+	}
+	r, ok := p.refreshes[refreshToken]
+	if !ok {
+		r = &refresh{done: make(chan struct{})}
+		p.refreshes[refreshToken] = r
+	}
+	p.refreshMu.Unlock()
+
+	if ok {
+		<-r.done
+	} else {
+		r.token, r.err = p.refreshToken(refreshToken)
+		p.refreshMu.Lock()
+		if r.err != nil {
+			// a failed refresh can be retried
+			delete(p.refreshes, refreshToken)
+		} else {
+			r.finished = time.Now()
+		}
+		p.refreshMu.Unlock()
+		close(r.done)
+	}
+
+	if r.err != nil {
+		return nil, r.err
 	}
-	return newToken, err
+	token := *r.token
+	return &token, nil
+}
+
+func (p *Provider) refreshToken(refreshToken string) (*oauth2.Token, error) {
+	token := &oauth2.Token{RefreshToken: refreshToken}
+	ts := p.config.TokenSource(goth.ContextForClient(p.Client()), token)
+	return ts.Token()
 }
 
 //RefreshTokenAvailable refresh token is not provided by fitbit
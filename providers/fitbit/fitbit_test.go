@@ -1,16 +1,24 @@
-package fitbit_test
+package fitbit
 
 import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/markbates/goth"
-	"github.com/markbates/goth/providers/fitbit"
 	"github.com/stretchr/testify/assert"
 )
 
-func provider() *fitbit.Provider {
-	return fitbit.New(os.Getenv("FITBIT_KEY"), os.Getenv("FITBIT_SECRET"), "/foo", "user")
+func provider() *Provider {
+	return New(os.Getenv("FITBIT_KEY"), os.Getenv("FITBIT_SECRET"), "/foo", "user")
 }
 
 func Test_New(t *testing.T) {
@@ -35,7 +43,7 @@ func Test_BeginAuth(t *testing.T) {
 
 	p := provider()
 	session, err := p.BeginAuth("test_state")
-	s := session.(*fitbit.Session)
+	s := session.(*Session)
 	a.NoError(err)
 	a.Contains(s.AuthURL, "www.fitbit.com/oauth2/authorize")
 }
@@ -48,8 +56,79 @@ func Test_SessionFromJSON(t *testing.T) {
 	session, err := p.UnmarshalSession(`{"AuthURL":"https://www.fitbit.com/oauth2/authorize","AccessToken":"1234567890","UserID":"abc"}`)
 	a.NoError(err)
 
-	s := session.(*fitbit.Session)
+	s := session.(*Session)
 	a.Equal(s.AuthURL, "https://www.fitbit.com/oauth2/authorize")
 	a.Equal(s.AccessToken, "1234567890")
 	a.Equal(s.UserID, "abc")
 }
+
+func Test_FetchUser_RateLimited(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	withMockServer(provider(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/1/user/-/profile.json", r.URL.Path)
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}), func(p *Provider) {
+		_, err := p.FetchUser(&Session{AccessToken: "token"})
+		a.Equal(RateLimitError{RetryAfter: 2 * time.Minute}, err)
+	})
+}
+
+func Test_RefreshToken_SingleFlight(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	var calls int32
+	withMockServer(provider(), http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/oauth2/token", r.URL.Path)
+		n := atomic.AddInt32(&calls, 1)
+		// let the other callers pile up
+		time.Sleep(50 * time.Millisecond)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token":"access-%d","refresh_token":"rotated-%d","token_type":"Bearer","expires_in":28800}`, n, n)
+	}), func(p *Provider) {
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				token, err := p.RefreshToken("refresh")
+				a.NoError(err)
+				a.Equal("access-1", token.AccessToken)
+				a.Equal("rotated-1", token.RefreshToken)
+			}()
+		}
+		wg.Wait()
+
+		// shortly after the refresh, the invalidated refresh token still gets its result
+		token, err := p.RefreshToken("refresh")
+		a.NoError(err)
+		a.Equal("rotated-1", token.RefreshToken)
+		a.Equal(int32(1), atomic.LoadInt32(&calls))
+
+		token, err = p.RefreshToken("rotated-1")
+		a.NoError(err)
+		a.Equal("rotated-2", token.RefreshToken)
+		a.Equal(int32(2), atomic.LoadInt32(&calls))
+	})
+}
+
+func withMockServer(p *Provider, handler http.Handler, fn func(p *Provider)) {
+	server := httptest.NewTLSServer(handler)
+	defer server.Close()
+
+	p.HTTPClient = &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return net.Dial(network, server.Listener.Addr().String())
+			},
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		},
+	}
+
+	fn(p)
+}
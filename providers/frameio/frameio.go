@@ -0,0 +1,229 @@
+// Package frameio implements the OAuth2 protocol for authenticating users through Frame.io.
+package frameio
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+)
+
+// These vars define the Authentication, Token, and API URLS for Frame.io.
+var (
+	AuthURL  = "https://applications.frame.io/oauth2/auth"
+	TokenURL = "https://applications.frame.io/oauth2/token"
+	APIURL   = "https://api.frame.io/v2"
+)
+
+const (
+	// ScopeOffline is required to get a refresh token.
+	ScopeOffline = "offline"
+	// ScopeAccountRead allows to read the accounts of the user, it is required by FetchUser.
+	ScopeAccountRead = "account.read"
+	// ScopeTeamRead allows to read the teams of the user, it is required by FetchUser.
+	ScopeTeamRead = "team.read"
+	// ScopeProjectRead allows to read projects.
+	ScopeProjectRead = "project.read"
+	// ScopeAssetRead allows to read assets.
+	ScopeAssetRead = "asset.read"
+)
+
+// Provider is the implementation of `goth.Provider` for accessing Frame.io.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	HTTPClient   *http.Client
+	config       *oauth2.Config
+	providerName string
+}
+
+// New creates a new Frame.io provider and sets up important connection details.
+// You should always call `frameio.New` to get a new provider.  Never try to
+// create one manually.
+func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "frameio",
+	}
+	p.config = newConfig(p, scopes)
+	return p
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns an HTTP client to be used in all fetch operations.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// Debug is a no-op for the frameio package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks Frame.io for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	verifier, err := goth.GenerateCodeVerifier()
+	if err != nil {
+		return nil, err
+	}
+	return &Session{
+		AuthURL:      p.config.AuthCodeURL(state, goth.PKCEChallengeOptions(verifier)...),
+		CodeVerifier: verifier,
+	}, nil
+}
+
+// FetchUser will go to Frame.io and access basic information about the user.
+// The accounts and teams the user is a member of are available in RawData under
+// "accounts" and "teams".
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	req, err := http.NewRequest("GET", APIURL+"/me", nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+
+	response, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+	}
+
+	bits, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return user, err
+	}
+
+	err = json.NewDecoder(bytes.NewReader(bits)).Decode(&user.RawData)
+	if err != nil {
+		return user, err
+	}
+
+	err = userFromReader(bytes.NewReader(bits), &user)
+	if err != nil {
+		return user, err
+	}
+
+	for _, membership := range []string{"accounts", "teams"} {
+		list := []interface{}{}
+		err = p.fetchList(sess.AccessToken, membership, &list)
+		if err != nil {
+			return user, err
+		}
+		user.RawData[membership] = list
+	}
+	return user, nil
+}
+
+// fetchList reads a list of the API, such as the accounts or teams of the user.
+func (p *Provider) fetchList(accessToken, path string, list *[]interface{}) error {
+	req, err := http.NewRequest("GET", APIURL+"/"+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Authorization", "Bearer "+accessToken)
+
+	response, err := p.Client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s responded with a %d trying to fetch %s", p.providerName, response.StatusCode, path)
+	}
+
+	return json.NewDecoder(response.Body).Decode(list)
+}
+
+func userFromReader(r io.Reader, user *goth.User) error {
+	u := struct {
+		ID       string `json:"id"`
+		Name     string `json:"name"`
+		Email    string `json:"email"`
+		Image    string `json:"image_256"`
+		Location string `json:"location"`
+		Bio      string `json:"bio"`
+	}{}
+	err := json.NewDecoder(r).Decode(&u)
+	if err != nil {
+		return err
+	}
+	user.UserID = u.ID
+	user.Name = u.Name
+	user.Email = u.Email
+	user.AvatarURL = u.Image
+	user.Location = u.Location
+	user.Description = u.Bio
+	return nil
+}
+
+func newConfig(provider *Provider, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   AuthURL,
+			TokenURL:  TokenURL,
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+		Scopes: []string{},
+	}
+
+	if len(scopes) > 0 {
+		for _, scope := range scopes {
+			c.Scopes = append(c.Scopes, scope)
+		}
+	} else {
+		c.Scopes = []string{ScopeOffline, ScopeAccountRead, ScopeTeamRead}
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	token := &oauth2.Token{RefreshToken: refreshToken}
+	ts := p.config.TokenSource(goth.ContextForClient(p.Client()), token)
+	newToken, err := ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	return newToken, err
+}
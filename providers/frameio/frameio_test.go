@@ -0,0 +1,95 @@
+package frameio_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/frameio"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("FRAMEIO_KEY"))
+	a.Equal(p.Secret, os.Getenv("FRAMEIO_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	session, err := p.BeginAuth("test_state")
+	s := session.(*frameio.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "applications.frame.io/oauth2/auth")
+	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("FRAMEIO_KEY")))
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "scope=offline+account.read+team.read")
+	a.Contains(s.AuthURL, "code_challenge="+goth.S256CodeChallenge(s.CodeVerifier))
+	a.Contains(s.AuthURL, "code_challenge_method=S256")
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://applications.frame.io/oauth2/auth","AccessToken":"1234567890"}`)
+	a.NoError(err)
+
+	s := session.(*frameio.Session)
+	a.Equal(s.AuthURL, "https://applications.frame.io/oauth2/auth")
+	a.Equal(s.AccessToken, "1234567890")
+}
+
+func Test_FetchUser(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("Bearer 1234567890", r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/me":
+			w.Write([]byte(`{"id":"b4e0f5a9-1f8e-4d2c-9b0a-7c6d5e4f3a2b","name":"Jane Doe","email":"jane@example.com","image_256":"https://static.frame.io/jane.png","location":"New York","bio":"Editor","account_id":"a1b2c3d4-0000-4000-8000-000000000001"}`))
+		case "/accounts":
+			w.Write([]byte(`[{"id":"a1b2c3d4-0000-4000-8000-000000000001","display_name":"Acme Post"}]`))
+		case "/teams":
+			w.Write([]byte(`[{"id":"c1d2e3f4-0000-4000-8000-000000000002","name":"Editorial","account_id":"a1b2c3d4-0000-4000-8000-000000000001"}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	originalAPIURL := frameio.APIURL
+	frameio.APIURL = ts.URL
+	defer func() { frameio.APIURL = originalAPIURL }()
+
+	user, err := provider().FetchUser(&frameio.Session{AccessToken: "1234567890"})
+	a.NoError(err)
+	a.Equal("b4e0f5a9-1f8e-4d2c-9b0a-7c6d5e4f3a2b", user.UserID)
+	a.Equal("Jane Doe", user.Name)
+	a.Equal("jane@example.com", user.Email)
+	a.Equal("https://static.frame.io/jane.png", user.AvatarURL)
+	a.Equal("a1b2c3d4-0000-4000-8000-000000000001", user.RawData["account_id"])
+	a.Len(user.RawData["accounts"], 1)
+	teams := user.RawData["teams"].([]interface{})
+	a.Equal("Editorial", teams[0].(map[string]interface{})["name"])
+}
+
+func provider() *frameio.Provider {
+	return frameio.New(os.Getenv("FRAMEIO_KEY"), os.Getenv("FRAMEIO_SECRET"), "/foo")
+}
@@ -0,0 +1,276 @@
+// Package franceconnect implements the OpenID Connect protocol for authenticating users through
+// FranceConnect v2.
+//
+// FranceConnect signs both the id_token and the userinfo response, which is returned as
+// a JWT. Their signatures are verified with the keys published by FranceConnect, and the
+// nonce sent by BeginAuth is checked against the id_token. The eIDAS level is requested
+// with ACRValues, the level that was reached is available in the session as ACR.
+package franceconnect
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/internal/jwks"
+	"golang.org/x/oauth2"
+)
+
+// These are the base URLs of the FranceConnect v2 environments, they are also the
+// issuers of the signed tokens.
+const (
+	ProductionURL  = "https://oidc.franceconnect.gouv.fr/api/v2"
+	IntegrationURL = "https://fcp-low.sbx.dev-franceconnect.fr/api/v2"
+)
+
+// These are the eIDAS levels that can be requested with acr_values.
+const (
+	EIDAS1 = "eidas1"
+	EIDAS2 = "eidas2"
+	EIDAS3 = "eidas3"
+)
+
+const (
+	// ScopeOpenID is required to sign in with FranceConnect.
+	ScopeOpenID = "openid"
+	// ScopeGivenName grants access to the user's given names.
+	ScopeGivenName = "given_name"
+	// ScopeFamilyName grants access to the user's birth name.
+	ScopeFamilyName = "family_name"
+	// ScopePreferredUsername grants access to the user's usual name.
+	ScopePreferredUsername = "preferred_username"
+	// ScopeBirthdate grants access to the user's birthdate.
+	ScopeBirthdate = "birthdate"
+	// ScopeGender grants access to the user's gender.
+	ScopeGender = "gender"
+	// ScopeBirthplace grants access to the INSEE code of the user's place of birth.
+	ScopeBirthplace = "birthplace"
+	// ScopeBirthcountry grants access to the INSEE code of the user's country of birth.
+	ScopeBirthcountry = "birthcountry"
+	// ScopeEmail grants access to the user's email address.
+	ScopeEmail = "email"
+)
+
+// Provider is the implementation of `goth.Provider` for accessing FranceConnect.
+type Provider struct {
+	ClientKey   string
+	Secret      string
+	CallbackURL string
+	// ACRValues are the requested eIDAS levels, EIDAS1 by default.
+	ACRValues    []string
+	HTTPClient   *http.Client
+	config       *oauth2.Config
+	providerName string
+	baseURL      string
+	keys         jwks.Cache
+}
+
+// New creates a new FranceConnect provider for the production environment and sets up
+// important connection details.
+// You should always call `franceconnect.New` to get a new provider.  Never try to
+// create one manually.
+func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	return NewCustomisedURL(clientKey, secret, callbackURL, ProductionURL, scopes...)
+}
+
+// NewIntegration is similar to New(...) but uses the integration environment.
+func NewIntegration(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	return NewCustomisedURL(clientKey, secret, callbackURL, IntegrationURL, scopes...)
+}
+
+// NewCustomisedURL is similar to New(...) but can be used to set a custom base URL.
+func NewCustomisedURL(clientKey, secret, callbackURL, baseURL string, scopes ...string) *Provider {
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		ACRValues:    []string{EIDAS1},
+		providerName: "franceconnect",
+		baseURL:      strings.TrimSuffix(baseURL, "/"),
+	}
+	p.config = newConfig(p, scopes)
+	return p
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns an HTTP client to be used in all fetch operations.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// Debug is a no-op for the franceconnect package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks FranceConnect for an authentication end-point.
+// FranceConnect requires a state, so it cannot be empty.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	if state == "" {
+		return nil, fmt.Errorf("%s requires a state", p.providerName)
+	}
+	nonce, err := goth.GenerateCodeVerifier()
+	if err != nil {
+		return nil, err
+	}
+	url := p.config.AuthCodeURL(state,
+		oauth2.SetAuthURLParam("nonce", nonce),
+		oauth2.SetAuthURLParam("acr_values", strings.Join(p.ACRValues, " ")),
+	)
+	return &Session{
+		AuthURL: url,
+		Nonce:   nonce,
+	}, nil
+}
+
+// FetchUser will go to FranceConnect and access the identity of the user, after
+// verifying the signature of the response.
+// The birth name is used as LastName, the usual name, when there is one, as NickName.
+// The identity pivot ("birthdate", "gender", "birthplace" and "birthcountry") is
+// available in RawData.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken: sess.AccessToken,
+		Provider:    p.Name(),
+		ExpiresAt:   sess.ExpiresAt,
+		IDToken:     sess.IDToken,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	req, err := http.NewRequest("GET", p.baseURL+"/userinfo", nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+
+	response, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+	}
+
+	bits, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return user, err
+	}
+
+	claims, err := p.verify(strings.TrimSpace(string(bits)))
+	if err != nil {
+		return user, err
+	}
+
+	user.RawData = claims
+	userFromClaims(claims, &user)
+	return user, nil
+}
+
+func userFromClaims(claims jwt.MapClaims, user *goth.User) {
+	str := func(name string) string {
+		s, _ := claims[name].(string)
+		return s
+	}
+	user.UserID = str("sub")
+	user.Email = str("email")
+	user.FirstName = str("given_name")
+	user.LastName = str("family_name")
+	user.NickName = str("preferred_username")
+	lastName := user.LastName
+	if user.NickName != "" {
+		lastName = user.NickName
+	}
+	user.Name = strings.TrimSpace(user.FirstName + " " + lastName)
+}
+
+// verify checks the signature, issuer and audience of a JWT signed by FranceConnect,
+// and returns its claims.
+func (p *Provider) verify(raw string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(raw, claims, func(token *jwt.Token) (interface{}, error) {
+		switch token.Method {
+		case jwt.SigningMethodES256, jwt.SigningMethodRS256:
+		default:
+			return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+		}
+		kid, _ := token.Header["kid"].(string)
+		return p.publicKey(kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s returned an invalid token: %v", p.providerName, err)
+	}
+	if !claims.VerifyIssuer(p.baseURL, true) {
+		return nil, fmt.Errorf("%s returned a token with an unexpected issuer", p.providerName)
+	}
+	if !claims.VerifyAudience(p.ClientKey, true) {
+		return nil, fmt.Errorf("%s returned a token for another audience", p.providerName)
+	}
+	return claims, nil
+}
+
+// publicKey gets the key with the given id from the keys published by FranceConnect,
+// which are cached.
+func (p *Provider) publicKey(kid string) (interface{}, error) {
+	key, err := p.keys.Key(p.Client(), p.baseURL+"/jwks", kid)
+	if err != nil {
+		return nil, err
+	}
+	switch key.(type) {
+	case *ecdsa.PublicKey, *rsa.PublicKey:
+		return key, nil
+	}
+	return nil, errors.New("unexpected key type")
+}
+
+func newConfig(provider *Provider, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   provider.baseURL + "/authorize",
+			TokenURL:  provider.baseURL + "/token",
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+		Scopes: []string{},
+	}
+
+	if len(scopes) > 0 {
+		for _, scope := range scopes {
+			c.Scopes = append(c.Scopes, scope)
+		}
+	} else {
+		c.Scopes = []string{ScopeOpenID, ScopeGivenName, ScopeFamilyName, ScopeEmail}
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is not provided by FranceConnect
+func (p *Provider) RefreshTokenAvailable() bool {
+	return false
+}
+
+// RefreshToken refresh token is not provided by FranceConnect
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	return nil, errors.New("Refresh token is not provided by FranceConnect")
+}
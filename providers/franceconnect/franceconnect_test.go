@@ -0,0 +1,208 @@
+package franceconnect_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/lestrrat-go/jwx/jwk"
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/franceconnect"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("FRANCECONNECT_KEY"))
+	a.Equal(p.Secret, os.Getenv("FRANCECONNECT_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+	a.Equal(p.ACRValues, []string{franceconnect.EIDAS1})
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	p.ACRValues = []string{franceconnect.EIDAS2}
+	session, err := p.BeginAuth("test_state")
+	s := session.(*franceconnect.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://oidc.franceconnect.gouv.fr/api/v2/authorize")
+	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("FRANCECONNECT_KEY")))
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "scope=openid+given_name+family_name+email")
+	a.Contains(s.AuthURL, "acr_values=eidas2")
+	a.NotEmpty(s.Nonce)
+	a.Contains(s.AuthURL, "nonce="+s.Nonce)
+}
+
+func Test_BeginAuthWithoutState(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	_, err := provider().BeginAuth("")
+	a.Error(err)
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://oidc.franceconnect.gouv.fr/api/v2/authorize","AccessToken":"1234567890","ACR":"eidas1"}`)
+	a.NoError(err)
+
+	s := session.(*franceconnect.Session)
+	a.Equal(s.AuthURL, "https://oidc.franceconnect.gouv.fr/api/v2/authorize")
+	a.Equal(s.AccessToken, "1234567890")
+	a.Equal(s.ACR, "eidas1")
+}
+
+func Test_Authorize(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	f := newFranceConnect(t)
+	defer f.Close()
+	f.idToken = jwt.MapClaims{"iss": f.URL, "aud": "client-id", "sub": "sub", "nonce": "nonce", "acr": "eidas1"}
+
+	p := franceconnect.NewCustomisedURL("client-id", "client-secret", "/foo", f.URL)
+	s := &franceconnect.Session{Nonce: "nonce"}
+	token, err := s.Authorize(p, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("SlAV32hkKG", token)
+	a.NotEmpty(s.IDToken)
+	a.Equal("eidas1", s.ACR)
+}
+
+func Test_AuthorizeWithWrongNonce(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	f := newFranceConnect(t)
+	defer f.Close()
+	f.idToken = jwt.MapClaims{"iss": f.URL, "aud": "client-id", "sub": "sub", "nonce": "other", "acr": "eidas1"}
+
+	p := franceconnect.NewCustomisedURL("client-id", "client-secret", "/foo", f.URL)
+	s := &franceconnect.Session{Nonce: "nonce"}
+	_, err := s.Authorize(p, url.Values{"code": {"code"}})
+	a.Error(err)
+	a.Empty(s.AccessToken)
+}
+
+func Test_FetchUser(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	f := newFranceConnect(t)
+	defer f.Close()
+	f.userInfo = jwt.MapClaims{
+		"iss":                f.URL,
+		"aud":                "client-id",
+		"sub":                "b6048e95bb134ec5b1d1e1fa69f287172e91722b9354d637a1bcf2ebb0fd2ef5v1",
+		"given_name":         "Angela Claire Louise",
+		"family_name":        "DUBOIS",
+		"preferred_username": "MARTIN",
+		"email":              "wossewodda-3728@yopmail.com",
+		"birthdate":          "1962-08-24",
+	}
+
+	p := franceconnect.NewCustomisedURL("client-id", "client-secret", "/foo", f.URL)
+	user, err := p.FetchUser(&franceconnect.Session{AccessToken: "1234567890"})
+	a.NoError(err)
+	a.Equal("b6048e95bb134ec5b1d1e1fa69f287172e91722b9354d637a1bcf2ebb0fd2ef5v1", user.UserID)
+	a.Equal("Angela Claire Louise", user.FirstName)
+	a.Equal("DUBOIS", user.LastName)
+	a.Equal("MARTIN", user.NickName)
+	a.Equal("Angela Claire Louise MARTIN", user.Name)
+	a.Equal("wossewodda-3728@yopmail.com", user.Email)
+	a.Equal("1962-08-24", user.RawData["birthdate"])
+}
+
+func Test_FetchUserWithWrongIssuer(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	f := newFranceConnect(t)
+	defer f.Close()
+	f.userInfo = jwt.MapClaims{"iss": "https://example.com", "aud": "client-id", "sub": "sub"}
+
+	p := franceconnect.NewCustomisedURL("client-id", "client-secret", "/foo", f.URL)
+	_, err := p.FetchUser(&franceconnect.Session{AccessToken: "1234567890"})
+	a.Error(err)
+}
+
+func provider() *franceconnect.Provider {
+	return franceconnect.New(os.Getenv("FRANCECONNECT_KEY"), os.Getenv("FRANCECONNECT_SECRET"), "/foo")
+}
+
+// fakeFranceConnect serves the token, userinfo and jwks endpoints, signing the
+// id_token and the userinfo response with its own key.
+type fakeFranceConnect struct {
+	*httptest.Server
+	key      *ecdsa.PrivateKey
+	idToken  jwt.MapClaims
+	userInfo jwt.MapClaims
+}
+
+func newFranceConnect(t *testing.T) *fakeFranceConnect {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeFranceConnect{key: key}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/token":
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"access_token": "SlAV32hkKG",
+				"token_type":   "Bearer",
+				"expires_in":   60,
+				"id_token":     f.sign(t, f.idToken),
+			})
+		case "/userinfo":
+			w.Header().Set("Content-Type", "application/jwt")
+			w.Write([]byte(f.sign(t, f.userInfo)))
+		case "/jwks":
+			k, err := jwk.New(&f.key.PublicKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+			k.Set(jwk.KeyIDKey, "key-1")
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(jwk.Set{Keys: []jwk.Key{k}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return f
+}
+
+func (f *fakeFranceConnect) sign(t *testing.T, claims jwt.MapClaims) string {
+	claims["exp"] = time.Now().Add(time.Minute).Unix()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["kid"] = "key-1"
+	s, err := token.SignedString(f.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
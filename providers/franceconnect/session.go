@@ -0,0 +1,77 @@
+package franceconnect
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/markbates/goth"
+)
+
+// Session stores data during the auth process with FranceConnect.
+type Session struct {
+	AuthURL     string
+	Nonce       string
+	AccessToken string
+	ExpiresAt   time.Time
+	IDToken     string
+	ACR         string
+}
+
+var _ goth.Session = &Session{}
+
+// GetAuthURL will return the URL set by calling the `BeginAuth` function on the FranceConnect provider.
+func (s Session) GetAuthURL() (string, error) {
+	if s.AuthURL == "" {
+		return "", errors.New(goth.NoAuthUrlErrorMessage)
+	}
+	return s.AuthURL, nil
+}
+
+// Authorize the session with FranceConnect and return the access token to be stored for future use.
+// The id_token is verified and must carry the nonce sent by BeginAuth.
+func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string, error) {
+	p := provider.(*Provider)
+	token, err := p.config.Exchange(goth.ContextForClient(p.Client()), params.Get("code"))
+	if err != nil {
+		return "", err
+	}
+
+	if !token.Valid() {
+		return "", errors.New("Invalid token received from provider")
+	}
+
+	idToken, _ := token.Extra("id_token").(string)
+	claims, err := p.verify(idToken)
+	if err != nil {
+		return "", err
+	}
+	if nonce, _ := claims["nonce"].(string); s.Nonce == "" || nonce != s.Nonce {
+		return "", fmt.Errorf("%s returned an id_token with an unexpected nonce", p.providerName)
+	}
+
+	s.AccessToken = token.AccessToken
+	s.ExpiresAt = token.Expiry
+	s.IDToken = idToken
+	s.ACR, _ = claims["acr"].(string)
+	return token.AccessToken, err
+}
+
+// Marshal the session into a string
+func (s Session) Marshal() string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+func (s Session) String() string {
+	return s.Marshal()
+}
+
+// UnmarshalSession will unmarshal a JSON string into a session.
+func (p *Provider) UnmarshalSession(data string) (goth.Session, error) {
+	s := &Session{}
+	err := json.NewDecoder(strings.NewReader(data)).Decode(s)
+	return s, err
+}
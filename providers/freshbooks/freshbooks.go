@@ -0,0 +1,191 @@
+// Package freshbooks implements the OAuth2 protocol for authenticating users through FreshBooks.
+package freshbooks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+)
+
+// These vars define the Authentication, Token, and Profile URLS for FreshBooks.
+var (
+	AuthURL    = "https://auth.freshbooks.com/oauth/authorize"
+	TokenURL   = "https://api.freshbooks.com/auth/oauth/token"
+	ProfileURL = "https://api.freshbooks.com/auth/api/v1/users/me"
+)
+
+const (
+	// ScopeProfileRead allows to read the user's identity and business memberships.
+	ScopeProfileRead = "user:profile:read"
+)
+
+// Provider is the implementation of `goth.Provider` for accessing FreshBooks.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	HTTPClient   *http.Client
+	config       *oauth2.Config
+	providerName string
+}
+
+// New creates a new FreshBooks provider and sets up important connection details.
+// You should always call `freshbooks.New` to get a new provider.  Never try to
+// create one manually.
+func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "freshbooks",
+	}
+	p.config = newConfig(p, scopes)
+	return p
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns an HTTP client to be used in all fetch operations.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// Debug is a no-op for the freshbooks package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks FreshBooks for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	return &Session{
+		AuthURL: p.config.AuthCodeURL(state),
+	}, nil
+}
+
+// FetchUser will go to FreshBooks and access basic information about the user.
+// The businesses the user belongs to are available in RawData as "business_memberships".
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	req, err := http.NewRequest("GET", ProfileURL, nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+
+	response, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+	}
+
+	bits, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return user, err
+	}
+
+	err = userFromReader(bytes.NewReader(bits), &user)
+	return user, err
+}
+
+// userFromReader reads the user from the "response" envelope FreshBooks wraps it in,
+// the content of the envelope is used as RawData.
+func userFromReader(r io.Reader, user *goth.User) error {
+	envelope := struct {
+		Response json.RawMessage `json:"response"`
+	}{}
+	err := json.NewDecoder(r).Decode(&envelope)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(envelope.Response, &user.RawData)
+	if err != nil {
+		return err
+	}
+
+	u := struct {
+		IdentityID int64  `json:"identity_id"`
+		FirstName  string `json:"first_name"`
+		LastName   string `json:"last_name"`
+		Email      string `json:"email"`
+	}{}
+	err = json.Unmarshal(envelope.Response, &u)
+	if err != nil {
+		return err
+	}
+	user.UserID = strconv.FormatInt(u.IdentityID, 10)
+	user.FirstName = u.FirstName
+	user.LastName = u.LastName
+	user.Name = strings.TrimSpace(u.FirstName + " " + u.LastName)
+	user.Email = u.Email
+	return nil
+}
+
+func newConfig(provider *Provider, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   AuthURL,
+			TokenURL:  TokenURL,
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+		Scopes: []string{},
+	}
+
+	if len(scopes) > 0 {
+		for _, scope := range scopes {
+			c.Scopes = append(c.Scopes, scope)
+		}
+	} else {
+		c.Scopes = []string{ScopeProfileRead}
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	token := &oauth2.Token{RefreshToken: refreshToken}
+	ts := p.config.TokenSource(goth.ContextForClient(p.Client()), token)
+	newToken, err := ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	return newToken, err
+}
@@ -0,0 +1,82 @@
+package freshbooks_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/freshbooks"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("FRESHBOOKS_KEY"))
+	a.Equal(p.Secret, os.Getenv("FRESHBOOKS_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	session, err := p.BeginAuth("test_state")
+	s := session.(*freshbooks.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "auth.freshbooks.com/oauth/authorize")
+	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("FRESHBOOKS_KEY")))
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "scope=user%3Aprofile%3Aread")
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://auth.freshbooks.com/oauth/authorize","AccessToken":"1234567890"}`)
+	a.NoError(err)
+
+	s := session.(*freshbooks.Session)
+	a.Equal(s.AuthURL, "https://auth.freshbooks.com/oauth/authorize")
+	a.Equal(s.AccessToken, "1234567890")
+}
+
+func Test_FetchUser(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("Bearer 1234567890", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"response":{"id":2192788,"identity_id":2192788,"first_name":"Simon","last_name":"Smith","email":"simon@example.com","business_memberships":[{"id":160321,"role":"owner","business":{"id":77128,"name":"Simon's Bakery","account_id":"zDmNq"}}]}}`))
+	}))
+	defer ts.Close()
+
+	originalProfileURL := freshbooks.ProfileURL
+	freshbooks.ProfileURL = ts.URL
+	defer func() { freshbooks.ProfileURL = originalProfileURL }()
+
+	user, err := provider().FetchUser(&freshbooks.Session{AccessToken: "1234567890"})
+	a.NoError(err)
+	a.Equal("2192788", user.UserID)
+	a.Equal("Simon", user.FirstName)
+	a.Equal("Smith", user.LastName)
+	a.Equal("Simon Smith", user.Name)
+	a.Equal("simon@example.com", user.Email)
+	a.Len(user.RawData["business_memberships"], 1)
+}
+
+func provider() *freshbooks.Provider {
+	return freshbooks.New(os.Getenv("FRESHBOOKS_KEY"), os.Getenv("FRESHBOOKS_SECRET"), "/foo")
+}
@@ -0,0 +1,105 @@
+package garmin_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/garmin"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("GARMIN_KEY"))
+	a.Equal(p.Secret, os.Getenv("GARMIN_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	session, err := p.BeginAuth("test_state")
+	s := session.(*garmin.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "connect.garmin.com/oauth2Confirm")
+	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("GARMIN_KEY")))
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "response_type=code")
+	a.Contains(s.AuthURL, "code_challenge="+goth.S256CodeChallenge(s.CodeVerifier))
+	a.Contains(s.AuthURL, "code_challenge_method=S256")
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://connect.garmin.com/oauth2Confirm","AccessToken":"1234567890"}`)
+	a.NoError(err)
+
+	s := session.(*garmin.Session)
+	a.Equal(s.AuthURL, "https://connect.garmin.com/oauth2Confirm")
+	a.Equal(s.AccessToken, "1234567890")
+}
+
+func Test_FetchUser(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("Bearer 1234567890", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"userId":"d3315b1072421d0dd7c8f6b8e1de4df8"}`))
+	}))
+	defer ts.Close()
+
+	originalProfileURL := garmin.ProfileURL
+	garmin.ProfileURL = ts.URL
+	defer func() { garmin.ProfileURL = originalProfileURL }()
+
+	user, err := provider().FetchUser(&garmin.Session{AccessToken: "1234567890"})
+	a.NoError(err)
+	a.Equal("d3315b1072421d0dd7c8f6b8e1de4df8", user.UserID)
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.NoError(r.ParseForm())
+		a.Equal("authorization_code", r.Form.Get("grant_type"))
+		a.Equal("verifier", r.Form.Get("code_verifier"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"abc","token_type":"bearer","refresh_token":"def","expires_in":86400,"scope":"ACTIVITY_EXPORT HEALTH_EXPORT","refresh_token_expires_in":7775998}`))
+	}))
+	defer ts.Close()
+
+	originalTokenURL := garmin.TokenURL
+	garmin.TokenURL = ts.URL
+	defer func() { garmin.TokenURL = originalTokenURL }()
+
+	s := &garmin.Session{CodeVerifier: "verifier"}
+	token, err := s.Authorize(provider(), url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("abc", token)
+	a.Equal("def", s.RefreshToken)
+	a.WithinDuration(time.Now().Add(7775998*time.Second), s.RefreshTokenExpiresAt, time.Minute)
+}
+
+func provider() *garmin.Provider {
+	return garmin.New(os.Getenv("GARMIN_KEY"), os.Getenv("GARMIN_SECRET"), "/foo")
+}
@@ -1,4 +1,6 @@
 // Package gitea implements the OAuth2 protocol for authenticating users through gitea.
+// Since Forgejo (and Codeberg, which runs it) keeps Gitea's OAuth2 and API endpoints,
+// this package works for those instances too.
 // This package can be used as a reference implementation of an OAuth2 provider for Goth.
 package gitea
 
@@ -8,8 +10,8 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
-	"net/url"
 	"strconv"
+	"strings"
 
 	"fmt"
 	"github.com/markbates/goth"
@@ -19,9 +21,9 @@ import (
 // These vars define the default Authentication, Token, and Profile URLS for Gitea.
 //
 // Examples:
-//	gitea.AuthURL = "https://gitea.acme.com/oauth/authorize
-//	gitea.TokenURL = "https://gitea.acme.com/oauth/token
-//	gitea.ProfileURL = "https://gitea.acme.com/api/v3/user
+//	gitea.AuthURL = "https://gitea.acme.com/login/oauth/authorize
+//	gitea.TokenURL = "https://gitea.acme.com/login/oauth/access_token
+//	gitea.ProfileURL = "https://gitea.acme.com/api/v1/user
 var (
 	AuthURL    = "https://gitea.com/login/oauth/authorize"
 	TokenURL   = "https://gitea.com/login/oauth/access_token"
@@ -61,6 +63,22 @@ func NewCustomisedURL(clientKey, secret, callbackURL, authURL, tokenURL, profile
 	return p
 }
 
+// NewCustomisedDNS is the simplest method to create a provider for a self-hosted
+// Gitea or Forgejo instance, e.g. "https://codeberg.org". The OAuth2 and API
+// endpoints are derived from the instance URL, which may include a sub-path.
+func NewCustomisedDNS(clientKey, secret, callbackURL, giteaURL string, scopes ...string) *Provider {
+	giteaURL = strings.TrimSuffix(giteaURL, "/")
+	return NewCustomisedURL(
+		clientKey,
+		secret,
+		callbackURL,
+		giteaURL+"/login/oauth/authorize",
+		giteaURL+"/login/oauth/access_token",
+		giteaURL+"/api/v1/user",
+		scopes...,
+	)
+}
+
 // Name is the name used to retrieve this provider later.
 func (p *Provider) Name() string {
 	return p.providerName
@@ -100,7 +118,14 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
 	}
 
-	response, err := p.Client().Get(p.profileURL + "?access_token=" + url.QueryEscape(sess.AccessToken))
+	req, err := http.NewRequest("GET", p.profileURL, nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+	req.Header.Add("Accept", "application/json")
+
+	response, err := p.Client().Do(req)
 	if err != nil {
 		if response != nil {
 			response.Body.Close()
@@ -1,6 +1,8 @@
 package gitea_test
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 
@@ -29,6 +31,37 @@ func Test_NewCustomisedURL(t *testing.T) {
 	a.Contains(s.AuthURL, "http://authURL")
 }
 
+func Test_NewCustomisedDNS(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := gitea.NewCustomisedDNS(os.Getenv("GITEA_KEY"), os.Getenv("GITEA_SECRET"), "/foo", "https://codeberg.org/")
+	session, err := p.BeginAuth("test_state")
+	s := session.(*gitea.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://codeberg.org/login/oauth/authorize")
+}
+
+func Test_FetchUser(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/api/v1/user", r.URL.Path)
+		a.Equal("Bearer 1234567890", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"id":42,"login":"wash","full_name":"Hoban Washburne","email":"wash@serenity.now","avatar_url":"http://avatarURL"}`))
+	}))
+	defer ts.Close()
+
+	p := gitea.NewCustomisedDNS(os.Getenv("GITEA_KEY"), os.Getenv("GITEA_SECRET"), "/foo", ts.URL)
+	user, err := p.FetchUser(&gitea.Session{AccessToken: "1234567890"})
+	a.NoError(err)
+	a.Equal("42", user.UserID)
+	a.Equal("wash", user.NickName)
+	a.Equal("Hoban Washburne", user.Name)
+	a.Equal("wash@serenity.now", user.Email)
+	a.Equal("http://avatarURL", user.AvatarURL)
+}
+
 func Test_Implements_Provider(t *testing.T) {
 	t.Parallel()
 	a := assert.New(t)
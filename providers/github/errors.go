@@ -0,0 +1,47 @@
+package github
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// MembershipError is returned by FetchUser when the user is not a member of any of the
+// organizations or teams the provider is restricted to.
+type MembershipError struct {
+	Login string
+	Orgs  []string
+	Teams []string
+}
+
+func (e MembershipError) Error() string {
+	allowed := append(append([]string{}, e.Orgs...), e.Teams...)
+	return fmt.Sprintf("GitHub user %s is not a member of %s", e.Login, strings.Join(allowed, ", "))
+}
+
+// SSOError is returned when the user must sign in through the SAML single sign-on of an
+// organization before the token can access its resources, as reported by the
+// X-GitHub-SSO header. Send the user to URL to authorize the token.
+type SSOError struct {
+	URL string
+}
+
+func (e SSOError) Error() string {
+	return "GitHub requires SAML single sign-on to authorize the token: " + e.URL
+}
+
+// ssoError returns an SSOError when the response requires single sign-on.
+func ssoError(response *http.Response) error {
+	header := response.Header.Get("X-GitHub-SSO")
+	if response.StatusCode != http.StatusForbidden || !strings.HasPrefix(header, "required") {
+		return nil
+	}
+	e := SSOError{}
+	for _, param := range strings.Split(header, ";") {
+		param = strings.TrimSpace(param)
+		if strings.HasPrefix(param, "url=") {
+			e.URL = strings.TrimPrefix(param, "url=")
+		}
+	}
+	return e
+}
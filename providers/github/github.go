@@ -1,5 +1,9 @@
 // Package github implements the OAuth2 protocol for authenticating users through Github.
 // This package can be used as a reference implementation of an OAuth2 provider for Goth.
+//
+// Both OAuth Apps and GitHub Apps are supported, use NewApp for GitHub Apps. The
+// user-to-server tokens of GitHub Apps expire and are refreshed with a refresh token,
+// unless token expiration is disabled in the settings of the app.
 package github
 
 import (
@@ -12,6 +16,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/markbates/goth"
 	"golang.org/x/oauth2"
@@ -21,15 +26,19 @@ import (
 // using GitHub enterprise you should change these values before calling New.
 //
 // Examples:
-//	github.AuthURL = "https://github.acme.com/login/oauth/authorize
-//	github.TokenURL = "https://github.acme.com/login/oauth/access_token
-//	github.ProfileURL = "https://github.acme.com/api/v3/user
-//	github.EmailURL = "https://github.acme.com/api/v3/user/emails
+//	github.AuthURL = "https://github.acme.com/login/oauth/authorize"
+//	github.TokenURL = "https://github.acme.com/login/oauth/access_token"
+//	github.ProfileURL = "https://github.acme.com/api/v3/user"
+//	github.EmailURL = "https://github.acme.com/api/v3/user/emails"
+//	github.GraphQLURL = "https://github.acme.com/api/graphql"
+//
+// NewEnterprise can be used instead to configure all of them from the host.
 var (
 	AuthURL    = "https://github.com/login/oauth/authorize"
 	TokenURL   = "https://github.com/login/oauth/access_token"
 	ProfileURL = "https://api.github.com/user"
 	EmailURL   = "https://api.github.com/user/emails"
+	GraphQLURL = "https://api.github.com/graphql"
 )
 
 // New creates a new Github provider, and sets up important connection details.
@@ -45,6 +54,7 @@ func NewCustomisedURL(clientKey, secret, callbackURL, authURL, tokenURL, profile
 		ClientKey:    clientKey,
 		Secret:       secret,
 		CallbackURL:  callbackURL,
+		GraphQLURL:   GraphQLURL,
 		providerName: "github",
 		profileURL:   profileURL,
 		emailURL:     emailURL,
@@ -53,16 +63,60 @@ func NewCustomisedURL(clientKey, secret, callbackURL, authURL, tokenURL, profile
 	return p
 }
 
+// NewEnterprise is similar to New(...) but connects to a GitHub Enterprise Server, e.g.
+// "https://github.acme.com".
+func NewEnterprise(clientKey, secret, callbackURL, host string, scopes ...string) *Provider {
+	host = strings.TrimSuffix(host, "/")
+	p := NewCustomisedURL(clientKey, secret, callbackURL, host+"/login/oauth/authorize", host+"/login/oauth/access_token", host+"/api/v3/user", host+"/api/v3/user/emails", scopes...)
+	p.GraphQLURL = host + "/api/graphql"
+	return p
+}
+
+// NewApp is similar to New(...) but for GitHub Apps, whose permissions are configured on
+// the app instead of requested with scopes. The installations of the app the user can
+// access are available in RawData under "installation_ids".
+func NewApp(clientKey, secret, callbackURL string) *Provider {
+	p := New(clientKey, secret, callbackURL)
+	p.app = true
+	return p
+}
+
 // Provider is the implementation of `goth.Provider` for accessing Github.
 type Provider struct {
-	ClientKey    string
-	Secret       string
-	CallbackURL  string
+	ClientKey   string
+	Secret      string
+	CallbackURL string
+	// APIVersion, when set, is sent as the X-GitHub-Api-Version header of the requests to
+	// the API, e.g. "2022-11-28".
+	APIVersion string
+	// GraphQLURL is the endpoint of the GraphQL API, used by GraphQL.
+	GraphQLURL   string
 	HTTPClient   *http.Client
 	config       *oauth2.Config
 	providerName string
 	profileURL   string
 	emailURL     string
+	allowedOrgs  []string
+	allowedTeams []string
+	app          bool
+}
+
+// WithAllowedOrgs restricts the provider to the members of the given organizations,
+// FetchUser returns a MembershipError for other users. The organizations of the user are
+// available in RawData under "orgs". It requires the read:org scope.
+func (p *Provider) WithAllowedOrgs(orgs ...string) *Provider {
+	p.allowedOrgs = append(p.allowedOrgs, orgs...)
+	return p
+}
+
+// WithAllowedTeams restricts the provider to the members of the given teams, written as
+// "org/team-slug", FetchUser returns a MembershipError for other users. The teams of the
+// user are available in RawData under "teams". It requires the read:org scope.
+//
+// When both organizations and teams are allowed, being a member of either is enough.
+func (p *Provider) WithAllowedTeams(teams ...string) *Provider {
+	p.allowedTeams = append(p.allowedTeams, teams...)
+	return p
 }
 
 // Name is the name used to retrieve this provider later.
@@ -95,8 +149,10 @@ func (p *Provider) BeginAuth(state string) (goth.Session, error) {
 func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 	sess := session.(*Session)
 	user := goth.User{
-		AccessToken: sess.AccessToken,
-		Provider:    p.Name(),
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
 	}
 
 	if user.AccessToken == "" {
@@ -104,13 +160,7 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
 	}
 
-	req, err := http.NewRequest("GET", p.profileURL, nil)
-	if err != nil {
-		return user, err
-	}
-
-	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
-	response, err := p.Client().Do(req)
+	response, err := p.get(p.profileURL, sess.AccessToken)
 	if err != nil {
 		return user, err
 	}
@@ -146,9 +196,162 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 			}
 		}
 	}
+
+	if p.app {
+		user.RawData["installation_ids"], err = p.installationIDs(sess)
+		if err != nil {
+			return user, err
+		}
+	}
+
+	if len(p.allowedOrgs) > 0 || len(p.allowedTeams) > 0 {
+		err = p.checkMembership(sess, &user)
+	}
 	return user, err
 }
 
+// installationIDs lists the ids of the installations of the app the user can access.
+func (p *Provider) installationIDs(sess *Session) ([]int64, error) {
+	ids := []int64{}
+	url := p.apiURL("/user/installations") + "?per_page=100"
+	for url != "" {
+		response, err := p.get(url, sess.AccessToken)
+		if err != nil {
+			return nil, err
+		}
+
+		if response.StatusCode != http.StatusOK {
+			response.Body.Close()
+			return nil, fmt.Errorf("GitHub API responded with a %d trying to fetch user installations", response.StatusCode)
+		}
+
+		page := struct {
+			Installations []struct {
+				ID int64 `json:"id"`
+			} `json:"installations"`
+		}{}
+		err = json.NewDecoder(response.Body).Decode(&page)
+		response.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		for _, i := range page.Installations {
+			ids = append(ids, i.ID)
+		}
+		url = nextPage(response.Header.Get("Link"))
+	}
+	return ids, nil
+}
+
+// checkMembership adds the organizations and teams of the user to RawData, and checks
+// the user is a member of one of the allowed ones.
+func (p *Provider) checkMembership(sess *Session, user *goth.User) error {
+	member := false
+
+	if len(p.allowedOrgs) > 0 {
+		orgs := []struct {
+			Login string `json:"login"`
+		}{}
+		err := p.getAll(sess, p.apiURL("/user/orgs"), &orgs)
+		if err != nil {
+			return err
+		}
+		logins := make([]string, 0, len(orgs))
+		for _, o := range orgs {
+			logins = append(logins, o.Login)
+			member = member || contains(p.allowedOrgs, o.Login)
+		}
+		user.RawData["orgs"] = logins
+	}
+
+	if len(p.allowedTeams) > 0 {
+		teams := []struct {
+			Slug         string `json:"slug"`
+			Organization struct {
+				Login string `json:"login"`
+			} `json:"organization"`
+		}{}
+		err := p.getAll(sess, p.apiURL("/user/teams"), &teams)
+		if err != nil {
+			return err
+		}
+		names := make([]string, 0, len(teams))
+		for _, t := range teams {
+			name := t.Organization.Login + "/" + t.Slug
+			names = append(names, name)
+			member = member || contains(p.allowedTeams, name)
+		}
+		user.RawData["teams"] = names
+	}
+
+	if !member {
+		return MembershipError{Login: user.NickName, Orgs: p.allowedOrgs, Teams: p.allowedTeams}
+	}
+	return nil
+}
+
+// apiURL returns the URL of the given path of the API the profile URL belongs to.
+func (p *Provider) apiURL(path string) string {
+	return strings.TrimSuffix(p.profileURL, "/user") + path
+}
+
+// getAll reads every page of a list of the GitHub API into v, which must point to a slice.
+func (p *Provider) getAll(sess *Session, url string, v interface{}) error {
+	all := []json.RawMessage{}
+	url += "?per_page=100"
+	for url != "" {
+		response, err := p.get(url, sess.AccessToken)
+		if err != nil {
+			return err
+		}
+
+		if response.StatusCode != http.StatusOK {
+			response.Body.Close()
+			return fmt.Errorf("GitHub API responded with a %d trying to fetch user memberships", response.StatusCode)
+		}
+
+		page := []json.RawMessage{}
+		err = json.NewDecoder(response.Body).Decode(&page)
+		response.Body.Close()
+		if err != nil {
+			return err
+		}
+		all = append(all, page...)
+		url = nextPage(response.Header.Get("Link"))
+	}
+
+	bits, err := json.Marshal(all)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(bits, v)
+}
+
+// nextPage returns the URL of the next page from a Link header.
+func nextPage(link string) string {
+	for _, l := range strings.Split(link, ",") {
+		parts := strings.Split(l, ";")
+		if len(parts) < 2 {
+			continue
+		}
+		for _, param := range parts[1:] {
+			if strings.TrimSpace(param) == `rel="next"` {
+				return strings.Trim(strings.TrimSpace(parts[0]), "<>")
+			}
+		}
+	}
+	return ""
+}
+
+func contains(list []string, s string) bool {
+	for _, l := range list {
+		if strings.EqualFold(l, s) {
+			return true
+		}
+	}
+	return false
+}
+
 func userFromReader(reader io.Reader, user *goth.User) error {
 	u := struct {
 		ID       int    `json:"id"`
@@ -176,14 +379,89 @@ func userFromReader(reader io.Reader, user *goth.User) error {
 	return err
 }
 
-func getPrivateMail(p *Provider, sess *Session) (email string, err error) {
-	req, err := http.NewRequest("GET", p.emailURL, nil)
+// get reads a resource of the GitHub API with the access token. It returns an SSOError
+// when the resource belongs to an organization that requires the user to sign in
+// with SAML single sign-on.
+func (p *Provider) get(url, accessToken string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Authorization", "Bearer "+accessToken)
+	if p.APIVersion != "" {
+		req.Header.Add("X-GitHub-Api-Version", p.APIVersion)
+	}
+
+	response, err := p.Client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = ssoError(response); err != nil {
+		response.Body.Close()
+		return nil, err
+	}
+	return response, nil
+}
+
+// GraphQL runs a query against the GraphQL API of GitHub with the access token of the
+// session, and decodes the data of the response into result.
+func (p *Provider) GraphQL(session goth.Session, query string, variables map[string]interface{}, result interface{}) error {
+	sess := session.(*Session)
+	body, err := json.Marshal(map[string]interface{}{
+		"query":     query,
+		"variables": variables,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("POST", p.GraphQLURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
 	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+	req.Header.Add("Content-Type", "application/json")
+	if p.APIVersion != "" {
+		req.Header.Add("X-GitHub-Api-Version", p.APIVersion)
+	}
+
 	response, err := p.Client().Do(req)
 	if err != nil {
-		if response != nil {
-			response.Body.Close()
-		}
+		return err
+	}
+	defer response.Body.Close()
+
+	if err = ssoError(response); err != nil {
+		return err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("GitHub API responded with a %d trying to run a GraphQL query", response.StatusCode)
+	}
+
+	r := struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}{}
+	err = json.NewDecoder(response.Body).Decode(&r)
+	if err != nil {
+		return err
+	}
+	if len(r.Errors) > 0 {
+		return fmt.Errorf("GitHub API returned an error running a GraphQL query: %s", r.Errors[0].Message)
+	}
+	if result == nil || len(r.Data) == 0 {
+		return nil
+	}
+	return json.Unmarshal(r.Data, result)
+}
+
+func getPrivateMail(p *Provider, sess *Session) (email string, err error) {
+	response, err := p.get(p.emailURL, sess.AccessToken)
+	if err != nil {
 		return email, err
 	}
 	defer response.Body.Close()
@@ -228,12 +506,38 @@ func newConfig(provider *Provider, authURL, tokenURL string, scopes []string) *o
 	return c
 }
 
-//RefreshToken refresh token is not provided by github
+// refreshTokenExpiry returns when the refresh token of the token expires, as reported by
+// the refresh_token_expires_in field of the token response.
+func refreshTokenExpiry(token *oauth2.Token) time.Time {
+	var secs int64
+	switch v := token.Extra("refresh_token_expires_in").(type) {
+	case int64:
+		secs = v
+	case float64:
+		secs = int64(v)
+	}
+	if secs > 0 {
+		return time.Now().Add(time.Duration(secs) * time.Second)
+	}
+	return time.Time{}
+}
+
+// RefreshToken get new access token based on the refresh token, only GitHub Apps provide
+// refresh tokens. GitHub rotates refresh tokens, so always store the new one.
 func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
-	return nil, errors.New("Refresh token is not provided by github")
+	if !p.app {
+		return nil, errors.New("Refresh token is not provided by github")
+	}
+	token := &oauth2.Token{RefreshToken: refreshToken}
+	ts := p.config.TokenSource(goth.ContextForClient(p.Client()), token)
+	newToken, err := ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	return newToken, err
 }
 
-//RefreshTokenAvailable refresh token is not provided by github
+// RefreshTokenAvailable refresh token is only provided by github for GitHub Apps
 func (p *Provider) RefreshTokenAvailable() bool {
-	return false
+	return p.app
 }
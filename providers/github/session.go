@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"strings"
+	"time"
 
 	"github.com/markbates/goth"
 )
@@ -12,6 +13,11 @@ import (
 type Session struct {
 	AuthURL     string
 	AccessToken string
+	// These fields are only set for the user-to-server tokens of GitHub Apps that
+	// expire, the tokens of OAuth Apps do not expire.
+	RefreshToken          string
+	ExpiresAt             time.Time
+	RefreshTokenExpiresAt time.Time
 }
 
 // GetAuthURL will return the URL set by calling the `BeginAuth` function on the Github provider.
@@ -35,6 +41,9 @@ func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string,
 	}
 
 	s.AccessToken = token.AccessToken
+	s.RefreshToken = token.RefreshToken
+	s.ExpiresAt = token.Expiry
+	s.RefreshTokenExpiresAt = refreshTokenExpiry(token)
 	return token.AccessToken, err
 }
 
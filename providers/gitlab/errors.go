@@ -0,0 +1,17 @@
+package gitlab
+
+import (
+	"fmt"
+	"strings"
+)
+
+// GroupError is returned by FetchUser when the user is not a member of any of the
+// groups the provider is restricted to.
+type GroupError struct {
+	Username string
+	Groups   []string
+}
+
+func (e GroupError) Error() string {
+	return fmt.Sprintf("GitLab user %s is not a member of %s", e.Username, strings.Join(e.Groups, ", "))
+}
@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 
 	"fmt"
 	"github.com/markbates/goth"
@@ -22,11 +23,25 @@ import (
 // Examples:
 //	gitlab.AuthURL = "https://gitlab.acme.com/oauth/authorize
 //	gitlab.TokenURL = "https://gitlab.acme.com/oauth/token
-//	gitlab.ProfileURL = "https://gitlab.acme.com/api/v3/user
+//	gitlab.ProfileURL = "https://gitlab.acme.com/api/v4/user
+//
+// NewCustomisedBaseURL is simpler to use for self-hosted instances.
 var (
 	AuthURL    = "https://gitlab.com/oauth/authorize"
 	TokenURL   = "https://gitlab.com/oauth/token"
-	ProfileURL = "https://gitlab.com/api/v3/user"
+	ProfileURL = "https://gitlab.com/api/v4/user"
+)
+
+// AccessLevel is the access level of a member of a group.
+type AccessLevel int
+
+// Access levels of the members of groups, see WithGroups.
+const (
+	GuestAccess      AccessLevel = 10
+	ReporterAccess   AccessLevel = 20
+	DeveloperAccess  AccessLevel = 30
+	MaintainerAccess AccessLevel = 40
+	OwnerAccess      AccessLevel = 50
 )
 
 // Provider is the implementation of `goth.Provider` for accessing Gitlab.
@@ -40,6 +55,12 @@ type Provider struct {
 	authURL      string
 	tokenURL     string
 	profileURL   string
+	apiURL       string
+
+	pkce           bool
+	fetchGroups    bool
+	minAccessLevel AccessLevel
+	allowedGroups  []string
 }
 
 // New creates a new Gitlab provider and sets up important connection details.
@@ -57,11 +78,43 @@ func NewCustomisedURL(clientKey, secret, callbackURL, authURL, tokenURL, profile
 		CallbackURL:  callbackURL,
 		providerName: "gitlab",
 		profileURL:   profileURL,
+		apiURL:       strings.TrimSuffix(profileURL, "/user"),
 	}
 	p.config = newConfig(p, authURL, tokenURL, scopes)
 	return p
 }
 
+// NewCustomisedBaseURL is similar to New(...) but connects to the GitLab instance at the
+// given base URL, which can have a relative URL root, e.g. "https://example.com/gitlab".
+func NewCustomisedBaseURL(clientKey, secret, callbackURL, baseURL string, scopes ...string) *Provider {
+	baseURL = strings.TrimSuffix(baseURL, "/")
+	return NewCustomisedURL(clientKey, secret, callbackURL,
+		baseURL+"/oauth/authorize",
+		baseURL+"/oauth/token",
+		baseURL+"/api/v4/user",
+		scopes...)
+}
+
+// WithPKCE makes the provider use PKCE, which is required when the secret of the
+// application is not confidential, e.g. for native apps.
+func (p *Provider) WithPKCE() *Provider {
+	p.pkce = true
+	return p
+}
+
+// WithGroups makes FetchUser get the groups in which the user has at least the given
+// access level, their full paths are set in the Groups of the user. It requires the
+// read_api scope.
+//
+// When group full paths are given, the provider is restricted to the members of these
+// groups, FetchUser returns a GroupError for other users.
+func (p *Provider) WithGroups(minAccessLevel AccessLevel, allowed ...string) *Provider {
+	p.fetchGroups = true
+	p.minAccessLevel = minAccessLevel
+	p.allowedGroups = append(p.allowedGroups, allowed...)
+	return p
+}
+
 // Name is the name used to retrieve this provider later.
 func (p *Provider) Name() string {
 	return p.providerName
@@ -81,8 +134,19 @@ func (p *Provider) Debug(debug bool) {}
 
 // BeginAuth asks Gitlab for an authentication end-point.
 func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	if !p.pkce {
+		return &Session{
+			AuthURL: p.config.AuthCodeURL(state),
+		}, nil
+	}
+
+	verifier, err := goth.GenerateCodeVerifier()
+	if err != nil {
+		return nil, err
+	}
 	return &Session{
-		AuthURL: p.config.AuthCodeURL(state),
+		AuthURL:      p.config.AuthCodeURL(state, goth.PKCEChallengeOptions(verifier)...),
+		CodeVerifier: verifier,
 	}, nil
 }
 
@@ -126,10 +190,65 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 	}
 
 	err = userFromReader(bytes.NewReader(bits), &user)
+	if err != nil || !p.fetchGroups {
+		return user, err
+	}
 
+	err = p.groups(&user)
 	return user, err
 }
 
+// groups sets the groups of the user and checks the user is a member of one of the
+// allowed groups. The groups are listed page by page.
+func (p *Provider) groups(user *goth.User) error {
+	next := fmt.Sprintf("%s/groups?min_access_level=%d&per_page=100", p.apiURL, p.minAccessLevel)
+	for next != "" {
+		req, err := http.NewRequest("GET", next, nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+user.AccessToken)
+		response, err := p.Client().Do(req)
+		if err != nil {
+			return err
+		}
+
+		groups := []struct {
+			FullPath string `json:"full_path"`
+		}{}
+		if response.StatusCode == http.StatusOK {
+			err = json.NewDecoder(response.Body).Decode(&groups)
+		} else {
+			err = fmt.Errorf("%s responded with a %d trying to fetch groups", p.providerName, response.StatusCode)
+		}
+		response.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		for _, group := range groups {
+			user.Groups = append(user.Groups, group.FullPath)
+		}
+
+		next = ""
+		if page := response.Header.Get("X-Next-Page"); page != "" {
+			next = fmt.Sprintf("%s/groups?min_access_level=%d&per_page=100&page=%s", p.apiURL, p.minAccessLevel, page)
+		}
+	}
+
+	if len(p.allowedGroups) == 0 {
+		return nil
+	}
+	for _, group := range user.Groups {
+		for _, allowed := range p.allowedGroups {
+			if group == allowed {
+				return nil
+			}
+		}
+	}
+	return GroupError{Username: user.NickName, Groups: p.allowedGroups}
+}
+
 func newConfig(provider *Provider, authURL, tokenURL string, scopes []string) *oauth2.Config {
 	c := &oauth2.Config{
 		ClientID:     provider.ClientKey,
@@ -1,6 +1,9 @@
 package gitlab_test
 
 import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"testing"
 
@@ -58,6 +61,53 @@ func Test_SessionFromJSON(t *testing.T) {
 	a.Equal(s.AccessToken, "1234567890")
 }
 
+func Test_SelfHostedWithPKCEAndGroups(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/gitlab/oauth/token":
+			a.NotEmpty(r.FormValue("code_verifier"))
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"access_token":"TOKEN","token_type":"Bearer","expires_in":7200,"refresh_token":"REFRESH"}`))
+		case "/gitlab/api/v4/user":
+			w.Write([]byte(`{"id":1,"username":"john_smith","name":"John Smith","email":"john@example.com"}`))
+		case "/gitlab/api/v4/groups":
+			a.Equal("Bearer TOKEN", r.Header.Get("Authorization"))
+			a.Equal("30", r.URL.Query().Get("min_access_level"))
+			if r.URL.Query().Get("page") == "" {
+				w.Header().Set("X-Next-Page", "2")
+				w.Write([]byte(`[{"id":1,"full_path":"acme"}]`))
+				return
+			}
+			w.Write([]byte(`[{"id":2,"full_path":"acme/platform"}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	p := gitlab.NewCustomisedBaseURL("key", "secret", "/foo", ts.URL+"/gitlab/").WithPKCE().WithGroups(gitlab.DeveloperAccess, "acme/platform")
+	session, err := p.BeginAuth("test_state")
+	a.NoError(err)
+	s := session.(*gitlab.Session)
+	a.Contains(s.AuthURL, ts.URL+"/gitlab/oauth/authorize")
+	a.Contains(s.AuthURL, "code_challenge_method=S256")
+
+	_, err = s.Authorize(p, url.Values{"code": {"code"}})
+	a.NoError(err)
+
+	user, err := p.FetchUser(s)
+	a.NoError(err)
+	a.Equal("john_smith", user.NickName)
+	a.Equal([]string{"acme", "acme/platform"}, user.Groups)
+
+	p = gitlab.NewCustomisedBaseURL("key", "secret", "/foo", ts.URL+"/gitlab").WithGroups(gitlab.DeveloperAccess, "other")
+	_, err = p.FetchUser(s)
+	a.Equal(gitlab.GroupError{Username: "john_smith", Groups: []string{"other"}}, err)
+}
+
 func provider() *gitlab.Provider {
 	return gitlab.New(os.Getenv("GITLAB_KEY"), os.Getenv("GITLAB_SECRET"), "/foo")
 }
@@ -7,11 +7,13 @@ import (
 	"time"
 
 	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
 )
 
 // Session stores data during the auth process with Gitlab.
 type Session struct {
 	AuthURL      string
+	CodeVerifier string
 	AccessToken  string
 	RefreshToken string
 	ExpiresAt    time.Time
@@ -30,7 +32,11 @@ func (s Session) GetAuthURL() (string, error) {
 // Authorize the session with Gitlab and return the access token to be stored for future use.
 func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string, error) {
 	p := provider.(*Provider)
-	token, err := p.config.Exchange(goth.ContextForClient(p.Client()), params.Get("code"))
+	opts := []oauth2.AuthCodeOption{}
+	if s.CodeVerifier != "" {
+		opts = append(opts, goth.PKCEVerifierOption(s.CodeVerifier))
+	}
+	token, err := p.config.Exchange(goth.ContextForClient(p.Client()), params.Get("code"), opts...)
 	if err != nil {
 		return "", err
 	}
@@ -10,11 +10,20 @@ import (
 	"net/url"
 	"strings"
 
+	"github.com/dgrijalva/jwt-go"
 	"github.com/markbates/goth"
 	"golang.org/x/oauth2"
 )
 
-const endpointProfile string = "https://www.googleapis.com/oauth2/v2/userinfo"
+const (
+	endpointProfile string = "https://www.googleapis.com/oauth2/v2/userinfo"
+	endpointGroups  string = "https://admin.googleapis.com/admin/directory/v1/groups"
+)
+
+// ScopeDirectoryGroupReadonly allows to read the groups of a Google Workspace domain with
+// the Directory API, it is required to look the groups of the user up with the token of
+// the user.
+const ScopeDirectoryGroupReadonly = "https://www.googleapis.com/auth/admin.directory.group.readonly"
 
 // New creates a new Google provider, and sets up important connection details.
 // You should always call `google.New` to get a new Provider. Never try to create
@@ -39,6 +48,10 @@ type Provider struct {
 	config          *oauth2.Config
 	authCodeOptions []oauth2.AuthCodeOption
 	providerName    string
+	hostedDomain    string
+	groups          bool
+	groupsRequired  bool
+	groupsToken     oauth2.TokenSource
 }
 
 // Name is the name used to retrieve this provider later.
@@ -86,6 +99,7 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 		Provider:     p.Name(),
 		RefreshToken: sess.RefreshToken,
 		ExpiresAt:    sess.ExpiresAt,
+		IDToken:      sess.IDToken,
 	}
 
 	if user.AccessToken == "" {
@@ -126,9 +140,75 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 		return user, err
 	}
 
+	if p.groups {
+		user.Groups, err = p.fetchGroups(sess.AccessToken, u.Email)
+		if err != nil {
+			if p.groupsRequired {
+				return user, err
+			}
+			// the user is still signed in, without groups
+			user.RawData["groups_error"] = err.Error()
+		}
+	}
+
 	return user, nil
 }
 
+// fetchGroups looks the groups of the user up with the Directory API, with the token
+// source given to WithGroups or else the access token of the user.
+func (p *Provider) fetchGroups(accessToken, email string) ([]string, error) {
+	if p.groupsToken != nil {
+		token, err := p.groupsToken.Token()
+		if err != nil {
+			return nil, err
+		}
+		accessToken = token.AccessToken
+	}
+
+	groups := []string{}
+	pageToken := ""
+	for {
+		v := url.Values{"userKey": {email}, "maxResults": {"200"}}
+		if pageToken != "" {
+			v.Set("pageToken", pageToken)
+		}
+		req, err := http.NewRequest("GET", endpointGroups+"?"+v.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Add("Authorization", "Bearer "+accessToken)
+
+		response, err := p.Client().Do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		if response.StatusCode != http.StatusOK {
+			response.Body.Close()
+			return nil, fmt.Errorf("%s responded with a %d trying to fetch user groups", p.providerName, response.StatusCode)
+		}
+
+		page := struct {
+			Groups []struct {
+				Email string `json:"email"`
+			} `json:"groups"`
+			NextPageToken string `json:"nextPageToken"`
+		}{}
+		err = json.NewDecoder(response.Body).Decode(&page)
+		response.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		for _, g := range page.Groups {
+			groups = append(groups, g.Email)
+		}
+		if page.NextPageToken == "" {
+			return groups, nil
+		}
+		pageToken = page.NextPageToken
+	}
+}
+
 func newConfig(provider *Provider, scopes []string) *oauth2.Config {
 	c := &oauth2.Config{
 		ClientID:     provider.ClientKey,
@@ -185,6 +265,64 @@ func (p *Provider) SetHostedDomain(hd string) {
 	p.authCodeOptions = append(p.authCodeOptions, oauth2.SetAuthURLParam("hd", hd))
 }
 
+// WithHostedDomain restricts the provider to the users of a Google Workspace domain. The
+// hd parameter is sent to Google, like SetHostedDomain does, and the hd claim of the
+// ID token is verified when the session is authorized, which fails for other users.
+// The ID token is only returned with the openid or email scopes.
+// See https://developers.google.com/identity/protocols/oauth2/openid-connect#hd-param
+func (p *Provider) WithHostedDomain(hd string) *Provider {
+	p.SetHostedDomain(hd)
+	p.hostedDomain = hd
+	return p
+}
+
+// verifyHostedDomain checks the hd claim of the ID token matches the hosted domain the
+// provider is restricted to. The ID token comes straight from the token endpoint of
+// Google over TLS, so its signature does not need to be checked.
+func (p *Provider) verifyHostedDomain(idToken string) error {
+	if idToken == "" {
+		return fmt.Errorf("%s did not return an ID token to verify the hosted domain", p.providerName)
+	}
+	claims := jwt.MapClaims{}
+	_, _, err := new(jwt.Parser).ParseUnverified(idToken, claims)
+	if err != nil {
+		return err
+	}
+	if hd, _ := claims["hd"].(string); !strings.EqualFold(hd, p.hostedDomain) {
+		return fmt.Errorf("%s user is not part of the %s hosted domain", p.providerName, p.hostedDomain)
+	}
+	return nil
+}
+
+// WithGroups makes FetchUser look the Google Workspace groups of the user up with the
+// Directory API, and set their email addresses as the Groups of the user. When they
+// cannot be looked up, the user has no Groups and the error is in the "groups_error" of
+// its RawData, unless RequireGroups is set.
+//
+// Without a token source the access token of the user is used, which requires the
+// ScopeDirectoryGroupReadonly scope and a user allowed to read groups, usually an
+// administrator. Otherwise pass the token source of a service account with domain-wide
+// delegation impersonating an administrator, e.g. from golang.org/x/oauth2/google
+// imported as googleoauth:
+//
+//	conf, _ := googleoauth.JWTConfigFromJSON(key, google.ScopeDirectoryGroupReadonly)
+//	conf.Subject = "admin@example.com"
+//	provider := google.New(clientKey, secret, callbackURL).WithGroups(conf.TokenSource(context.Background()))
+func (p *Provider) WithGroups(ts ...oauth2.TokenSource) *Provider {
+	p.groups = true
+	if len(ts) > 0 {
+		p.groupsToken = ts[0]
+	}
+	return p
+}
+
+// RequireGroups makes FetchUser fail when the groups of WithGroups cannot be looked up,
+// e.g. for the applications authorizing the users by their groups.
+func (p *Provider) RequireGroups() *Provider {
+	p.groupsRequired = true
+	return p
+}
+
 // SetLoginHint sets the login_hint parameter for the google OAuth call.
 // Use this to prompt the user to login with a specific account.
 // See https://developers.google.com/identity/protocols/oauth2/openid-connect#login-hint
@@ -1,13 +1,22 @@
 package google_test
 
 import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
 	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"testing"
 
+	"github.com/dgrijalva/jwt-go"
 	"github.com/markbates/goth"
 	"github.com/markbates/goth/providers/google"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
 )
 
 func Test_New(t *testing.T) {
@@ -111,6 +120,155 @@ func Test_SessionFromJSON(t *testing.T) {
 	a.Equal(session.AccessToken, "1234567890")
 }
 
+func Test_AuthorizeWithHostedDomain(t *testing.T) {
+	a := assert.New(t)
+
+	ts := tokenServer(t, jwt.MapClaims{"sub": "110169484474386276334", "email": "jane@example.com", "hd": "example.com"})
+	defer ts.Close()
+
+	originalEndpoint := google.Endpoint
+	google.Endpoint.TokenURL = ts.URL
+	defer func() { google.Endpoint = originalEndpoint }()
+
+	p := googleProvider().WithHostedDomain("example.com")
+	session, err := p.BeginAuth("test_state")
+	a.NoError(err)
+	s := session.(*google.Session)
+	a.Contains(s.AuthURL, "hd=example.com")
+
+	token, err := s.Authorize(p, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("1234567890", token)
+	a.NotEmpty(s.IDToken)
+}
+
+func Test_AuthorizeWithOtherHostedDomain(t *testing.T) {
+	a := assert.New(t)
+
+	ts := tokenServer(t, jwt.MapClaims{"sub": "110169484474386276334", "email": "jane@gmail.com"})
+	defer ts.Close()
+
+	originalEndpoint := google.Endpoint
+	google.Endpoint.TokenURL = ts.URL
+	defer func() { google.Endpoint = originalEndpoint }()
+
+	p := googleProvider().WithHostedDomain("example.com")
+	s := &google.Session{}
+	_, err := s.Authorize(p, url.Values{"code": {"code"}})
+	a.Error(err)
+	a.Empty(s.AccessToken)
+}
+
+func Test_FetchUserWithGroups(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/oauth2/v2/userinfo":
+			w.Write([]byte(`{"id":"110169484474386276334","email":"jane@example.com","name":"Jane Doe","hd":"example.com"}`))
+		case "/admin/directory/v1/groups":
+			a.Equal("Bearer service-account-token", r.Header.Get("Authorization"))
+			a.Equal("jane@example.com", r.URL.Query().Get("userKey"))
+			if r.URL.Query().Get("pageToken") == "" {
+				w.Write([]byte(`{"kind":"admin#directory#groups","groups":[{"id":"0184mhaj2thv9r6","email":"engineering@example.com","name":"Engineering"}],"nextPageToken":"next"}`))
+				return
+			}
+			w.Write([]byte(`{"kind":"admin#directory#groups","groups":[{"id":"03rdcrjn1hs8e2w","email":"admins@example.com","name":"Admins"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	p := googleProvider().WithGroups(oauth2.StaticTokenSource(&oauth2.Token{AccessToken: "service-account-token"}))
+	withMockServer(p, handler, func(p *google.Provider) {
+		user, err := p.FetchUser(&google.Session{AccessToken: "1234567890"})
+		a.NoError(err)
+		a.Equal("jane@example.com", user.Email)
+		a.Equal([]string{"engineering@example.com", "admins@example.com"}, user.Groups)
+	})
+}
+
+func Test_FetchUserWithGroupsError(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/oauth2/v2/userinfo" {
+			w.Write([]byte(`{"id":"110169484474386276334","email":"jane@example.com","name":"Jane Doe"}`))
+			return
+		}
+		w.WriteHeader(http.StatusForbidden)
+	})
+
+	// the user is still signed in, without groups
+	withMockServer(googleProvider().WithGroups(), handler, func(p *google.Provider) {
+		user, err := p.FetchUser(&google.Session{AccessToken: "1234567890"})
+		a.NoError(err)
+		a.Equal("jane@example.com", user.Email)
+		a.Nil(user.Groups)
+		a.Contains(user.RawData["groups_error"], "403")
+	})
+
+	withMockServer(googleProvider().WithGroups().RequireGroups(), handler, func(p *google.Provider) {
+		_, err := p.FetchUser(&google.Session{AccessToken: "1234567890"})
+		a.Error(err)
+	})
+}
+
+func Test_FetchUserWithoutGroups(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/oauth2/v2/userinfo", r.URL.Path)
+		w.Write([]byte(`{"id":"110169484474386276334","email":"jane@example.com","name":"Jane Doe"}`))
+	})
+
+	withMockServer(googleProvider(), handler, func(p *google.Provider) {
+		user, err := p.FetchUser(&google.Session{AccessToken: "1234567890"})
+		a.NoError(err)
+		a.Nil(user.Groups)
+	})
+}
+
+func withMockServer(p *google.Provider, handler http.Handler, fn func(p *google.Provider)) {
+	server := httptest.NewTLSServer(handler)
+	defer server.Close()
+
+	httpClient := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return net.Dial(network, server.Listener.Addr().String())
+			},
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		},
+	}
+
+	p.HTTPClient = httpClient
+
+	fn(p)
+}
+
+// tokenServer serves a token response with an ID token carrying the given claims.
+func tokenServer(t *testing.T, claims jwt.MapClaims) *httptest.Server {
+	idToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "1234567890",
+			"token_type":   "Bearer",
+			"expires_in":   3599,
+			"id_token":     idToken,
+		})
+	}))
+}
+
 func googleProvider() *google.Provider {
 	return google.New(os.Getenv("GOOGLE_KEY"), os.Getenv("GOOGEL_SECRET"), "/foo")
 }
@@ -0,0 +1,376 @@
+// Package govuk implements the OpenID Connect protocol for authenticating users through GOV.UK One Login.
+//
+// The app authenticates at the token endpoint with its client secret (client_secret_post),
+// or with a private_key_jwt assertion when PrivateKey is set. The requested levels of
+// authentication and identity confidence are set with VectorsOfTrust.
+//
+// When the core identity claim is requested, FetchUser verifies the signed identity with
+// IdentitySigningKey before trusting it.
+package govuk
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+)
+
+// These are the hosts of the GOV.UK One Login environments.
+const (
+	ProductionHost  = "https://oidc.account.gov.uk"
+	IntegrationHost = "https://oidc.integration.account.gov.uk"
+)
+
+// These are the issuers of the core identity claim of the GOV.UK One Login environments.
+const (
+	ProductionIdentityIssuer  = "https://identity.account.gov.uk/"
+	IntegrationIdentityIssuer = "https://identity.integration.account.gov.uk/"
+)
+
+const (
+	// ScopeOpenID is required to sign in with GOV.UK One Login.
+	ScopeOpenID = "openid"
+	// ScopeEmail grants access to the user's email address.
+	ScopeEmail = "email"
+	// ScopePhone grants access to the user's phone number.
+	ScopePhone = "phone"
+)
+
+// These are the claims that can be requested with Claims, they require an identity
+// confidence level in VectorsOfTrust, such as "P2.Cl.Cm".
+const (
+	ClaimCoreIdentity  = "https://vocab.account.gov.uk/v1/coreIdentityJWT"
+	ClaimAddress       = "https://vocab.account.gov.uk/v1/address"
+	ClaimPassport      = "https://vocab.account.gov.uk/v1/passport"
+	ClaimDrivingPermit = "https://vocab.account.gov.uk/v1/drivingPermit"
+	ClaimReturnCode    = "https://vocab.account.gov.uk/v1/returnCode"
+)
+
+// clientAssertionType is the type of the private_key_jwt client assertion.
+const clientAssertionType = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+// Provider is the implementation of `goth.Provider` for accessing GOV.UK One Login.
+type Provider struct {
+	ClientKey   string
+	Secret      string
+	CallbackURL string
+	// PrivateKey signs a private_key_jwt assertion instead of sending the Secret, when set.
+	PrivateKey *rsa.PrivateKey
+	// VectorsOfTrust are sent as vtr, "Cl.Cm" (medium level of authentication) by default.
+	VectorsOfTrust []string
+	// Claims are the identity claims requested for the userinfo endpoint.
+	Claims []string
+	// IdentitySigningKey is the public key that signs the core identity claim.
+	IdentitySigningKey *ecdsa.PublicKey
+	HTTPClient         *http.Client
+	config             *oauth2.Config
+	providerName       string
+	host               string
+	identityIssuer     string
+}
+
+// New creates a new GOV.UK One Login provider for the production environment and sets up
+// important connection details.
+// You should always call `govuk.New` to get a new provider.  Never try to
+// create one manually.
+func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	return NewCustomisedHost(clientKey, secret, callbackURL, ProductionHost, ProductionIdentityIssuer, scopes...)
+}
+
+// NewIntegration is similar to New(...) but uses the integration environment.
+func NewIntegration(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	return NewCustomisedHost(clientKey, secret, callbackURL, IntegrationHost, IntegrationIdentityIssuer, scopes...)
+}
+
+// NewCustomisedHost is similar to New(...) but can be used to set a custom host and
+// issuer of the core identity claim.
+func NewCustomisedHost(clientKey, secret, callbackURL, host, identityIssuer string, scopes ...string) *Provider {
+	p := &Provider{
+		ClientKey:      clientKey,
+		Secret:         secret,
+		CallbackURL:    callbackURL,
+		VectorsOfTrust: []string{"Cl.Cm"},
+		providerName:   "govuk",
+		host:           strings.TrimSuffix(host, "/"),
+		identityIssuer: identityIssuer,
+	}
+	p.config = newConfig(p, scopes)
+	return p
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns an HTTP client to be used in all fetch operations.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// Debug is a no-op for the govuk package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks GOV.UK One Login for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	nonce, err := goth.GenerateCodeVerifier()
+	if err != nil {
+		return nil, err
+	}
+	vtr, err := json.Marshal(p.VectorsOfTrust)
+	if err != nil {
+		return nil, err
+	}
+	opts := []oauth2.AuthCodeOption{
+		oauth2.SetAuthURLParam("nonce", nonce),
+		oauth2.SetAuthURLParam("vtr", string(vtr)),
+	}
+	if len(p.Claims) > 0 {
+		userinfo := map[string]interface{}{}
+		for _, claim := range p.Claims {
+			userinfo[claim] = nil
+		}
+		claims, err := json.Marshal(map[string]interface{}{"userinfo": userinfo})
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, oauth2.SetAuthURLParam("claims", string(claims)))
+	}
+	return &Session{
+		AuthURL: p.config.AuthCodeURL(state, opts...),
+		Nonce:   nonce,
+	}, nil
+}
+
+// FetchUser will go to GOV.UK One Login and access the attributes of the user.
+// When present, the verified core identity is available in RawData as "core_identity"
+// and its name is used as FirstName and LastName; the other identity claims are
+// available in RawData under their claim name.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken: sess.AccessToken,
+		Provider:    p.Name(),
+		ExpiresAt:   sess.ExpiresAt,
+		IDToken:     sess.IDToken,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	req, err := http.NewRequest("GET", p.host+"/userinfo", nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+
+	response, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+	}
+
+	bits, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return user, err
+	}
+
+	err = json.NewDecoder(bytes.NewReader(bits)).Decode(&user.RawData)
+	if err != nil {
+		return user, err
+	}
+
+	u := struct {
+		Sub          string `json:"sub"`
+		Email        string `json:"email"`
+		CoreIdentity string `json:"https://vocab.account.gov.uk/v1/coreIdentityJWT"`
+	}{}
+	err = json.NewDecoder(bytes.NewReader(bits)).Decode(&u)
+	if err != nil {
+		return user, err
+	}
+	user.UserID = u.Sub
+	user.Email = u.Email
+
+	if u.CoreIdentity != "" {
+		err = p.verifyCoreIdentity(u.CoreIdentity, &user)
+	}
+	return user, err
+}
+
+// verifyCoreIdentity checks the signature, issuer, audience, subject and level of
+// confidence of the core identity claim, and adds the identity to the user.
+func (p *Provider) verifyCoreIdentity(identity string, user *goth.User) error {
+	if p.IdentitySigningKey == nil {
+		return fmt.Errorf("%s cannot verify the core identity without IdentitySigningKey", p.providerName)
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(identity, claims, func(token *jwt.Token) (interface{}, error) {
+		if token.Method != jwt.SigningMethodES256 {
+			return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+		}
+		return p.IdentitySigningKey, nil
+	})
+	if err != nil {
+		return fmt.Errorf("%s core identity is invalid: %v", p.providerName, err)
+	}
+
+	if iss, _ := claims["iss"].(string); iss != p.identityIssuer {
+		return fmt.Errorf("%s core identity has issuer %q, expected %q", p.providerName, iss, p.identityIssuer)
+	}
+	if aud, _ := claims["aud"].(string); aud != p.ClientKey {
+		return fmt.Errorf("%s core identity was issued for %q", p.providerName, aud)
+	}
+	if sub, _ := claims["sub"].(string); sub != user.UserID {
+		return fmt.Errorf("%s core identity belongs to another user", p.providerName)
+	}
+	vot, _ := claims["vot"].(string)
+	if !p.requestedConfidence(vot) {
+		return fmt.Errorf("%s core identity has level of confidence %q, which was not requested", p.providerName, vot)
+	}
+
+	vc, _ := claims["vc"].(map[string]interface{})
+	user.RawData["core_identity"] = vc
+	user.FirstName, user.LastName = identityName(vc)
+	user.Name = strings.TrimSpace(user.FirstName + " " + user.LastName)
+	return nil
+}
+
+// requestedConfidence reports whether the level of confidence is part of one of the
+// requested vectors of trust, e.g. "P2" for "P2.Cl.Cm".
+func (p *Provider) requestedConfidence(vot string) bool {
+	if vot == "" {
+		return false
+	}
+	for _, vtr := range p.VectorsOfTrust {
+		for _, component := range strings.Split(vtr, ".") {
+			if component == vot {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// identityName reads the given and family names of the current name of the identity.
+func identityName(vc map[string]interface{}) (given, family string) {
+	subject, _ := vc["credentialSubject"].(map[string]interface{})
+	names, _ := subject["name"].([]interface{})
+	if len(names) == 0 {
+		return "", ""
+	}
+	name, _ := names[0].(map[string]interface{})
+	parts, _ := name["nameParts"].([]interface{})
+	for _, part := range parts {
+		part, _ := part.(map[string]interface{})
+		value, _ := part["value"].(string)
+		switch part["type"] {
+		case "GivenName":
+			given = strings.TrimSpace(given + " " + value)
+		case "FamilyName":
+			family = strings.TrimSpace(family + " " + value)
+		}
+	}
+	return given, family
+}
+
+// clientAssertion signs the private_key_jwt assertion that authenticates the app at the
+// token endpoint.
+func (p *Provider) clientAssertion() (string, error) {
+	jti, err := goth.GenerateCodeVerifier()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss": p.ClientKey,
+		"sub": p.ClientKey,
+		"aud": p.config.Endpoint.TokenURL,
+		"jti": jti,
+		"iat": now.Unix(),
+		"exp": now.Add(5 * time.Minute).Unix(),
+	})
+	return token.SignedString(p.PrivateKey)
+}
+
+// tokenOptions returns the options to authenticate the app at the token endpoint with
+// private_key_jwt, the client secret is sent by the config otherwise.
+func (p *Provider) tokenOptions() ([]oauth2.AuthCodeOption, error) {
+	if p.PrivateKey == nil {
+		return nil, nil
+	}
+	assertion, err := p.clientAssertion()
+	if err != nil {
+		return nil, err
+	}
+	return []oauth2.AuthCodeOption{
+		oauth2.SetAuthURLParam("client_assertion_type", clientAssertionType),
+		oauth2.SetAuthURLParam("client_assertion", assertion),
+	}, nil
+}
+
+// exchangeConfig returns the config used for the code exchange, which leaves out the
+// client secret when a private_key_jwt assertion is used.
+func (p *Provider) exchangeConfig() *oauth2.Config {
+	if p.PrivateKey == nil {
+		return p.config
+	}
+	c := *p.config
+	c.ClientSecret = ""
+	return &c
+}
+
+func newConfig(provider *Provider, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   provider.host + "/authorize",
+			TokenURL:  provider.host + "/token",
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+		Scopes: []string{},
+	}
+
+	if len(scopes) > 0 {
+		for _, scope := range scopes {
+			c.Scopes = append(c.Scopes, scope)
+		}
+	} else {
+		c.Scopes = []string{ScopeOpenID, ScopeEmail}
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is not provided by GOV.UK One Login
+func (p *Provider) RefreshTokenAvailable() bool {
+	return false
+}
+
+// RefreshToken refresh token is not provided by GOV.UK One Login
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	return nil, errors.New("Refresh token is not provided by GOV.UK One Login")
+}
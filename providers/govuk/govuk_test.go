@@ -0,0 +1,222 @@
+package govuk_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/govuk"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("GOVUK_KEY"))
+	a.Equal(p.Secret, os.Getenv("GOVUK_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	session, err := p.BeginAuth("test_state")
+	s := session.(*govuk.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://oidc.account.gov.uk/authorize")
+	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("GOVUK_KEY")))
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "scope=openid+email")
+	a.Contains(s.AuthURL, "nonce="+s.Nonce)
+	a.Contains(s.AuthURL, "vtr="+url.QueryEscape(`["Cl.Cm"]`))
+	a.NotContains(s.AuthURL, "claims=")
+}
+
+func Test_BeginAuthWithIdentity(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := govuk.NewIntegration(os.Getenv("GOVUK_KEY"), os.Getenv("GOVUK_SECRET"), "/foo")
+	p.VectorsOfTrust = []string{"P2.Cl.Cm"}
+	p.Claims = []string{govuk.ClaimCoreIdentity}
+	session, err := p.BeginAuth("test_state")
+	s := session.(*govuk.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://oidc.integration.account.gov.uk/authorize")
+	a.Contains(s.AuthURL, "vtr="+url.QueryEscape(`["P2.Cl.Cm"]`))
+	a.Contains(s.AuthURL, "claims="+url.QueryEscape(`{"userinfo":{"https://vocab.account.gov.uk/v1/coreIdentityJWT":null}}`))
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://oidc.account.gov.uk/authorize","AccessToken":"1234567890"}`)
+	a.NoError(err)
+
+	s := session.(*govuk.Session)
+	a.Equal(s.AuthURL, "https://oidc.account.gov.uk/authorize")
+	a.Equal(s.AccessToken, "1234567890")
+}
+
+func Test_AuthorizeWithSecret(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.NoError(r.ParseForm())
+		a.Equal("client-id", r.Form.Get("client_id"))
+		a.Equal("client-secret", r.Form.Get("client_secret"))
+		a.Empty(r.Form.Get("client_assertion"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"SlAV32hkKG","token_type":"Bearer","expires_in":180,"id_token":"id-token"}`))
+	}))
+	defer ts.Close()
+
+	p := govuk.NewCustomisedHost("client-id", "client-secret", "/foo", ts.URL, govuk.IntegrationIdentityIssuer)
+	s := &govuk.Session{}
+	token, err := s.Authorize(p, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("SlAV32hkKG", token)
+	a.Equal("id-token", s.IDToken)
+}
+
+func Test_AuthorizeWithPrivateKey(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	a.NoError(err)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.NoError(r.ParseForm())
+		a.Empty(r.Form.Get("client_secret"))
+		a.Equal("urn:ietf:params:oauth:client-assertion-type:jwt-bearer", r.Form.Get("client_assertion_type"))
+		assertion, err := jwt.Parse(r.Form.Get("client_assertion"), func(token *jwt.Token) (interface{}, error) {
+			return &key.PublicKey, nil
+		})
+		a.NoError(err)
+		a.Equal("client-id", assertion.Claims.(jwt.MapClaims)["iss"])
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"SlAV32hkKG","token_type":"Bearer","expires_in":180,"id_token":"id-token"}`))
+	}))
+	defer ts.Close()
+
+	p := govuk.NewCustomisedHost("client-id", "client-secret", "/foo", ts.URL, govuk.IntegrationIdentityIssuer)
+	p.PrivateKey = key
+	s := &govuk.Session{}
+	token, err := s.Authorize(p, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("SlAV32hkKG", token)
+}
+
+func Test_FetchUser(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/userinfo", r.URL.Path)
+		a.Equal("Bearer 1234567890", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"sub":"urn:fdc:gov.uk:2022:56P4CMsGh_02YOlWpd8PAOI-2sVlB2nsNU7mcLZYhYw=","email":"test@example.com","email_verified":true,"phone_number":"+441406946277","phone_number_verified":true}`))
+	}))
+	defer ts.Close()
+
+	p := govuk.NewCustomisedHost("client-id", "client-secret", "/foo", ts.URL, govuk.IntegrationIdentityIssuer)
+	user, err := p.FetchUser(&govuk.Session{AccessToken: "1234567890"})
+	a.NoError(err)
+	a.Equal("urn:fdc:gov.uk:2022:56P4CMsGh_02YOlWpd8PAOI-2sVlB2nsNU7mcLZYhYw=", user.UserID)
+	a.Equal("test@example.com", user.Email)
+	a.Equal("+441406946277", user.RawData["phone_number"])
+}
+
+func Test_FetchUserCoreIdentity(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	a.NoError(err)
+
+	sub := "urn:fdc:gov.uk:2022:56P4CMsGh_02YOlWpd8PAOI-2sVlB2nsNU7mcLZYhYw="
+	identity := func(claims jwt.MapClaims) string {
+		signed, err := jwt.NewWithClaims(jwt.SigningMethodES256, claims).SignedString(key)
+		a.NoError(err)
+		return signed
+	}
+	claims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss": govuk.IntegrationIdentityIssuer,
+			"aud": "client-id",
+			"sub": sub,
+			"vot": "P2",
+			"exp": time.Now().Add(time.Hour).Unix(),
+			"vc": map[string]interface{}{
+				"type": []string{"VerifiableCredential", "IdentityCheckCredential"},
+				"credentialSubject": map[string]interface{}{
+					"name": []interface{}{map[string]interface{}{"nameParts": []interface{}{
+						map[string]interface{}{"type": "GivenName", "value": "Kenneth"},
+						map[string]interface{}{"type": "FamilyName", "value": "Decerqueira"},
+					}}},
+					"birthDate": []interface{}{map[string]interface{}{"value": "1965-07-08"}},
+				},
+			},
+		}
+	}
+
+	var coreIdentity string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"sub":"` + sub + `","email":"test@example.com","https://vocab.account.gov.uk/v1/coreIdentityJWT":"` + coreIdentity + `"}`))
+	}))
+	defer ts.Close()
+
+	p := govuk.NewCustomisedHost("client-id", "client-secret", "/foo", ts.URL, govuk.IntegrationIdentityIssuer)
+	p.VectorsOfTrust = []string{"P2.Cl.Cm"}
+
+	coreIdentity = identity(claims())
+	_, err = p.FetchUser(&govuk.Session{AccessToken: "1234567890"})
+	a.Error(err, "the identity cannot be verified without a key")
+
+	p.IdentitySigningKey = &key.PublicKey
+	user, err := p.FetchUser(&govuk.Session{AccessToken: "1234567890"})
+	a.NoError(err)
+	a.Equal("Kenneth", user.FirstName)
+	a.Equal("Decerqueira", user.LastName)
+	a.Equal("Kenneth Decerqueira", user.Name)
+	a.NotNil(user.RawData["core_identity"])
+
+	for name, change := range map[string]func(jwt.MapClaims){
+		"issuer":   func(c jwt.MapClaims) { c["iss"] = govuk.ProductionIdentityIssuer },
+		"audience": func(c jwt.MapClaims) { c["aud"] = "another-client" },
+		"subject":  func(c jwt.MapClaims) { c["sub"] = "urn:fdc:gov.uk:2022:another" },
+		"vot":      func(c jwt.MapClaims) { c["vot"] = "P1" },
+		"expired":  func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Hour).Unix() },
+	} {
+		c := claims()
+		change(c)
+		coreIdentity = identity(c)
+		_, err = p.FetchUser(&govuk.Session{AccessToken: "1234567890"})
+		a.Error(err, name)
+	}
+}
+
+func provider() *govuk.Provider {
+	return govuk.New(os.Getenv("GOVUK_KEY"), os.Getenv("GOVUK_SECRET"), "/foo")
+}
@@ -0,0 +1,107 @@
+package gusto_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/gusto"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("GUSTO_KEY"))
+	a.Equal(p.Secret, os.Getenv("GUSTO_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+}
+
+func Test_NewDemo(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := gusto.NewDemo(os.Getenv("GUSTO_KEY"), os.Getenv("GUSTO_SECRET"), "/foo")
+	session, err := p.BeginAuth("test_state")
+	s := session.(*gusto.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://api.gusto-demo.com/oauth/authorize")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	session, err := p.BeginAuth("test_state")
+	s := session.(*gusto.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://api.gusto.com/oauth/authorize")
+	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("GUSTO_KEY")))
+	a.Contains(s.AuthURL, "state=test_state")
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://api.gusto.com/oauth/authorize","AccessToken":"1234567890"}`)
+	a.NoError(err)
+
+	s := session.(*gusto.Session)
+	a.Equal(s.AuthURL, "https://api.gusto.com/oauth/authorize")
+	a.Equal(s.AccessToken, "1234567890")
+}
+
+func Test_FetchUser(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/v1/me", r.URL.Path)
+		a.Equal("Bearer 1234567890", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"uuid":"1f7c8c6e-5b8e-4c3b-9a57-0b3a9f3b2d1e","email":"alex@example.com","roles":{"payroll_admin":{"companies":[{"uuid":"c44d66dc-c41b-4a60-9e25-5e93ff8583f2","name":"Acme"}]}}}`))
+	}))
+	defer ts.Close()
+
+	p := gusto.NewCustomisedHost(os.Getenv("GUSTO_KEY"), os.Getenv("GUSTO_SECRET"), "/foo", ts.URL)
+	user, err := p.FetchUser(&gusto.Session{AccessToken: "1234567890"})
+	a.NoError(err)
+	a.Equal("1f7c8c6e-5b8e-4c3b-9a57-0b3a9f3b2d1e", user.UserID)
+	a.Equal("alex@example.com", user.Email)
+	a.NotNil(user.RawData["roles"])
+}
+
+func Test_RefreshToken(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/oauth/token", r.URL.Path)
+		a.NoError(r.ParseForm())
+		a.Equal("old-refresh-token", r.Form.Get("refresh_token"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"new-access-token","token_type":"bearer","expires_in":7200,"refresh_token":"new-refresh-token"}`))
+	}))
+	defer ts.Close()
+
+	p := gusto.NewCustomisedHost(os.Getenv("GUSTO_KEY"), os.Getenv("GUSTO_SECRET"), "/foo", ts.URL)
+	token, err := p.RefreshToken("old-refresh-token")
+	a.NoError(err)
+	a.Equal("new-access-token", token.AccessToken)
+	a.Equal("new-refresh-token", token.RefreshToken)
+}
+
+func provider() *gusto.Provider {
+	return gusto.New(os.Getenv("GUSTO_KEY"), os.Getenv("GUSTO_SECRET"), "/foo")
+}
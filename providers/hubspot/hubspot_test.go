@@ -0,0 +1,80 @@
+package hubspot_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/hubspot"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("HUBSPOT_KEY"))
+	a.Equal(p.Secret, os.Getenv("HUBSPOT_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	session, err := p.BeginAuth("test_state")
+	s := session.(*hubspot.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "app.hubspot.com/oauth/authorize")
+	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("HUBSPOT_KEY")))
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "scope=oauth")
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://app.hubspot.com/oauth/authorize","AccessToken":"1234567890"}`)
+	a.NoError(err)
+
+	s := session.(*hubspot.Session)
+	a.Equal(s.AuthURL, "https://app.hubspot.com/oauth/authorize")
+	a.Equal(s.AccessToken, "1234567890")
+}
+
+func Test_FetchUser(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/1234567890", r.URL.Path)
+		w.Write([]byte(`{"token":"1234567890","user":"test@hubspot.com","hub_domain":"demo.hubapi.com","scopes":["oauth"],"hub_id":62515,"app_id":456,"expires_in":21588,"user_id":123,"token_type":"access"}`))
+	}))
+	defer ts.Close()
+
+	originalTokenInfoURL := hubspot.TokenInfoURL
+	hubspot.TokenInfoURL = ts.URL
+	defer func() { hubspot.TokenInfoURL = originalTokenInfoURL }()
+
+	user, err := provider().FetchUser(&hubspot.Session{AccessToken: "1234567890"})
+	a.NoError(err)
+	a.Equal("123", user.UserID)
+	a.Equal("test@hubspot.com", user.Email)
+	a.Equal("62515", user.RawData["portal_id"])
+	a.Equal(float64(62515), user.RawData["hub_id"])
+}
+
+func provider() *hubspot.Provider {
+	return hubspot.New(os.Getenv("HUBSPOT_KEY"), os.Getenv("HUBSPOT_SECRET"), "/foo")
+}
@@ -0,0 +1,241 @@
+// Package idme implements the OAuth2 protocol for authenticating users through ID.me.
+//
+// Besides signing in, ID.me verifies that users belong to groups such as the military,
+// students or first responders, which is typically used to gate discounts. Request the
+// scope of each group to verify, the groups the user was verified for are available in
+// RawData under "verified_groups".
+package idme
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+)
+
+// These are the hosts of the ID.me environments.
+const (
+	ProductionHost = "https://api.id.me"
+	SandboxHost    = "https://api.idmelabs.com"
+)
+
+const (
+	// ScopeLogin only signs the user in, without any verification.
+	ScopeLogin = "login"
+	// ScopeOpenID signs the user in with OpenID Connect.
+	ScopeOpenID = "openid"
+	// ScopeMilitary verifies that the user is a member of the military community.
+	ScopeMilitary = "military"
+	// ScopeStudent verifies that the user is a student.
+	ScopeStudent = "student"
+	// ScopeResponder verifies that the user is a first responder.
+	ScopeResponder = "responder"
+	// ScopeTeacher verifies that the user is a teacher.
+	ScopeTeacher = "teacher"
+	// ScopeNurse verifies that the user is a nurse.
+	ScopeNurse = "nurse"
+	// ScopeGovernment verifies that the user is a government employee.
+	ScopeGovernment = "government"
+)
+
+// Provider is the implementation of `goth.Provider` for accessing ID.me.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	HTTPClient   *http.Client
+	config       *oauth2.Config
+	providerName string
+	host         string
+}
+
+// New creates a new ID.me provider for the production environment and sets up
+// important connection details.
+// You should always call `idme.New` to get a new provider.  Never try to
+// create one manually.
+func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	return NewCustomisedHost(clientKey, secret, callbackURL, ProductionHost, scopes...)
+}
+
+// NewSandbox is similar to New(...) but uses the sandbox environment.
+func NewSandbox(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	return NewCustomisedHost(clientKey, secret, callbackURL, SandboxHost, scopes...)
+}
+
+// NewCustomisedHost is similar to New(...) but can be used to set a custom host.
+func NewCustomisedHost(clientKey, secret, callbackURL, host string, scopes ...string) *Provider {
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "idme",
+		host:         strings.TrimSuffix(host, "/"),
+	}
+	p.config = newConfig(p, scopes)
+	return p
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns an HTTP client to be used in all fetch operations.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// Debug is a no-op for the idme package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks ID.me for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	return &Session{
+		AuthURL: p.config.AuthCodeURL(state),
+	}, nil
+}
+
+// FetchUser will go to ID.me and access the attributes of the user.
+// Every attribute is available in RawData under its handle (e.g. "zip"), the
+// verification status of each group under "status" and the groups the user was
+// verified for under "verified_groups".
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	req, err := http.NewRequest("GET", p.host+"/api/public/v3/attributes.json", nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+
+	response, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+	}
+
+	bits, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return user, err
+	}
+
+	err = userFromReader(bytes.NewReader(bits), &user)
+	return user, err
+}
+
+// userFromReader flattens the attribute payload of ID.me, which is a list of
+// attributes and a list of group statuses, into the user.
+func userFromReader(r io.Reader, user *goth.User) error {
+	u := struct {
+		Attributes []struct {
+			Handle string      `json:"handle"`
+			Value  interface{} `json:"value"`
+		} `json:"attributes"`
+		Status []struct {
+			Group     string   `json:"group"`
+			Subgroups []string `json:"subgroups"`
+			Verified  bool     `json:"verified"`
+		} `json:"status"`
+	}{}
+	err := json.NewDecoder(r).Decode(&u)
+	if err != nil {
+		return err
+	}
+
+	user.RawData = map[string]interface{}{}
+	for _, a := range u.Attributes {
+		user.RawData[a.Handle] = a.Value
+	}
+
+	status := make([]interface{}, 0, len(u.Status))
+	groups := []string{}
+	for _, s := range u.Status {
+		status = append(status, map[string]interface{}{
+			"group":     s.Group,
+			"subgroups": s.Subgroups,
+			"verified":  s.Verified,
+		})
+		if s.Verified {
+			groups = append(groups, s.Group)
+		}
+	}
+	user.RawData["status"] = status
+	user.RawData["verified_groups"] = groups
+
+	str := func(handle string) string {
+		s, _ := user.RawData[handle].(string)
+		return s
+	}
+	user.UserID = str("uuid")
+	user.Email = str("email")
+	user.FirstName = str("fname")
+	user.LastName = str("lname")
+	user.Name = strings.TrimSpace(user.FirstName + " " + user.LastName)
+	user.Location = str("zip")
+	return nil
+}
+
+func newConfig(provider *Provider, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   provider.host + "/oauth/authorize",
+			TokenURL:  provider.host + "/oauth/token",
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+		Scopes: []string{},
+	}
+
+	if len(scopes) > 0 {
+		for _, scope := range scopes {
+			c.Scopes = append(c.Scopes, scope)
+		}
+	} else {
+		c.Scopes = []string{ScopeLogin}
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	token := &oauth2.Token{RefreshToken: refreshToken}
+	ts := p.config.TokenSource(goth.ContextForClient(p.Client()), token)
+	newToken, err := ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	return newToken, err
+}
@@ -0,0 +1,111 @@
+package idme_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/idme"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("IDME_KEY"))
+	a.Equal(p.Secret, os.Getenv("IDME_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+}
+
+func Test_NewSandbox(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := idme.NewSandbox(os.Getenv("IDME_KEY"), os.Getenv("IDME_SECRET"), "/foo")
+	session, err := p.BeginAuth("test_state")
+	s := session.(*idme.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://api.idmelabs.com/oauth/authorize")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := idme.New(os.Getenv("IDME_KEY"), os.Getenv("IDME_SECRET"), "/foo", idme.ScopeMilitary)
+	session, err := p.BeginAuth("test_state")
+	s := session.(*idme.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://api.id.me/oauth/authorize")
+	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("IDME_KEY")))
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "scope=military")
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://api.id.me/oauth/authorize","AccessToken":"1234567890"}`)
+	a.NoError(err)
+
+	s := session.(*idme.Session)
+	a.Equal(s.AuthURL, "https://api.id.me/oauth/authorize")
+	a.Equal(s.AccessToken, "1234567890")
+}
+
+func Test_FetchUser(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/api/public/v3/attributes.json", r.URL.Path)
+		a.Equal("Bearer 1234567890", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"attributes":[{"handle":"fname","name":"First Name","value":"Jane"},{"handle":"lname","name":"Last Name","value":"Doe"},{"handle":"email","name":"Email","value":"jane@example.com"},{"handle":"zip","name":"Zip Code","value":"22102"},{"handle":"uuid","name":"Unique Identifier","value":"0a1b2c3d4e5f"}],"status":[{"group":"military","subgroups":["Veteran"],"verified":true},{"group":"student","subgroups":[],"verified":false}]}`))
+	}))
+	defer ts.Close()
+
+	p := idme.NewCustomisedHost(os.Getenv("IDME_KEY"), os.Getenv("IDME_SECRET"), "/foo", ts.URL)
+	user, err := p.FetchUser(&idme.Session{AccessToken: "1234567890"})
+	a.NoError(err)
+	a.Equal("0a1b2c3d4e5f", user.UserID)
+	a.Equal("Jane Doe", user.Name)
+	a.Equal("jane@example.com", user.Email)
+	a.Equal("22102", user.RawData["zip"])
+	a.Equal([]string{"military"}, user.RawData["verified_groups"])
+	a.Len(user.RawData["status"], 2)
+}
+
+func Test_RefreshToken(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/oauth/token", r.URL.Path)
+		a.NoError(r.ParseForm())
+		a.Equal("old-refresh-token", r.Form.Get("refresh_token"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"new-access-token","token_type":"bearer","expires_in":300,"refresh_token":"new-refresh-token"}`))
+	}))
+	defer ts.Close()
+
+	p := idme.NewCustomisedHost(os.Getenv("IDME_KEY"), os.Getenv("IDME_SECRET"), "/foo", ts.URL)
+	token, err := p.RefreshToken("old-refresh-token")
+	a.NoError(err)
+	a.Equal("new-access-token", token.AccessToken)
+	a.Equal("new-refresh-token", token.RefreshToken)
+}
+
+func provider() *idme.Provider {
+	return idme.New(os.Getenv("IDME_KEY"), os.Getenv("IDME_SECRET"), "/foo")
+}
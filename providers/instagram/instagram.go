@@ -1,5 +1,9 @@
 // Package instagram implements the OAuth2 protocol for authenticating users through Instagram.
 // This package can be used as a reference implementation of an OAuth2 provider for Goth.
+//
+// It uses the Instagram API with Instagram Login, for business and creator accounts, the
+// Instagram Basic Display API is no longer available. Business accounts linked to a
+// Facebook Page can also sign in through the facebook package with the instagram_basic scope.
 package instagram
 
 import (
@@ -11,17 +15,33 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strings"
+	"time"
 
 	"github.com/markbates/goth"
 	"golang.org/x/oauth2"
 )
 
 var (
-	authURL         = "https://api.instagram.com/oauth/authorize/"
+	authURL         = "https://www.instagram.com/oauth/authorize"
 	tokenURL        = "https://api.instagram.com/oauth/access_token"
-	endPointProfile = "https://api.instagram.com/v1/users/self/"
+	longLivedURL    = "https://graph.instagram.com/access_token"
+	refreshURL      = "https://graph.instagram.com/refresh_access_token"
+	endPointProfile = "https://graph.instagram.com/me"
 )
 
+// Scopes of the Instagram API with Instagram Login
+const (
+	ScopeBusinessBasic          = "instagram_business_basic"
+	ScopeBusinessContentPublish = "instagram_business_content_publish"
+	ScopeBusinessManageComments = "instagram_business_manage_comments"
+	ScopeBusinessManageMessages = "instagram_business_manage_messages"
+	ScopeBusinessManageInsights = "instagram_business_manage_insights"
+)
+
+// profileFields are the fields of the user requested from the me endpoint.
+const profileFields = "user_id,username,name,account_type,profile_picture_url,followers_count,follows_count,media_count"
+
 // New creates a new Instagram provider, and sets up important connection details.
 // You should always call `instagram.New` to get a new Provider. Never try to craete
 // one manually.
@@ -61,12 +81,13 @@ func (p *Provider) Client() *http.Client {
 	return goth.HTTPClientWithFallBack(p.HTTPClient)
 }
 
-//Debug TODO
+// Debug TODO
 func (p *Provider) Debug(debug bool) {}
 
 // BeginAuth asks Instagram for an authentication end-point.
 func (p *Provider) BeginAuth(state string) (goth.Session, error) {
-	url := p.config.AuthCodeURL(state)
+	// Instagram requires comma separated scopes
+	url := p.config.AuthCodeURL(state, oauth2.SetAuthURLParam("scope", strings.Join(p.config.Scopes, ",")))
 	session := &Session{
 		AuthURL: url,
 	}
@@ -77,8 +98,10 @@ func (p *Provider) BeginAuth(state string) (goth.Session, error) {
 func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 	sess := session.(*Session)
 	user := goth.User{
-		AccessToken: sess.AccessToken,
-		Provider:    p.Name(),
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.AccessToken,
+		ExpiresAt:    sess.ExpiresAt,
 	}
 
 	if user.AccessToken == "" {
@@ -86,7 +109,7 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
 	}
 
-	response, err := p.Client().Get(endPointProfile + "?access_token=" + url.QueryEscape(sess.AccessToken))
+	response, err := p.Client().Get(endPointProfile + "?fields=" + profileFields + "&access_token=" + url.QueryEscape(sess.AccessToken))
 
 	if err != nil {
 		return user, err
@@ -111,32 +134,102 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 
 func userFromReader(reader io.Reader, user *goth.User) error {
 	u := struct {
-		Data struct {
-			ID             string `json:"id"`
-			UserName       string `json:"username"`
-			FullName       string `json:"full_name"`
-			ProfilePicture string `json:"profile_picture"`
-			Bio            string `json:"bio"`
-			Website        string `json:"website"`
-			Counts         struct {
-				Media      int `json:"media"`
-				Follows    int `json:"follows"`
-				FollowedBy int `json:"followed_by"`
-			} `json:"counts"`
-		} `json:"data"`
+		UserID            string `json:"user_id"`
+		UserName          string `json:"username"`
+		Name              string `json:"name"`
+		ProfilePictureURL string `json:"profile_picture_url"`
 	}{}
 	err := json.NewDecoder(reader).Decode(&u)
 	if err != nil {
 		return err
 	}
-	user.UserID = u.Data.ID
-	user.Name = u.Data.FullName
-	user.NickName = u.Data.UserName
-	user.AvatarURL = u.Data.ProfilePicture
-	user.Description = u.Data.Bio
+	user.UserID = u.UserID
+	user.Name = u.Name
+	user.NickName = u.UserName
+	user.AvatarURL = u.ProfilePictureURL
 	return err
 }
 
+// exchange gets a short-lived access token for the code, Instagram may return it in a
+// data list.
+func (p *Provider) exchange(code string) (string, error) {
+	response, err := p.Client().PostForm(tokenURL, url.Values{
+		"client_id":     {p.ClientKey},
+		"client_secret": {p.Secret},
+		"grant_type":    {"authorization_code"},
+		"redirect_uri":  {p.CallbackURL},
+		"code":          {code},
+	})
+	if err != nil {
+		return "", err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s responded with a %d trying to get an access token", p.providerName, response.StatusCode)
+	}
+
+	type token struct {
+		AccessToken string `json:"access_token"`
+	}
+	t := struct {
+		token
+		Data []token `json:"data"`
+	}{}
+	err = json.NewDecoder(response.Body).Decode(&t)
+	if err != nil {
+		return "", err
+	}
+	if t.AccessToken == "" && len(t.Data) > 0 {
+		t.AccessToken = t.Data[0].AccessToken
+	}
+	if t.AccessToken == "" {
+		return "", errors.New("Invalid token received from provider")
+	}
+	return t.AccessToken, nil
+}
+
+// longLivedToken gets a long-lived access token, valid for 60 days, from the token.
+// The grant type is ig_exchange_token for a short-lived token, or ig_refresh_token to
+// refresh a long-lived token.
+func (p *Provider) longLivedToken(endpoint, grantType, accessToken string) (*oauth2.Token, error) {
+	params := url.Values{
+		"grant_type":   {grantType},
+		"access_token": {accessToken},
+	}
+	if grantType == "ig_exchange_token" {
+		params.Set("client_secret", p.Secret)
+	}
+	response, err := p.Client().Get(endpoint + "?" + params.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s responded with a %d trying to get a long-lived token", p.providerName, response.StatusCode)
+	}
+
+	t := struct {
+		AccessToken string `json:"access_token"`
+		TokenType   string `json:"token_type"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}{}
+	err = json.NewDecoder(response.Body).Decode(&t)
+	if err != nil {
+		return nil, err
+	}
+	if t.AccessToken == "" {
+		return nil, errors.New("Invalid token received from provider")
+	}
+	return &oauth2.Token{
+		AccessToken:  t.AccessToken,
+		TokenType:    t.TokenType,
+		RefreshToken: t.AccessToken,
+		Expiry:       time.Now().Add(time.Duration(t.ExpiresIn) * time.Second),
+	}, nil
+}
+
 func newConfig(p *Provider, scopes []string) *oauth2.Config {
 	c := &oauth2.Config{
 		ClientID:     p.ClientKey,
@@ -147,11 +240,11 @@ func newConfig(p *Provider, scopes []string) *oauth2.Config {
 			TokenURL: tokenURL,
 		},
 		Scopes: []string{
-			"basic",
+			ScopeBusinessBasic,
 		},
 	}
 	defaultScopes := map[string]struct{}{
-		"basic": {},
+		ScopeBusinessBasic: {},
 	}
 
 	for _, scope := range scopes {
@@ -163,12 +256,13 @@ func newConfig(p *Provider, scopes []string) *oauth2.Config {
 	return c
 }
 
-//RefreshToken refresh token is not provided by instagram
+// RefreshToken refreshes the long-lived access token, which is also used as the refresh
+// token. The token must be at least 24 hours old and not expired.
 func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
-	return nil, errors.New("Refresh token is not provided by instagram")
+	return p.longLivedToken(refreshURL, "ig_refresh_token", refreshToken)
 }
 
-//RefreshTokenAvailable refresh token is not provided by instagram
+// RefreshTokenAvailable refresh token is provided by auth provider or not
 func (p *Provider) RefreshTokenAvailable() bool {
-	return false
+	return true
 }
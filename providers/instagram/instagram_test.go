@@ -1,9 +1,16 @@
 package instagram_test
 
 import (
+	"context"
+	"crypto/tls"
 	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/markbates/goth"
 	"github.com/markbates/goth/providers/instagram"
@@ -32,10 +39,10 @@ func Test_BeginAuth(t *testing.T) {
 	session, err := provider.BeginAuth("test_state")
 	s := session.(*instagram.Session)
 	a.NoError(err)
-	a.Contains(s.AuthURL, "api.instagram.com/oauth/authorize/")
+	a.Contains(s.AuthURL, "www.instagram.com/oauth/authorize")
 	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("INSTAGRAM_KEY")))
 	a.Contains(s.AuthURL, "state=test_state")
-	a.Contains(s.AuthURL, "scope=basic")
+	a.Contains(s.AuthURL, "scope=instagram_business_basic%2Cinstagram_business_manage_comments")
 }
 
 func Test_SessionFromJSON(t *testing.T) {
@@ -51,6 +58,71 @@ func Test_SessionFromJSON(t *testing.T) {
 	a.Equal(session.AccessToken, "1234567890")
 }
 
+func Test_AuthorizeAndFetchUser(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Host + r.URL.Path {
+		case "api.instagram.com/oauth/access_token":
+			a.Equal("code", r.FormValue("code"))
+			w.Write([]byte(`{"data":[{"access_token":"SHORT","user_id":"17841405793187218","permissions":"instagram_business_basic"}]}`))
+		case "graph.instagram.com/access_token":
+			a.Equal("ig_exchange_token", r.FormValue("grant_type"))
+			a.Equal("SHORT", r.FormValue("access_token"))
+			w.Write([]byte(`{"access_token":"LONG","token_type":"bearer","expires_in":5183944}`))
+		case "graph.instagram.com/refresh_access_token":
+			a.Equal("ig_refresh_token", r.FormValue("grant_type"))
+			a.Equal("LONG", r.FormValue("access_token"))
+			w.Write([]byte(`{"access_token":"REFRESHED","token_type":"bearer","expires_in":5183944}`))
+		case "graph.instagram.com/me":
+			a.Equal("LONG", r.FormValue("access_token"))
+			a.Contains(r.FormValue("fields"), "username")
+			w.Write([]byte(`{"user_id":"17841405793187218","username":"jayposiris","name":"Jay","account_type":"BUSINESS","profile_picture_url":"https://scontent.cdninstagram.com/p.jpg","followers_count":42}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	withMockServer(instagramProvider(), handler, func(p *instagram.Provider) {
+		s := &instagram.Session{}
+		_, err := s.Authorize(p, url.Values{"code": {"code"}})
+		a.NoError(err)
+		a.Equal("LONG", s.AccessToken)
+		a.True(s.ExpiresAt.After(time.Now().Add(59 * 24 * time.Hour)))
+
+		user, err := p.FetchUser(s)
+		a.NoError(err)
+		a.Equal("17841405793187218", user.UserID)
+		a.Equal("jayposiris", user.NickName)
+		a.Equal("Jay", user.Name)
+		a.Equal("BUSINESS", user.RawData["account_type"])
+
+		token, err := p.RefreshToken(user.RefreshToken)
+		a.NoError(err)
+		a.Equal("REFRESHED", token.AccessToken)
+		a.Equal("REFRESHED", token.RefreshToken)
+	})
+}
+
+func withMockServer(p *instagram.Provider, handler http.Handler, fn func(p *instagram.Provider)) {
+	server := httptest.NewTLSServer(handler)
+	defer server.Close()
+
+	p.HTTPClient = &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return net.Dial(network, server.Listener.Addr().String())
+			},
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		},
+	}
+
+	fn(p)
+}
+
 func instagramProvider() *instagram.Provider {
-	return instagram.New(os.Getenv("INSTAGRAM_KEY"), os.Getenv("INSTAGRAM_SECRET"), "/foo", "basic")
+	return instagram.New(os.Getenv("INSTAGRAM_KEY"), os.Getenv("INSTAGRAM_SECRET"), "/foo", instagram.ScopeBusinessBasic, instagram.ScopeBusinessManageComments)
 }
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"strings"
+	"time"
 
 	"github.com/markbates/goth"
 )
@@ -12,6 +13,7 @@ import (
 type Session struct {
 	AuthURL     string
 	AccessToken string
+	ExpiresAt   time.Time
 }
 
 // GetAuthURL will return the URL set by calling the `BeginAuth` function on the Instagram provider.
@@ -23,18 +25,21 @@ func (s Session) GetAuthURL() (string, error) {
 }
 
 // Authorize the session with Instagram and return the access token to be stored for future use.
+// The short-lived access token is exchanged for a long-lived one, valid for 60 days.
 func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string, error) {
 	p := provider.(*Provider)
-	token, err := p.config.Exchange(goth.ContextForClient(p.Client()), params.Get("code"))
+	shortLived, err := p.exchange(params.Get("code"))
 	if err != nil {
 		return "", err
 	}
 
-	if !token.Valid() {
-		return "", errors.New("Invalid token received from provider")
+	token, err := p.longLivedToken(longLivedURL, "ig_exchange_token", shortLived)
+	if err != nil {
+		return "", err
 	}
 
 	s.AccessToken = token.AccessToken
+	s.ExpiresAt = token.Expiry
 	return token.AccessToken, err
 }
 
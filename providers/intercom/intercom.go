@@ -69,7 +69,8 @@ func (p *Provider) BeginAuth(state string) (goth.Session, error) {
 	return session, nil
 }
 
-// FetchUser will fetch basic information about Intercom admin
+// FetchUser will fetch basic information about Intercom admin.
+// The id of the app (workspace) the admin authorized is available in RawData as "app_id".
 func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 	sess := session.(*Session)
 	user := goth.User{
@@ -129,6 +130,10 @@ func userFromReader(reader io.Reader, user *goth.User) error {
 		Avatar        struct {
 			URL string `json:"image_url"`
 		} `json:"avatar"`
+		App *struct {
+			IDCode string `json:"id_code"`
+			Name   string `json:"name"`
+		} `json:"app"`
 	}{}
 
 	err := json.NewDecoder(reader).Decode(&u)
@@ -136,6 +141,11 @@ func userFromReader(reader io.Reader, user *goth.User) error {
 		return err
 	}
 
+	if u.App != nil && u.App.IDCode != "" && user.RawData != nil {
+		user.RawData["app_id"] = u.App.IDCode
+		user.RawData["app_name"] = u.App.Name
+	}
+
 	user.Name = u.Name
 	user.FirstName, user.LastName = splitName(u.Name)
 	user.Email = u.Email
@@ -22,6 +22,12 @@ type fetchUserPayload struct {
 	Avatar        struct {
 		URL string `json:"image_url"`
 	} `json:"avatar"`
+	App *fetchUserApp `json:"app,omitempty"`
+}
+
+type fetchUserApp struct {
+	IDCode string `json:"id_code"`
+	Name   string `json:"name"`
 }
 
 func Test_New(t *testing.T) {
@@ -77,6 +83,7 @@ func Test_FetchUser(t *testing.T) {
 	u.Name = "Hoban Washburne"
 	u.EmailVerified = true
 	u.Avatar.URL = "http://avatarURL"
+	u.App = &fetchUserApp{IDCode: "ecahpwf5", Name: "Serenity"}
 
 	mockIntercomFetchUser(&u, func(ts *httptest.Server) {
 		provider := intercomProvider()
@@ -92,6 +99,8 @@ func Test_FetchUser(t *testing.T) {
 		a.Equal("Washburne", user.LastName)
 		a.Equal("http://avatarURL", user.AvatarURL)
 		a.Equal(true, user.RawData["email_verified"])
+		a.Equal("ecahpwf5", user.RawData["app_id"])
+		a.Equal("Serenity", user.RawData["app_name"])
 		a.Equal("token", user.AccessToken)
 	})
 }
@@ -120,6 +129,7 @@ func Test_FetchUnverifiedUser(t *testing.T) {
 		a.Equal("Washburne", user.LastName)
 		a.Equal("http://avatarURL", user.AvatarURL)
 		a.Equal(false, user.RawData["email_verified"])
+		a.NotContains(user.RawData, "app_id")
 		a.Equal("token", user.AccessToken)
 	})
 }
@@ -0,0 +1,263 @@
+// Package intuit implements the OAuth2 and OpenID Connect protocols for authenticating
+// users through Intuit (QuickBooks Online).
+//
+// The endpoints are read from Intuit's discovery documents, which differ between the
+// sandbox and production environments.
+package intuit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+)
+
+// These vars define the discovery documents of the Intuit environments.
+var (
+	ProductionDiscoveryURL = "https://developer.api.intuit.com/.well-known/openid_configuration"
+	SandboxDiscoveryURL    = "https://developer.api.intuit.com/.well-known/openid_sandbox_configuration"
+)
+
+const (
+	// ScopeAccounting grants access to the QuickBooks Online Accounting API.
+	ScopeAccounting = "com.intuit.quickbooks.accounting"
+	// ScopePayment grants access to the QuickBooks Payments API.
+	ScopePayment = "com.intuit.quickbooks.payment"
+	// ScopeOpenID is required to access the user info endpoint.
+	ScopeOpenID = "openid"
+	// ScopeProfile grants access to the user's name.
+	ScopeProfile = "profile"
+	// ScopeEmail grants access to the user's email address.
+	ScopeEmail = "email"
+	// ScopePhone grants access to the user's phone number.
+	ScopePhone = "phone"
+	// ScopeAddress grants access to the user's address.
+	ScopeAddress = "address"
+)
+
+// Discovery holds the parts of Intuit's discovery document used by the provider.
+type Discovery struct {
+	Issuer           string `json:"issuer"`
+	AuthEndpoint     string `json:"authorization_endpoint"`
+	TokenEndpoint    string `json:"token_endpoint"`
+	UserInfoEndpoint string `json:"userinfo_endpoint"`
+	RevokeEndpoint   string `json:"revocation_endpoint"`
+}
+
+// Provider is the implementation of `goth.Provider` for accessing Intuit.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	HTTPClient   *http.Client
+	Discovery    *Discovery
+	config       *oauth2.Config
+	providerName string
+}
+
+// New creates a new Intuit provider for the production environment and sets up
+// important connection details.
+// You should always call `intuit.New` to get a new provider.  Never try to
+// create one manually.
+func New(clientKey, secret, callbackURL string, scopes ...string) (*Provider, error) {
+	return NewCustomisedDiscoveryURL(clientKey, secret, callbackURL, ProductionDiscoveryURL, scopes...)
+}
+
+// NewSandbox is similar to New(...) but uses the sandbox environment.
+func NewSandbox(clientKey, secret, callbackURL string, scopes ...string) (*Provider, error) {
+	return NewCustomisedDiscoveryURL(clientKey, secret, callbackURL, SandboxDiscoveryURL, scopes...)
+}
+
+// NewCustomisedDiscoveryURL is similar to New(...) but reads the endpoints from the given discovery document.
+func NewCustomisedDiscoveryURL(clientKey, secret, callbackURL, discoveryURL string, scopes ...string) (*Provider, error) {
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "intuit",
+	}
+
+	discovery, err := p.discover(discoveryURL)
+	if err != nil {
+		return nil, err
+	}
+	p.Discovery = discovery
+
+	p.config = newConfig(p, scopes)
+	return p, nil
+}
+
+func (p *Provider) discover(discoveryURL string) (*Discovery, error) {
+	response, err := p.Client().Get(discoveryURL)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s responded with a %d trying to fetch the discovery document", p.providerName, response.StatusCode)
+	}
+
+	discovery := &Discovery{}
+	err = json.NewDecoder(response.Body).Decode(discovery)
+	if err != nil {
+		return nil, err
+	}
+	return discovery, nil
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns an HTTP client to be used in all fetch operations.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// Debug is a no-op for the intuit package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks Intuit for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	return &Session{
+		AuthURL: p.config.AuthCodeURL(state),
+	}, nil
+}
+
+// FetchUser will go to Intuit and access basic information about the user.
+// The QuickBooks company the user connected is available in RawData as "realmId".
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	req, err := http.NewRequest("GET", p.Discovery.UserInfoEndpoint, nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+	req.Header.Add("Accept", "application/json")
+
+	response, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+	}
+
+	bits, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return user, err
+	}
+
+	err = json.NewDecoder(bytes.NewReader(bits)).Decode(&user.RawData)
+	if err != nil {
+		return user, err
+	}
+	if sess.RealmID != "" {
+		user.RawData["realmId"] = sess.RealmID
+	}
+
+	err = userFromReader(bytes.NewReader(bits), &user)
+	return user, err
+}
+
+func userFromReader(r io.Reader, user *goth.User) error {
+	u := struct {
+		Sub        string `json:"sub"`
+		Email      string `json:"email"`
+		GivenName  string `json:"givenName"`
+		FamilyName string `json:"familyName"`
+	}{}
+	err := json.NewDecoder(r).Decode(&u)
+	if err != nil {
+		return err
+	}
+	user.UserID = u.Sub
+	user.Email = u.Email
+	user.FirstName = u.GivenName
+	user.LastName = u.FamilyName
+	user.Name = u.GivenName
+	if u.FamilyName != "" {
+		if user.Name != "" {
+			user.Name += " "
+		}
+		user.Name += u.FamilyName
+	}
+	return nil
+}
+
+func newConfig(provider *Provider, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   provider.Discovery.AuthEndpoint,
+			TokenURL:  provider.Discovery.TokenEndpoint,
+			AuthStyle: oauth2.AuthStyleInHeader,
+		},
+		Scopes: []string{},
+	}
+
+	if len(scopes) > 0 {
+		for _, scope := range scopes {
+			c.Scopes = append(c.Scopes, scope)
+		}
+	} else {
+		c.Scopes = []string{ScopeAccounting, ScopeOpenID, ScopeProfile, ScopeEmail}
+	}
+	return c
+}
+
+// refreshTokenExpiry returns when the refresh token of the token expires, as reported by
+// the x_refresh_token_expires_in field of the token response (about 100 days).
+func refreshTokenExpiry(token *oauth2.Token) time.Time {
+	if secs, ok := token.Extra("x_refresh_token_expires_in").(float64); ok && secs > 0 {
+		return time.Now().Add(time.Duration(secs) * time.Second)
+	}
+	return time.Time{}
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token.
+// Intuit rotates refresh tokens, so always store the refresh token of the returned
+// token; each one expires about 100 days after it was issued.
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	token := &oauth2.Token{RefreshToken: refreshToken}
+	ts := p.config.TokenSource(goth.ContextForClient(p.Client()), token)
+	newToken, err := ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	return newToken, err
+}
@@ -0,0 +1,145 @@
+package intuit_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/intuit"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	ts := intuitServer(a)
+	defer ts.Close()
+	p := provider(a, ts)
+
+	a.Equal(p.ClientKey, os.Getenv("INTUIT_KEY"))
+	a.Equal(p.Secret, os.Getenv("INTUIT_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+	a.Equal(ts.URL+"/v1/openid_connect/userinfo", p.Discovery.UserInfoEndpoint)
+}
+
+func Test_New_DiscoveryError(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	ts := intuitServer(a)
+	defer ts.Close()
+
+	_, err := intuit.NewCustomisedDiscoveryURL(os.Getenv("INTUIT_KEY"), os.Getenv("INTUIT_SECRET"), "/foo", ts.URL+"/missing")
+	a.Error(err)
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	ts := intuitServer(a)
+	defer ts.Close()
+	a.Implements((*goth.Provider)(nil), provider(a, ts))
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	ts := intuitServer(a)
+	defer ts.Close()
+	p := provider(a, ts)
+	session, err := p.BeginAuth("test_state")
+	s := session.(*intuit.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, ts.URL+"/connect/oauth2")
+	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("INTUIT_KEY")))
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "scope=com.intuit.quickbooks.accounting+openid+profile+email")
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	ts := intuitServer(a)
+	defer ts.Close()
+
+	p := provider(a, ts)
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://appcenter.intuit.com/connect/oauth2","AccessToken":"1234567890","RealmID":"4620816365"}`)
+	a.NoError(err)
+
+	s := session.(*intuit.Session)
+	a.Equal(s.AuthURL, "https://appcenter.intuit.com/connect/oauth2")
+	a.Equal(s.AccessToken, "1234567890")
+	a.Equal(s.RealmID, "4620816365")
+}
+
+func Test_Authorize(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	ts := intuitServer(a)
+	defer ts.Close()
+
+	s := &intuit.Session{}
+	token, err := s.Authorize(provider(a, ts), url.Values{"code": {"code"}, "realmId": {"4620816365"}})
+	a.NoError(err)
+	a.Equal("1234567890", token)
+	a.Equal("0987654321", s.RefreshToken)
+	a.Equal("4620816365", s.RealmID)
+	a.WithinDuration(time.Now().Add(8726400*time.Second), s.RefreshTokenExpiresAt, time.Minute)
+}
+
+func Test_FetchUser(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	ts := intuitServer(a)
+	defer ts.Close()
+
+	user, err := provider(a, ts).FetchUser(&intuit.Session{AccessToken: "1234567890", RealmID: "4620816365"})
+	a.NoError(err)
+	a.Equal("6a9a8b9a-6b8e-4d12-9d8a-3f5e1a2b3c4d", user.UserID)
+	a.Equal("john@example.com", user.Email)
+	a.Equal("John", user.FirstName)
+	a.Equal("Doe", user.LastName)
+	a.Equal("John Doe", user.Name)
+	a.Equal("4620816365", user.RawData["realmId"])
+}
+
+func Test_RefreshToken(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	ts := intuitServer(a)
+	defer ts.Close()
+
+	token, err := provider(a, ts).RefreshToken("0987654321")
+	a.NoError(err)
+	a.Equal("1234567890", token.AccessToken)
+	a.Equal("0987654321", token.RefreshToken)
+}
+
+func intuitServer(a *assert.Assertions) *httptest.Server {
+	var ts *httptest.Server
+	ts = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/.well-known/openid_configuration":
+			fmt.Fprintf(w, `{"issuer":"https://oauth.platform.intuit.com/op/v1","authorization_endpoint":"%[1]s/connect/oauth2","token_endpoint":"%[1]s/oauth2/v1/tokens/bearer","userinfo_endpoint":"%[1]s/v1/openid_connect/userinfo","revocation_endpoint":"%[1]s/oauth2/v1/tokens/revoke"}`, ts.URL)
+		case "/oauth2/v1/tokens/bearer":
+			w.Write([]byte(`{"access_token":"1234567890","token_type":"bearer","expires_in":3600,"refresh_token":"0987654321","x_refresh_token_expires_in":8726400}`))
+		case "/v1/openid_connect/userinfo":
+			a.Equal("Bearer 1234567890", r.Header.Get("Authorization"))
+			w.Write([]byte(`{"sub":"6a9a8b9a-6b8e-4d12-9d8a-3f5e1a2b3c4d","email":"john@example.com","emailVerified":true,"givenName":"John","familyName":"Doe"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return ts
+}
+
+func provider(a *assert.Assertions, ts *httptest.Server) *intuit.Provider {
+	p, err := intuit.NewCustomisedDiscoveryURL(os.Getenv("INTUIT_KEY"), os.Getenv("INTUIT_SECRET"), "/foo", ts.URL+"/.well-known/openid_configuration")
+	a.NoError(err)
+	return p
+}
@@ -0,0 +1,83 @@
+package kick_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/kick"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("KICK_KEY"))
+	a.Equal(p.Secret, os.Getenv("KICK_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	session, err := p.BeginAuth("test_state")
+	s := session.(*kick.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "id.kick.com/oauth/authorize")
+	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("KICK_KEY")))
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "scope=user%3Aread")
+	a.Contains(s.AuthURL, "code_challenge="+goth.S256CodeChallenge(s.CodeVerifier))
+	a.Contains(s.AuthURL, "code_challenge_method=S256")
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://id.kick.com/oauth/authorize","AccessToken":"1234567890"}`)
+	a.NoError(err)
+
+	s := session.(*kick.Session)
+	a.Equal(s.AuthURL, "https://id.kick.com/oauth/authorize")
+	a.Equal(s.AccessToken, "1234567890")
+}
+
+func Test_FetchUser(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("Bearer 1234567890", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"data":[{"user_id":123456,"name":"wash","email":"wash@serenity.now","profile_picture":"http://avatarURL"}],"message":"OK"}`))
+	}))
+	defer ts.Close()
+
+	originalProfileURL := kick.ProfileURL
+	kick.ProfileURL = ts.URL
+	defer func() { kick.ProfileURL = originalProfileURL }()
+
+	user, err := provider().FetchUser(&kick.Session{AccessToken: "1234567890"})
+	a.NoError(err)
+	a.Equal("123456", user.UserID)
+	a.Equal("wash", user.Name)
+	a.Equal("wash", user.NickName)
+	a.Equal("wash@serenity.now", user.Email)
+	a.Equal("http://avatarURL", user.AvatarURL)
+}
+
+func provider() *kick.Provider {
+	return kick.New(os.Getenv("KICK_KEY"), os.Getenv("KICK_SECRET"), "/foo")
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 
@@ -14,6 +15,7 @@ import (
 )
 
 // more details about linkedin fields:
+// Sign In with LinkedIn using OpenID Connect - https://learn.microsoft.com/en-us/linkedin/consumer/integrations/self-serve/sign-in-with-linkedin-v2
 // User Profile and Email Address - https://docs.microsoft.com/en-gb/linkedin/consumer/integrations/self-serve/sign-in-with-linkedin
 // User Avatar - https://docs.microsoft.com/en-gb/linkedin/shared/references/v2/digital-media-asset
 
@@ -21,6 +23,9 @@ const (
 	authURL  string = "https://www.linkedin.com/oauth/v2/authorization"
 	tokenURL string = "https://www.linkedin.com/oauth/v2/accessToken"
 
+	//userInfoEndpoint requires scopes "openid", "profile" and "email"
+	userInfoEndpoint string = "https://api.linkedin.com/v2/userinfo"
+
 	//userEndpoint requires scope "r_liteprofile"
 	userEndpoint string = "//api.linkedin.com/v2/me?projection=(id,firstName,lastName,profilePicture(displayImage~:playableStreams))"
 	//emailEndpoint requires scope "r_emailaddress"
@@ -30,6 +35,9 @@ const (
 // New creates a new linkedin provider, and sets up important connection details.
 // You should always call `linkedin.New` to get a new Provider. Never try to create
 // one manually.
+//
+// The user is fetched with Sign In with LinkedIn using OpenID Connect, the openid, profile
+// and email scopes are requested when no scopes are given.
 func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
 	p := &Provider{
 		ClientKey:    clientKey,
@@ -41,6 +49,23 @@ func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
 	return p
 }
 
+// NewLegacy is similar to New(...) but fetches the user with the profile and email
+// address endpoints, which need the r_liteprofile and r_emailaddress scopes, requested
+// when no scopes are given.
+//
+// Deprecated: LinkedIn does not give these scopes to new apps, use New.
+func NewLegacy(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "linkedin",
+		legacy:       true,
+	}
+	p.config = newConfig(p, scopes)
+	return p
+}
+
 // Provider is the implementation of `goth.Provider` for accessing Linkedin.
 type Provider struct {
 	ClientKey    string
@@ -49,6 +74,7 @@ type Provider struct {
 	HTTPClient   *http.Client
 	config       *oauth2.Config
 	providerName string
+	legacy       bool
 }
 
 // Name is the name used to retrieve this provider later.
@@ -92,6 +118,10 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
 	}
 
+	if !p.legacy {
+		return p.fetchUserInfo(user)
+	}
+
 	// create request for user r_liteprofile
 	req, err := http.NewRequest("GET", "", nil)
 	if err != nil {
@@ -152,6 +182,55 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 	return user, err
 }
 
+// fetchUserInfo gets the user from the OpenID Connect userinfo endpoint.
+func (p *Provider) fetchUserInfo(user goth.User) (goth.User, error) {
+	req, err := http.NewRequest("GET", userInfoEndpoint, nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Set("Authorization", "Bearer "+user.AccessToken)
+	resp, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, resp.StatusCode)
+	}
+
+	bits, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return user, err
+	}
+	err = json.Unmarshal(bits, &user.RawData)
+	if err != nil {
+		return user, err
+	}
+
+	u := struct {
+		Sub        string `json:"sub"`
+		Name       string `json:"name"`
+		GivenName  string `json:"given_name"`
+		FamilyName string `json:"family_name"`
+		Picture    string `json:"picture"`
+		Email      string `json:"email"`
+	}{}
+	err = json.Unmarshal(bits, &u)
+	if err != nil {
+		return user, err
+	}
+
+	user.UserID = u.Sub
+	user.Name = u.Name
+	user.FirstName = u.GivenName
+	user.LastName = u.FamilyName
+	user.NickName = u.GivenName
+	user.AvatarURL = u.Picture
+	user.Email = u.Email
+	return user, nil
+}
+
 func userFromReader(reader io.Reader, user *goth.User) error {
 	u := struct {
 		ID        string `json:"id"`
@@ -255,9 +334,11 @@ func newConfig(provider *Provider, scopes []string) *oauth2.Config {
 		Scopes: []string{},
 	}
 
-	if len(scopes) == 0 {
+	if len(scopes) == 0 && provider.legacy {
 		// add helper as new API requires the scope to be specified and these are the minimum to retrieve profile information and user's email address
 		scopes = append(scopes, "r_liteprofile", "r_emailaddress")
+	} else if len(scopes) == 0 {
+		scopes = append(scopes, "openid", "profile", "email")
 	}
 
 	for _, scope := range scopes {
@@ -1,7 +1,12 @@
 package linkedin_test
 
 import (
+	"context"
+	"crypto/tls"
 	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 
@@ -54,6 +59,60 @@ func Test_SessionFromJSON(t *testing.T) {
 	a.Equal(session.AccessToken, "1234567890")
 }
 
+func Test_FetchUser(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := linkedin.New("key", "secret", "/foo")
+	session, err := provider.BeginAuth("test_state")
+	a.NoError(err)
+	a.Contains(session.(*linkedin.Session).AuthURL, "scope=openid+profile+email&state")
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/v2/userinfo", r.URL.Path)
+		a.Equal("Bearer 1234567890", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"sub":"782bbtaQ","name":"John Doe","given_name":"John","family_name":"Doe","picture":"https://media.licdn-ei.com/dms/image/C5F03AQHqK8v7tB1HCQ/profile-displayphoto-shrink_100_100/0/","locale":"en-US","email":"doe@email.com","email_verified":true}`))
+	})
+
+	withMockServer(provider, handler, func(p *linkedin.Provider) {
+		user, err := p.FetchUser(&linkedin.Session{AccessToken: "1234567890"})
+		a.NoError(err)
+		a.Equal("782bbtaQ", user.UserID)
+		a.Equal("John Doe", user.Name)
+		a.Equal("John", user.FirstName)
+		a.Equal("Doe", user.LastName)
+		a.Equal("doe@email.com", user.Email)
+		a.Equal(true, user.RawData["email_verified"])
+	})
+}
+
+func Test_NewLegacy(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := linkedin.NewLegacy("key", "secret", "/foo").BeginAuth("test_state")
+	a.NoError(err)
+	a.Contains(session.(*linkedin.Session).AuthURL, "scope=r_liteprofile+r_emailaddress&state")
+}
+
+func withMockServer(p *linkedin.Provider, handler http.Handler, fn func(p *linkedin.Provider)) {
+	server := httptest.NewTLSServer(handler)
+	defer server.Close()
+
+	p.HTTPClient = &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return net.Dial(network, server.Listener.Addr().String())
+			},
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		},
+	}
+
+	fn(p)
+}
+
 func linkedinProvider() *linkedin.Provider {
 	return linkedin.New(os.Getenv("LINKEDIN_KEY"), os.Getenv("LINKEDIN_SECRET"), "/foo", "r_liteprofile", "r_emailaddress")
 }
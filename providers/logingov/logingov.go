@@ -0,0 +1,332 @@
+// Package logingov implements the OpenID Connect protocol for authenticating users through Login.gov.
+//
+// Every authentication uses PKCE. Apps registered with the private_key_jwt method also
+// sign a client assertion with their private key when exchanging the code, pass the key
+// to New for that. The level of assurance is requested with acr_values, see ACRValues.
+package logingov
+
+import (
+	"bytes"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/internal/jwks"
+	"golang.org/x/oauth2"
+)
+
+// These are the hosts of the Login.gov environments.
+const (
+	ProductionHost = "https://secure.login.gov"
+	SandboxHost    = "https://idp.int.identitysandbox.gov"
+)
+
+// These are the values that can be requested with acr_values.
+const (
+	// ACRAuthOnly only authenticates the user, no identity proofing is done.
+	ACRAuthOnly = "urn:acr.login.gov:auth-only"
+	// ACRVerified requires the identity of the user to be verified (IAL2).
+	ACRVerified = "urn:acr.login.gov:verified"
+	// ACRAAL2 requires multi-factor authentication that is remembered for at most 12 hours.
+	ACRAAL2 = "http://idmanagement.gov/ns/assurance/aal/2"
+	// ACRAAL2PhishingResistant requires a phishing resistant authentication method.
+	ACRAAL2PhishingResistant = "http://idmanagement.gov/ns/assurance/aal/2?phishing_resistant=true"
+	// ACRAAL2HSPD12 requires a PIV/CAC card.
+	ACRAAL2HSPD12 = "http://idmanagement.gov/ns/assurance/aal/2?hspd12=true"
+)
+
+const (
+	// ScopeOpenID is required to sign in with Login.gov.
+	ScopeOpenID = "openid"
+	// ScopeEmail grants access to the user's email address.
+	ScopeEmail = "email"
+	// ScopeAllEmails grants access to all the email addresses of the user.
+	ScopeAllEmails = "all_emails"
+	// ScopeProfileName grants access to the user's verified name, it requires ACRVerified.
+	ScopeProfileName = "profile:name"
+	// ScopeProfile grants access to the user's verified name and birthdate, it requires ACRVerified.
+	ScopeProfile = "profile"
+	// ScopeAddress grants access to the user's verified address, it requires ACRVerified.
+	ScopeAddress = "address"
+	// ScopePhone grants access to the user's verified phone number, it requires ACRVerified.
+	ScopePhone = "phone"
+	// ScopeSocialSecurityNumber grants access to the user's social security number, it requires ACRVerified.
+	ScopeSocialSecurityNumber = "social_security_number"
+	// ScopeVerifiedAt grants access to the time the identity of the user was last verified.
+	ScopeVerifiedAt = "profile:verified_at"
+	// ScopeX509 grants access to the details of the PIV/CAC card of the user.
+	ScopeX509 = "x509"
+)
+
+// clientAssertionType is the type of the private_key_jwt client assertion.
+const clientAssertionType = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+// Provider is the implementation of `goth.Provider` for accessing Login.gov.
+type Provider struct {
+	ClientKey   string
+	PrivateKey  *rsa.PrivateKey
+	CallbackURL string
+	// ACRValues are the requested authentication context classes, ACRAuthOnly by default.
+	ACRValues    []string
+	HTTPClient   *http.Client
+	config       *oauth2.Config
+	providerName string
+	host         string
+	keys         jwks.Cache
+}
+
+// New creates a new Login.gov provider for the production environment and sets up
+// important connection details.
+// You should always call `logingov.New` to get a new provider.  Never try to
+// create one manually.
+// The privateKey is used to sign the private_key_jwt client assertion, it can be nil
+// for apps registered to only use PKCE.
+func New(clientKey string, privateKey *rsa.PrivateKey, callbackURL string, scopes ...string) *Provider {
+	return NewCustomisedHost(clientKey, privateKey, callbackURL, ProductionHost, scopes...)
+}
+
+// NewSandbox is similar to New(...) but uses the sandbox environment.
+func NewSandbox(clientKey string, privateKey *rsa.PrivateKey, callbackURL string, scopes ...string) *Provider {
+	return NewCustomisedHost(clientKey, privateKey, callbackURL, SandboxHost, scopes...)
+}
+
+// NewCustomisedHost is similar to New(...) but can be used to set a custom host.
+func NewCustomisedHost(clientKey string, privateKey *rsa.PrivateKey, callbackURL, host string, scopes ...string) *Provider {
+	p := &Provider{
+		ClientKey:    clientKey,
+		PrivateKey:   privateKey,
+		CallbackURL:  callbackURL,
+		ACRValues:    []string{ACRAuthOnly},
+		providerName: "logingov",
+		host:         strings.TrimSuffix(host, "/"),
+	}
+	p.config = newConfig(p, scopes)
+	return p
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns an HTTP client to be used in all fetch operations.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// Debug is a no-op for the logingov package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks Login.gov for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	verifier, err := goth.GenerateCodeVerifier()
+	if err != nil {
+		return nil, err
+	}
+	// Login.gov requires a nonce of at least 22 characters, a code verifier is 43
+	nonce, err := goth.GenerateCodeVerifier()
+	if err != nil {
+		return nil, err
+	}
+	opts := append(goth.PKCEChallengeOptions(verifier),
+		oauth2.SetAuthURLParam("acr_values", strings.Join(p.ACRValues, " ")),
+		oauth2.SetAuthURLParam("nonce", nonce),
+		oauth2.SetAuthURLParam("prompt", "select_account"),
+	)
+	return &Session{
+		AuthURL:      p.config.AuthCodeURL(state, opts...),
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+	}, nil
+}
+
+// FetchUser will go to Login.gov and access the attributes of the user.
+// The verified attributes that were granted, such as "verified_at", "birthdate",
+// "address" and "social_security_number", and the reached "ial" and "aal" are available
+// in RawData.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken: sess.AccessToken,
+		Provider:    p.Name(),
+		ExpiresAt:   sess.ExpiresAt,
+		IDToken:     sess.IDToken,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	req, err := http.NewRequest("GET", p.host+"/api/openid_connect/userinfo", nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+
+	response, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+	}
+
+	bits, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return user, err
+	}
+
+	err = json.NewDecoder(bytes.NewReader(bits)).Decode(&user.RawData)
+	if err != nil {
+		return user, err
+	}
+
+	err = userFromReader(bytes.NewReader(bits), &user)
+	return user, err
+}
+
+func userFromReader(r io.Reader, user *goth.User) error {
+	u := struct {
+		Sub        string `json:"sub"`
+		Email      string `json:"email"`
+		GivenName  string `json:"given_name"`
+		FamilyName string `json:"family_name"`
+		Address    *struct {
+			Formatted string `json:"formatted"`
+		} `json:"address"`
+	}{}
+	err := json.NewDecoder(r).Decode(&u)
+	if err != nil {
+		return err
+	}
+	user.UserID = u.Sub
+	user.Email = u.Email
+	user.FirstName = u.GivenName
+	user.LastName = u.FamilyName
+	user.Name = strings.TrimSpace(u.GivenName + " " + u.FamilyName)
+	if u.Address != nil {
+		user.Location = u.Address.Formatted
+	}
+	return nil
+}
+
+// verify checks the signature, issuer, audience and expiry of an id_token issued by
+// Login.gov, and returns its claims.
+func (p *Provider) verify(raw string) (jwt.MapClaims, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(raw, claims, func(token *jwt.Token) (interface{}, error) {
+		if token.Method != jwt.SigningMethodRS256 {
+			return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+		}
+		kid, _ := token.Header["kid"].(string)
+		return p.publicKey(kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%s returned an invalid id_token: %v", p.providerName, err)
+	}
+	if !claims.VerifyIssuer(p.host+"/", true) {
+		return nil, fmt.Errorf("%s returned an id_token with an unexpected issuer", p.providerName)
+	}
+	if !claims.VerifyAudience(p.ClientKey, true) {
+		return nil, fmt.Errorf("%s returned an id_token for another audience", p.providerName)
+	}
+	if !claims.VerifyExpiresAt(time.Now().Unix(), true) {
+		return nil, fmt.Errorf("%s returned an expired id_token", p.providerName)
+	}
+	return claims, nil
+}
+
+// publicKey gets the key with the given id from the keys published by Login.gov, which
+// are cached.
+func (p *Provider) publicKey(kid string) (interface{}, error) {
+	key, err := p.keys.Key(p.Client(), p.host+"/api/openid_connect/certs", kid)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := key.(*rsa.PublicKey); !ok {
+		return nil, errors.New("unexpected key type")
+	}
+	return key, nil
+}
+
+// clientAssertion signs the private_key_jwt assertion that authenticates the app at the
+// token endpoint.
+func (p *Provider) clientAssertion() (string, error) {
+	jti, err := goth.GenerateCodeVerifier()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss": p.ClientKey,
+		"sub": p.ClientKey,
+		"aud": p.config.Endpoint.TokenURL,
+		"jti": jti,
+		"iat": now.Unix(),
+		"exp": now.Add(5 * time.Minute).Unix(),
+	})
+	return token.SignedString(p.PrivateKey)
+}
+
+// tokenOptions returns the options to authenticate the app at the token endpoint.
+func (p *Provider) tokenOptions() ([]oauth2.AuthCodeOption, error) {
+	if p.PrivateKey == nil {
+		return nil, nil
+	}
+	assertion, err := p.clientAssertion()
+	if err != nil {
+		return nil, err
+	}
+	return []oauth2.AuthCodeOption{
+		oauth2.SetAuthURLParam("client_assertion_type", clientAssertionType),
+		oauth2.SetAuthURLParam("client_assertion", assertion),
+	}, nil
+}
+
+func newConfig(provider *Provider, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:    provider.ClientKey,
+		RedirectURL: provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   provider.host + "/openid_connect/authorize",
+			TokenURL:  provider.host + "/api/openid_connect/token",
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+		Scopes: []string{},
+	}
+
+	if len(scopes) > 0 {
+		for _, scope := range scopes {
+			c.Scopes = append(c.Scopes, scope)
+		}
+	} else {
+		c.Scopes = []string{ScopeOpenID, ScopeEmail}
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is not provided by Login.gov
+func (p *Provider) RefreshTokenAvailable() bool {
+	return false
+}
+
+// RefreshToken refresh token is not provided by Login.gov
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	return nil, errors.New("Refresh token is not provided by Login.gov")
+}
@@ -0,0 +1,144 @@
+package mastodon
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/markbates/goth"
+)
+
+// App holds the client credentials Mastodon hands out when an application
+// registers itself on an instance.
+type App struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+}
+
+// AppStore persists the credentials of apps registered on Mastodon instances,
+// so an app is registered only once per instance, callback URL and scopes. The
+// credentials are stored under the key returned by AppKey. Get must return a nil
+// App (and a nil error) when nothing is stored for the key yet.
+type AppStore interface {
+	Get(key string) (*App, error)
+	Set(key string, app *App) error
+}
+
+// AppKey returns the key of the app registered on the instance with the callback
+// URL and the scopes, as an app cannot be used with other ones.
+func AppKey(instanceURL, callbackURL string, scopes ...string) string {
+	sorted := append([]string{}, scopes...)
+	sort.Strings(sorted)
+	return url.Values{
+		"instance":      {normalizeInstanceURL(instanceURL)},
+		"redirect_uris": {callbackURL},
+		"scopes":        {strings.Join(sorted, " ")},
+	}.Encode()
+}
+
+// MemoryAppStore is an in-memory AppStore. Credentials are lost on restart,
+// which means the app is registered again; use a persistent store in production.
+type MemoryAppStore struct {
+	mu   sync.RWMutex
+	apps map[string]*App
+}
+
+// NewMemoryAppStore returns an empty MemoryAppStore.
+func NewMemoryAppStore() *MemoryAppStore {
+	return &MemoryAppStore{apps: map[string]*App{}}
+}
+
+// Get returns the app stored under the key, if any.
+func (s *MemoryAppStore) Get(key string) (*App, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.apps[key], nil
+}
+
+// Set stores the app under the key.
+func (s *MemoryAppStore) Set(key string, app *App) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.apps[key] = app
+	return nil
+}
+
+// RegisterApp registers an application on the given instance via /api/v1/apps
+// and returns its client credentials.
+func RegisterApp(client *http.Client, instanceURL, appName, callbackURL string, scopes ...string) (*App, error) {
+	form := url.Values{
+		"client_name":   {appName},
+		"redirect_uris": {callbackURL},
+		"scopes":        {strings.Join(scopes, " ")},
+	}
+
+	res, err := goth.HTTPClientWithFallBack(client).PostForm(normalizeInstanceURL(instanceURL)+"api/v1/apps", form)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("mastodon responded with a %d trying to register the app on %s", res.StatusCode, instanceURL)
+	}
+
+	app := &App{}
+	if err := json.NewDecoder(res.Body).Decode(app); err != nil {
+		return nil, err
+	}
+	if app.ClientID == "" || app.ClientSecret == "" {
+		return nil, fmt.Errorf("mastodon did not return client credentials for %s", instanceURL)
+	}
+	return app, nil
+}
+
+// NewRegistered creates a provider for an arbitrary Mastodon instance. Since every
+// instance requires its own client, the app is registered on the instance the
+// first time it is seen with the callback URL and the scopes, and the credentials
+// are kept in the given store.
+// The provider is named after the instance host (e.g. "mastodon.social") so that
+// several instances can be used side by side.
+func NewRegistered(appName, callbackURL, instanceURL string, store AppStore, scopes ...string) (*Provider, error) {
+	return NewRegisteredWithClient(nil, appName, callbackURL, instanceURL, store, scopes...)
+}
+
+// NewRegisteredWithClient is similar to NewRegistered(...) but uses the given
+// HTTP client to register the app.
+func NewRegisteredWithClient(client *http.Client, appName, callbackURL, instanceURL string, store AppStore, scopes ...string) (*Provider, error) {
+	if len(scopes) == 0 {
+		scopes = []string{"read:accounts"}
+	}
+
+	key := AppKey(instanceURL, callbackURL, scopes...)
+	app, err := store.Get(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if app == nil {
+		app, err = RegisterApp(client, instanceURL, appName, callbackURL, scopes...)
+		if err != nil {
+			return nil, err
+		}
+		if err := store.Set(key, app); err != nil {
+			return nil, err
+		}
+	}
+
+	p := NewCustomisedURL(app.ClientID, app.ClientSecret, callbackURL, instanceURL, scopes...)
+	p.HTTPClient = client
+	if u, err := url.Parse(instanceURL); err == nil && u.Host != "" {
+		p.SetName(u.Host)
+	}
+	return p, nil
+}
+
+func normalizeInstanceURL(instanceURL string) string {
+	return fmt.Sprintf("%s/", strings.TrimSuffix(instanceURL, "/"))
+}
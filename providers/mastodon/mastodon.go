@@ -9,7 +9,6 @@ import (
 	"io"
 	"io/ioutil"
 	"net/http"
-	"strings"
 
 	"github.com/markbates/goth"
 	"golang.org/x/oauth2"
@@ -42,7 +41,7 @@ func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
 
 // NewCustomisedURL is similar to New(...) but can be used to set custom URLs to connect to
 func NewCustomisedURL(clientKey, secret, callbackURL, instanceURL string, scopes ...string) *Provider {
-	instanceURL = fmt.Sprintf("%s/", strings.TrimSuffix(instanceURL, "/"))
+	instanceURL = normalizeInstanceURL(instanceURL)
 	profileURL := fmt.Sprintf("%sapi/v1/accounts/verify_credentials", instanceURL)
 	authURL := fmt.Sprintf("%soauth/authorize", instanceURL)
 	tokenURL := fmt.Sprintf("%soauth/token", instanceURL)
@@ -1,6 +1,8 @@
 package mastodon_test
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 
@@ -58,6 +60,68 @@ func Test_SessionFromJSON(t *testing.T) {
 	a.Equal(s.AccessToken, "1234567890")
 }
 
+func Test_NewRegistered(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	registrations := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/api/v1/apps", r.URL.Path)
+		a.Equal("goth", r.FormValue("client_name"))
+		registrations++
+		w.Write([]byte(`{"id":"1","name":"goth","client_id":"instance-key","client_secret":"instance-secret"}`))
+	}))
+	defer ts.Close()
+
+	store := mastodon.NewMemoryAppStore()
+	p, err := mastodon.NewRegistered("goth", "/foo", ts.URL, store)
+	a.NoError(err)
+	a.Equal("instance-key", p.ClientKey)
+	a.Equal("instance-secret", p.Secret)
+
+	session, err := p.BeginAuth("test_state")
+	a.NoError(err)
+	a.Contains(session.(*mastodon.Session).AuthURL, ts.URL+"/oauth/authorize")
+
+	// the credentials are reused for the same instance
+	_, err = mastodon.NewRegistered("goth", "/foo", ts.URL+"/", store)
+	a.NoError(err)
+	a.Equal(1, registrations)
+
+	app, err := store.Get(mastodon.AppKey(ts.URL, "/foo", "read:accounts"))
+	a.NoError(err)
+	a.Equal("instance-key", app.ClientID)
+
+	// but not with other scopes or another callback URL
+	_, err = mastodon.NewRegistered("goth", "/foo", ts.URL, store, "read:accounts", "write:statuses")
+	a.NoError(err)
+	a.Equal(2, registrations)
+	_, err = mastodon.NewRegistered("goth", "/bar", ts.URL, store)
+	a.NoError(err)
+	a.Equal(3, registrations)
+	_, err = mastodon.NewRegistered("goth", "/foo", ts.URL, store, "write:statuses", "read:accounts")
+	a.NoError(err)
+	a.Equal(3, registrations)
+}
+
+func Test_NewRegistered_Failure(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnprocessableEntity)
+	}))
+	defer ts.Close()
+
+	store := mastodon.NewMemoryAppStore()
+	_, err := mastodon.NewRegistered("goth", "/foo", ts.URL, store)
+	a.Error(err)
+
+	app, err := store.Get(mastodon.AppKey(ts.URL, "/foo", "read:accounts"))
+	a.NoError(err)
+	a.Nil(app)
+}
+
 func provider() *mastodon.Provider {
 	return mastodon.New(os.Getenv("MASTODON_KEY"), os.Getenv("MASTODON_SECRET"), "/foo")
 }
@@ -0,0 +1,82 @@
+package onshape_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/onshape"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("ONSHAPE_KEY"))
+	a.Equal(p.Secret, os.Getenv("ONSHAPE_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	session, err := p.BeginAuth("test_state")
+	s := session.(*onshape.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "oauth.onshape.com/oauth/authorize")
+	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("ONSHAPE_KEY")))
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "response_type=code")
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://oauth.onshape.com/oauth/authorize","AccessToken":"1234567890"}`)
+	a.NoError(err)
+
+	s := session.(*onshape.Session)
+	a.Equal(s.AuthURL, "https://oauth.onshape.com/oauth/authorize")
+	a.Equal(s.AccessToken, "1234567890")
+}
+
+func Test_FetchUser(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("Bearer 1234567890", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"id":"5a1b2c3d4e5f6a7b8c9d0e1f","name":"Jane Doe","firstName":"Jane","lastName":"Doe","email":"jane@example.com","image":"https://cad.onshape.com/images/jane.png","href":"https://cad.onshape.com/api/users/5a1b2c3d4e5f6a7b8c9d0e1f"}`))
+	}))
+	defer ts.Close()
+
+	originalProfileURL := onshape.ProfileURL
+	onshape.ProfileURL = ts.URL
+	defer func() { onshape.ProfileURL = originalProfileURL }()
+
+	user, err := provider().FetchUser(&onshape.Session{AccessToken: "1234567890"})
+	a.NoError(err)
+	a.Equal("5a1b2c3d4e5f6a7b8c9d0e1f", user.UserID)
+	a.Equal("Jane Doe", user.Name)
+	a.Equal("Jane", user.FirstName)
+	a.Equal("Doe", user.LastName)
+	a.Equal("jane@example.com", user.Email)
+	a.Equal("https://cad.onshape.com/images/jane.png", user.AvatarURL)
+}
+
+func provider() *onshape.Provider {
+	return onshape.New(os.Getenv("ONSHAPE_KEY"), os.Getenv("ONSHAPE_SECRET"), "/foo")
+}
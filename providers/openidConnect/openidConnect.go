@@ -81,6 +81,7 @@ type OpenIDConfig struct {
 	// https://openid.net/specs/openid-connect-session-1_0-17.html#OPMetadata
 	EndSessionEndpoint string `json:"end_session_endpoint, omitempty"`
 	Issuer             string `json:"issuer"`
+	JwksURI            string `json:"jwks_uri"`
 }
 
 type RefreshTokenResponse struct {
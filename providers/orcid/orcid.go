@@ -0,0 +1,247 @@
+// Package orcid implements the OAuth2 protocol for authenticating researchers through ORCID.
+//
+// ORCID has separate hosts for its sandbox and production registries, use New for
+// production and NewSandbox for the sandbox. The ORCID iD of the user is part of the
+// token response and is used as UserID.
+package orcid
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+)
+
+// These are the registry and public API hosts of the ORCID environments.
+const (
+	ProductionHost    = "https://orcid.org"
+	ProductionAPIHost = "https://pub.orcid.org"
+	SandboxHost       = "https://sandbox.orcid.org"
+	SandboxAPIHost    = "https://pub.sandbox.orcid.org"
+)
+
+const (
+	// ScopeAuthenticate allows to get the ORCID iD of the user, it is enough to sign in.
+	ScopeAuthenticate = "/authenticate"
+	// ScopeReadLimited allows member API clients to read limited-access information.
+	ScopeReadLimited = "/read-limited"
+	// ScopeActivitiesUpdate allows member API clients to add activities to the record.
+	ScopeActivitiesUpdate = "/activities/update"
+	// ScopePersonUpdate allows member API clients to update the biographical information of the record.
+	ScopePersonUpdate = "/person/update"
+)
+
+// Provider is the implementation of `goth.Provider` for accessing ORCID.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	HTTPClient   *http.Client
+	config       *oauth2.Config
+	providerName string
+	apiHost      string
+}
+
+// New creates a new ORCID provider for the production registry and sets up important
+// connection details.
+// You should always call `orcid.New` to get a new provider.  Never try to
+// create one manually.
+func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	return NewCustomisedHosts(clientKey, secret, callbackURL, ProductionHost, ProductionAPIHost, scopes...)
+}
+
+// NewSandbox is similar to New(...) but uses the sandbox registry.
+func NewSandbox(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	return NewCustomisedHosts(clientKey, secret, callbackURL, SandboxHost, SandboxAPIHost, scopes...)
+}
+
+// NewCustomisedHosts is similar to New(...) but can be used to set custom registry and API hosts,
+// e.g. the member API host.
+func NewCustomisedHosts(clientKey, secret, callbackURL, host, apiHost string, scopes ...string) *Provider {
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "orcid",
+		apiHost:      strings.TrimSuffix(apiHost, "/"),
+	}
+	p.config = newConfig(p, strings.TrimSuffix(host, "/"), scopes)
+	return p
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns an HTTP client to be used in all fetch operations.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// Debug is a no-op for the orcid package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks ORCID for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	return &Session{
+		AuthURL: p.config.AuthCodeURL(state),
+	}, nil
+}
+
+// FetchUser will go to the ORCID record API and access the biographical information
+// of the user. The ORCID iD is used as UserID, only the emails the user made visible
+// to the app are returned.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+		UserID:       sess.ORCID,
+		Name:         sess.Name,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	if user.UserID == "" {
+		return user, fmt.Errorf("%s cannot get user information without the ORCID iD", p.providerName)
+	}
+
+	req, err := http.NewRequest("GET", p.apiHost+"/v3.0/"+url.PathEscape(sess.ORCID)+"/person", nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+	req.Header.Add("Accept", "application/json")
+
+	response, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+	}
+
+	bits, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return user, err
+	}
+
+	err = json.NewDecoder(bytes.NewReader(bits)).Decode(&user.RawData)
+	if err != nil {
+		return user, err
+	}
+
+	err = userFromReader(bytes.NewReader(bits), &user)
+	return user, err
+}
+
+type value struct {
+	Value string `json:"value"`
+}
+
+func userFromReader(r io.Reader, user *goth.User) error {
+	u := struct {
+		Name *struct {
+			GivenNames *value `json:"given-names"`
+			FamilyName *value `json:"family-name"`
+			CreditName *value `json:"credit-name"`
+		} `json:"name"`
+		Biography *struct {
+			Content string `json:"content"`
+		} `json:"biography"`
+		Emails struct {
+			Email []struct {
+				Email   string `json:"email"`
+				Primary bool   `json:"primary"`
+			} `json:"email"`
+		} `json:"emails"`
+	}{}
+	err := json.NewDecoder(r).Decode(&u)
+	if err != nil {
+		return err
+	}
+
+	if n := u.Name; n != nil {
+		if n.GivenNames != nil {
+			user.FirstName = n.GivenNames.Value
+		}
+		if n.FamilyName != nil {
+			user.LastName = n.FamilyName.Value
+		}
+		if n.CreditName != nil && n.CreditName.Value != "" {
+			user.NickName = n.CreditName.Value
+		}
+		if name := strings.TrimSpace(user.FirstName + " " + user.LastName); name != "" {
+			user.Name = name
+		}
+	}
+	if u.Biography != nil {
+		user.Description = u.Biography.Content
+	}
+	for _, e := range u.Emails.Email {
+		if user.Email == "" || e.Primary {
+			user.Email = e.Email
+		}
+	}
+	return nil
+}
+
+func newConfig(provider *Provider, host string, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   host + "/oauth/authorize",
+			TokenURL:  host + "/oauth/token",
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+		Scopes: []string{},
+	}
+
+	if len(scopes) > 0 {
+		for _, scope := range scopes {
+			c.Scopes = append(c.Scopes, scope)
+		}
+	} else {
+		c.Scopes = []string{ScopeAuthenticate}
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token.
+// ORCID access tokens are long lived (about 20 years), refreshing is rarely needed.
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	token := &oauth2.Token{RefreshToken: refreshToken}
+	ts := p.config.TokenSource(goth.ContextForClient(p.Client()), token)
+	newToken, err := ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	return newToken, err
+}
@@ -0,0 +1,115 @@
+package orcid_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/orcid"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("ORCID_KEY"))
+	a.Equal(p.Secret, os.Getenv("ORCID_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+}
+
+func Test_NewSandbox(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := orcid.NewSandbox(os.Getenv("ORCID_KEY"), os.Getenv("ORCID_SECRET"), "/foo")
+	session, err := p.BeginAuth("test_state")
+	s := session.(*orcid.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://sandbox.orcid.org/oauth/authorize")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	session, err := p.BeginAuth("test_state")
+	s := session.(*orcid.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://orcid.org/oauth/authorize")
+	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("ORCID_KEY")))
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "scope=%2Fauthenticate")
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://orcid.org/oauth/authorize","AccessToken":"1234567890","ORCID":"0000-0002-1825-0097"}`)
+	a.NoError(err)
+
+	s := session.(*orcid.Session)
+	a.Equal(s.AuthURL, "https://orcid.org/oauth/authorize")
+	a.Equal(s.AccessToken, "1234567890")
+	a.Equal(s.ORCID, "0000-0002-1825-0097")
+}
+
+func Test_Authorize(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/oauth/token", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"f5af9f51-07e6-4332-8f1a-c0c11c1e3728","token_type":"bearer","refresh_token":"f725f747-3a65-49f6-a231-3e8944ce464d","expires_in":631138518,"scope":"/authenticate","name":"Sofia Maria Hernandez Garcia","orcid":"0000-0002-1825-0097"}`))
+	}))
+	defer ts.Close()
+
+	p := orcid.NewCustomisedHosts(os.Getenv("ORCID_KEY"), os.Getenv("ORCID_SECRET"), "/foo", ts.URL, ts.URL)
+	s := &orcid.Session{}
+	token, err := s.Authorize(p, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("f5af9f51-07e6-4332-8f1a-c0c11c1e3728", token)
+	a.Equal("0000-0002-1825-0097", s.ORCID)
+	a.Equal("Sofia Maria Hernandez Garcia", s.Name)
+}
+
+func Test_FetchUser(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/v3.0/0000-0002-1825-0097/person", r.URL.Path)
+		a.Equal("Bearer 1234567890", r.Header.Get("Authorization"))
+		a.Equal("application/json", r.Header.Get("Accept"))
+		w.Write([]byte(`{"name":{"given-names":{"value":"Sofia Maria"},"family-name":{"value":"Hernandez Garcia"},"credit-name":{"value":"S. M. Hernandez Garcia"}},"biography":{"content":"Researcher"},"emails":{"email":[{"email":"sofia@example.org","primary":true,"verified":true}]}}`))
+	}))
+	defer ts.Close()
+
+	p := orcid.NewCustomisedHosts(os.Getenv("ORCID_KEY"), os.Getenv("ORCID_SECRET"), "/foo", ts.URL, ts.URL)
+	user, err := p.FetchUser(&orcid.Session{AccessToken: "1234567890", ORCID: "0000-0002-1825-0097", Name: "Sofia Maria Hernandez Garcia"})
+	a.NoError(err)
+	a.Equal("0000-0002-1825-0097", user.UserID)
+	a.Equal("Sofia Maria", user.FirstName)
+	a.Equal("Hernandez Garcia", user.LastName)
+	a.Equal("Sofia Maria Hernandez Garcia", user.Name)
+	a.Equal("S. M. Hernandez Garcia", user.NickName)
+	a.Equal("sofia@example.org", user.Email)
+	a.Equal("Researcher", user.Description)
+}
+
+func provider() *orcid.Provider {
+	return orcid.New(os.Getenv("ORCID_KEY"), os.Getenv("ORCID_SECRET"), "/foo")
+}
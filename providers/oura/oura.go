@@ -2,12 +2,13 @@
 package oura
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 
-	"fmt"
-
 	"github.com/markbates/goth"
 	"golang.org/x/oauth2"
 )
@@ -15,7 +16,7 @@ import (
 const (
 	authURL         string = "https://cloud.ouraring.com/oauth/authorize"
 	tokenURL        string = "https://api.ouraring.com/oauth/token"
-	endpointProfile string = "https://api.ouraring.com/v1/userinfo"
+	endpointProfile string = "https://api.ouraring.com/v2/usercollection/personal_info"
 )
 
 const (
@@ -25,6 +26,16 @@ const (
 	ScopePersonal = "personal"
 	// ScopeDaily includes daily summaries of sleep, activity and readiness
 	ScopeDaily = "daily"
+	// ScopeHeartRate includes time series heart rate
+	ScopeHeartRate = "heartrate"
+	// ScopeWorkout includes workout summaries
+	ScopeWorkout = "workout"
+	// ScopeTag includes user entered tags
+	ScopeTag = "tag"
+	// ScopeSession includes guided and unguided sessions in the Oura app
+	ScopeSession = "session"
+	// ScopeSpO2 includes the average blood oxygen saturation during sleep
+	ScopeSpO2 = "spo2"
 )
 
 // New creates a new Oura provider (for OuraRing), and sets up important connection details.
@@ -78,7 +89,9 @@ func (p *Provider) BeginAuth(state string) (goth.Session, error) {
 	return session, nil
 }
 
-// FetchUser will go to Oura and access basic information about the user.
+// FetchUser will go to Oura and access the personal info of the user.
+// The age, weight (kg), height (m) and biological sex of the user are available in RawData,
+// they require the personal scope.
 func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 	s := session.(*Session)
 	user := goth.User{
@@ -112,19 +125,24 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 		return user, NewAPIError(resp.StatusCode, fmt.Sprintf("%s responded with a %d trying to fetch user information", p.providerName, resp.StatusCode))
 	}
 
-	//err = userFromReader(io.TeeReader(resp.Body, os.Stdout), &user)
-	err = userFromReader(resp.Body, &user)
+	bits, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return user, err
+	}
+
+	err = json.NewDecoder(bytes.NewReader(bits)).Decode(&user.RawData)
+	if err != nil {
+		return user, err
+	}
+
+	err = userFromReader(bytes.NewReader(bits), &user)
 	return user, err
 }
 
 func userFromReader(reader io.Reader, user *goth.User) error {
 	u := struct {
-		Age    int     `json:"age"`
-		Weight float32 `json:"weight"` // kg
-		Height int     `json:"height"` // cm
-		Gender string  `json:"gender"`
-		Email  string  `json:"email"`
-		UserID string  `json:"user_id"`
+		ID    string `json:"id"`
+		Email string `json:"email"`
 	}{}
 
 	err := json.NewDecoder(reader).Decode(&u)
@@ -132,28 +150,9 @@ func userFromReader(reader io.Reader, user *goth.User) error {
 		return err
 	}
 
-	rawData := make(map[string]interface{})
-
-	if u.Age != 0 {
-		rawData["age"] = u.Age
-	}
-	if u.Weight != 0 {
-		rawData["weight"] = u.Weight
-	}
-	if u.Height != 0 {
-		rawData["height"] = u.Height
-	}
-	if u.Gender != "" {
-		rawData["gender"] = u.Gender
-	}
-
-	user.UserID = u.UserID
+	user.UserID = u.ID
 	user.Email = u.Email
-	if len(rawData) > 0 {
-		user.RawData = rawData
-	}
-
-	return err
+	return nil
 }
 
 func newConfig(provider *Provider, scopes []string) *oauth2.Config {
@@ -1,6 +1,11 @@
 package oura_test
 
 import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 
@@ -53,3 +58,44 @@ func Test_SessionFromJSON(t *testing.T) {
 	a.Equal(s.AccessToken, "1234567890")
 	a.Equal(s.UserID, "abc")
 }
+
+func Test_FetchUser(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/v2/usercollection/personal_info", r.URL.Path)
+		a.Equal("Bearer 1234567890", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"id":"8f9a5221-639e-4a85-81cb-4065ef23f979","age":31,"weight":74.8,"height":1.8,"biological_sex":"female","email":"jane@example.com"}`))
+	})
+
+	withMockServer(provider(), handler, func(p *oura.Provider) {
+		user, err := p.FetchUser(&oura.Session{AccessToken: "1234567890"})
+		a.NoError(err)
+		a.Equal("8f9a5221-639e-4a85-81cb-4065ef23f979", user.UserID)
+		a.Equal("jane@example.com", user.Email)
+		a.Equal(float64(31), user.RawData["age"])
+		a.Equal(1.8, user.RawData["height"])
+		a.Equal("female", user.RawData["biological_sex"])
+	})
+}
+
+func withMockServer(p *oura.Provider, handler http.Handler, fn func(p *oura.Provider)) {
+	server := httptest.NewTLSServer(handler)
+	defer server.Close()
+
+	httpClient := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return net.Dial(network, server.Listener.Addr().String())
+			},
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		},
+	}
+
+	p.HTTPClient = httpClient
+
+	fn(p)
+}
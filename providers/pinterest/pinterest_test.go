@@ -0,0 +1,108 @@
+package pinterest_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/pinterest"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("PINTEREST_KEY"))
+	a.Equal(p.Secret, os.Getenv("PINTEREST_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	session, err := p.BeginAuth("test_state")
+	s := session.(*pinterest.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "www.pinterest.com/oauth/")
+	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("PINTEREST_KEY")))
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "scope=user_accounts%3Aread")
+	a.Contains(s.AuthURL, "response_type=code")
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://www.pinterest.com/oauth/","AccessToken":"1234567890"}`)
+	a.NoError(err)
+
+	s := session.(*pinterest.Session)
+	a.Equal(s.AuthURL, "https://www.pinterest.com/oauth/")
+	a.Equal(s.AccessToken, "1234567890")
+}
+
+func Test_FetchUser(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("Bearer 1234567890", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"id":"549755885175","username":"wash","account_type":"BUSINESS","business_name":"Serenity Shipping","profile_image":"http://avatarURL","about":"Pilot"}`))
+	}))
+	defer ts.Close()
+
+	originalProfileURL := pinterest.ProfileURL
+	pinterest.ProfileURL = ts.URL
+	defer func() { pinterest.ProfileURL = originalProfileURL }()
+
+	user, err := provider().FetchUser(&pinterest.Session{AccessToken: "1234567890"})
+	a.NoError(err)
+	a.Equal("549755885175", user.UserID)
+	a.Equal("wash", user.NickName)
+	a.Equal("Serenity Shipping", user.Name)
+	a.Equal("http://avatarURL", user.AvatarURL)
+	a.Equal("Pilot", user.Description)
+	a.Equal("BUSINESS", user.RawData["account_type"])
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _, ok := r.BasicAuth()
+		a.True(ok)
+		a.Equal("true", r.FormValue("continuous_refresh"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"1234567890","refresh_token":"refresh","response_type":"authorization_code","token_type":"bearer","expires_in":2592000,"scope":"user_accounts:read"}`))
+	}))
+	defer ts.Close()
+
+	originalTokenURL := pinterest.TokenURL
+	pinterest.TokenURL = ts.URL
+	p := provider()
+	pinterest.TokenURL = originalTokenURL
+
+	s := &pinterest.Session{}
+	token, err := s.Authorize(p, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("1234567890", token)
+	a.Equal("refresh", s.RefreshToken)
+}
+
+func provider() *pinterest.Provider {
+	return pinterest.New(os.Getenv("PINTEREST_KEY"), os.Getenv("PINTEREST_SECRET"), "/foo")
+}
@@ -0,0 +1,194 @@
+// Package pipedrive implements the OAuth2 protocol for authenticating users through Pipedrive.
+package pipedrive
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+)
+
+// These vars define the Authentication and Token URLS for Pipedrive, and the API used
+// when the token response has no api_domain.
+var (
+	AuthURL  = "https://oauth.pipedrive.com/oauth/authorize"
+	TokenURL = "https://oauth.pipedrive.com/oauth/token"
+	APIURL   = "https://api.pipedrive.com"
+)
+
+// Provider is the implementation of `goth.Provider` for accessing Pipedrive.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	HTTPClient   *http.Client
+	config       *oauth2.Config
+	providerName string
+}
+
+// New creates a new Pipedrive provider and sets up important connection details.
+// You should always call `pipedrive.New` to get a new provider.  Never try to
+// create one manually.
+func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "pipedrive",
+	}
+	p.config = newConfig(p, scopes)
+	return p
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns an HTTP client to be used in all fetch operations.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// Debug is a no-op for the pipedrive package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks Pipedrive for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	return &Session{
+		AuthURL: p.config.AuthCodeURL(state),
+	}, nil
+}
+
+// FetchUser will go to Pipedrive and access basic information about the user.
+// The user is fetched from the company's api_domain, which is also available in
+// RawData as "api_domain" for the API calls made afterwards.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	apiDomain := sess.APIDomain
+	if apiDomain == "" {
+		apiDomain = APIURL
+	}
+
+	req, err := http.NewRequest("GET", strings.TrimSuffix(apiDomain, "/")+"/api/v1/users/me", nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+
+	response, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+	}
+
+	bits, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return user, err
+	}
+
+	err = userFromReader(bytes.NewReader(bits), &user)
+	if err != nil {
+		return user, err
+	}
+	user.RawData["api_domain"] = apiDomain
+	return user, nil
+}
+
+// userFromReader reads the user from the "data" envelope Pipedrive wraps it in,
+// the content of the envelope is used as RawData.
+func userFromReader(r io.Reader, user *goth.User) error {
+	envelope := struct {
+		Data json.RawMessage `json:"data"`
+	}{}
+	err := json.NewDecoder(r).Decode(&envelope)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(envelope.Data, &user.RawData)
+	if err != nil {
+		return err
+	}
+
+	u := struct {
+		ID      int64  `json:"id"`
+		Name    string `json:"name"`
+		Email   string `json:"email"`
+		IconURL string `json:"icon_url"`
+	}{}
+	err = json.Unmarshal(envelope.Data, &u)
+	if err != nil {
+		return err
+	}
+	user.UserID = strconv.FormatInt(u.ID, 10)
+	user.Name = u.Name
+	user.Email = u.Email
+	user.AvatarURL = u.IconURL
+	return nil
+}
+
+func newConfig(provider *Provider, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   AuthURL,
+			TokenURL:  TokenURL,
+			AuthStyle: oauth2.AuthStyleInHeader,
+		},
+		Scopes: []string{},
+	}
+
+	if len(scopes) > 0 {
+		for _, scope := range scopes {
+			c.Scopes = append(c.Scopes, scope)
+		}
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	token := &oauth2.Token{RefreshToken: refreshToken}
+	ts := p.config.TokenSource(goth.ContextForClient(p.Client()), token)
+	newToken, err := ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	return newToken, err
+}
@@ -0,0 +1,104 @@
+package pipedrive_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/pipedrive"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("PIPEDRIVE_KEY"))
+	a.Equal(p.Secret, os.Getenv("PIPEDRIVE_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	session, err := p.BeginAuth("test_state")
+	s := session.(*pipedrive.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "oauth.pipedrive.com/oauth/authorize")
+	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("PIPEDRIVE_KEY")))
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "response_type=code")
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://oauth.pipedrive.com/oauth/authorize","AccessToken":"1234567890"}`)
+	a.NoError(err)
+
+	s := session.(*pipedrive.Session)
+	a.Equal(s.AuthURL, "https://oauth.pipedrive.com/oauth/authorize")
+	a.Equal(s.AccessToken, "1234567890")
+}
+
+func Test_FetchUser(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/api/v1/users/me", r.URL.Path)
+		a.Equal("Bearer 1234567890", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"success":true,"data":{"id":123,"name":"Jane Doe","email":"jane@example.com","icon_url":"https://example.com/jane.png","company_id":456,"company_name":"Acme","company_domain":"acme"}}`))
+	}))
+	defer ts.Close()
+
+	user, err := provider().FetchUser(&pipedrive.Session{AccessToken: "1234567890", APIDomain: ts.URL})
+	a.NoError(err)
+	a.Equal("123", user.UserID)
+	a.Equal("Jane Doe", user.Name)
+	a.Equal("jane@example.com", user.Email)
+	a.Equal("https://example.com/jane.png", user.AvatarURL)
+	a.Equal(ts.URL, user.RawData["api_domain"])
+	a.Equal("Acme", user.RawData["company_name"])
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, _, ok := r.BasicAuth()
+		a.True(ok)
+		a.Equal(os.Getenv("PIPEDRIVE_KEY"), user)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"1234567890","token_type":"Bearer","refresh_token":"0987654321","scope":"base","expires_in":3599,"api_domain":"https://acme.pipedrive.com"}`))
+	}))
+	defer ts.Close()
+
+	originalTokenURL := pipedrive.TokenURL
+	pipedrive.TokenURL = ts.URL
+	defer func() { pipedrive.TokenURL = originalTokenURL }()
+
+	s := &pipedrive.Session{}
+	token, err := s.Authorize(provider(), url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("1234567890", token)
+	a.Equal("https://acme.pipedrive.com", s.APIDomain)
+}
+
+func provider() *pipedrive.Provider {
+	return pipedrive.New(os.Getenv("PIPEDRIVE_KEY"), os.Getenv("PIPEDRIVE_SECRET"), "/foo")
+}
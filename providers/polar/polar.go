@@ -0,0 +1,213 @@
+// Package polar implements the OAuth2 protocol for authenticating users through Polar AccessLink.
+//
+// Polar requires users to be registered with the partner before their data can be read,
+// FetchUser takes care of registering the user that signed in.
+package polar
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+)
+
+// These vars define the Authentication, Token, and API URLS for Polar.
+var (
+	AuthURL  = "https://flow.polar.com/oauth2/authorization"
+	TokenURL = "https://polarremote.com/v2/oauth2/token"
+	APIURL   = "https://www.polaraccesslink.com/v3"
+)
+
+// ScopeReadAll allows to read all the data of the user, it is the only AccessLink scope.
+const ScopeReadAll = "accesslink.read_all"
+
+// Provider is the implementation of `goth.Provider` for accessing Polar.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	HTTPClient   *http.Client
+	config       *oauth2.Config
+	providerName string
+}
+
+// New creates a new Polar provider and sets up important connection details.
+// You should always call `polar.New` to get a new provider.  Never try to
+// create one manually.
+func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "polar",
+	}
+	p.config = newConfig(p, scopes)
+	return p
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns an HTTP client to be used in all fetch operations.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// Debug is a no-op for the polar package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks Polar for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	return &Session{
+		AuthURL: p.config.AuthCodeURL(state),
+	}, nil
+}
+
+// FetchUser registers the user with Polar AccessLink, if that was not done before,
+// and accesses basic information about the user.
+// The Polar user id (x_user_id of the token response) is used as UserID and as the
+// member id of the registration.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken: sess.AccessToken,
+		Provider:    p.Name(),
+		ExpiresAt:   sess.ExpiresAt,
+		UserID:      sess.UserID,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	if user.UserID == "" {
+		return user, fmt.Errorf("%s cannot get user information without the user id", p.providerName)
+	}
+
+	response, err := p.registerUser(sess.AccessToken, sess.UserID)
+	if err != nil {
+		return user, err
+	}
+
+	// 409 means the user has been registered before
+	if response.StatusCode == http.StatusConflict {
+		response.Body.Close()
+		response, err = p.apiRequest("GET", sess.AccessToken, "/users/"+url.PathEscape(sess.UserID), nil)
+		if err != nil {
+			return user, err
+		}
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+	}
+
+	bits, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return user, err
+	}
+
+	err = json.NewDecoder(bytes.NewReader(bits)).Decode(&user.RawData)
+	if err != nil {
+		return user, err
+	}
+
+	err = userFromReader(bytes.NewReader(bits), &user)
+	return user, err
+}
+
+// registerUser registers the user with the partner, using the user id as member id.
+func (p *Provider) registerUser(accessToken, userID string) (*http.Response, error) {
+	body, err := json.Marshal(map[string]string{"member-id": userID})
+	if err != nil {
+		return nil, err
+	}
+	return p.apiRequest("POST", accessToken, "/users", bytes.NewReader(body))
+}
+
+func (p *Provider) apiRequest(method, accessToken, path string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequest(method, APIURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Authorization", "Bearer "+accessToken)
+	req.Header.Add("Accept", "application/json")
+	if body != nil {
+		req.Header.Add("Content-Type", "application/json")
+	}
+	return p.Client().Do(req)
+}
+
+func userFromReader(r io.Reader, user *goth.User) error {
+	u := struct {
+		FirstName string `json:"first-name"`
+		LastName  string `json:"last-name"`
+	}{}
+	err := json.NewDecoder(r).Decode(&u)
+	if err != nil {
+		return err
+	}
+	user.FirstName = u.FirstName
+	user.LastName = u.LastName
+	user.Name = strings.TrimSpace(u.FirstName + " " + u.LastName)
+	return nil
+}
+
+// userIDFromToken reads the Polar user id from the x_user_id field of the token response.
+func userIDFromToken(token *oauth2.Token) string {
+	if id, ok := token.Extra("x_user_id").(float64); ok {
+		return strconv.FormatFloat(id, 'f', -1, 64)
+	}
+	return ""
+}
+
+func newConfig(provider *Provider, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   AuthURL,
+			TokenURL:  TokenURL,
+			AuthStyle: oauth2.AuthStyleInHeader,
+		},
+		Scopes: []string{},
+	}
+
+	if len(scopes) > 0 {
+		for _, scope := range scopes {
+			c.Scopes = append(c.Scopes, scope)
+		}
+	} else {
+		c.Scopes = []string{ScopeReadAll}
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is not provided by Polar
+func (p *Provider) RefreshTokenAvailable() bool {
+	return false
+}
+
+// RefreshToken refresh token is not provided by Polar
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	return nil, errors.New("Refresh token is not provided by Polar")
+}
@@ -0,0 +1,133 @@
+package polar_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/polar"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("POLAR_KEY"))
+	a.Equal(p.Secret, os.Getenv("POLAR_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	session, err := p.BeginAuth("test_state")
+	s := session.(*polar.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "flow.polar.com/oauth2/authorization")
+	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("POLAR_KEY")))
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "scope=accesslink.read_all")
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://flow.polar.com/oauth2/authorization","AccessToken":"1234567890","UserID":"475"}`)
+	a.NoError(err)
+
+	s := session.(*polar.Session)
+	a.Equal(s.AuthURL, "https://flow.polar.com/oauth2/authorization")
+	a.Equal(s.AccessToken, "1234567890")
+	a.Equal(s.UserID, "475")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _, ok := r.BasicAuth()
+		a.True(ok)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"abc","token_type":"bearer","expires_in":315359999,"x_user_id":475}`))
+	}))
+	defer ts.Close()
+
+	originalTokenURL := polar.TokenURL
+	polar.TokenURL = ts.URL
+	defer func() { polar.TokenURL = originalTokenURL }()
+
+	s := &polar.Session{}
+	token, err := s.Authorize(provider(), url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("abc", token)
+	a.Equal("475", s.UserID)
+}
+
+func Test_FetchUser(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("Bearer 1234567890", r.Header.Get("Authorization"))
+		a.Equal("POST", r.Method)
+		a.Equal("/users", r.URL.Path)
+		body := map[string]string{}
+		a.NoError(json.NewDecoder(r.Body).Decode(&body))
+		a.Equal("475", body["member-id"])
+		w.Write([]byte(`{"polar-user-id":475,"member-id":"475","registration-date":"2011-10-14T12:50:37.000Z","first-name":"Eka","last-name":"Toka","birthdate":"1985-09-06","gender":"MALE","weight":66,"height":170}`))
+	}))
+	defer ts.Close()
+
+	originalAPIURL := polar.APIURL
+	polar.APIURL = ts.URL
+	defer func() { polar.APIURL = originalAPIURL }()
+
+	user, err := provider().FetchUser(&polar.Session{AccessToken: "1234567890", UserID: "475"})
+	a.NoError(err)
+	a.Equal("475", user.UserID)
+	a.Equal("Eka Toka", user.Name)
+	a.Equal("MALE", user.RawData["gender"])
+}
+
+func Test_FetchUserRegistered(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "POST":
+			w.WriteHeader(http.StatusConflict)
+		case "GET":
+			a.Equal("/users/475", r.URL.Path)
+			w.Write([]byte(`{"polar-user-id":475,"member-id":"475","first-name":"Eka","last-name":"Toka"}`))
+		}
+	}))
+	defer ts.Close()
+
+	originalAPIURL := polar.APIURL
+	polar.APIURL = ts.URL
+	defer func() { polar.APIURL = originalAPIURL }()
+
+	user, err := provider().FetchUser(&polar.Session{AccessToken: "1234567890", UserID: "475"})
+	a.NoError(err)
+	a.Equal("Eka", user.FirstName)
+	a.Equal("Toka", user.LastName)
+}
+
+func provider() *polar.Provider {
+	return polar.New(os.Getenv("POLAR_KEY"), os.Getenv("POLAR_SECRET"), "/foo")
+}
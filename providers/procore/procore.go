@@ -0,0 +1,218 @@
+// Package procore implements the OAuth2 protocol for authenticating users through Procore.
+//
+// Procore has separate login and API hosts for its sandbox and production environments,
+// use New for production and NewSandbox for the sandbox.
+package procore
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+)
+
+// These are the login and API hosts of the Procore environments.
+const (
+	ProductionLoginHost = "https://login.procore.com"
+	ProductionAPIHost   = "https://api.procore.com"
+	SandboxLoginHost    = "https://login-sandbox.procore.com"
+	SandboxAPIHost      = "https://sandbox.procore.com"
+)
+
+// Provider is the implementation of `goth.Provider` for accessing Procore.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	HTTPClient   *http.Client
+	config       *oauth2.Config
+	providerName string
+	apiHost      string
+}
+
+// New creates a new Procore provider for the production environment and sets up
+// important connection details.
+// You should always call `procore.New` to get a new provider.  Never try to
+// create one manually.
+func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	return NewCustomisedHosts(clientKey, secret, callbackURL, ProductionLoginHost, ProductionAPIHost, scopes...)
+}
+
+// NewSandbox is similar to New(...) but uses the sandbox environment.
+func NewSandbox(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	return NewCustomisedHosts(clientKey, secret, callbackURL, SandboxLoginHost, SandboxAPIHost, scopes...)
+}
+
+// NewCustomisedHosts is similar to New(...) but can be used to set custom login and API hosts.
+func NewCustomisedHosts(clientKey, secret, callbackURL, loginHost, apiHost string, scopes ...string) *Provider {
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "procore",
+		apiHost:      strings.TrimSuffix(apiHost, "/"),
+	}
+	p.config = newConfig(p, strings.TrimSuffix(loginHost, "/"), scopes)
+	return p
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns an HTTP client to be used in all fetch operations.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// Debug is a no-op for the procore package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks Procore for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	return &Session{
+		AuthURL: p.config.AuthCodeURL(state),
+	}, nil
+}
+
+// FetchUser will go to Procore and access basic information about the user.
+// The companies the user is a member of are available in RawData under "companies".
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	req, err := http.NewRequest("GET", p.apiHost+"/rest/v1.0/me", nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+
+	response, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+	}
+
+	bits, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return user, err
+	}
+
+	err = json.NewDecoder(bytes.NewReader(bits)).Decode(&user.RawData)
+	if err != nil {
+		return user, err
+	}
+
+	err = userFromReader(bytes.NewReader(bits), &user)
+	if err != nil {
+		return user, err
+	}
+
+	companies, err := p.fetchCompanies(sess.AccessToken)
+	if err != nil {
+		return user, err
+	}
+	user.RawData["companies"] = companies
+	return user, nil
+}
+
+// fetchCompanies lists the companies the user is a member of.
+func (p *Provider) fetchCompanies(accessToken string) ([]interface{}, error) {
+	req, err := http.NewRequest("GET", p.apiHost+"/rest/v1.0/companies", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Authorization", "Bearer "+accessToken)
+
+	response, err := p.Client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s responded with a %d trying to fetch companies", p.providerName, response.StatusCode)
+	}
+
+	companies := []interface{}{}
+	err = json.NewDecoder(response.Body).Decode(&companies)
+	return companies, err
+}
+
+func userFromReader(r io.Reader, user *goth.User) error {
+	u := struct {
+		ID    int64  `json:"id"`
+		Login string `json:"login"`
+		Name  string `json:"name"`
+	}{}
+	err := json.NewDecoder(r).Decode(&u)
+	if err != nil {
+		return err
+	}
+	user.UserID = strconv.FormatInt(u.ID, 10)
+	user.Email = u.Login
+	user.Name = u.Name
+	return nil
+}
+
+func newConfig(provider *Provider, loginHost string, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   loginHost + "/oauth/authorize",
+			TokenURL:  loginHost + "/oauth/token",
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+		Scopes: []string{},
+	}
+
+	for _, scope := range scopes {
+		c.Scopes = append(c.Scopes, scope)
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	token := &oauth2.Token{RefreshToken: refreshToken}
+	ts := p.config.TokenSource(goth.ContextForClient(p.Client()), token)
+	newToken, err := ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	return newToken, err
+}
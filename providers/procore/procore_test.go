@@ -0,0 +1,116 @@
+package procore_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/procore"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("PROCORE_KEY"))
+	a.Equal(p.Secret, os.Getenv("PROCORE_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+}
+
+func Test_NewSandbox(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := procore.NewSandbox(os.Getenv("PROCORE_KEY"), os.Getenv("PROCORE_SECRET"), "/foo")
+	session, err := p.BeginAuth("test_state")
+	s := session.(*procore.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://login-sandbox.procore.com/oauth/authorize")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	session, err := p.BeginAuth("test_state")
+	s := session.(*procore.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://login.procore.com/oauth/authorize")
+	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("PROCORE_KEY")))
+	a.Contains(s.AuthURL, "state=test_state")
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://login.procore.com/oauth/authorize","AccessToken":"1234567890"}`)
+	a.NoError(err)
+
+	s := session.(*procore.Session)
+	a.Equal(s.AuthURL, "https://login.procore.com/oauth/authorize")
+	a.Equal(s.AccessToken, "1234567890")
+}
+
+func Test_FetchUser(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("Bearer 1234567890", r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/rest/v1.0/me":
+			w.Write([]byte(`{"id":3456,"login":"jane@example.com","name":"Jane Doe"}`))
+		case "/rest/v1.0/companies":
+			w.Write([]byte(`[{"id":8089,"name":"Construction Co","is_active":true}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	p := procore.NewCustomisedHosts(os.Getenv("PROCORE_KEY"), os.Getenv("PROCORE_SECRET"), "/foo", ts.URL, ts.URL)
+	user, err := p.FetchUser(&procore.Session{AccessToken: "1234567890"})
+	a.NoError(err)
+	a.Equal("3456", user.UserID)
+	a.Equal("jane@example.com", user.Email)
+	a.Equal("Jane Doe", user.Name)
+	companies := user.RawData["companies"].([]interface{})
+	a.Len(companies, 1)
+	a.Equal("Construction Co", companies[0].(map[string]interface{})["name"])
+}
+
+func Test_RefreshToken(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/oauth/token", r.URL.Path)
+		a.NoError(r.ParseForm())
+		a.Equal("old-refresh-token", r.Form.Get("refresh_token"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"new-access-token","token_type":"bearer","expires_in":5400,"refresh_token":"new-refresh-token"}`))
+	}))
+	defer ts.Close()
+
+	p := procore.NewCustomisedHosts(os.Getenv("PROCORE_KEY"), os.Getenv("PROCORE_SECRET"), "/foo", ts.URL, ts.URL)
+	token, err := p.RefreshToken("old-refresh-token")
+	a.NoError(err)
+	a.Equal("new-access-token", token.AccessToken)
+	a.Equal("new-refresh-token", token.RefreshToken)
+}
+
+func provider() *procore.Provider {
+	return procore.New(os.Getenv("PROCORE_KEY"), os.Getenv("PROCORE_SECRET"), "/foo")
+}
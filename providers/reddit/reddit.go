@@ -0,0 +1,209 @@
+// Package reddit implements the OAuth2 protocol for authenticating users through Reddit.
+package reddit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+)
+
+// These vars define the Authentication, Token, and Profile URLS for Reddit.
+var (
+	AuthURL    = "https://www.reddit.com/api/v1/authorize"
+	TokenURL   = "https://www.reddit.com/api/v1/access_token"
+	ProfileURL = "https://oauth.reddit.com/api/v1/me"
+)
+
+// DefaultUserAgent is used when no UserAgent is set on the provider.
+const DefaultUserAgent = "golang:github.com/markbates/goth:v1 (goth reddit provider)"
+
+const (
+	// ScopeIdentity allows access to the reddit username and signup date.
+	ScopeIdentity = "identity"
+)
+
+// Provider is the implementation of `goth.Provider` for accessing Reddit.
+type Provider struct {
+	ClientKey   string
+	Secret      string
+	CallbackURL string
+	HTTPClient  *http.Client
+	// UserAgent is sent with every request, including the token exchange. Reddit
+	// throttles or blocks generic user agents, so set one that identifies your app,
+	// see https://github.com/reddit-archive/reddit/wiki/API#rules
+	UserAgent    string
+	config       *oauth2.Config
+	providerName string
+}
+
+// New creates a new Reddit provider and sets up important connection details.
+// You should always call `reddit.New` to get a new provider.  Never try to
+// create one manually.
+func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		UserAgent:    DefaultUserAgent,
+		providerName: "reddit",
+	}
+	p.config = newConfig(p, scopes)
+	return p
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns an HTTP client to be used in all fetch operations.
+// It sets the provider's UserAgent on every request.
+func (p *Provider) Client() *http.Client {
+	c := *goth.HTTPClientWithFallBack(p.HTTPClient)
+	base := c.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	c.Transport = &userAgentTransport{userAgent: p.UserAgent, base: base}
+	return &c
+}
+
+type userAgentTransport struct {
+	userAgent string
+	base      http.RoundTripper
+}
+
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	r := req.Clone(req.Context())
+	r.Header.Set("User-Agent", t.userAgent)
+	return t.base.RoundTrip(r)
+}
+
+// Debug is a no-op for the reddit package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks Reddit for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	url := p.config.AuthCodeURL(state,
+		oauth2.SetAuthURLParam("duration", "permanent"),
+	)
+	return &Session{
+		AuthURL: url,
+	}, nil
+}
+
+// FetchUser will go to Reddit and access basic information about the user.
+// The karma counters (total_karma, link_karma, comment_karma) are available in RawData.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	req, err := http.NewRequest("GET", ProfileURL, nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+
+	response, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+	}
+
+	bits, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return user, err
+	}
+
+	err = json.NewDecoder(bytes.NewReader(bits)).Decode(&user.RawData)
+	if err != nil {
+		return user, err
+	}
+
+	err = userFromReader(bytes.NewReader(bits), &user)
+	return user, err
+}
+
+func userFromReader(r io.Reader, user *goth.User) error {
+	u := struct {
+		ID      string `json:"id"`
+		Name    string `json:"name"`
+		IconImg string `json:"icon_img"`
+	}{}
+	err := json.NewDecoder(r).Decode(&u)
+	if err != nil {
+		return err
+	}
+	user.UserID = u.ID
+	user.Name = u.Name
+	user.NickName = u.Name
+	// reddit returns the icon URL HTML-escaped
+	user.AvatarURL = html.UnescapeString(u.IconImg)
+	return nil
+}
+
+func newConfig(provider *Provider, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   AuthURL,
+			TokenURL:  TokenURL,
+			AuthStyle: oauth2.AuthStyleInHeader,
+		},
+		Scopes: []string{},
+	}
+
+	if len(scopes) > 0 {
+		for _, scope := range scopes {
+			c.Scopes = append(c.Scopes, scope)
+		}
+	} else {
+		c.Scopes = []string{ScopeIdentity}
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token.
+// Reddit only issues refresh tokens for permanent grants, which this provider requests.
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	token := &oauth2.Token{RefreshToken: refreshToken}
+	ts := p.config.TokenSource(goth.ContextForClient(p.Client()), token)
+	newToken, err := ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	return newToken, err
+}
@@ -0,0 +1,83 @@
+package reddit_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/reddit"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("REDDIT_KEY"))
+	a.Equal(p.Secret, os.Getenv("REDDIT_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	session, err := p.BeginAuth("test_state")
+	s := session.(*reddit.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "www.reddit.com/api/v1/authorize")
+	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("REDDIT_KEY")))
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "duration=permanent")
+	a.Contains(s.AuthURL, "scope=identity")
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://www.reddit.com/api/v1/authorize","AccessToken":"1234567890"}`)
+	a.NoError(err)
+
+	s := session.(*reddit.Session)
+	a.Equal(s.AuthURL, "https://www.reddit.com/api/v1/authorize")
+	a.Equal(s.AccessToken, "1234567890")
+}
+
+func Test_FetchUser(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("Bearer 1234567890", r.Header.Get("Authorization"))
+		a.Equal(reddit.DefaultUserAgent, r.Header.Get("User-Agent"))
+		w.Write([]byte(`{"id":"abc12","name":"wash","icon_img":"https://styles.redditmedia.com/icon.png?width=256&amp;s=1","total_karma":42,"link_karma":40,"comment_karma":2}`))
+	}))
+	defer ts.Close()
+
+	originalProfileURL := reddit.ProfileURL
+	reddit.ProfileURL = ts.URL
+	defer func() { reddit.ProfileURL = originalProfileURL }()
+
+	user, err := provider().FetchUser(&reddit.Session{AccessToken: "1234567890"})
+	a.NoError(err)
+	a.Equal("abc12", user.UserID)
+	a.Equal("wash", user.Name)
+	a.Equal("wash", user.NickName)
+	a.Equal("https://styles.redditmedia.com/icon.png?width=256&s=1", user.AvatarURL)
+	a.Equal(float64(42), user.RawData["total_karma"])
+}
+
+func provider() *reddit.Provider {
+	return reddit.New(os.Getenv("REDDIT_KEY"), os.Getenv("REDDIT_SECRET"), "/foo")
+}
@@ -0,0 +1,82 @@
+package roblox_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/roblox"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("ROBLOX_KEY"))
+	a.Equal(p.Secret, os.Getenv("ROBLOX_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	session, err := p.BeginAuth("test_state")
+	s := session.(*roblox.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "apis.roblox.com/oauth/v1/authorize")
+	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("ROBLOX_KEY")))
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "scope=openid+profile")
+	a.Contains(s.AuthURL, "code_challenge="+goth.S256CodeChallenge(s.CodeVerifier))
+	a.Contains(s.AuthURL, "code_challenge_method=S256")
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://apis.roblox.com/oauth/v1/authorize","AccessToken":"1234567890"}`)
+	a.NoError(err)
+
+	s := session.(*roblox.Session)
+	a.Equal(s.AuthURL, "https://apis.roblox.com/oauth/v1/authorize")
+	a.Equal(s.AccessToken, "1234567890")
+}
+
+func Test_FetchUser(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("Bearer 1234567890", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"sub":"1516563360","name":"wash","nickname":"Wash","preferred_username":"wash","created_at":1584682495,"profile":"https://www.roblox.com/users/1516563360/profile","picture":"http://avatarURL"}`))
+	}))
+	defer ts.Close()
+
+	originalProfileURL := roblox.ProfileURL
+	roblox.ProfileURL = ts.URL
+	defer func() { roblox.ProfileURL = originalProfileURL }()
+
+	user, err := provider().FetchUser(&roblox.Session{AccessToken: "1234567890"})
+	a.NoError(err)
+	a.Equal("1516563360", user.UserID)
+	a.Equal("Wash", user.Name)
+	a.Equal("wash", user.NickName)
+	a.Equal("http://avatarURL", user.AvatarURL)
+}
+
+func provider() *roblox.Provider {
+	return roblox.New(os.Getenv("ROBLOX_KEY"), os.Getenv("ROBLOX_SECRET"), "/foo")
+}
@@ -9,6 +9,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
 
 	"github.com/markbates/goth"
 	"golang.org/x/oauth2"
@@ -27,6 +28,12 @@ var (
 	//endpointProfile    string = "https://api.salesforce.com/2.0/users/me"
 )
 
+// Hosts of the Salesforce login servers, for production orgs and for sandboxes.
+const (
+	LoginHost   string = "login.salesforce.com"
+	SandboxHost string = "test.salesforce.com"
+)
+
 // Provider is the implementation of `goth.Provider` for accessing Salesforce.
 type Provider struct {
 	ClientKey    string
@@ -35,17 +42,39 @@ type Provider struct {
 	HTTPClient   *http.Client
 	config       *oauth2.Config
 	providerName string
+	authURL      string
+	tokenURL     string
 }
 
 // New creates a new Salesforce provider and sets up important connection details.
 // You should always call `salesforce.New` to get a new provider.  Never try to
 // create one manually.
 func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	return newProvider(clientKey, secret, callbackURL, AuthURL, TokenURL, scopes)
+}
+
+// NewSandbox is similar to New(...) but signs in to sandboxes, through test.salesforce.com.
+func NewSandbox(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	return NewCustomisedHost(clientKey, secret, callbackURL, SandboxHost, scopes...)
+}
+
+// NewCustomisedHost is similar to New(...) but signs in through the given host, such as the
+// My Domain of an org, e.g. "acme.my.salesforce.com".
+func NewCustomisedHost(clientKey, secret, callbackURL, host string, scopes ...string) *Provider {
+	return newProvider(clientKey, secret, callbackURL,
+		"https://"+host+"/services/oauth2/authorize",
+		"https://"+host+"/services/oauth2/token",
+		scopes)
+}
+
+func newProvider(clientKey, secret, callbackURL, authURL, tokenURL string, scopes []string) *Provider {
 	p := &Provider{
 		ClientKey:    clientKey,
 		Secret:       secret,
 		CallbackURL:  callbackURL,
 		providerName: "salesforce",
+		authURL:      authURL,
+		tokenURL:     tokenURL,
 	}
 	p.config = newConfig(p, scopes)
 	return p
@@ -95,7 +124,7 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 	}
 
 	//creating dynamic url to retrieve user information
-	userURL := url.Scheme + "://" + url.Host + "/" + url.Path
+	userURL := url.Scheme + "://" + url.Host + url.Path
 	req, err := http.NewRequest("GET", userURL, nil)
 	if err != nil {
 		return user, err
@@ -112,7 +141,21 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 	}
 
 	err = userFromReader(resp.Body, &user)
-	return user, err
+	if err != nil {
+		return user, err
+	}
+
+	// the identity URL ends with the IDs of the org and the user
+	// e.g. https://login.salesforce.com/id/00Dx0000000BV7z/005x00000012Q9P
+	parts := strings.Split(strings.Trim(url.Path, "/"), "/")
+	if len(parts) >= 3 && parts[len(parts)-3] == "id" {
+		user.RawData["organization_id"] = parts[len(parts)-2]
+		if user.UserID == "" {
+			user.UserID = parts[len(parts)-1]
+		}
+	}
+	user.RawData["instance_url"] = s.InstanceURL
+	return user, nil
 }
 
 func newConfig(provider *Provider, scopes []string) *oauth2.Config {
@@ -121,8 +164,8 @@ func newConfig(provider *Provider, scopes []string) *oauth2.Config {
 		ClientSecret: provider.Secret,
 		RedirectURL:  provider.CallbackURL,
 		Endpoint: oauth2.Endpoint{
-			AuthURL:  AuthURL,
-			TokenURL: TokenURL,
+			AuthURL:  provider.authURL,
+			TokenURL: provider.tokenURL,
 		},
 		Scopes: []string{},
 	}
@@ -1,11 +1,18 @@
 package salesforce_test
 
 import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+
 	"github.com/markbates/goth"
 	"github.com/markbates/goth/providers/salesforce"
 	"github.com/stretchr/testify/assert"
-	"os"
-	"testing"
 )
 
 func Test_New(t *testing.T) {
@@ -47,6 +54,69 @@ func Test_SessionFromJSON(t *testing.T) {
 	a.Equal(s.AccessToken, "1234567890")
 }
 
+func Test_NewSandbox(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	session, err := salesforce.NewSandbox("key", "secret", "/foo").BeginAuth("test_state")
+	a.NoError(err)
+	a.Contains(session.(*salesforce.Session).AuthURL, "https://test.salesforce.com/services/oauth2/authorize")
+
+	session, err = salesforce.NewCustomisedHost("key", "secret", "/foo", "acme.my.salesforce.com").BeginAuth("test_state")
+	a.NoError(err)
+	a.Contains(session.(*salesforce.Session).AuthURL, "https://acme.my.salesforce.com/services/oauth2/authorize")
+}
+
+func Test_FetchUser(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/services/oauth2/token":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"access_token":"TOKEN","token_type":"Bearer","id":"https://test.salesforce.com/id/00Dx0000000BV7z/005x00000012Q9P","instance_url":"https://acme--uat.sandbox.my.salesforce.com"}`))
+		case "/id/00Dx0000000BV7z/005x00000012Q9P":
+			a.Equal("Bearer TOKEN", r.Header.Get("Authorization"))
+			w.Write([]byte(`{"display_name":"Jane Doe","email":"jane@acme.com"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	withMockServer(salesforce.NewSandbox("key", "secret", "/foo"), handler, func(p *salesforce.Provider) {
+		s := &salesforce.Session{}
+		_, err := s.Authorize(p, url.Values{"code": {"code"}})
+		a.NoError(err)
+		a.Equal("https://acme--uat.sandbox.my.salesforce.com", s.InstanceURL)
+
+		user, err := p.FetchUser(s)
+		a.NoError(err)
+		a.Equal("005x00000012Q9P", user.UserID)
+		a.Equal("Jane Doe", user.Name)
+		a.Equal("00Dx0000000BV7z", user.RawData["organization_id"])
+		a.Equal("https://acme--uat.sandbox.my.salesforce.com", user.RawData["instance_url"])
+	})
+}
+
+func withMockServer(p *salesforce.Provider, handler http.Handler, fn func(p *salesforce.Provider)) {
+	server := httptest.NewTLSServer(handler)
+	defer server.Close()
+
+	p.HTTPClient = &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return net.Dial(network, server.Listener.Addr().String())
+			},
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		},
+	}
+
+	fn(p)
+}
+
 func provider() *salesforce.Provider {
 	return salesforce.New(os.Getenv("SALESFORCE_KEY"), os.Getenv("SALESFORCE_SECRET"), "/foo")
 }
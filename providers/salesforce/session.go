@@ -23,6 +23,7 @@ type Session struct {
 	AccessToken  string
 	RefreshToken string
 	ID           string //Required to get the user info from sales force
+	InstanceURL  string // Base URL of the API of the org of the user
 }
 
 var _ goth.Session = &Session{}
@@ -50,7 +51,8 @@ func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string,
 
 	s.AccessToken = token.AccessToken
 	s.RefreshToken = token.RefreshToken
-	s.ID = token.Extra("id").(string) //Required to get the user info from sales force
+	s.ID, _ = token.Extra("id").(string) //Required to get the user info from sales force
+	s.InstanceURL, _ = token.Extra("instance_url").(string)
 	return token.AccessToken, err
 }
 
@@ -35,7 +35,7 @@ func Test_ToJSON(t *testing.T) {
 	s := &salesforce.Session{}
 
 	data := s.Marshal()
-	a.Equal(data, `{"AuthURL":"","AccessToken":"","RefreshToken":"","ID":""}`)
+	a.Equal(data, `{"AuthURL":"","AccessToken":"","RefreshToken":"","ID":"","InstanceURL":""}`)
 }
 
 func Test_String(t *testing.T) {
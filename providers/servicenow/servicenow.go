@@ -0,0 +1,198 @@
+// Package servicenow implements the OAuth2 protocol for authenticating users through a ServiceNow instance.
+package servicenow
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+)
+
+// currentUserPath looks up the sys_user record of the user the token belongs to.
+const currentUserPath = "/api/now/table/sys_user?sysparm_query=sys_id%3Djavascript%3Ags.getUserID()&sysparm_limit=1&sysparm_display_value=true"
+
+const (
+	// ScopeUserAccount gives access to everything the user can access.
+	ScopeUserAccount = "useraccount"
+)
+
+// Provider is the implementation of `goth.Provider` for accessing ServiceNow.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	InstanceURL  string
+	HTTPClient   *http.Client
+	config       *oauth2.Config
+	providerName string
+}
+
+// New creates a new ServiceNow provider and sets up important connection details.
+// You should always call `servicenow.New` to get a new provider.  Never try to
+// create one manually.
+// The instanceURL is the URL of the ServiceNow instance, e.g. https://acme.service-now.com
+func New(clientKey, secret, callbackURL, instanceURL string, scopes ...string) *Provider {
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		InstanceURL:  strings.TrimSuffix(instanceURL, "/"),
+		providerName: "servicenow",
+	}
+	p.config = newConfig(p, scopes)
+	return p
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns an HTTP client to be used in all fetch operations.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// Debug is a no-op for the servicenow package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks ServiceNow for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	return &Session{
+		AuthURL: p.config.AuthCodeURL(state),
+	}, nil
+}
+
+// FetchUser will go to ServiceNow and read the sys_user record of the user.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	req, err := http.NewRequest("GET", p.InstanceURL+currentUserPath, nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+	req.Header.Add("Accept", "application/json")
+
+	response, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+	}
+
+	bits, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return user, err
+	}
+
+	err = userFromReader(bytes.NewReader(bits), &user)
+	return user, err
+}
+
+// userFromReader reads the sys_user record from the table API result, the record
+// is used as RawData.
+func userFromReader(r io.Reader, user *goth.User) error {
+	result := struct {
+		Result []json.RawMessage `json:"result"`
+	}{}
+	err := json.NewDecoder(r).Decode(&result)
+	if err != nil {
+		return err
+	}
+	if len(result.Result) == 0 {
+		return errors.New("servicenow: no sys_user record found for the access token")
+	}
+
+	err = json.Unmarshal(result.Result[0], &user.RawData)
+	if err != nil {
+		return err
+	}
+
+	u := struct {
+		SysID     string `json:"sys_id"`
+		UserName  string `json:"user_name"`
+		Name      string `json:"name"`
+		FirstName string `json:"first_name"`
+		LastName  string `json:"last_name"`
+		Email     string `json:"email"`
+		Title     string `json:"title"`
+	}{}
+	err = json.Unmarshal(result.Result[0], &u)
+	if err != nil {
+		return err
+	}
+	user.UserID = u.SysID
+	user.NickName = u.UserName
+	user.Name = u.Name
+	user.FirstName = u.FirstName
+	user.LastName = u.LastName
+	user.Email = u.Email
+	user.Description = u.Title
+	return nil
+}
+
+func newConfig(provider *Provider, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   provider.InstanceURL + "/oauth_auth.do",
+			TokenURL:  provider.InstanceURL + "/oauth_token.do",
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+		Scopes: []string{},
+	}
+
+	if len(scopes) > 0 {
+		for _, scope := range scopes {
+			c.Scopes = append(c.Scopes, scope)
+		}
+	} else {
+		c.Scopes = []string{ScopeUserAccount}
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	token := &oauth2.Token{RefreshToken: refreshToken}
+	ts := p.config.TokenSource(goth.ContextForClient(p.Client()), token)
+	newToken, err := ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	return newToken, err
+}
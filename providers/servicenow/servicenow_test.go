@@ -0,0 +1,117 @@
+package servicenow_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/servicenow"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("SERVICENOW_KEY"))
+	a.Equal(p.Secret, os.Getenv("SERVICENOW_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+	a.Equal(p.InstanceURL, "https://acme.service-now.com")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	session, err := p.BeginAuth("test_state")
+	s := session.(*servicenow.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://acme.service-now.com/oauth_auth.do")
+	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("SERVICENOW_KEY")))
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "scope=useraccount")
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://acme.service-now.com/oauth_auth.do","AccessToken":"1234567890"}`)
+	a.NoError(err)
+
+	s := session.(*servicenow.Session)
+	a.Equal(s.AuthURL, "https://acme.service-now.com/oauth_auth.do")
+	a.Equal(s.AccessToken, "1234567890")
+}
+
+func Test_FetchUser(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/api/now/table/sys_user", r.URL.Path)
+		a.Equal("sys_id=javascript:gs.getUserID()", r.URL.Query().Get("sysparm_query"))
+		a.Equal("Bearer 1234567890", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"result":[{"sys_id":"6816f79cc0a8016401c5a33be04be441","user_name":"abel.tuter","name":"Abel Tuter","first_name":"Abel","last_name":"Tuter","email":"abel.tuter@example.com","title":"Network Engineer"}]}`))
+	}))
+	defer ts.Close()
+
+	p := servicenow.New(os.Getenv("SERVICENOW_KEY"), os.Getenv("SERVICENOW_SECRET"), "/foo", ts.URL)
+	user, err := p.FetchUser(&servicenow.Session{AccessToken: "1234567890"})
+	a.NoError(err)
+	a.Equal("6816f79cc0a8016401c5a33be04be441", user.UserID)
+	a.Equal("abel.tuter", user.NickName)
+	a.Equal("Abel Tuter", user.Name)
+	a.Equal("Abel", user.FirstName)
+	a.Equal("Tuter", user.LastName)
+	a.Equal("abel.tuter@example.com", user.Email)
+	a.Equal("Network Engineer", user.Description)
+}
+
+func Test_FetchUser_NoRecord(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"result":[]}`))
+	}))
+	defer ts.Close()
+
+	p := servicenow.New(os.Getenv("SERVICENOW_KEY"), os.Getenv("SERVICENOW_SECRET"), "/foo", ts.URL)
+	_, err := p.FetchUser(&servicenow.Session{AccessToken: "1234567890"})
+	a.Error(err)
+}
+
+func Test_RefreshToken(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/oauth_token.do", r.URL.Path)
+		a.NoError(r.ParseForm())
+		a.Equal("0987654321", r.Form.Get("refresh_token"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"1234567890","refresh_token":"0987654321","scope":"useraccount","token_type":"Bearer","expires_in":1799}`))
+	}))
+	defer ts.Close()
+
+	p := servicenow.New(os.Getenv("SERVICENOW_KEY"), os.Getenv("SERVICENOW_SECRET"), "/foo", ts.URL+"/")
+	token, err := p.RefreshToken("0987654321")
+	a.NoError(err)
+	a.Equal("1234567890", token.AccessToken)
+}
+
+func provider() *servicenow.Provider {
+	return servicenow.New(os.Getenv("SERVICENOW_KEY"), os.Getenv("SERVICENOW_SECRET"), "/foo", "https://acme.service-now.com/")
+}
@@ -6,9 +6,8 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"errors"
-	"fmt"
-	"os"
-	"regexp"
+	"net/url"
+	"sort"
 	"strings"
 	"time"
 
@@ -26,6 +25,8 @@ type Session struct {
 	Hostname    string
 	HMAC        string
 	ExpiresAt   time.Time
+	// Shop is the name of the shop the session is for, see BeginAuthForShop.
+	Shop string
 }
 
 var _ goth.Session = &Session{}
@@ -39,35 +40,29 @@ func (s Session) GetAuthURL() (string, error) {
 }
 
 // Authorize the session with Shopify and return the access token to be stored for future use.
+// The access token is requested from the shop given by the shop parameter of the callback.
 func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string, error) {
+	p := provider.(*Provider)
+
 	// Validate the incoming HMAC is valid.
-	// See: https://help.shopify.com/en/api/getting-started/authentication/oauth#verification
-	digest := fmt.Sprintf(
-		"code=%s&shop=%s&state=%s&timestamp=%s",
-		params.Get("code"),
-		params.Get("shop"),
-		params.Get("state"),
-		params.Get("timestamp"),
-	)
-	h := hmac.New(sha256.New, []byte(os.Getenv("SHOPIFY_SECRET")))
-	h.Write([]byte(digest))
-	sha := hex.EncodeToString(h.Sum(nil))
-
-	// Ensure our HMAC hash's match.
-	if sha != params.Get("hmac") {
+	// See: https://shopify.dev/apps/auth/oauth/getting-started#step-2-verify-the-installation-request
+	if !hmac.Equal([]byte(p.sign(params)), []byte(params.Get("hmac"))) {
 		return "", errors.New("Invalid HMAC received")
 	}
 
 	// Validate the hostname matches what we're expecting.
 	// See: https://help.shopify.com/en/api/getting-started/authentication/oauth#step-3-confirm-installation
-	re := regexp.MustCompile(shopifyHostnameRegex)
-	if !re.MatchString(params.Get("shop")) {
+	shop := params.Get("shop")
+	shopName := strings.TrimSuffix(shop, shopDomain)
+	if !strings.HasSuffix(shop, shopDomain) || !validShopName(shopName) {
 		return "", errors.New("Invalid hostname received")
 	}
+	if s.Shop != "" && s.Shop != shopName {
+		return "", errors.New("Hostname received does not match the shop of the session")
+	}
 
 	// Make the exchange for an access token.
-	p := provider.(*Provider)
-	token, err := p.config.Exchange(goth.ContextForClient(p.Client()), params.Get("code"))
+	token, err := p.configForShop(shopName).Exchange(goth.ContextForClient(p.Client()), params.Get("code"))
 	if err != nil {
 		return "", err
 	}
@@ -80,10 +75,38 @@ func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string,
 	s.AccessToken = token.AccessToken
 	s.Hostname = params.Get("hostname")
 	s.HMAC = params.Get("hmac")
+	s.Shop = shopName
 
 	return token.AccessToken, err
 }
 
+// sign computes the HMAC of the parameters of a request from Shopify: all of them but the
+// hmac itself, sorted and joined as a query string. Only the code, shop, state and timestamp
+// can be signed when the parameters are not url.Values.
+func (p *Provider) sign(params goth.Params) string {
+	values, ok := params.(url.Values)
+	if !ok {
+		values = url.Values{}
+		for _, key := range []string{"code", "shop", "state", "timestamp"} {
+			values.Set(key, params.Get(key))
+		}
+	}
+
+	escaper := strings.NewReplacer("%", "%25", "&", "%26", "=", "%3D")
+	pairs := []string{}
+	for key, value := range values {
+		if key == "hmac" || key == "signature" {
+			continue
+		}
+		pairs = append(pairs, escaper.Replace(key)+"="+escaper.Replace(strings.Join(value, ",")))
+	}
+	sort.Strings(pairs)
+
+	h := hmac.New(sha256.New, []byte(p.Secret))
+	h.Write([]byte(strings.Join(pairs, "&")))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
 // Marshal the session into a string
 func (s Session) Marshal() string {
 	b, _ := json.Marshal(s)
@@ -36,7 +36,7 @@ func Test_ToJSON(t *testing.T) {
 	s := &shopify.Session{}
 
 	data := s.Marshal()
-	a.Equal(data, `{"AuthURL":"","AccessToken":"","Hostname":"","HMAC":"","ExpiresAt":"0001-01-01T00:00:00Z"}`)
+	a.Equal(data, `{"AuthURL":"","AccessToken":"","Hostname":"","HMAC":"","ExpiresAt":"0001-01-01T00:00:00Z","Shop":""}`)
 }
 
 func Test_String(t *testing.T) {
@@ -7,7 +7,9 @@ import (
 	"errors"
 	"io"
 	"net/http"
+	"regexp"
 	"strconv"
+	"strings"
 
 	"fmt"
 
@@ -22,6 +24,8 @@ const (
 	authURL         = "myshopify.com/admin/oauth/authorize"
 	tokenURL        = "myshopify.com/admin/oauth/access_token"
 	endpointProfile = "myshopify.com/admin/api/2019-04/shop.json"
+
+	shopDomain = ".myshopify.com"
 )
 
 // Provider is the implementation of `goth.Provider` for accessing Shopify.
@@ -84,6 +88,48 @@ func (p *Provider) BeginAuth(state string) (goth.Session, error) {
 	}, nil
 }
 
+// BeginAuthForShop asks the given shop for an authentication end-point, for apps installed
+// in several shops. The shop is the name of the shop or its myshopify.com domain, as given
+// by the shop parameter of the request installing the app. Authorize checks the callback
+// is from the same shop.
+func (p *Provider) BeginAuthForShop(shop, state string) (goth.Session, error) {
+	shopName := strings.TrimSuffix(shop, shopDomain)
+	if !validShopName(shopName) {
+		return nil, fmt.Errorf("%s cannot authenticate with the invalid shop %q", p.providerName, shop)
+	}
+	return &Session{
+		AuthURL: p.configForShop(shopName).AuthCodeURL(state),
+		Shop:    shopName,
+	}, nil
+}
+
+// BeginAuthWithParams asks the shop given by the shop parameter of the request for an
+// authentication end-point, see BeginAuthForShop, and the shop of the provider when
+// there is none. gothic calls it with the parameters of the request beginning the
+// authentication.
+func (p *Provider) BeginAuthWithParams(state string, params goth.Params) (goth.Session, error) {
+	if shop := params.Get("shop"); shop != "" {
+		return p.BeginAuthForShop(shop, state)
+	}
+	return p.BeginAuth(state)
+}
+
+// validShopName tells whether the name of a shop is a single label of a hostname, so
+// that it can only designate a subdomain of myshopify.com.
+func validShopName(shopName string) bool {
+	return regexp.MustCompile(shopifyHostnameRegex).MatchString(shopName) && !strings.Contains(shopName, ".")
+}
+
+// configForShop returns the config of the provider with the endpoints of the given shop.
+func (p *Provider) configForShop(shopName string) *oauth2.Config {
+	c := *p.config
+	c.Endpoint = oauth2.Endpoint{
+		AuthURL:  fmt.Sprintf("https://%s.%s", shopName, authURL),
+		TokenURL: fmt.Sprintf("https://%s.%s", shopName, tokenURL),
+	}
+	return &c
+}
+
 // RefreshTokenAvailable refresh token is provided by auth provider or not
 func (p *Provider) RefreshTokenAvailable() bool {
 	return false
@@ -107,8 +153,13 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 		return shop, fmt.Errorf("%s cannot get shop information without accessToken", p.providerName)
 	}
 
+	shopName := p.shopName
+	if s.Shop != "" {
+		shopName = s.Shop
+	}
+
 	// Build the request.
-	req, err := http.NewRequest("GET", fmt.Sprintf("https://%s.%s", p.shopName, endpointProfile), nil)
+	req, err := http.NewRequest("GET", fmt.Sprintf("https://%s.%s", shopName, endpointProfile), nil)
 	if err != nil {
 		return shop, err
 	}
@@ -1,6 +1,15 @@
 package shopify_test
 
 import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"testing"
 
@@ -48,6 +57,110 @@ func Test_SessionFromJSON(t *testing.T) {
 	a.Equal(s.AccessToken, "1234567890")
 }
 
+func Test_BeginAuthForShop(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := shopify.New(os.Getenv("SHOPIFY_KEY"), os.Getenv("SHOPIFY_SECRET"), "/foo")
+
+	session, err := p.BeginAuthForShop("other-shop.myshopify.com", "test_state")
+	a.NoError(err)
+	s := session.(*shopify.Session)
+	a.Contains(s.AuthURL, "https://other-shop.myshopify.com/admin/oauth/authorize")
+	a.Equal("other-shop", s.Shop)
+
+	_, err = p.BeginAuthForShop("evil.com/shop", "test_state")
+	a.Error(err)
+}
+
+func Test_BeginAuthWithParams(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	a.Implements((*goth.ParamsProvider)(nil), p)
+
+	session, err := p.BeginAuthWithParams("test_state", url.Values{"shop": {"other-shop.myshopify.com"}})
+	a.NoError(err)
+	a.Contains(session.(*shopify.Session).AuthURL, "https://other-shop.myshopify.com/admin/oauth/authorize")
+	a.Equal("other-shop", session.(*shopify.Session).Shop)
+
+	session, err = p.BeginAuthWithParams("test_state", url.Values{})
+	a.NoError(err)
+	a.Contains(session.(*shopify.Session).AuthURL, "https://test-shop.myshopify.com/admin/oauth/authorize")
+
+	_, err = p.BeginAuthWithParams("test_state", url.Values{"shop": {"evil.com/shop"}})
+	a.Error(err)
+}
+
+func Test_AuthorizeShop(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("other-shop.myshopify.com", r.Host)
+		a.Equal("/admin/oauth/access_token", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"f85632530bf277ec9ac6f649fc327f17","scope":"read_customers"}`))
+	})
+	params := url.Values{
+		"code":      {"0907a61c0c8d55e99db179b68161bc00"},
+		"host":      {"b3RoZXItc2hvcC5teXNob3BpZnkuY29tL2FkbWlu"},
+		"shop":      {"other-shop.myshopify.com"},
+		"state":     {"test_state"},
+		"timestamp": {"1337178173"},
+	}
+	h := hmac.New(sha256.New, []byte("secret"))
+	h.Write([]byte("code=0907a61c0c8d55e99db179b68161bc00&host=b3RoZXItc2hvcC5teXNob3BpZnkuY29tL2FkbWlu&shop=other-shop.myshopify.com&state=test_state&timestamp=1337178173"))
+	params.Set("hmac", hex.EncodeToString(h.Sum(nil)))
+
+	withMockServer(shopify.New("key", "secret", "/foo"), handler, func(p *shopify.Provider) {
+		session, err := p.BeginAuthForShop("other-shop", "test_state")
+		a.NoError(err)
+		s := session.(*shopify.Session)
+		_, err = s.Authorize(p, params)
+		a.NoError(err)
+		a.Equal("f85632530bf277ec9ac6f649fc327f17", s.AccessToken)
+
+		session, _ = p.BeginAuthForShop("test-shop", "test_state")
+		_, err = session.(*shopify.Session).Authorize(p, params)
+		a.Error(err)
+
+		// the shop of the sessions begun without one must be a single label too
+		dotted := url.Values{
+			"code":      {"0907a61c0c8d55e99db179b68161bc00"},
+			"shop":      {"evil.other-shop.myshopify.com"},
+			"state":     {"test_state"},
+			"timestamp": {"1337178173"},
+		}
+		h := hmac.New(sha256.New, []byte("secret"))
+		h.Write([]byte("code=0907a61c0c8d55e99db179b68161bc00&shop=evil.other-shop.myshopify.com&state=test_state&timestamp=1337178173"))
+		dotted.Set("hmac", hex.EncodeToString(h.Sum(nil)))
+		_, err = (&shopify.Session{}).Authorize(p, dotted)
+		a.EqualError(err, "Invalid hostname received")
+
+		params.Set("state", "forged")
+		_, err = (&shopify.Session{}).Authorize(p, params)
+		a.EqualError(err, "Invalid HMAC received")
+	})
+}
+
+func withMockServer(p *shopify.Provider, handler http.Handler, fn func(p *shopify.Provider)) {
+	server := httptest.NewTLSServer(handler)
+	defer server.Close()
+
+	p.HTTPClient = &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return net.Dial(network, server.Listener.Addr().String())
+			},
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		},
+	}
+
+	fn(p)
+}
+
 func provider() *shopify.Provider {
 	p := shopify.New(os.Getenv("SHOPIFY_KEY"), os.Getenv("SHOPIFY_SECRET"), "/foo")
 	p.SetShopName("test-shop")
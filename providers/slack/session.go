@@ -15,6 +15,8 @@ type Session struct {
 	AccessToken  string
 	RefreshToken string
 	ExpiresAt    time.Time
+	// IDToken is only set by Sign in with Slack, see NewOpenID.
+	IDToken string `json:",omitempty"`
 }
 
 var _ goth.Session = &Session{}
@@ -42,6 +44,7 @@ func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string,
 	s.AccessToken = token.AccessToken
 	s.RefreshToken = token.RefreshToken
 	s.ExpiresAt = token.Expiry
+	s.IDToken, _ = token.Extra("id_token").(string)
 	return token.AccessToken, err
 }
 
@@ -19,6 +19,11 @@ import (
 // Scopes
 const (
 	ScopeUserRead string = "users:read"
+
+	// Scopes of Sign in with Slack, see NewOpenID.
+	ScopeOpenID  string = "openid"
+	ScopeEmail   string = "email"
+	ScopeProfile string = "profile"
 )
 
 // URLs and endpoints
@@ -27,6 +32,10 @@ const (
 	tokenURL        string = "https://slack.com/api/oauth.access"
 	endpointUser    string = "https://slack.com/api/auth.test"
 	endpointProfile string = "https://slack.com/api/users.info"
+
+	openIDAuthURL      string = "https://slack.com/openid/connect/authorize"
+	openIDTokenURL     string = "https://slack.com/api/openid.connect.token"
+	openIDEndpointUser string = "https://slack.com/api/openid.connect.userInfo"
 )
 
 // Provider is the implementation of `goth.Provider` for accessing Slack.
@@ -37,6 +46,7 @@ type Provider struct {
 	HTTPClient   *http.Client
 	config       *oauth2.Config
 	providerName string
+	openID       bool
 }
 
 // New creates a new Slack provider and sets up important connection details.
@@ -53,6 +63,24 @@ func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
 	return p
 }
 
+// NewOpenID creates a new Slack provider for Sign in with Slack, which is based on
+// OpenID Connect. It is the recommended way to sign in with Slack for new apps, the
+// identity.* scopes used with New are deprecated. The openid, email and profile
+// scopes are requested when no scopes are given.
+//
+// See https://api.slack.com/authentication/sign-in-with-slack
+func NewOpenID(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "slack",
+		openID:       true,
+	}
+	p.config = newConfig(p, scopes)
+	return p
+}
+
 // Name is the name used to retrieve this provider later.
 func (p *Provider) Name() string {
 	return p.providerName
@@ -93,6 +121,10 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
 	}
 
+	if p.openID {
+		return p.fetchOpenIDUser(sess, user)
+	}
+
 	// Get the userID, slack needs userID in order to get user profile info
 	response, err := p.Client().Get(endpointUser + "?token=" + url.QueryEscape(sess.AccessToken))
 	if err != nil {
@@ -144,6 +176,68 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 	return user, err
 }
 
+// fetchOpenIDUser gets the user from the userinfo endpoint of Sign in with Slack. The
+// ID and name of the team of the user are available in RawData as team_id and team_name.
+func (p *Provider) fetchOpenIDUser(sess *Session, user goth.User) (goth.User, error) {
+	user.IDToken = sess.IDToken
+
+	req, err := http.NewRequest("GET", openIDEndpointUser, nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Set("Authorization", "Bearer "+sess.AccessToken)
+	response, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+	}
+
+	u := struct {
+		OK         bool   `json:"ok"`
+		Error      string `json:"error"`
+		Sub        string `json:"sub"`
+		UserID     string `json:"https://slack.com/user_id"`
+		TeamID     string `json:"https://slack.com/team_id"`
+		TeamName   string `json:"https://slack.com/team_name"`
+		Email      string `json:"email"`
+		Name       string `json:"name"`
+		GivenName  string `json:"given_name"`
+		FamilyName string `json:"family_name"`
+		Picture    string `json:"picture"`
+	}{}
+	bits, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return user, err
+	}
+	if err = json.Unmarshal(bits, &user.RawData); err != nil {
+		return user, err
+	}
+	if err = json.Unmarshal(bits, &u); err != nil {
+		return user, err
+	}
+	if !u.OK {
+		return user, fmt.Errorf("%s responded with an error trying to fetch user information: %s", p.providerName, u.Error)
+	}
+
+	user.UserID = u.UserID
+	if user.UserID == "" {
+		user.UserID = u.Sub
+	}
+	user.Email = u.Email
+	user.Name = u.Name
+	user.NickName = u.Name
+	user.FirstName = u.GivenName
+	user.LastName = u.FamilyName
+	user.AvatarURL = u.Picture
+	user.RawData["team_id"] = u.TeamID
+	user.RawData["team_name"] = u.TeamName
+	return user, nil
+}
+
 func (p *Provider) hasScope(scope string) bool {
 	hasScope := false
 
@@ -169,10 +263,19 @@ func newConfig(provider *Provider, scopes []string) *oauth2.Config {
 		Scopes: []string{},
 	}
 
+	if provider.openID {
+		c.Endpoint = oauth2.Endpoint{
+			AuthURL:  openIDAuthURL,
+			TokenURL: openIDTokenURL,
+		}
+	}
+
 	if len(scopes) > 0 {
 		for _, scope := range scopes {
 			c.Scopes = append(c.Scopes, scope)
 		}
+	} else if provider.openID {
+		c.Scopes = append(c.Scopes, ScopeOpenID, ScopeEmail, ScopeProfile)
 	} else {
 		c.Scopes = append(c.Scopes, ScopeUserRead)
 	}
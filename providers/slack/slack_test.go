@@ -7,6 +7,7 @@ import (
 	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"testing"
 
@@ -198,6 +199,46 @@ func Test_FetchUser(t *testing.T) {
 	}
 }
 
+func Test_OpenID(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := slack.NewOpenID(os.Getenv("SLACK_KEY"), os.Getenv("SLACK_SECRET"), "/foo")
+	session, err := p.BeginAuth("test_state")
+	a.NoError(err)
+	a.Contains(session.(*slack.Session).AuthURL, "slack.com/openid/connect/authorize")
+	a.Contains(session.(*slack.Session).AuthURL, "scope=openid+email+profile")
+
+	handler := http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/api/openid.connect.token":
+			res.Header().Set("Content-Type", "application/json")
+			res.Write([]byte(`{"ok":true,"access_token":"TOKEN","token_type":"Bearer","id_token":"ID_TOKEN"}`))
+		case "/api/openid.connect.userInfo":
+			a.Equal("Bearer TOKEN", req.Header.Get("Authorization"))
+			res.Write([]byte(`{"ok":true,"sub":"U0R7JM","https://slack.com/user_id":"U0R7JM","https://slack.com/team_id":"T0R7GR","https://slack.com/team_name":"Umbrella Corporation","email":"krane@slack-corp.com","name":"krane","picture":"https://secure.gravatar.com/avatar.png","given_name":"Bront","family_name":"Labradoodle"}`))
+		default:
+			res.WriteHeader(http.StatusNotFound)
+		}
+	})
+	withMockServer(p, handler, func(p *slack.Provider) {
+		s := &slack.Session{}
+		_, err := s.Authorize(p, url.Values{"code": {"code"}})
+		a.NoError(err)
+		a.Equal("ID_TOKEN", s.IDToken)
+
+		user, err := p.FetchUser(s)
+		a.NoError(err)
+		a.Equal("U0R7JM", user.UserID)
+		a.Equal("krane@slack-corp.com", user.Email)
+		a.Equal("Bront", user.FirstName)
+		a.Equal("Labradoodle", user.LastName)
+		a.Equal("ID_TOKEN", user.IDToken)
+		a.Equal("T0R7GR", user.RawData["team_id"])
+		a.Equal("Umbrella Corporation", user.RawData["team_name"])
+	})
+}
+
 func Test_SessionFromJSON(t *testing.T) {
 	t.Parallel()
 	a := assert.New(t)
@@ -0,0 +1,192 @@
+// Package snapchat implements the OAuth2 protocol (Login Kit) for authenticating users through Snapchat.
+package snapchat
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+)
+
+// These vars define the Authentication, Token, and Profile URLS for Snapchat.
+var (
+	AuthURL    = "https://accounts.snapchat.com/accounts/oauth2/auth"
+	TokenURL   = "https://accounts.snapchat.com/accounts/oauth2/token"
+	ProfileURL = "https://kit.snapchat.com/v1/me?query=%7Bme%7BdisplayName%2CexternalId%2Cbitmoji%7Bavatar%7D%7D%7D"
+)
+
+const (
+	// ScopeDisplayName grants access to the user's display name.
+	ScopeDisplayName = "https://auth.snapchat.com/oauth2/api/user.display_name"
+	// ScopeExternalID grants access to the user's external ID, unique to the app.
+	ScopeExternalID = "https://auth.snapchat.com/oauth2/api/user.external_id"
+	// ScopeBitmojiAvatar grants access to the user's Bitmoji avatar.
+	ScopeBitmojiAvatar = "https://auth.snapchat.com/oauth2/api/user.bitmoji.avatar"
+)
+
+// Provider is the implementation of `goth.Provider` for accessing Snapchat.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	HTTPClient   *http.Client
+	config       *oauth2.Config
+	providerName string
+}
+
+// New creates a new Snapchat provider and sets up important connection details.
+// You should always call `snapchat.New` to get a new provider.  Never try to
+// create one manually.
+func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "snapchat",
+	}
+	p.config = newConfig(p, scopes)
+	return p
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns an HTTP client to be used in all fetch operations.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// Debug is a no-op for the snapchat package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks Snapchat for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	verifier, err := goth.GenerateCodeVerifier()
+	if err != nil {
+		return nil, err
+	}
+	opts := goth.PKCEChallengeOptions(verifier)
+	return &Session{
+		AuthURL:      p.config.AuthCodeURL(state, opts...),
+		CodeVerifier: verifier,
+	}, nil
+}
+
+// FetchUser will query the Snap Kit /v1/me endpoint for the display name, external ID and Bitmoji avatar of the user.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	req, err := http.NewRequest("GET", ProfileURL, nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+
+	response, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+	}
+
+	bits, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return user, err
+	}
+
+	err = json.NewDecoder(bytes.NewReader(bits)).Decode(&user.RawData)
+	if err != nil {
+		return user, err
+	}
+
+	err = userFromReader(bytes.NewReader(bits), &user)
+	return user, err
+}
+
+func userFromReader(r io.Reader, user *goth.User) error {
+	u := struct {
+		Data struct {
+			Me struct {
+				DisplayName string `json:"displayName"`
+				ExternalID  string `json:"externalId"`
+				Bitmoji     struct {
+					Avatar string `json:"avatar"`
+				} `json:"bitmoji"`
+			} `json:"me"`
+		} `json:"data"`
+	}{}
+	err := json.NewDecoder(r).Decode(&u)
+	if err != nil {
+		return err
+	}
+	user.UserID = u.Data.Me.ExternalID
+	user.Name = u.Data.Me.DisplayName
+	user.NickName = u.Data.Me.DisplayName
+	user.AvatarURL = u.Data.Me.Bitmoji.Avatar
+	return nil
+}
+
+func newConfig(provider *Provider, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   AuthURL,
+			TokenURL:  TokenURL,
+			AuthStyle: oauth2.AuthStyleInHeader,
+		},
+		Scopes: []string{},
+	}
+
+	if len(scopes) > 0 {
+		for _, scope := range scopes {
+			c.Scopes = append(c.Scopes, scope)
+		}
+	} else {
+		c.Scopes = []string{ScopeDisplayName, ScopeExternalID, ScopeBitmojiAvatar}
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	token := &oauth2.Token{RefreshToken: refreshToken}
+	ts := p.config.TokenSource(goth.ContextForClient(p.Client()), token)
+	newToken, err := ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	return newToken, err
+}
@@ -0,0 +1,82 @@
+package snapchat_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/snapchat"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("SNAPCHAT_KEY"))
+	a.Equal(p.Secret, os.Getenv("SNAPCHAT_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	session, err := p.BeginAuth("test_state")
+	s := session.(*snapchat.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "accounts.snapchat.com/accounts/oauth2/auth")
+	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("SNAPCHAT_KEY")))
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "accounts.snapchat.com/accounts/oauth2/auth")
+	a.Contains(s.AuthURL, "user.display_name")
+	a.Contains(s.AuthURL, "code_challenge="+goth.S256CodeChallenge(s.CodeVerifier))
+	a.Contains(s.AuthURL, "code_challenge_method=S256")
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://accounts.snapchat.com/accounts/oauth2/auth","AccessToken":"1234567890"}`)
+	a.NoError(err)
+
+	s := session.(*snapchat.Session)
+	a.Equal(s.AuthURL, "https://accounts.snapchat.com/accounts/oauth2/auth")
+	a.Equal(s.AccessToken, "1234567890")
+}
+
+func Test_FetchUser(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("Bearer 1234567890", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"data":{"me":{"displayName":"Wash","externalId":"CAESIPiRBp0e5gLDq7VVurQ3rVdmdbqxpOJWynjyBL/xlo0w","bitmoji":{"avatar":"http://avatarURL"}}}}`))
+	}))
+	defer ts.Close()
+
+	originalProfileURL := snapchat.ProfileURL
+	snapchat.ProfileURL = ts.URL
+	defer func() { snapchat.ProfileURL = originalProfileURL }()
+
+	user, err := provider().FetchUser(&snapchat.Session{AccessToken: "1234567890"})
+	a.NoError(err)
+	a.Equal("CAESIPiRBp0e5gLDq7VVurQ3rVdmdbqxpOJWynjyBL/xlo0w", user.UserID)
+	a.Equal("Wash", user.Name)
+	a.Equal("http://avatarURL", user.AvatarURL)
+}
+
+func provider() *snapchat.Provider {
+	return snapchat.New(os.Getenv("SNAPCHAT_KEY"), os.Getenv("SNAPCHAT_SECRET"), "/foo")
+}
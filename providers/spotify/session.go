@@ -3,9 +3,11 @@ package spotify
 import (
 	"encoding/json"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
 )
 
 // Session stores data during the auth process with Spotify.
@@ -14,6 +16,8 @@ type Session struct {
 	AccessToken  string
 	RefreshToken string
 	ExpiresAt    time.Time
+	// Scopes are the scopes the user granted, which may differ from the requested ones.
+	Scopes []string
 }
 
 // GetAuthURL will return the URL set by calling the `BeginAuth` function on the
@@ -41,9 +45,16 @@ func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string,
 	s.AccessToken = token.AccessToken
 	s.RefreshToken = token.RefreshToken
 	s.ExpiresAt = token.Expiry
+	s.Scopes = grantedScopes(token)
 	return token.AccessToken, err
 }
 
+// grantedScopes returns the scopes of the token, given as a space separated list.
+func grantedScopes(token *oauth2.Token) []string {
+	scope, _ := token.Extra("scope").(string)
+	return strings.Fields(scope)
+}
+
 // Marshal marshals a session into a JSON string.
 func (s Session) Marshal() string {
 	j, _ := json.Marshal(s)
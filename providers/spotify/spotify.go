@@ -3,9 +3,12 @@
 package spotify
 
 import (
+	"bytes"
 	"encoding/json"
 	"io"
+	"io/ioutil"
 	"net/http"
+	"strings"
 
 	"fmt"
 	"github.com/markbates/goth"
@@ -92,6 +95,12 @@ type Provider struct {
 	HTTPClient   *http.Client
 	config       *oauth2.Config
 	providerName string
+
+	// ShowDialog forces the user to approve the app again, even if they already did,
+	// which lets them switch to another account.
+	ShowDialog bool
+	// Locale is the language of the Spotify login and consent pages, e.g. "es" or "pt-BR".
+	Locale string
 }
 
 // Name gets the name used to retrieve this provider.
@@ -113,7 +122,14 @@ func (p *Provider) Debug(debug bool) {}
 
 // BeginAuth asks Spotify for an authentication end-point.
 func (p *Provider) BeginAuth(state string) (goth.Session, error) {
-	url := p.config.AuthCodeURL(state)
+	opts := []oauth2.AuthCodeOption{}
+	if p.ShowDialog {
+		opts = append(opts, oauth2.SetAuthURLParam("show_dialog", "true"))
+	}
+	if p.Locale != "" {
+		opts = append(opts, oauth2.SetAuthURLParam("locale", p.Locale))
+	}
+	url := p.config.AuthCodeURL(state, opts...)
 	session := &Session{
 		AuthURL: url,
 	}
@@ -121,6 +137,8 @@ func (p *Provider) BeginAuth(state string) (goth.Session, error) {
 }
 
 // FetchUser will go to Spotify and access basic information about the user.
+// The product of the user ("free" or "premium") and the country of their market are
+// available in RawData, as well as the granted scopes under "scopes".
 func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 	s := session.(*Session)
 	user := goth.User{
@@ -150,8 +168,18 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, resp.StatusCode)
 	}
 
-	//err = userFromReader(io.TeeReader(resp.Body, os.Stdout), &user)
-	err = userFromReader(resp.Body, &user)
+	bits, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return user, err
+	}
+
+	err = json.NewDecoder(bytes.NewReader(bits)).Decode(&user.RawData)
+	if err != nil {
+		return user, err
+	}
+	user.RawData["scopes"] = s.Scopes
+
+	err = userFromReader(bytes.NewReader(bits), &user)
 	return user, err
 }
 
@@ -212,7 +240,9 @@ func (p *Provider) RefreshTokenAvailable() bool {
 	return true
 }
 
-//RefreshToken get new access token based on the refresh token
+//RefreshToken get new access token based on the refresh token.
+//Spotify may not return a new refresh token, nor the scopes, the refresh token is kept
+//and the scope extra of the new token is then set to the requested scopes.
 func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
 	token := &oauth2.Token{RefreshToken: refreshToken}
 	ts := p.config.TokenSource(goth.ContextForClient(p.Client()), token)
@@ -220,5 +250,10 @@ func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
 	if err != nil {
 		return nil, err
 	}
+	if newToken.Extra("scope") == nil {
+		newToken = newToken.WithExtra(map[string]interface{}{
+			"scope": strings.Join(p.config.Scopes, " "),
+		})
+	}
 	return newToken, err
 }
@@ -1,6 +1,12 @@
 package spotify_test
 
 import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
 	"testing"
 
@@ -52,3 +58,76 @@ func Test_SessionFromJSON(t *testing.T) {
 	a.Equal(s.AuthURL, "http://accounts.spotify.com/authorize")
 	a.Equal(s.AccessToken, "1234567890")
 }
+
+func Test_BeginAuthWithOptions(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	p.ShowDialog = true
+	p.Locale = "pt-BR"
+	session, err := p.BeginAuth("test_state")
+	a.NoError(err)
+	a.Contains(session.(*spotify.Session).AuthURL, "show_dialog=true")
+	a.Contains(session.(*spotify.Session).AuthURL, "locale=pt-BR")
+}
+
+func Test_AuthorizeAndFetchUser(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/token":
+			w.Header().Set("Content-Type", "application/json")
+			if r.FormValue("grant_type") == "refresh_token" {
+				w.Write([]byte(`{"access_token":"NEW_TOKEN","token_type":"Bearer","expires_in":3600}`))
+				return
+			}
+			w.Write([]byte(`{"access_token":"TOKEN","token_type":"Bearer","expires_in":3600,"refresh_token":"REFRESH","scope":"user-read-email user-read-private"}`))
+		case "/v1/me":
+			a.Equal("Bearer TOKEN", r.Header.Get("Authorization"))
+			w.Write([]byte(`{"id":"wizzler","display_name":"JM Wizzler","email":"email@example.com","country":"SE","product":"premium"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	withMockServer(provider(), handler, func(p *spotify.Provider) {
+		s := &spotify.Session{}
+		_, err := s.Authorize(p, url.Values{"code": {"code"}})
+		a.NoError(err)
+		a.Equal([]string{"user-read-email", "user-read-private"}, s.Scopes)
+
+		user, err := p.FetchUser(s)
+		a.NoError(err)
+		a.Equal("wizzler", user.UserID)
+		a.Equal("premium", user.RawData["product"])
+		a.Equal("SE", user.RawData["country"])
+		a.Equal([]string{"user-read-email", "user-read-private"}, user.RawData["scopes"])
+
+		token, err := p.RefreshToken("REFRESH")
+		a.NoError(err)
+		a.Equal("NEW_TOKEN", token.AccessToken)
+		a.Equal("REFRESH", token.RefreshToken)
+		a.Equal("user-read-email user-read-private user", token.Extra("scope"))
+	})
+}
+
+func withMockServer(p *spotify.Provider, handler http.Handler, fn func(p *spotify.Provider)) {
+	server := httptest.NewTLSServer(handler)
+	defer server.Close()
+
+	p.HTTPClient = &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return net.Dial(network, server.Listener.Addr().String())
+			},
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		},
+	}
+
+	fn(p)
+}
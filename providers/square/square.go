@@ -0,0 +1,268 @@
+// Package square implements the OAuth2 protocol for authenticating merchants through Square.
+//
+// Square has separate hosts for its sandbox and production environments, use New for
+// production and NewSandbox for the sandbox. Square's token endpoint only accepts JSON
+// requests, so the tokens are requested by this package instead of golang.org/x/oauth2.
+package square
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+)
+
+// These are the hosts of the Square environments.
+const (
+	ProductionHost = "https://connect.squareup.com"
+	SandboxHost    = "https://connect.squareupsandbox.com"
+)
+
+// APIVersion is sent as the Square-Version header.
+const APIVersion = "2024-01-18"
+
+const (
+	// ScopeMerchantProfileRead allows to read the merchant's business information.
+	ScopeMerchantProfileRead = "MERCHANT_PROFILE_READ"
+	// ScopePaymentsRead allows to read payments.
+	ScopePaymentsRead = "PAYMENTS_READ"
+	// ScopePaymentsWrite allows to process payments.
+	ScopePaymentsWrite = "PAYMENTS_WRITE"
+)
+
+// Provider is the implementation of `goth.Provider` for accessing Square.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	HTTPClient   *http.Client
+	config       *oauth2.Config
+	providerName string
+	host         string
+}
+
+// New creates a new Square provider for the production environment and sets up
+// important connection details.
+// You should always call `square.New` to get a new provider.  Never try to
+// create one manually.
+func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	return NewCustomisedHost(clientKey, secret, callbackURL, ProductionHost, scopes...)
+}
+
+// NewSandbox is similar to New(...) but uses the sandbox environment.
+func NewSandbox(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	return NewCustomisedHost(clientKey, secret, callbackURL, SandboxHost, scopes...)
+}
+
+// NewCustomisedHost is similar to New(...) but can be used to set a custom host.
+func NewCustomisedHost(clientKey, secret, callbackURL, host string, scopes ...string) *Provider {
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "square",
+		host:         strings.TrimSuffix(host, "/"),
+	}
+	p.config = newConfig(p, scopes)
+	return p
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns an HTTP client to be used in all fetch operations.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// Debug is a no-op for the square package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks Square for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	url := p.config.AuthCodeURL(state,
+		oauth2.SetAuthURLParam("session", "false"),
+	)
+	return &Session{
+		AuthURL: url,
+	}, nil
+}
+
+// FetchUser will go to Square and access the merchant the user signed in as.
+// The merchant id is used as UserID and the business name as Name.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	req, err := http.NewRequest("GET", p.host+"/v2/merchants/me", nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+	req.Header.Add("Square-Version", APIVersion)
+
+	response, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+	}
+
+	bits, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return user, err
+	}
+
+	err = userFromReader(bytes.NewReader(bits), &user)
+	return user, err
+}
+
+// userFromReader reads the merchant from the "merchant" envelope Square wraps it in,
+// the content of the envelope is used as RawData.
+func userFromReader(r io.Reader, user *goth.User) error {
+	envelope := struct {
+		Merchant json.RawMessage `json:"merchant"`
+	}{}
+	err := json.NewDecoder(r).Decode(&envelope)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(envelope.Merchant, &user.RawData)
+	if err != nil {
+		return err
+	}
+
+	m := struct {
+		ID           string `json:"id"`
+		BusinessName string `json:"business_name"`
+		Country      string `json:"country"`
+	}{}
+	err = json.Unmarshal(envelope.Merchant, &m)
+	if err != nil {
+		return err
+	}
+	user.UserID = m.ID
+	user.Name = m.BusinessName
+	user.NickName = m.BusinessName
+	user.Location = m.Country
+	return nil
+}
+
+func newConfig(provider *Provider, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  provider.host + "/oauth2/authorize",
+			TokenURL: provider.host + "/oauth2/token",
+		},
+		Scopes: []string{},
+	}
+
+	if len(scopes) > 0 {
+		for _, scope := range scopes {
+			c.Scopes = append(c.Scopes, scope)
+		}
+	} else {
+		c.Scopes = []string{ScopeMerchantProfileRead}
+	}
+	return c
+}
+
+type tokenResponse struct {
+	AccessToken  string    `json:"access_token"`
+	TokenType    string    `json:"token_type"`
+	ExpiresAt    time.Time `json:"expires_at"`
+	MerchantID   string    `json:"merchant_id"`
+	RefreshToken string    `json:"refresh_token"`
+	ShortLived   bool      `json:"short_lived"`
+}
+
+// requestToken posts a JSON token request for the given grant to Square.
+func (p *Provider) requestToken(grant map[string]string) (*oauth2.Token, error) {
+	grant["client_id"] = p.ClientKey
+	grant["client_secret"] = p.Secret
+
+	body, err := json.Marshal(grant)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", p.config.Endpoint.TokenURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Square-Version", APIVersion)
+
+	response, err := p.Client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s responded with a %d trying to obtain a token", p.providerName, response.StatusCode)
+	}
+
+	tr := tokenResponse{}
+	err = json.NewDecoder(response.Body).Decode(&tr)
+	if err != nil {
+		return nil, err
+	}
+	if tr.AccessToken == "" {
+		return nil, errors.New("Invalid token received from provider")
+	}
+
+	token := &oauth2.Token{
+		AccessToken:  tr.AccessToken,
+		TokenType:    tr.TokenType,
+		RefreshToken: tr.RefreshToken,
+		Expiry:       tr.ExpiresAt,
+	}
+	return token.WithExtra(map[string]interface{}{"merchant_id": tr.MerchantID}), nil
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token.
+// Square access tokens expire after 30 days, refresh tokens are kept until revoked.
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	return p.requestToken(map[string]string{
+		"grant_type":    "refresh_token",
+		"refresh_token": refreshToken,
+	})
+}
@@ -0,0 +1,138 @@
+package square_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/square"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("SQUARE_KEY"))
+	a.Equal(p.Secret, os.Getenv("SQUARE_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+}
+
+func Test_NewSandbox(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := square.NewSandbox(os.Getenv("SQUARE_KEY"), os.Getenv("SQUARE_SECRET"), "/foo")
+	session, err := p.BeginAuth("test_state")
+	s := session.(*square.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://connect.squareupsandbox.com/oauth2/authorize")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	session, err := p.BeginAuth("test_state")
+	s := session.(*square.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://connect.squareup.com/oauth2/authorize")
+	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("SQUARE_KEY")))
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "scope=MERCHANT_PROFILE_READ")
+	a.Contains(s.AuthURL, "session=false")
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://connect.squareup.com/oauth2/authorize","AccessToken":"1234567890"}`)
+	a.NoError(err)
+
+	s := session.(*square.Session)
+	a.Equal(s.AuthURL, "https://connect.squareup.com/oauth2/authorize")
+	a.Equal(s.AccessToken, "1234567890")
+}
+
+func Test_Authorize(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/oauth2/token", r.URL.Path)
+		a.Equal("application/json", r.Header.Get("Content-Type"))
+		body := map[string]string{}
+		a.NoError(json.NewDecoder(r.Body).Decode(&body))
+		a.Equal("authorization_code", body["grant_type"])
+		a.Equal("code", body["code"])
+		a.Equal(os.Getenv("SQUARE_KEY"), body["client_id"])
+		w.Write([]byte(`{"access_token":"EAAAEOuLQObrVwJvCvoio","token_type":"bearer","expires_at":"2099-04-19T22:05:37Z","merchant_id":"6SSW7HV8K2ST5","refresh_token":"EQAAEBWxtm","short_lived":false}`))
+	}))
+	defer ts.Close()
+
+	p := square.NewCustomisedHost(os.Getenv("SQUARE_KEY"), os.Getenv("SQUARE_SECRET"), "/foo", ts.URL)
+	s := &square.Session{}
+	token, err := s.Authorize(p, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("EAAAEOuLQObrVwJvCvoio", token)
+	a.Equal("EQAAEBWxtm", s.RefreshToken)
+	a.Equal(2099, s.ExpiresAt.Year())
+}
+
+func Test_RefreshToken(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := map[string]string{}
+		a.NoError(json.NewDecoder(r.Body).Decode(&body))
+		a.Equal("refresh_token", body["grant_type"])
+		a.Equal("EQAAEBWxtm", body["refresh_token"])
+		w.Write([]byte(`{"access_token":"EAAAEOnew","token_type":"bearer","expires_at":"2099-05-19T22:05:37Z","merchant_id":"6SSW7HV8K2ST5","refresh_token":"EQAAEBWxtm"}`))
+	}))
+	defer ts.Close()
+
+	p := square.NewCustomisedHost(os.Getenv("SQUARE_KEY"), os.Getenv("SQUARE_SECRET"), "/foo", ts.URL)
+	token, err := p.RefreshToken("EQAAEBWxtm")
+	a.NoError(err)
+	a.Equal("EAAAEOnew", token.AccessToken)
+	a.Equal("6SSW7HV8K2ST5", token.Extra("merchant_id"))
+}
+
+func Test_FetchUser(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/v2/merchants/me", r.URL.Path)
+		a.Equal("Bearer 1234567890", r.Header.Get("Authorization"))
+		a.NotEmpty(r.Header.Get("Square-Version"))
+		w.Write([]byte(`{"merchant":{"id":"6SSW7HV8K2ST5","business_name":"Apple A Day","country":"US","language_code":"en-US","currency":"USD","status":"ACTIVE","main_location_id":"L1"}}`))
+	}))
+	defer ts.Close()
+
+	p := square.NewCustomisedHost(os.Getenv("SQUARE_KEY"), os.Getenv("SQUARE_SECRET"), "/foo", ts.URL)
+	user, err := p.FetchUser(&square.Session{AccessToken: "1234567890"})
+	a.NoError(err)
+	a.Equal("6SSW7HV8K2ST5", user.UserID)
+	a.Equal("Apple A Day", user.Name)
+	a.Equal("US", user.Location)
+	a.Equal("L1", user.RawData["main_location_id"])
+}
+
+func provider() *square.Provider {
+	return square.New(os.Getenv("SQUARE_KEY"), os.Getenv("SQUARE_SECRET"), "/foo")
+}
@@ -0,0 +1,261 @@
+// Package squarespace implements the OAuth2 protocol for authenticating websites through Squarespace.
+//
+// Squarespace tokens are issued for a website rather than for a person, the user returned
+// by FetchUser is the website that authorized the app. The token endpoint only accepts
+// JSON requests, so the tokens are requested by this package instead of golang.org/x/oauth2.
+package squarespace
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+)
+
+// These vars define the Authentication, Token, and Website URLS for Squarespace.
+var (
+	AuthURL    = "https://login.squarespace.com/api/1/login/oauth/provider/authorize"
+	TokenURL   = "https://login.squarespace.com/api/1/login/oauth/provider/tokens"
+	WebsiteURL = "https://api.squarespace.com/1.0/authorization/website"
+)
+
+const (
+	// ScopeOrders allows to read and update orders.
+	ScopeOrders = "website.orders"
+	// ScopeOrdersRead allows to read orders.
+	ScopeOrdersRead = "website.orders.read"
+	// ScopeInventory allows to read and update stock levels.
+	ScopeInventory = "website.inventory"
+	// ScopeInventoryRead allows to read stock levels.
+	ScopeInventoryRead = "website.inventory.read"
+	// ScopeProducts allows to read and update products.
+	ScopeProducts = "website.products"
+	// ScopeProductsRead allows to read products.
+	ScopeProductsRead = "website.products.read"
+	// ScopeTransactionsRead allows to read the financial transactions of the website.
+	ScopeTransactionsRead = "website.transactions.read"
+)
+
+// Provider is the implementation of `goth.Provider` for accessing Squarespace.
+type Provider struct {
+	ClientKey   string
+	Secret      string
+	CallbackURL string
+	// UserAgent is sent with every request, Squarespace requires it to identify the app.
+	UserAgent    string
+	HTTPClient   *http.Client
+	config       *oauth2.Config
+	providerName string
+}
+
+// New creates a new Squarespace provider and sets up important connection details.
+// You should always call `squarespace.New` to get a new provider.  Never try to
+// create one manually.
+func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		UserAgent:    "goth",
+		providerName: "squarespace",
+	}
+	p.config = newConfig(p, scopes)
+	return p
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns an HTTP client to be used in all fetch operations.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// Debug is a no-op for the squarespace package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks Squarespace for an authentication end-point.
+// Offline access is requested so that a refresh token is issued.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	url := p.config.AuthCodeURL(state,
+		oauth2.SetAuthURLParam("access_type", "offline"),
+	)
+	return &Session{
+		AuthURL: url,
+	}, nil
+}
+
+// FetchUser will go to Squarespace and access the website that authorized the app.
+// The website id is used as UserID and the site title as Name.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	req, err := http.NewRequest("GET", WebsiteURL, nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+	req.Header.Add("User-Agent", p.UserAgent)
+
+	response, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+	}
+
+	bits, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return user, err
+	}
+
+	err = json.NewDecoder(bytes.NewReader(bits)).Decode(&user.RawData)
+	if err != nil {
+		return user, err
+	}
+
+	err = userFromReader(bytes.NewReader(bits), &user)
+	return user, err
+}
+
+func userFromReader(r io.Reader, user *goth.User) error {
+	u := struct {
+		ID        string `json:"id"`
+		URL       string `json:"url"`
+		SiteTitle string `json:"siteTitle"`
+		Language  string `json:"language"`
+	}{}
+	err := json.NewDecoder(r).Decode(&u)
+	if err != nil {
+		return err
+	}
+	user.UserID = u.ID
+	user.Name = u.SiteTitle
+	user.NickName = u.URL
+	return nil
+}
+
+func newConfig(provider *Provider, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  AuthURL,
+			TokenURL: TokenURL,
+		},
+		Scopes: []string{},
+	}
+
+	if len(scopes) > 0 {
+		for _, scope := range scopes {
+			c.Scopes = append(c.Scopes, scope)
+		}
+	} else {
+		c.Scopes = []string{ScopeOrdersRead}
+	}
+	return c
+}
+
+type tokenResponse struct {
+	AccessToken           string  `json:"access_token"`
+	AccessTokenExpiresAt  float64 `json:"access_token_expires_at"`
+	RefreshToken          string  `json:"refresh_token"`
+	RefreshTokenExpiresAt float64 `json:"refresh_token_expires_at"`
+	TokenType             string  `json:"token_type"`
+}
+
+// requestToken posts a JSON token request for the given grant to Squarespace,
+// the client is authenticated with Basic auth.
+func (p *Provider) requestToken(grant map[string]string) (*oauth2.Token, error) {
+	body, err := json.Marshal(grant)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest("POST", p.config.Endpoint.TokenURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.SetBasicAuth(p.ClientKey, p.Secret)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", p.UserAgent)
+
+	response, err := p.Client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s responded with a %d trying to obtain a token", p.providerName, response.StatusCode)
+	}
+
+	tr := tokenResponse{}
+	err = json.NewDecoder(response.Body).Decode(&tr)
+	if err != nil {
+		return nil, err
+	}
+	if tr.AccessToken == "" {
+		return nil, errors.New("Invalid token received from provider")
+	}
+
+	token := &oauth2.Token{
+		AccessToken:  tr.AccessToken,
+		TokenType:    tr.TokenType,
+		RefreshToken: tr.RefreshToken,
+		Expiry:       epoch(tr.AccessTokenExpiresAt),
+	}
+	return token.WithExtra(map[string]interface{}{"refresh_token_expires_at": epoch(tr.RefreshTokenExpiresAt)}), nil
+}
+
+// epoch converts the fractional unix timestamps of the token response to a time.
+func epoch(seconds float64) time.Time {
+	if seconds == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, int64(seconds*float64(time.Second)))
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token.
+// Squarespace access tokens expire after 30 minutes, the refresh token is rotated on
+// every refresh, so always store the new one.
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	return p.requestToken(map[string]string{
+		"grant_type":    "refresh_token",
+		"refresh_token": refreshToken,
+	})
+}
@@ -0,0 +1,138 @@
+package squarespace_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/squarespace"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("SQUARESPACE_KEY"))
+	a.Equal(p.Secret, os.Getenv("SQUARESPACE_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	session, err := p.BeginAuth("test_state")
+	s := session.(*squarespace.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "login.squarespace.com/api/1/login/oauth/provider/authorize")
+	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("SQUARESPACE_KEY")))
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "scope=website.orders.read")
+	a.Contains(s.AuthURL, "access_type=offline")
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://login.squarespace.com/api/1/login/oauth/provider/authorize","AccessToken":"1234567890"}`)
+	a.NoError(err)
+
+	s := session.(*squarespace.Session)
+	a.Equal(s.AuthURL, "https://login.squarespace.com/api/1/login/oauth/provider/authorize")
+	a.Equal(s.AccessToken, "1234567890")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("application/json", r.Header.Get("Content-Type"))
+		a.Equal("goth", r.Header.Get("User-Agent"))
+		key, secret, ok := r.BasicAuth()
+		a.True(ok)
+		a.Equal(os.Getenv("SQUARESPACE_KEY"), key)
+		a.Equal(os.Getenv("SQUARESPACE_SECRET"), secret)
+		body := map[string]string{}
+		a.NoError(json.NewDecoder(r.Body).Decode(&body))
+		a.Equal("authorization_code", body["grant_type"])
+		a.Equal("code", body["code"])
+		a.Equal("/foo", body["redirect_uri"])
+		w.Write([]byte(`{"token_type":"bearer","access_token":"abc","access_token_expires_at":4102444800.5,"refresh_token":"def","refresh_token_expires_at":4103049600.5,"session_expired":false}`))
+	}))
+	defer ts.Close()
+
+	originalTokenURL := squarespace.TokenURL
+	squarespace.TokenURL = ts.URL
+	defer func() { squarespace.TokenURL = originalTokenURL }()
+
+	s := &squarespace.Session{}
+	token, err := s.Authorize(provider(), url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("abc", token)
+	a.Equal("def", s.RefreshToken)
+	a.Equal(int64(4102444800), s.ExpiresAt.Unix())
+	a.Equal(int64(4103049600), s.RefreshTokenExpiresAt.Unix())
+}
+
+func Test_RefreshToken(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := map[string]string{}
+		a.NoError(json.NewDecoder(r.Body).Decode(&body))
+		a.Equal("refresh_token", body["grant_type"])
+		a.Equal("def", body["refresh_token"])
+		w.Write([]byte(`{"token_type":"bearer","access_token":"new","access_token_expires_at":4102444800,"refresh_token":"ghi","refresh_token_expires_at":4103049600}`))
+	}))
+	defer ts.Close()
+
+	originalTokenURL := squarespace.TokenURL
+	squarespace.TokenURL = ts.URL
+	defer func() { squarespace.TokenURL = originalTokenURL }()
+
+	token, err := provider().RefreshToken("def")
+	a.NoError(err)
+	a.Equal("new", token.AccessToken)
+	a.Equal("ghi", token.RefreshToken)
+}
+
+func Test_FetchUser(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("Bearer 1234567890", r.Header.Get("Authorization"))
+		a.Equal("goth", r.Header.Get("User-Agent"))
+		w.Write([]byte(`{"id":"5e41c6d4b2a3f13e70d3f1c9","url":"https://example.squarespace.com","siteTitle":"Example Store","language":"en-US","timeZone":"America/New_York"}`))
+	}))
+	defer ts.Close()
+
+	originalWebsiteURL := squarespace.WebsiteURL
+	squarespace.WebsiteURL = ts.URL
+	defer func() { squarespace.WebsiteURL = originalWebsiteURL }()
+
+	user, err := provider().FetchUser(&squarespace.Session{AccessToken: "1234567890"})
+	a.NoError(err)
+	a.Equal("5e41c6d4b2a3f13e70d3f1c9", user.UserID)
+	a.Equal("Example Store", user.Name)
+	a.Equal("https://example.squarespace.com", user.NickName)
+	a.Equal("America/New_York", user.RawData["timeZone"])
+}
+
+func provider() *squarespace.Provider {
+	return squarespace.New(os.Getenv("SQUARESPACE_KEY"), os.Getenv("SQUARESPACE_SECRET"), "/foo")
+}
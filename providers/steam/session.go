@@ -4,6 +4,7 @@ package steam
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"net/url"
 	"regexp"
@@ -12,6 +13,14 @@ import (
 	"github.com/markbates/goth"
 )
 
+var (
+	// claimedIDRegExp matches the claimed ID of a Steam account, a URL ending with its 64 bit SteamID.
+	claimedIDRegExp = regexp.MustCompile(`^https?://steamcommunity\.com/openid/id/(7656119[0-9]{10})$`)
+
+	// requiredSignedFields are the fields of the response that must be covered by its signature.
+	requiredSignedFields = []string{"op_endpoint", "claimed_id", "identity", "return_to", "response_nonce", "assoc_handle"}
+)
+
 // Session stores data during the auth process with Steam.
 type Session struct {
 	AuthURL       string
@@ -29,24 +38,51 @@ func (s Session) GetAuthURL() (string, error) {
 }
 
 // Authorize the session with Steam and return the unique response_nonce by OpenID.
+// The response is checked as required by OpenID 2.0 and verified by Steam, each
+// response nonce can only be used once.
 func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string, error) {
 	p := provider.(*Provider)
 	if params.Get("openid.mode") != "id_res" {
 		return "", errors.New("Mode must equal to \"id_res\".")
 	}
 
+	if params.Get("openid.ns") != openIDNs {
+		return "", errors.New("Wrong ns in the request.")
+	}
+
+	if params.Get("openid.op_endpoint") != apiLoginEndpoint {
+		return "", errors.New("The \"op_endpoint\" must be the Steam login endpoint.")
+	}
+
 	if params.Get("openid.return_to") != s.CallbackURL {
 		return "", errors.New("The \"return_to url\" must match the url of current request.")
 	}
 
+	openIDURL := params.Get("openid.claimed_id")
+	matches := claimedIDRegExp.FindStringSubmatch(openIDURL)
+	if matches == nil || params.Get("openid.identity") != openIDURL {
+		return "", errors.New("Invalid Steam ID pattern.")
+	}
+
+	signed := strings.Split(params.Get("openid.signed"), ",")
+	for _, field := range requiredSignedFields {
+		if !contains(signed, field) {
+			return "", fmt.Errorf("The \"%s\" field must be signed.", field)
+		}
+	}
+
+	nonce := params.Get("openid.response_nonce")
+	if err := p.useNonce(nonce); err != nil {
+		return "", err
+	}
+
 	v := make(url.Values)
 	v.Set("openid.assoc_handle", params.Get("openid.assoc_handle"))
 	v.Set("openid.signed", params.Get("openid.signed"))
 	v.Set("openid.sig", params.Get("openid.sig"))
 	v.Set("openid.ns", params.Get("openid.ns"))
 
-	split := strings.Split(params.Get("openid.signed"), ",")
-	for _, item := range split {
+	for _, item := range signed {
 		v.Set("openid."+item, params.Get("openid."+item))
 	}
 	v.Set("openid.mode", "check_authentication")
@@ -61,27 +97,30 @@ func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string,
 		return "", err
 	}
 
-	response := strings.Split(string(content), "\n")
+	response := strings.Split(strings.TrimSpace(string(content)), "\n")
 	if response[0] != "ns:"+openIDNs {
 		return "", errors.New("Wrong ns in the response.")
 	}
 
-	if response[1] == "is_valid:false" {
+	if !contains(response[1:], "is_valid:true") {
 		return "", errors.New("Unable validate openId.")
 	}
 
-	openIDURL := params.Get("openid.claimed_id")
-	validationRegExp := regexp.MustCompile("^(http|https)://steamcommunity.com/openid/id/[0-9]{15,25}$")
-	if !validationRegExp.MatchString(openIDURL) {
-		return "", errors.New("Invalid Steam ID pattern.")
-	}
-
-	s.SteamID = regexp.MustCompile("\\D+").ReplaceAllString(openIDURL, "")
-	s.ResponseNonce = params.Get("openid.response_nonce")
+	s.SteamID = matches[1]
+	s.ResponseNonce = nonce
 
 	return s.ResponseNonce, nil
 }
 
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
 // Marshal the session into a string
 func (s Session) Marshal() string {
 	b, _ := json.Marshal(s)
@@ -2,11 +2,16 @@
 package steam
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"net/http"
 	"net/url"
+	"sync"
+	"time"
 
 	"github.com/markbates/goth"
 	"golang.org/x/oauth2"
@@ -15,17 +20,23 @@ import (
 const (
 	// Steam API Endpoints
 	apiLoginEndpoint       = "https://steamcommunity.com/openid/login"
-	apiUserSummaryEndpoint = "http://api.steampowered.com/ISteamUser/GetPlayerSummaries/v0002/?key=%s&steamids=%s"
+	apiUserSummaryEndpoint = "https://api.steampowered.com/ISteamUser/GetPlayerSummaries/v0002/?key=%s&steamids=%s"
 
 	// OpenID settings
 	openIDMode       = "checkid_setup"
 	openIDNs         = "http://specs.openid.net/auth/2.0"
 	openIDIdentifier = "http://specs.openid.net/auth/2.0/identifier_select"
+
+	// nonceMaxAge is how long after it was issued a response nonce is accepted.
+	nonceMaxAge = 5 * time.Minute
 )
 
 // New creates a new Steam provider, and sets up important connection details.
 // You should always call `steam.New` to get a new Provider. Never try to create
 // one manually.
+//
+// The apiKey is a Steam Web API key, used to get the persona name, avatar and country of
+// the user. Only the SteamID of the user is known when it is empty.
 func New(apiKey string, callbackURL string) *Provider {
 	p := &Provider{
 		APIKey:       apiKey,
@@ -41,6 +52,9 @@ type Provider struct {
 	CallbackURL  string
 	HTTPClient   *http.Client
 	providerName string
+
+	mu     sync.Mutex
+	nonces map[string]time.Time
 }
 
 // Name gets the name used to retrieve this provider.
@@ -101,6 +115,39 @@ func (p *Provider) getAuthURL() (*url.URL, error) {
 	return u, nil
 }
 
+// useNonce checks the response nonce was issued recently and was not used before.
+func (p *Provider) useNonce(nonce string) error {
+	// the nonce starts with the time it was issued, e.g. 2016-03-13T16:56:30Z
+	const issuedLength = len("2006-01-02T15:04:05Z")
+	if len(nonce) < issuedLength {
+		return errors.New("Invalid response nonce.")
+	}
+	issued, err := time.Parse(time.RFC3339, nonce[:issuedLength])
+	if err != nil {
+		return errors.New("Invalid response nonce.")
+	}
+	now := time.Now()
+	if now.Sub(issued) > nonceMaxAge || issued.Sub(now) > time.Minute {
+		return errors.New("The response nonce has expired.")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.nonces == nil {
+		p.nonces = map[string]time.Time{}
+	}
+	for n, t := range p.nonces {
+		if now.Sub(t) > nonceMaxAge {
+			delete(p.nonces, n)
+		}
+	}
+	if _, ok := p.nonces[nonce]; ok {
+		return errors.New("The response nonce was already used.")
+	}
+	p.nonces[nonce] = issued
+	return nil
+}
+
 // FetchUser will go to Steam and access basic info about the user.
 func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 	s := session.(*Session)
@@ -114,6 +161,11 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 		return u, fmt.Errorf("%s cannot get user information without SteamID", p.providerName)
 	}
 
+	if p.APIKey == "" {
+		u.UserID = s.SteamID
+		return u, nil
+	}
+
 	apiURL := fmt.Sprintf(apiUserSummaryEndpoint, p.APIKey, s.SteamID)
 	req, err := http.NewRequest("GET", apiURL, nil)
 	if err != nil {
@@ -133,8 +185,26 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 		return u, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, resp.StatusCode)
 	}
 
-	u, err = buildUserObject(resp.Body, u)
+	bits, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return u, err
+	}
 
+	u, err = buildUserObject(bytes.NewReader(bits), u)
+	if err != nil {
+		return u, err
+	}
+
+	// the player is available as returned by the Steam Web API
+	players := struct {
+		Response struct {
+			Players []map[string]interface{} `json:"players"`
+		} `json:"response"`
+	}{}
+	err = json.Unmarshal(bits, &players)
+	if err == nil {
+		u.RawData = players.Response.Players[0]
+	}
 	return u, err
 }
 
@@ -1,11 +1,19 @@
 package steam_test
 
 import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
 	"github.com/markbates/goth"
 	"github.com/markbates/goth/providers/steam"
 	"github.com/stretchr/testify/assert"
-	"os"
-	"testing"
 )
 
 func Test_New(t *testing.T) {
@@ -49,6 +57,104 @@ func Test_SessionFromJSON(t *testing.T) {
 	a.Equal(s.ResponseNonce, "2016-03-13T16:56:30ZJ8tlKVquwHi9ZSPV4ElU5PY2dmI=")
 }
 
+func Test_Authorize(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/openid/login", r.URL.Path)
+		a.Equal("check_authentication", r.FormValue("openid.mode"))
+		a.Equal("https://steamcommunity.com/openid/id/76561197960435530", r.FormValue("openid.claimed_id"))
+		w.Write([]byte("ns:http://specs.openid.net/auth/2.0\nis_valid:true\n"))
+	})
+	params := func() url.Values {
+		return url.Values{
+			"openid.ns":             {"http://specs.openid.net/auth/2.0"},
+			"openid.mode":           {"id_res"},
+			"openid.op_endpoint":    {"https://steamcommunity.com/openid/login"},
+			"openid.claimed_id":     {"https://steamcommunity.com/openid/id/76561197960435530"},
+			"openid.identity":       {"https://steamcommunity.com/openid/id/76561197960435530"},
+			"openid.return_to":      {"http://localhost:3000/auth/steam/callback"},
+			"openid.response_nonce": {time.Now().UTC().Format(time.RFC3339) + "J8tlKVquwHi9ZSPV4ElU5PY2dmI="},
+			"openid.assoc_handle":   {"1234567890"},
+			"openid.signed":         {"signed,op_endpoint,claimed_id,identity,return_to,response_nonce,assoc_handle"},
+			"openid.sig":            {"W0u5DRbtHE1GG0ZKXjerUZDUGmc="},
+		}
+	}
+
+	withMockServer(steam.New("", "http://localhost:3000/auth/steam/callback"), handler, func(p *steam.Provider) {
+		session, err := p.BeginAuth("")
+		a.NoError(err)
+		s := session.(*steam.Session)
+
+		valid := params()
+		_, err = s.Authorize(p, valid)
+		a.NoError(err)
+		a.Equal("76561197960435530", s.SteamID)
+
+		_, err = s.Authorize(p, valid)
+		a.EqualError(err, "The response nonce was already used.")
+
+		forged := params()
+		forged.Set("openid.claimed_id", "https://steamcommunity.com/openid/id/76561197960435530?evil")
+		_, err = s.Authorize(p, forged)
+		a.EqualError(err, "Invalid Steam ID pattern.")
+
+		unsigned := params()
+		unsigned.Set("openid.signed", "signed,op_endpoint,return_to,response_nonce,assoc_handle")
+		_, err = s.Authorize(p, unsigned)
+		a.EqualError(err, `The "claimed_id" field must be signed.`)
+
+		expired := params()
+		expired.Set("openid.response_nonce", "2016-03-13T16:56:30ZJ8tlKVquwHi9ZSPV4ElU5PY2dmI=")
+		_, err = s.Authorize(p, expired)
+		a.EqualError(err, "The response nonce has expired.")
+	})
+}
+
+func Test_FetchUser(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/ISteamUser/GetPlayerSummaries/v0002/", r.URL.Path)
+		a.Equal("KEY", r.URL.Query().Get("key"))
+		w.Write([]byte(`{"response":{"players":[{"steamid":"76561197960435530","personaname":"Robin","avatarfull":"https://avatars.steamstatic.com/full.jpg","loccountrycode":"US"}]}}`))
+	})
+
+	withMockServer(steam.New("KEY", "/foo"), handler, func(p *steam.Provider) {
+		user, err := p.FetchUser(&steam.Session{SteamID: "76561197960435530"})
+		a.NoError(err)
+		a.Equal("76561197960435530", user.UserID)
+		a.Equal("Robin", user.NickName)
+		a.Equal("https://avatars.steamstatic.com/full.jpg", user.AvatarURL)
+		a.Equal("US", user.Location)
+		a.Equal("US", user.RawData["loccountrycode"])
+	})
+
+	user, err := steam.New("", "/foo").FetchUser(&steam.Session{SteamID: "76561197960435530"})
+	a.NoError(err)
+	a.Equal("76561197960435530", user.UserID)
+}
+
+func withMockServer(p *steam.Provider, handler http.Handler, fn func(p *steam.Provider)) {
+	server := httptest.NewTLSServer(handler)
+	defer server.Close()
+
+	p.HTTPClient = &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return net.Dial(network, server.Listener.Addr().String())
+			},
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		},
+	}
+
+	fn(p)
+}
+
 func provider() *steam.Provider {
 	return steam.New(os.Getenv("STEAM_KEY"), "/foo")
 }
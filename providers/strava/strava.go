@@ -166,12 +166,13 @@ func newConfig(provider *Provider, scopes []string) *oauth2.Config {
 	return c
 }
 
-// RefreshTokenAvailable refresh token is not provided by Strava
+// RefreshTokenAvailable refresh token is provided by Strava
 func (p *Provider) RefreshTokenAvailable() bool {
 	return true
 }
 
-// RefreshToken refresh token is not provided by Strava
+// RefreshToken get new access token based on the refresh token. Strava may rotate the
+// refresh token, the one of the returned token must be stored in place of the old one.
 func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
 	token := &oauth2.Token{RefreshToken: refreshToken}
 	ts := p.config.TokenSource(goth.ContextForClient(p.Client()), token)
@@ -181,3 +182,23 @@ func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
 	}
 	return newToken, err
 }
+
+// RefreshSession refreshes the access token of the session, and stores the new
+// access token, its expiry and the refresh token Strava rotated into the session,
+// which must be saved again afterwards.
+func (p *Provider) RefreshSession(session goth.Session) error {
+	s := session.(*Session)
+	if s.RefreshToken == "" {
+		return fmt.Errorf("%s cannot refresh a session without refreshToken", p.providerName)
+	}
+
+	token, err := p.RefreshToken(s.RefreshToken)
+	if err != nil {
+		return err
+	}
+
+	s.AccessToken = token.AccessToken
+	s.RefreshToken = token.RefreshToken
+	s.ExpiresAt = token.Expiry
+	return nil
+}
@@ -1,9 +1,15 @@
 package strava_test
 
 import (
+	"context"
+	"crypto/tls"
 	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/markbates/goth"
 	"github.com/markbates/goth/providers/strava"
@@ -54,6 +60,48 @@ func Test_SessionFromJSON(t *testing.T) {
 	a.Equal(session.AccessToken, "1234567890")
 }
 
+func Test_RefreshSession(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := strava.New("client", "secret", "/foo")
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/oauth/token", r.URL.Path)
+		a.NoError(r.ParseForm())
+		a.Equal("old", r.PostForm.Get("refresh_token"))
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"token_type":"Bearer","access_token":"access","expires_at":1568775134,"expires_in":20566,"refresh_token":"rotated"}`)
+	}))
+	defer server.Close()
+	provider.HTTPClient = &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return net.Dial(network, server.Listener.Addr().String())
+			},
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		},
+	}
+
+	a.Error(provider.RefreshSession(&strava.Session{}))
+
+	session := &strava.Session{AccessToken: "expired", RefreshToken: "old", ExpiresAt: time.Now().Add(-time.Hour)}
+	before := time.Now()
+	a.NoError(provider.RefreshSession(session))
+	a.Equal("access", session.AccessToken)
+	a.Equal("rotated", session.RefreshToken)
+	a.WithinDuration(before.Add(20566*time.Second), session.ExpiresAt, 5*time.Second)
+
+	// the rotated tokens are saved with the session
+	s, err := provider.UnmarshalSession(session.Marshal())
+	a.NoError(err)
+	saved := s.(*strava.Session)
+	a.Equal("access", saved.AccessToken)
+	a.Equal("rotated", saved.RefreshToken)
+	a.True(saved.ExpiresAt.Equal(session.ExpiresAt))
+}
+
 func stravaProvider() *strava.Provider {
 	return strava.New(os.Getenv("STRAVA_KEY"), os.Getenv("STRAVA_SECRET"), "/foo", "read")
 }
@@ -0,0 +1,164 @@
+package strava
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const pushSubscriptionsURL string = "https://www.strava.com/api/v3/push_subscriptions"
+
+// Subscription is a webhook push subscription of the application, there is at most one
+// per application.
+type Subscription struct {
+	ID            int64     `json:"id"`
+	ApplicationID int64     `json:"application_id"`
+	CallbackURL   string    `json:"callback_url"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// Event is an event pushed by Strava to the callback URL of the subscription, about an
+// activity or an athlete, e.g. a created activity or an athlete revoking the access
+// of the application ("authorized": "false" in Updates).
+type Event struct {
+	ObjectType     string            `json:"object_type"`
+	ObjectID       int64             `json:"object_id"`
+	AspectType     string            `json:"aspect_type"`
+	Updates        map[string]string `json:"updates"`
+	OwnerID        int64             `json:"owner_id"`
+	SubscriptionID int64             `json:"subscription_id"`
+	EventTime      int64             `json:"event_time"`
+}
+
+// CreateSubscription creates the webhook push subscription of the application. Strava
+// validates the callback URL before answering, so it must already be served by a handler
+// validating the subscription with the same verifyToken, see WebhookHandler.
+func (p *Provider) CreateSubscription(callbackURL, verifyToken string) (*Subscription, error) {
+	form := url.Values{
+		"client_id":     {p.ClientKey},
+		"client_secret": {p.Secret},
+		"callback_url":  {callbackURL},
+		"verify_token":  {verifyToken},
+	}
+	response, err := p.Client().PostForm(pushSubscriptionsURL, form)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusCreated && response.StatusCode != http.StatusOK {
+		return nil, p.apiError(response, "create a push subscription")
+	}
+
+	subscription := &Subscription{CallbackURL: callbackURL}
+	err = json.NewDecoder(response.Body).Decode(subscription)
+	return subscription, err
+}
+
+// Subscriptions returns the webhook push subscriptions of the application.
+func (p *Provider) Subscriptions() ([]Subscription, error) {
+	response, err := p.Client().Get(pushSubscriptionsURL + "?" + p.clientCredentials().Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, p.apiError(response, "fetch the push subscriptions")
+	}
+
+	subscriptions := []Subscription{}
+	err = json.NewDecoder(response.Body).Decode(&subscriptions)
+	return subscriptions, err
+}
+
+// DeleteSubscription deletes the webhook push subscription of the application.
+func (p *Provider) DeleteSubscription(id int64) error {
+	reqURL := pushSubscriptionsURL + "/" + strconv.FormatInt(id, 10) + "?" + p.clientCredentials().Encode()
+	req, err := http.NewRequest("DELETE", reqURL, nil)
+	if err != nil {
+		return err
+	}
+	response, err := p.Client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusNoContent && response.StatusCode != http.StatusOK {
+		return p.apiError(response, "delete the push subscription")
+	}
+	return nil
+}
+
+func (p *Provider) clientCredentials() url.Values {
+	return url.Values{
+		"client_id":     {p.ClientKey},
+		"client_secret": {p.Secret},
+	}
+}
+
+// apiError returns the error of a response of Strava, with its message if any.
+func (p *Provider) apiError(response *http.Response, action string) error {
+	err := fmt.Errorf("%s responded with a %d trying to %s", p.providerName, response.StatusCode, action)
+	bits, _ := ioutil.ReadAll(response.Body)
+	e := struct {
+		Message string `json:"message"`
+	}{}
+	if json.Unmarshal(bits, &e) == nil && e.Message != "" {
+		err = fmt.Errorf("%v: %s", err, e.Message)
+	}
+	return err
+}
+
+// ValidateSubscription checks the validation request Strava sends to the callback URL
+// when the subscription is created, and returns the challenge to echo back.
+func ValidateSubscription(r *http.Request, verifyToken string) (string, error) {
+	query := r.URL.Query()
+	if query.Get("hub.mode") != "subscribe" {
+		return "", errors.New("strava: not a subscription validation request")
+	}
+	if query.Get("hub.verify_token") != verifyToken {
+		return "", errors.New("strava: invalid verify token")
+	}
+	challenge := query.Get("hub.challenge")
+	if challenge == "" {
+		return "", errors.New("strava: missing challenge")
+	}
+	return challenge, nil
+}
+
+// WebhookHandler returns the handler of the callback URL of the subscription. It answers
+// the validation request of Strava with verifyToken, and calls handle with each pushed
+// event. Strava expects an answer within two seconds, so handle should not block.
+func WebhookHandler(verifyToken string, handle func(Event)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case "GET":
+			challenge, err := ValidateSubscription(r, verifyToken)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusForbidden)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]string{"hub.challenge": challenge})
+		case "POST":
+			event := Event{}
+			err := json.NewDecoder(r.Body).Decode(&event)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			handle(event)
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+}
@@ -0,0 +1,105 @@
+package strava
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Subscriptions(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := New("client", "secret", "/foo")
+	withMockServer(p, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.True(strings.HasPrefix(r.URL.Path, "/api/v3/push_subscriptions"))
+		switch r.Method {
+		case "POST":
+			a.NoError(r.ParseForm())
+			a.Equal("client", r.PostForm.Get("client_id"))
+			a.Equal("secret", r.PostForm.Get("client_secret"))
+			a.Equal("verify", r.PostForm.Get("verify_token"))
+			if r.PostForm.Get("callback_url") != "https://example.com/strava" {
+				w.WriteHeader(http.StatusBadRequest)
+				fmt.Fprint(w, `{"message":"Bad Request","errors":[{"resource":"PushSubscription","field":"callback url","code":"GET to callback URL does not return 200"}]}`)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"id":120475}`)
+		case "GET":
+			a.Equal("client", r.URL.Query().Get("client_id"))
+			fmt.Fprint(w, `[{"id":120475,"application_id":1,"callback_url":"https://example.com/strava","created_at":"2018-05-01T10:00:00+00:00","updated_at":"2018-05-01T10:00:00+00:00"}]`)
+		case "DELETE":
+			a.Equal("/api/v3/push_subscriptions/120475", r.URL.Path)
+			a.Equal("secret", r.URL.Query().Get("client_secret"))
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}), func(p *Provider) {
+		_, err := p.CreateSubscription("https://example.com/other", "verify")
+		a.EqualError(err, "strava responded with a 400 trying to create a push subscription: Bad Request")
+
+		subscription, err := p.CreateSubscription("https://example.com/strava", "verify")
+		a.NoError(err)
+		a.Equal(int64(120475), subscription.ID)
+		a.Equal("https://example.com/strava", subscription.CallbackURL)
+
+		subscriptions, err := p.Subscriptions()
+		a.NoError(err)
+		a.Len(subscriptions, 1)
+		a.Equal(int64(1), subscriptions[0].ApplicationID)
+
+		a.NoError(p.DeleteSubscription(120475))
+	})
+}
+
+func Test_WebhookHandler(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	events := []Event{}
+	handler := WebhookHandler("verify", func(e Event) {
+		events = append(events, e)
+	})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/strava?hub.verify_token=verify&hub.challenge=15f7d1a91c1f40f8a748fd134752feb3&hub.mode=subscribe", nil))
+	a.Equal(http.StatusOK, w.Code)
+	a.JSONEq(`{"hub.challenge":"15f7d1a91c1f40f8a748fd134752feb3"}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/strava?hub.verify_token=wrong&hub.challenge=abc&hub.mode=subscribe", nil))
+	a.Equal(http.StatusForbidden, w.Code)
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("POST", "/strava", strings.NewReader(`{"aspect_type":"update","event_time":1516126040,"object_id":1360128428,"object_type":"athlete","owner_id":134815,"subscription_id":120475,"updates":{"authorized":"false"}}`)))
+	a.Equal(http.StatusOK, w.Code)
+	a.Len(events, 1)
+	a.Equal("athlete", events[0].ObjectType)
+	a.Equal(int64(134815), events[0].OwnerID)
+	a.Equal("false", events[0].Updates["authorized"])
+}
+
+func withMockServer(p *Provider, handler http.Handler, fn func(p *Provider)) {
+	server := httptest.NewTLSServer(handler)
+	defer server.Close()
+
+	p.HTTPClient = &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return net.Dial(network, server.Listener.Addr().String())
+			},
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		},
+	}
+
+	fn(p)
+}
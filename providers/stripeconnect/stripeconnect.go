@@ -0,0 +1,231 @@
+// Package stripeconnect implements the Stripe Connect OAuth flow for Standard accounts.
+//
+// The provider is configured with the platform's client ID (ca_...) and secret key
+// (sk_...). The connected account ID (acct_...) is used as the UserID.
+package stripeconnect
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+)
+
+// These vars define the Authentication, Token, Deauthorization and Account URLS for Stripe Connect.
+var (
+	AuthURL        = "https://connect.stripe.com/oauth/authorize"
+	TokenURL       = "https://connect.stripe.com/oauth/token"
+	DeauthorizeURL = "https://connect.stripe.com/oauth/deauthorize"
+	AccountURL     = "https://api.stripe.com/v1/accounts/"
+)
+
+const (
+	// ScopeReadWrite allows the platform to act on behalf of the connected account.
+	ScopeReadWrite = "read_write"
+	// ScopeReadOnly allows the platform to read the connected account's data.
+	ScopeReadOnly = "read_only"
+)
+
+// Provider is the implementation of `goth.Provider` for accessing Stripe Connect.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	HTTPClient   *http.Client
+	config       *oauth2.Config
+	providerName string
+}
+
+// New creates a new Stripe Connect provider and sets up important connection details.
+// You should always call `stripeconnect.New` to get a new provider.  Never try to
+// create one manually.
+func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "stripeconnect",
+	}
+	p.config = newConfig(p, scopes)
+	return p
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns an HTTP client to be used in all fetch operations.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// Debug is a no-op for the stripeconnect package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks Stripe Connect for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	return &Session{
+		AuthURL: p.config.AuthCodeURL(state),
+	}, nil
+}
+
+// FetchUser will go to Stripe and access the connected account.
+// The account is read with the platform's secret key, so it works with both live and
+// test mode connections. Whether the connection is in live mode is available in
+// RawData as "livemode".
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+		UserID:       sess.StripeUserID,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+	if sess.StripeUserID == "" {
+		// without it the request would list the accounts of the platform
+		return user, fmt.Errorf("%s cannot get user information without stripe_user_id", p.providerName)
+	}
+
+	req, err := http.NewRequest("GET", AccountURL+url.PathEscape(sess.StripeUserID), nil)
+	if err != nil {
+		return user, err
+	}
+	req.SetBasicAuth(p.Secret, "")
+
+	response, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+	}
+
+	bits, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return user, err
+	}
+
+	err = json.NewDecoder(bytes.NewReader(bits)).Decode(&user.RawData)
+	if err != nil {
+		return user, err
+	}
+	user.RawData["livemode"] = sess.Livemode
+
+	err = userFromReader(bytes.NewReader(bits), &user)
+	return user, err
+}
+
+func userFromReader(r io.Reader, user *goth.User) error {
+	u := struct {
+		ID              string `json:"id"`
+		Email           string `json:"email"`
+		BusinessProfile struct {
+			Name string `json:"name"`
+		} `json:"business_profile"`
+		Settings struct {
+			Dashboard struct {
+				DisplayName string `json:"display_name"`
+			} `json:"dashboard"`
+		} `json:"settings"`
+	}{}
+	err := json.NewDecoder(r).Decode(&u)
+	if err != nil {
+		return err
+	}
+	if u.ID != user.UserID {
+		return fmt.Errorf("stripeconnect: received account %s instead of %s", u.ID, user.UserID)
+	}
+	user.Email = u.Email
+	user.Name = u.BusinessProfile.Name
+	user.NickName = u.Settings.Dashboard.DisplayName
+	if user.Name == "" {
+		user.Name = user.NickName
+	}
+	return nil
+}
+
+func newConfig(provider *Provider, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   AuthURL,
+			TokenURL:  TokenURL,
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+		Scopes: []string{},
+	}
+
+	if len(scopes) > 0 {
+		for _, scope := range scopes {
+			c.Scopes = append(c.Scopes, scope)
+		}
+	} else {
+		c.Scopes = []string{ScopeReadWrite}
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	token := &oauth2.Token{RefreshToken: refreshToken}
+	ts := p.config.TokenSource(goth.ContextForClient(p.Client()), token)
+	newToken, err := ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	return newToken, err
+}
+
+// Deauthorize disconnects the connected account from the platform, revoking the
+// platform's access to it.
+func (p *Provider) Deauthorize(stripeUserID string) error {
+	form := url.Values{
+		"client_id":      {p.ClientKey},
+		"stripe_user_id": {stripeUserID},
+	}
+	req, err := http.NewRequest("POST", DeauthorizeURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(p.Secret, "")
+
+	response, err := p.Client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s responded with a %d trying to deauthorize %s", p.providerName, response.StatusCode, stripeUserID)
+	}
+	return nil
+}
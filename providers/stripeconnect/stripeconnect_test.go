@@ -0,0 +1,132 @@
+package stripeconnect_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/stripeconnect"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("STRIPE_CONNECT_KEY"))
+	a.Equal(p.Secret, os.Getenv("STRIPE_CONNECT_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	session, err := p.BeginAuth("test_state")
+	s := session.(*stripeconnect.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "connect.stripe.com/oauth/authorize")
+	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("STRIPE_CONNECT_KEY")))
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "scope=read_write")
+
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://connect.stripe.com/oauth/authorize","AccessToken":"1234567890"}`)
+	a.NoError(err)
+
+	s := session.(*stripeconnect.Session)
+	a.Equal(s.AuthURL, "https://connect.stripe.com/oauth/authorize")
+	a.Equal(s.AccessToken, "1234567890")
+}
+
+func Test_FetchUser(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/acct_1032D82eZvKYlo2C", r.URL.Path)
+		secret, _, ok := r.BasicAuth()
+		a.True(ok)
+		a.Equal(os.Getenv("STRIPE_CONNECT_SECRET"), secret)
+		w.Write([]byte(`{"id":"acct_1032D82eZvKYlo2C","object":"account","email":"site@stripe.com","business_profile":{"name":"Acme Inc"},"settings":{"dashboard":{"display_name":"Acme"}}}`))
+	}))
+	defer ts.Close()
+
+	originalAccountURL := stripeconnect.AccountURL
+	stripeconnect.AccountURL = ts.URL + "/"
+	defer func() { stripeconnect.AccountURL = originalAccountURL }()
+
+	user, err := provider().FetchUser(&stripeconnect.Session{AccessToken: "1234567890", StripeUserID: "acct_1032D82eZvKYlo2C", Livemode: true})
+	a.NoError(err)
+	a.Equal("acct_1032D82eZvKYlo2C", user.UserID)
+	a.Equal("site@stripe.com", user.Email)
+	a.Equal("Acme Inc", user.Name)
+	a.Equal("Acme", user.NickName)
+	a.Equal(true, user.RawData["livemode"])
+
+	_, err = provider().FetchUser(&stripeconnect.Session{AccessToken: "1234567890"})
+	a.Error(err)
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.NoError(r.ParseForm())
+		a.Equal("authorization_code", r.Form.Get("grant_type"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"sk_test_abc","livemode":false,"refresh_token":"rt_abc","token_type":"bearer","stripe_publishable_key":"pk_test_abc","stripe_user_id":"acct_1032D82eZvKYlo2C","scope":"read_write"}`))
+	}))
+	defer ts.Close()
+
+	originalTokenURL := stripeconnect.TokenURL
+	stripeconnect.TokenURL = ts.URL
+	defer func() { stripeconnect.TokenURL = originalTokenURL }()
+
+	s := &stripeconnect.Session{}
+	token, err := s.Authorize(provider(), url.Values{"code": {"ac_abc"}})
+	a.NoError(err)
+	a.Equal("sk_test_abc", token)
+	a.Equal("acct_1032D82eZvKYlo2C", s.StripeUserID)
+	a.Equal("pk_test_abc", s.StripePublishableKey)
+	a.False(s.Livemode)
+}
+
+func Test_Deauthorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("POST", r.Method)
+		a.NoError(r.ParseForm())
+		a.Equal("acct_1032D82eZvKYlo2C", r.Form.Get("stripe_user_id"))
+		a.Equal(os.Getenv("STRIPE_CONNECT_KEY"), r.Form.Get("client_id"))
+		w.Write([]byte(`{"stripe_user_id":"acct_1032D82eZvKYlo2C"}`))
+	}))
+	defer ts.Close()
+
+	originalDeauthorizeURL := stripeconnect.DeauthorizeURL
+	stripeconnect.DeauthorizeURL = ts.URL
+	defer func() { stripeconnect.DeauthorizeURL = originalDeauthorizeURL }()
+
+	a.NoError(provider().Deauthorize("acct_1032D82eZvKYlo2C"))
+}
+
+func provider() *stripeconnect.Provider {
+	return stripeconnect.New(os.Getenv("STRIPE_CONNECT_KEY"), os.Getenv("STRIPE_CONNECT_SECRET"), "/foo")
+}
@@ -0,0 +1,72 @@
+package telegram
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/markbates/goth"
+)
+
+// Session stores data during the auth process with Telegram.
+type Session struct {
+	AuthURL   string
+	ID        string
+	FirstName string
+	LastName  string
+	Username  string
+	PhotoURL  string
+	AuthDate  int64
+	Hash      string
+}
+
+var _ goth.Session = &Session{}
+
+// GetAuthURL will return the URL set by calling the `BeginAuth` function on the Telegram provider.
+func (s Session) GetAuthURL() (string, error) {
+	if s.AuthURL == "" {
+		return "", errors.New(goth.NoAuthUrlErrorMessage)
+	}
+	return s.AuthURL, nil
+}
+
+// Authorize the session with the login sent by Telegram in the ResultParam parameter and
+// return its hash. The login is only trusted once its hash and freshness have been
+// checked.
+func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string, error) {
+	p := provider.(*Provider)
+	login, err := p.decodeResult(params.Get(ResultParam))
+	if err != nil {
+		return "", err
+	}
+	authDate, err := p.verify(login)
+	if err != nil {
+		return "", err
+	}
+
+	s.ID = login.Get("id")
+	s.FirstName = login.Get("first_name")
+	s.LastName = login.Get("last_name")
+	s.Username = login.Get("username")
+	s.PhotoURL = login.Get("photo_url")
+	s.AuthDate = authDate.Unix()
+	s.Hash = login.Get("hash")
+	return s.Hash, nil
+}
+
+// Marshal the session into a string
+func (s Session) Marshal() string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+func (s Session) String() string {
+	return s.Marshal()
+}
+
+// UnmarshalSession will unmarshal a JSON string into a session.
+func (p *Provider) UnmarshalSession(data string) (goth.Session, error) {
+	s := &Session{}
+	err := json.NewDecoder(strings.NewReader(data)).Decode(s)
+	return s, err
+}
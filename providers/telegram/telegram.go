@@ -0,0 +1,242 @@
+// Package telegram implements authenticating users through the Telegram Login Widget.
+//
+// Telegram does not implement OAuth2: its login page sends the user back to the callback
+// URL with the user's data and a hash signed with the bot token, encoded in the
+// "tgAuthResult" fragment of the URL. The fragment never reaches the server, so the
+// handler of the callback URL must be wrapped with RelayResult, which moves it into the
+// query. Authorize checks the hash, and that the login is recent, before the user is
+// trusted.
+//
+// The Login Widget can be used instead of the login page, as long as the page showing
+// it has begun the authentication, e.g. by calling gothic.GetAuthURL, so that the
+// session of the provider exists. Its data-onauth callback sends the user to the
+// callback URL in the same way:
+//
+//	<script async src="https://telegram.org/js/telegram-widget.js?22"
+//		data-telegram-login="my_bot" data-request-access="write"
+//		data-onauth="location.href = '/auth/telegram/callback?tgAuthResult=' +
+//			encodeURIComponent(btoa(unescape(encodeURIComponent(JSON.stringify(user)))))"></script>
+package telegram
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+)
+
+// AuthURL is the Telegram login page, which is also used by the Login Widget.
+var AuthURL = "https://oauth.telegram.org/auth"
+
+// DefaultMaxAge is how old a login can be before it is rejected by Authorize.
+const DefaultMaxAge = 24 * time.Hour
+
+// ResultParam is the parameter of the callback URL holding the login, as encoded by
+// Telegram.
+const ResultParam = "tgAuthResult"
+
+// relayPage moves the login from the fragment of the callback URL into its query. The
+// parameter is added even without a login, e.g. when the user cancelled it, so that the
+// page is only shown once.
+const relayPage = `<!DOCTYPE html>
+<html><head><meta charset="utf-8"><meta name="referrer" content="no-referrer"></head><body><script>
+var result = location.hash.indexOf("#tgAuthResult=") === 0 ? location.hash.substring(14) : "";
+location.replace(location.pathname + location.search + (location.search ? "&" : "?") + "tgAuthResult=" + encodeURIComponent(result));
+</script></body></html>`
+
+// Provider is the implementation of `goth.Provider` for the Telegram Login Widget.
+type Provider struct {
+	BotToken    string
+	CallbackURL string
+	// MaxAge is how old a login can be, DefaultMaxAge by default.
+	MaxAge       time.Duration
+	HTTPClient   *http.Client
+	providerName string
+}
+
+// New creates a new Telegram provider and sets up important connection details.
+// You should always call `telegram.New` to get a new provider.  Never try to
+// create one manually.
+// The bot token is the one given by @BotFather for the bot linked to the website.
+func New(botToken, callbackURL string) *Provider {
+	return &Provider{
+		BotToken:     botToken,
+		CallbackURL:  callbackURL,
+		MaxAge:       DefaultMaxAge,
+		providerName: "telegram",
+	}
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns an HTTP client to be used in all fetch operations.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// Debug is a no-op for the telegram package.
+func (p *Provider) Debug(debug bool) {}
+
+// RelayResult wraps the handler of the callback URL, e.g. the one calling
+// gothic.CompleteUserAuth, to serve the page moving the login Telegram sends in the
+// fragment of the URL into the query, which then reaches the handler.
+func RelayResult(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.URL.Query()[ResultParam]; ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		io.WriteString(w, relayPage)
+	})
+}
+
+// BeginAuth returns a session pointing at the Telegram login page of the bot, which
+// sends the user back to the callback URL, see RelayResult. Telegram does not send the
+// state back, so it is not part of the URL.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	callbackURL, err := url.Parse(p.CallbackURL)
+	if err != nil {
+		return nil, err
+	}
+
+	v := url.Values{
+		"bot_id":         {strings.SplitN(p.BotToken, ":", 2)[0]},
+		"origin":         {callbackURL.Scheme + "://" + callbackURL.Host},
+		"return_to":      {p.CallbackURL},
+		"request_access": {"write"},
+	}
+	return &Session{
+		AuthURL: AuthURL + "?" + v.Encode(),
+	}, nil
+}
+
+// FetchUser returns the user verified by Authorize, no request is made to Telegram.
+// The signed fields are available in RawData under their name, e.g. "auth_date".
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken: sess.Hash,
+		Provider:    p.Name(),
+	}
+
+	if sess.ID == "" {
+		// data is not yet retrieved since the login is not verified yet
+		return user, fmt.Errorf("%s cannot get user information without a verified login", p.providerName)
+	}
+
+	user.UserID = sess.ID
+	user.FirstName = sess.FirstName
+	user.LastName = sess.LastName
+	user.Name = strings.TrimSpace(sess.FirstName + " " + sess.LastName)
+	user.NickName = sess.Username
+	user.AvatarURL = sess.PhotoURL
+	user.RawData = map[string]interface{}{
+		"id":         sess.ID,
+		"first_name": sess.FirstName,
+		"last_name":  sess.LastName,
+		"username":   sess.Username,
+		"photo_url":  sess.PhotoURL,
+		"auth_date":  sess.AuthDate,
+	}
+	return user, nil
+}
+
+// decodeResult returns the fields of the login sent by Telegram, a JSON object encoded
+// in base64.
+func (p *Provider) decodeResult(result string) (url.Values, error) {
+	if result == "" || result == "false" {
+		return nil, fmt.Errorf("%s login was cancelled", p.providerName)
+	}
+	result = strings.TrimRight(strings.NewReplacer("+", "-", "/", "_").Replace(result), "=")
+	b, err := base64.RawURLEncoding.DecodeString(result)
+	if err != nil {
+		return nil, fmt.Errorf("%s login is not valid base64: %v", p.providerName, err)
+	}
+
+	raw := map[string]interface{}{}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	if err = d.Decode(&raw); err != nil {
+		return nil, fmt.Errorf("%s login is not a JSON object: %v", p.providerName, err)
+	}
+	login := url.Values{}
+	for k, v := range raw {
+		switch v := v.(type) {
+		case string:
+			login.Set(k, v)
+		case json.Number:
+			login.Set(k, v.String())
+		case bool:
+			login.Set(k, strconv.FormatBool(v))
+		}
+	}
+	return login, nil
+}
+
+// verify checks the hash of the login sent by Telegram, which signs all its fields, and
+// the freshness of the login. It returns the time of the login.
+func (p *Provider) verify(login url.Values) (time.Time, error) {
+	lines := []string{}
+	for k := range login {
+		if k != "hash" {
+			lines = append(lines, k+"="+login.Get(k))
+		}
+	}
+	sort.Strings(lines)
+
+	secret := sha256.Sum256([]byte(p.BotToken))
+	mac := hmac.New(sha256.New, secret[:])
+	mac.Write([]byte(strings.Join(lines, "\n")))
+	hash, err := hex.DecodeString(login.Get("hash"))
+	if err != nil || !hmac.Equal(hash, mac.Sum(nil)) {
+		return time.Time{}, fmt.Errorf("%s login has an invalid hash", p.providerName)
+	}
+
+	authDate, err := strconv.ParseInt(login.Get("auth_date"), 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%s login has an invalid auth_date", p.providerName)
+	}
+	t := time.Unix(authDate, 0)
+	maxAge := p.MaxAge
+	if maxAge == 0 {
+		maxAge = DefaultMaxAge
+	}
+	if time.Since(t) > maxAge {
+		return time.Time{}, fmt.Errorf("%s login has expired", p.providerName)
+	}
+	return t, nil
+}
+
+// RefreshTokenAvailable refresh token is not provided by Telegram
+func (p *Provider) RefreshTokenAvailable() bool {
+	return false
+}
+
+// RefreshToken refresh token is not provided by Telegram
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	return nil, errors.New("Refresh token is not provided by Telegram")
+}
@@ -0,0 +1,239 @@
+package telegram_test
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/sessions"
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/gothic"
+	"github.com/markbates/goth/providers/telegram"
+	"github.com/stretchr/testify/assert"
+)
+
+const botToken = "123456789:AAE-0123456789abcdefghijklmnopqrstu"
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.BotToken, os.Getenv("TELEGRAM_BOT_TOKEN"))
+	a.Equal(p.CallbackURL, "https://example.com/auth/telegram/callback")
+	a.Equal(p.MaxAge, telegram.DefaultMaxAge)
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := telegram.New(botToken, "https://example.com/auth/telegram/callback")
+	session, err := p.BeginAuth("test_state")
+	s := session.(*telegram.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://oauth.telegram.org/auth")
+	a.Contains(s.AuthURL, "bot_id=123456789&")
+	a.Contains(s.AuthURL, "origin="+url.QueryEscape("https://example.com"))
+	a.NotContains(s.AuthURL, "AAE-0123456789")
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://oauth.telegram.org/auth","ID":"42","Username":"jdoe"}`)
+	a.NoError(err)
+
+	s := session.(*telegram.Session)
+	a.Equal(s.AuthURL, "https://oauth.telegram.org/auth")
+	a.Equal(s.ID, "42")
+	a.Equal(s.Username, "jdoe")
+}
+
+func Test_Authorize(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := telegram.New(botToken, "https://example.com/auth/telegram/callback")
+	f := login(time.Now())
+	s := &telegram.Session{}
+	hash, err := s.Authorize(p, result(f))
+	a.NoError(err)
+	a.Equal(f["hash"], hash)
+
+	user, err := p.FetchUser(s)
+	a.NoError(err)
+	a.Equal("42", user.UserID)
+	a.Equal("Jane Doe", user.Name)
+	a.Equal("jdoe", user.NickName)
+	a.Equal("https://t.me/i/userpic/320/jdoe.jpg", user.AvatarURL)
+	a.Equal(s.AuthDate, user.RawData["auth_date"])
+}
+
+func Test_AuthorizeWithInvalidHash(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := telegram.New(botToken, "https://example.com/auth/telegram/callback")
+	for _, tamper := range []func(f fields){
+		func(f fields) { f["username"] = "admin" },
+		func(f fields) { delete(f, "allows_write_to_pm") },
+		func(f fields) { f["is_premium"] = true },
+	} {
+		f := login(time.Now())
+		tamper(f)
+		s := &telegram.Session{}
+		_, err := s.Authorize(p, result(f))
+		a.Error(err)
+		a.Empty(s.ID)
+
+		_, err = p.FetchUser(s)
+		a.Error(err)
+	}
+}
+
+func Test_AuthorizeWithExpiredLogin(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := telegram.New(botToken, "https://example.com/auth/telegram/callback")
+	p.MaxAge = time.Hour
+	s := &telegram.Session{}
+	_, err := s.Authorize(p, result(login(time.Now().Add(-2*time.Hour))))
+	a.Error(err)
+	a.Empty(s.ID)
+}
+
+func Test_AuthorizeWithoutLogin(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := telegram.New(botToken, "https://example.com/auth/telegram/callback")
+	for _, params := range []url.Values{
+		{},
+		{"tgAuthResult": {""}},
+		{"tgAuthResult": {"false"}},
+		{"tgAuthResult": {"not base64!"}},
+		{"tgAuthResult": {base64.StdEncoding.EncodeToString([]byte("[]"))}},
+		// the parameters of the Login Widget's data-auth-url are not trusted
+		login(time.Now()).values(),
+	} {
+		s := &telegram.Session{}
+		_, err := s.Authorize(p, params)
+		a.Error(err)
+		a.Empty(s.ID)
+	}
+}
+
+func Test_RelayResult(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	handler := telegram.RelayResult(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "callback")
+	}))
+
+	// the fragment is moved into the query by the page
+	res := httptest.NewRecorder()
+	handler.ServeHTTP(res, httptest.NewRequest("GET", "/auth/telegram/callback", nil))
+	a.Equal("text/html; charset=utf-8", res.Header().Get("Content-Type"))
+	a.Contains(res.Body.String(), "location.hash")
+	a.NotContains(res.Body.String(), "callback")
+
+	res = httptest.NewRecorder()
+	handler.ServeHTTP(res, httptest.NewRequest("GET", "/auth/telegram/callback?tgAuthResult=", nil))
+	a.Equal("callback", res.Body.String())
+}
+
+func Test_CompleteUserAuth(t *testing.T) {
+	a := assert.New(t)
+
+	p := telegram.New(botToken, "https://example.com/auth/telegram/callback")
+	goth.UseProviders(p)
+	defer goth.ClearProviders()
+	gothic.Store = sessions.NewCookieStore([]byte("secret"))
+
+	// the session is created by beginning the authentication, before the login page or
+	// the page showing the Login Widget
+	res := httptest.NewRecorder()
+	authURL, err := gothic.GetAuthURL(res, httptest.NewRequest("GET", "/auth/telegram?provider=telegram", nil))
+	a.NoError(err)
+	a.Contains(authURL, "return_to="+url.QueryEscape("https://example.com/auth/telegram/callback"))
+
+	var user goth.User
+	handler := telegram.RelayResult(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, err = gothic.CompleteUserAuth(w, r)
+	}))
+	req := httptest.NewRequest("GET", "/auth/telegram/callback?provider=telegram&"+result(login(time.Now())).Encode(), nil)
+	req.AddCookie(res.Result().Cookies()[0])
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	a.NoError(err)
+	a.Equal("42", user.UserID)
+	a.Equal("telegram", user.Provider)
+}
+
+func provider() *telegram.Provider {
+	return telegram.New(os.Getenv("TELEGRAM_BOT_TOKEN"), "https://example.com/auth/telegram/callback")
+}
+
+// fields are the fields of a login sent by Telegram.
+type fields map[string]interface{}
+
+// values returns the fields as the parameters of the data-auth-url of the Login Widget.
+func (f fields) values() url.Values {
+	v := url.Values{}
+	for k, value := range f {
+		v.Set(k, fmt.Sprint(value))
+	}
+	return v
+}
+
+// login returns the fields of a login at the given time, signed as Telegram does.
+func login(at time.Time) fields {
+	f := fields{
+		"id":                 42,
+		"first_name":         "Jane",
+		"last_name":          "Doe",
+		"username":           "jdoe",
+		"photo_url":          "https://t.me/i/userpic/320/jdoe.jpg",
+		"auth_date":          at.Unix(),
+		"allows_write_to_pm": true,
+	}
+
+	lines := []string{}
+	for k, v := range f {
+		lines = append(lines, fmt.Sprintf("%s=%v", k, v))
+	}
+	sort.Strings(lines)
+
+	secret := sha256.Sum256([]byte(botToken))
+	mac := hmac.New(sha256.New, secret[:])
+	mac.Write([]byte(strings.Join(lines, "\n")))
+	f["hash"] = hex.EncodeToString(mac.Sum(nil))
+	return f
+}
+
+// result returns the parameters of the callback holding the login, as moved from the
+// fragment of the URL by RelayResult.
+func result(f fields) url.Values {
+	b, _ := json.Marshal(f)
+	return url.Values{"tgAuthResult": {base64.StdEncoding.EncodeToString(b)}}
+}
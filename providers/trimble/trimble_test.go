@@ -0,0 +1,84 @@
+package trimble_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/trimble"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("TRIMBLE_KEY"))
+	a.Equal(p.Secret, os.Getenv("TRIMBLE_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	session, err := p.BeginAuth("test_state")
+	s := session.(*trimble.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "id.trimble.com/oauth/authorize")
+	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("TRIMBLE_KEY")))
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "scope=openid")
+	a.Contains(s.AuthURL, "code_challenge="+goth.S256CodeChallenge(s.CodeVerifier))
+	a.Contains(s.AuthURL, "code_challenge_method=S256")
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://id.trimble.com/oauth/authorize","AccessToken":"1234567890"}`)
+	a.NoError(err)
+
+	s := session.(*trimble.Session)
+	a.Equal(s.AuthURL, "https://id.trimble.com/oauth/authorize")
+	a.Equal(s.AccessToken, "1234567890")
+}
+
+func Test_FetchUser(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("Bearer 1234567890", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"sub":"a1b2c3d4-e5f6-7890-abcd-ef1234567890","given_name":"Jane","family_name":"Doe","email":"jane@example.com","email_verified":true,"picture":"https://id.trimble.com/picture.png","data_region":"us"}`))
+	}))
+	defer ts.Close()
+
+	originalProfileURL := trimble.ProfileURL
+	trimble.ProfileURL = ts.URL
+	defer func() { trimble.ProfileURL = originalProfileURL }()
+
+	user, err := provider().FetchUser(&trimble.Session{AccessToken: "1234567890"})
+	a.NoError(err)
+	a.Equal("a1b2c3d4-e5f6-7890-abcd-ef1234567890", user.UserID)
+	a.Equal("Jane", user.FirstName)
+	a.Equal("Doe", user.LastName)
+	a.Equal("Jane Doe", user.Name)
+	a.Equal("jane@example.com", user.Email)
+	a.Equal("https://id.trimble.com/picture.png", user.AvatarURL)
+}
+
+func provider() *trimble.Provider {
+	return trimble.New(os.Getenv("TRIMBLE_KEY"), os.Getenv("TRIMBLE_SECRET"), "/foo")
+}
@@ -0,0 +1,11 @@
+package twitch
+
+// InvalidTokenError is returned when Twitch reports an access token is not valid anymore,
+// because it expired or the user revoked it, or was issued to another application.
+type InvalidTokenError struct {
+	Message string
+}
+
+func (e InvalidTokenError) Error() string {
+	return "Twitch access token is invalid: " + e.Message
+}
@@ -3,9 +3,11 @@
 package twitch
 
 import (
+	"context"
 	"encoding/json"
 	"io"
 	"net/http"
+	"time"
 
 	"fmt"
 
@@ -17,8 +19,14 @@ const (
 	authURL      string = "https://id.twitch.tv/oauth2/authorize"
 	tokenURL     string = "https://id.twitch.tv/oauth2/token"
 	userEndpoint string = "https://api.twitch.tv/helix/users"
+
+	validateEndpoint string = "https://id.twitch.tv/oauth2/validate"
 )
 
+// validationInterval is how often Twitch requires apps to validate the access tokens of
+// the users who are signed in.
+var validationInterval = time.Hour
+
 const (
 	// ScopeChannelCheckSubscription provides access to read whether a user is
 	// subscribed to your channel.
@@ -142,6 +150,16 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
 	}
 
+	validation, err := p.Validate(s.AccessToken)
+	if err != nil {
+		return user, err
+	}
+	user.RawData = map[string]interface{}{
+		"client_id": validation.ClientID,
+		"login":     validation.Login,
+		"scopes":    validation.Scopes,
+	}
+
 	req, err := http.NewRequest("GET", userEndpoint, nil)
 	if err != nil {
 		return user, err
@@ -162,6 +180,74 @@ func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
 	return user, err
 }
 
+// Validation is the information Twitch returns about a valid access token.
+type Validation struct {
+	ClientID  string   `json:"client_id"`
+	Login     string   `json:"login"`
+	UserID    string   `json:"user_id"`
+	Scopes    []string `json:"scopes"`
+	ExpiresIn int64    `json:"expires_in"`
+}
+
+// Validate checks the access token with Twitch, as FetchUser does. It returns an
+// InvalidTokenError when the token expired, was revoked or was issued to another
+// application.
+//
+// See https://dev.twitch.tv/docs/authentication/validate-tokens
+func (p *Provider) Validate(accessToken string) (*Validation, error) {
+	req, err := http.NewRequest("GET", validateEndpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "OAuth "+accessToken)
+	resp, err := p.Client().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		e := struct {
+			Message string `json:"message"`
+		}{}
+		json.NewDecoder(resp.Body).Decode(&e)
+		return nil, InvalidTokenError{Message: e.Message}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s responded with a %d trying to validate the token", p.providerName, resp.StatusCode)
+	}
+
+	v := &Validation{}
+	err = json.NewDecoder(resp.Body).Decode(v)
+	if err != nil {
+		return nil, err
+	}
+	if v.ClientID != p.ClientKey {
+		return nil, InvalidTokenError{Message: "issued to another client " + v.ClientID}
+	}
+	return v, nil
+}
+
+// Revalidate validates the access token every hour, as Twitch requires for as long as
+// the user is signed in, until the context is done or the token is not valid anymore.
+// It returns the error of the validation, an InvalidTokenError when the token was
+// revoked, or the error of the context. Run it in its own goroutine and sign the user
+// out when it returns an InvalidTokenError.
+func (p *Provider) Revalidate(ctx context.Context, accessToken string) error {
+	ticker := time.NewTicker(validationInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if _, err := p.Validate(accessToken); err != nil {
+				return err
+			}
+		}
+	}
+}
+
 func userFromReader(r io.Reader, user *goth.User) error {
 	u := struct {
 		Data []struct {
@@ -1,8 +1,14 @@
 package twitch
 
 import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/markbates/goth"
 	"github.com/stretchr/testify/assert"
@@ -52,3 +58,78 @@ func Test_SessionFromJSON(t *testing.T) {
 	a.Equal(s.AuthURL, "https://id.twitch.tv/oauth2/authorize")
 	a.Equal(s.AccessToken, "1234567890")
 }
+
+func Test_FetchUserValidatesToken(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/oauth2/validate":
+			if r.Header.Get("Authorization") != "OAuth TOKEN" {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"status":401,"message":"invalid access token"}`))
+				return
+			}
+			w.Write([]byte(`{"client_id":"` + os.Getenv("TWITCH_KEY") + `","login":"twitchdev","scopes":["user:read:email"],"user_id":"141981764","expires_in":5520838}`))
+		case "/helix/users":
+			w.Write([]byte(`{"data":[{"id":"141981764","login":"twitchdev","display_name":"TwitchDev","email":"not-real@email.com"}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	withMockServer(provider(), handler, func(p *Provider) {
+		user, err := p.FetchUser(&Session{AccessToken: "TOKEN"})
+		a.NoError(err)
+		a.Equal("141981764", user.UserID)
+		a.Equal("twitchdev", user.RawData["login"])
+		a.Equal([]string{"user:read:email"}, user.RawData["scopes"])
+
+		_, err = p.FetchUser(&Session{AccessToken: "REVOKED"})
+		a.Equal(InvalidTokenError{Message: "invalid access token"}, err)
+	})
+}
+
+func Test_Revalidate(t *testing.T) {
+	a := assert.New(t)
+
+	interval := validationInterval
+	validationInterval = time.Millisecond
+	defer func() { validationInterval = interval }()
+
+	validations := 0
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		validations++
+		if validations < 3 {
+			w.Write([]byte(`{"client_id":"` + os.Getenv("TWITCH_KEY") + `","login":"twitchdev"}`))
+			return
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"status":401,"message":"invalid access token"}`))
+	})
+
+	withMockServer(provider(), handler, func(p *Provider) {
+		err := p.Revalidate(context.Background(), "TOKEN")
+		a.Equal(InvalidTokenError{Message: "invalid access token"}, err)
+		a.Equal(3, validations)
+	})
+}
+
+func withMockServer(p *Provider, handler http.Handler, fn func(p *Provider)) {
+	server := httptest.NewTLSServer(handler)
+	defer server.Close()
+
+	p.HTTPClient = &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return net.Dial(network, server.Listener.Addr().String())
+			},
+			TLSClientConfig: &tls.Config{
+				InsecureSkipVerify: true,
+			},
+		},
+	}
+
+	fn(p)
+}
@@ -12,6 +12,7 @@ import (
 	"fmt"
 
 	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/twitterv2oauth2"
 	"github.com/mrjones/oauth"
 	"golang.org/x/oauth2"
 )
@@ -53,6 +54,17 @@ func NewAuthenticate(clientKey, secret, callbackURL string) *Provider {
 	return p
 }
 
+// NewOAuth2 creates a Twitter provider using OAuth 2.0 with PKCE instead of OAuth 1.0a,
+// for apps without elevated OAuth 1.0a access. It is named "twitter" so it can replace
+// a provider created with New, see the twitterv2oauth2 package for the details. Users
+// are fetched from /2/users/me and refresh tokens are issued with the offline.access
+// scope, requested with the default scopes.
+func NewOAuth2(clientKey, secret, callbackURL string, scopes ...string) *twitterv2oauth2.Provider {
+	p := twitterv2oauth2.New(clientKey, secret, callbackURL, scopes...)
+	p.SetName("twitter")
+	return p
+}
+
 // Provider is the implementation of `goth.Provider` for accessing Twitter.
 type Provider struct {
 	ClientKey    string
@@ -31,6 +31,22 @@ func Test_Implements_Provider(t *testing.T) {
 	a.Implements((*goth.Provider)(nil), twitterProvider())
 }
 
+func Test_NewOAuth2(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	provider := NewOAuth2(os.Getenv("TWITTER_KEY"), os.Getenv("TWITTER_SECRET"), "/foo")
+	a.Equal("twitter", provider.Name())
+	a.True(provider.RefreshTokenAvailable())
+
+	session, err := provider.BeginAuth("test_state")
+	a.NoError(err)
+	url, err := session.GetAuthURL()
+	a.NoError(err)
+	a.Contains(url, "code_challenge_method=S256")
+	a.Contains(url, "offline.access")
+}
+
 func Test_BeginAuth(t *testing.T) {
 	t.Parallel()
 	a := assert.New(t)
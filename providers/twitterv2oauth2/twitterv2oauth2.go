@@ -0,0 +1,202 @@
+// Package twitterv2oauth2 implements the OAuth 2.0 authorization code flow with PKCE
+// for authenticating users through Twitter (X).
+// Unlike the twitter package, which uses OAuth 1.0a, this package works with apps
+// that only have OAuth 2.0 access, and it supports refresh tokens.
+package twitterv2oauth2
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+)
+
+// These vars define the Authentication, Token, and Profile URLS for Twitter.
+var (
+	AuthURL    = "https://twitter.com/i/oauth2/authorize"
+	TokenURL   = "https://api.twitter.com/2/oauth2/token"
+	ProfileURL = "https://api.twitter.com/2/users/me?user.fields=profile_image_url,description,location,verified"
+)
+
+const (
+	// ScopeTweetRead allows reading Tweets, it is required by /2/users/me.
+	ScopeTweetRead = "tweet.read"
+	// ScopeUsersRead allows reading user profiles.
+	ScopeUsersRead = "users.read"
+	// ScopeOfflineAccess makes Twitter issue a refresh token.
+	ScopeOfflineAccess = "offline.access"
+)
+
+// Provider is the implementation of `goth.Provider` for accessing Twitter with OAuth 2.0.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	HTTPClient   *http.Client
+	config       *oauth2.Config
+	providerName string
+}
+
+// New creates a new Twitter OAuth 2.0 provider and sets up important connection details.
+// You should always call `twitterv2oauth2.New` to get a new provider.  Never try to
+// create one manually.
+// The secret may be empty for public clients (native or single page apps).
+func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "twitterv2oauth2",
+	}
+	p.config = newConfig(p, scopes)
+	return p
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns an HTTP client to be used in all fetch operations.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// Debug is a no-op for the twitterv2oauth2 package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks Twitter for an authentication end-point. PKCE is mandatory,
+// so a code verifier is generated and kept in the session.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	verifier, err := goth.GenerateCodeVerifier()
+	if err != nil {
+		return nil, err
+	}
+	return &Session{
+		AuthURL:      p.config.AuthCodeURL(state, goth.PKCEChallengeOptions(verifier)...),
+		CodeVerifier: verifier,
+	}, nil
+}
+
+// FetchUser will go to Twitter and access basic information about the user.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	req, err := http.NewRequest("GET", ProfileURL, nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+
+	response, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+	}
+
+	bits, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return user, err
+	}
+
+	err = json.NewDecoder(bytes.NewReader(bits)).Decode(&user.RawData)
+	if err != nil {
+		return user, err
+	}
+
+	err = userFromReader(bytes.NewReader(bits), &user)
+	return user, err
+}
+
+func userFromReader(r io.Reader, user *goth.User) error {
+	u := struct {
+		Data struct {
+			ID              string `json:"id"`
+			Name            string `json:"name"`
+			Username        string `json:"username"`
+			Description     string `json:"description"`
+			Location        string `json:"location"`
+			ProfileImageURL string `json:"profile_image_url"`
+		} `json:"data"`
+	}{}
+	err := json.NewDecoder(r).Decode(&u)
+	if err != nil {
+		return err
+	}
+	user.UserID = u.Data.ID
+	user.Name = u.Data.Name
+	user.NickName = u.Data.Username
+	user.Description = u.Data.Description
+	user.Location = u.Data.Location
+	user.AvatarURL = u.Data.ProfileImageURL
+	return nil
+}
+
+func newConfig(provider *Provider, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   AuthURL,
+			TokenURL:  TokenURL,
+			AuthStyle: oauth2.AuthStyleInHeader,
+		},
+		Scopes: []string{},
+	}
+
+	if provider.Secret == "" {
+		c.Endpoint.AuthStyle = oauth2.AuthStyleInParams
+	}
+
+	if len(scopes) > 0 {
+		for _, scope := range scopes {
+			c.Scopes = append(c.Scopes, scope)
+		}
+	} else {
+		c.Scopes = []string{ScopeTweetRead, ScopeUsersRead, ScopeOfflineAccess}
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token.
+// Twitter rotates refresh tokens: the returned token holds the refresh token to use next time.
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	token := &oauth2.Token{RefreshToken: refreshToken}
+	ts := p.config.TokenSource(goth.ContextForClient(p.Client()), token)
+	newToken, err := ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	return newToken, err
+}
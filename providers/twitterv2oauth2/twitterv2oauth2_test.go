@@ -0,0 +1,131 @@
+package twitterv2oauth2_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/twitterv2oauth2"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("TWITTER_OAUTH2_KEY"))
+	a.Equal(p.Secret, os.Getenv("TWITTER_OAUTH2_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	session, err := p.BeginAuth("test_state")
+	s := session.(*twitterv2oauth2.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "twitter.com/i/oauth2/authorize")
+	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("TWITTER_OAUTH2_KEY")))
+	a.Contains(s.AuthURL, "scope=tweet.read+users.read+offline.access")
+	a.Contains(s.AuthURL, "code_challenge="+goth.S256CodeChallenge(s.CodeVerifier))
+	a.Contains(s.AuthURL, "code_challenge_method=S256")
+	a.NotEmpty(s.CodeVerifier)
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://twitter.com/i/oauth2/authorize","CodeVerifier":"verifier","AccessToken":"1234567890"}`)
+	a.NoError(err)
+
+	s := session.(*twitterv2oauth2.Session)
+	a.Equal(s.AuthURL, "https://twitter.com/i/oauth2/authorize")
+	a.Equal(s.CodeVerifier, "verifier")
+	a.Equal(s.AccessToken, "1234567890")
+}
+
+func Test_FetchUser(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("Bearer 1234567890", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"data":{"id":"2244994945","name":"Hoban Washburne","username":"wash","description":"Pilot","location":"Serenity","profile_image_url":"http://avatarURL"}}`))
+	}))
+	defer ts.Close()
+
+	originalProfileURL := twitterv2oauth2.ProfileURL
+	twitterv2oauth2.ProfileURL = ts.URL + "/2/users/me"
+	defer func() { twitterv2oauth2.ProfileURL = originalProfileURL }()
+
+	user, err := provider().FetchUser(&twitterv2oauth2.Session{AccessToken: "1234567890"})
+	a.NoError(err)
+	a.Equal("2244994945", user.UserID)
+	a.Equal("Hoban Washburne", user.Name)
+	a.Equal("wash", user.NickName)
+	a.Equal("Pilot", user.Description)
+	a.Equal("Serenity", user.Location)
+	a.Equal("http://avatarURL", user.AvatarURL)
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("verifier", r.FormValue("code_verifier"))
+		a.Equal("code", r.FormValue("code"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"token_type":"bearer","expires_in":7200,"access_token":"1234567890","refresh_token":"refresh","scope":"tweet.read users.read offline.access"}`))
+	}))
+	defer ts.Close()
+
+	originalTokenURL := twitterv2oauth2.TokenURL
+	twitterv2oauth2.TokenURL = ts.URL
+	p := provider()
+	twitterv2oauth2.TokenURL = originalTokenURL
+
+	s := &twitterv2oauth2.Session{CodeVerifier: "verifier"}
+	token, err := s.Authorize(p, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("1234567890", token)
+	a.Equal("refresh", s.RefreshToken)
+}
+
+func Test_RefreshToken(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("refresh_token", r.FormValue("grant_type"))
+		a.Equal("refresh", r.FormValue("refresh_token"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"token_type":"bearer","expires_in":7200,"access_token":"0987654321","refresh_token":"rotated","scope":"tweet.read users.read offline.access"}`))
+	}))
+	defer ts.Close()
+
+	originalTokenURL := twitterv2oauth2.TokenURL
+	twitterv2oauth2.TokenURL = ts.URL
+	p := provider()
+	twitterv2oauth2.TokenURL = originalTokenURL
+
+	token, err := p.RefreshToken("refresh")
+	a.NoError(err)
+	a.Equal("0987654321", token.AccessToken)
+	a.Equal("rotated", token.RefreshToken)
+}
+
+func provider() *twitterv2oauth2.Provider {
+	return twitterv2oauth2.New(os.Getenv("TWITTER_OAUTH2_KEY"), os.Getenv("TWITTER_OAUTH2_SECRET"), "/foo")
+}
@@ -0,0 +1,107 @@
+package webex_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/webex"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("WEBEX_KEY"))
+	a.Equal(p.Secret, os.Getenv("WEBEX_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	session, err := p.BeginAuth("test_state")
+	s := session.(*webex.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "webexapis.com/v1/authorize")
+	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("WEBEX_KEY")))
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "scope=spark%3Apeople_read")
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://webexapis.com/v1/authorize","AccessToken":"1234567890"}`)
+	a.NoError(err)
+
+	s := session.(*webex.Session)
+	a.Equal(s.AuthURL, "https://webexapis.com/v1/authorize")
+	a.Equal(s.AccessToken, "1234567890")
+}
+
+func Test_FetchUser(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("Bearer 1234567890", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"id":"Y2lzY29zcGFyazovL3VzL1BFT1BMRS9mNWIzNjE4Ny1jOGRkLTQ3MjctOGIyZi1mOWM0NDdmMjkwNDY","emails":["john.andersen@example.com"],"displayName":"John Andersen","nickName":"John","firstName":"John","lastName":"Andersen","avatar":"https://example.com/avatar.png","orgId":"org-1"}`))
+	}))
+	defer ts.Close()
+
+	originalProfileURL := webex.ProfileURL
+	webex.ProfileURL = ts.URL
+	defer func() { webex.ProfileURL = originalProfileURL }()
+
+	user, err := provider().FetchUser(&webex.Session{AccessToken: "1234567890"})
+	a.NoError(err)
+	a.Equal("Y2lzY29zcGFyazovL3VzL1BFT1BMRS9mNWIzNjE4Ny1jOGRkLTQ3MjctOGIyZi1mOWM0NDdmMjkwNDY", user.UserID)
+	a.Equal("john.andersen@example.com", user.Email)
+	a.Equal("John Andersen", user.Name)
+	a.Equal("John", user.NickName)
+	a.Equal("John", user.FirstName)
+	a.Equal("Andersen", user.LastName)
+	a.Equal("https://example.com/avatar.png", user.AvatarURL)
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"1234567890","expires_in":1209600,"refresh_token":"0987654321","refresh_token_expires_in":7776000}`))
+	}))
+	defer ts.Close()
+
+	originalTokenURL := webex.TokenURL
+	webex.TokenURL = ts.URL
+	defer func() { webex.TokenURL = originalTokenURL }()
+
+	s := &webex.Session{}
+	token, err := s.Authorize(provider(), url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("1234567890", token)
+	a.Equal("0987654321", s.RefreshToken)
+	a.WithinDuration(time.Now().Add(14*24*time.Hour), s.ExpiresAt, time.Minute)
+	a.WithinDuration(time.Now().Add(90*24*time.Hour), s.RefreshTokenExpiresAt, time.Minute)
+}
+
+func provider() *webex.Provider {
+	return webex.New(os.Getenv("WEBEX_KEY"), os.Getenv("WEBEX_SECRET"), "/foo")
+}
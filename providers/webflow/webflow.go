@@ -0,0 +1,229 @@
+// Package webflow implements the OAuth2 protocol for authenticating users through Webflow.
+package webflow
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+)
+
+// These vars define the Authentication, Token, Profile, and Introspection URLS for Webflow.
+var (
+	AuthURL       = "https://webflow.com/oauth/authorize"
+	TokenURL      = "https://api.webflow.com/oauth/access_token"
+	ProfileURL    = "https://api.webflow.com/v2/token/authorized_by"
+	IntrospectURL = "https://api.webflow.com/v2/token/introspect"
+)
+
+const (
+	// ScopeAuthorizedUserRead allows to read the user that authorized the app, it is required by FetchUser.
+	ScopeAuthorizedUserRead = "authorized_user:read"
+	// ScopeSitesRead allows to read the authorized sites.
+	ScopeSitesRead = "sites:read"
+	// ScopeSitesWrite allows to update and publish the authorized sites.
+	ScopeSitesWrite = "sites:write"
+	// ScopeWorkspaceRead allows to read the authorized workspaces.
+	ScopeWorkspaceRead = "workspace:read"
+	// ScopeCMSRead allows to read the CMS collections and items.
+	ScopeCMSRead = "cms:read"
+	// ScopeCMSWrite allows to update the CMS collections and items.
+	ScopeCMSWrite = "cms:write"
+)
+
+// Provider is the implementation of `goth.Provider` for accessing Webflow.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	HTTPClient   *http.Client
+	config       *oauth2.Config
+	providerName string
+}
+
+// New creates a new Webflow provider and sets up important connection details.
+// You should always call `webflow.New` to get a new provider.  Never try to
+// create one manually.
+func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "webflow",
+	}
+	p.config = newConfig(p, scopes)
+	return p
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns an HTTP client to be used in all fetch operations.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// Debug is a no-op for the webflow package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks Webflow for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	return &Session{
+		AuthURL: p.config.AuthCodeURL(state),
+	}, nil
+}
+
+// FetchUser will go to Webflow and access basic information about the user that
+// authorized the app, together with the workspaces and sites the app was authorized for.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	req, err := http.NewRequest("GET", ProfileURL, nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+
+	response, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+	}
+
+	bits, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return user, err
+	}
+
+	err = json.NewDecoder(bytes.NewReader(bits)).Decode(&user.RawData)
+	if err != nil {
+		return user, err
+	}
+
+	err = userFromReader(bytes.NewReader(bits), &user)
+	if err != nil {
+		return user, err
+	}
+
+	err = p.addAuthorizedResources(sess.AccessToken, &user)
+	return user, err
+}
+
+// addAuthorizedResources looks up the workspaces and sites the token is authorized for
+// and adds their ids to RawData as "workspace_ids" and "site_ids".
+func (p *Provider) addAuthorizedResources(accessToken string, user *goth.User) error {
+	req, err := http.NewRequest("GET", IntrospectURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Authorization", "Bearer "+accessToken)
+
+	response, err := p.Client().Do(req)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s responded with a %d trying to introspect the token", p.providerName, response.StatusCode)
+	}
+
+	introspection := struct {
+		Authorization struct {
+			AuthorizedTo struct {
+				SiteIDs      []string `json:"siteIds"`
+				WorkspaceIDs []string `json:"workspaceIds"`
+			} `json:"authorizedTo"`
+		} `json:"authorization"`
+	}{}
+	err = json.NewDecoder(response.Body).Decode(&introspection)
+	if err != nil {
+		return err
+	}
+
+	authorizedTo := introspection.Authorization.AuthorizedTo
+	user.RawData["workspace_ids"] = authorizedTo.WorkspaceIDs
+	user.RawData["site_ids"] = authorizedTo.SiteIDs
+	return nil
+}
+
+func userFromReader(r io.Reader, user *goth.User) error {
+	u := struct {
+		ID        string `json:"id"`
+		Email     string `json:"email"`
+		FirstName string `json:"firstName"`
+		LastName  string `json:"lastName"`
+	}{}
+	err := json.NewDecoder(r).Decode(&u)
+	if err != nil {
+		return err
+	}
+	user.UserID = u.ID
+	user.Email = u.Email
+	user.FirstName = u.FirstName
+	user.LastName = u.LastName
+	user.Name = strings.TrimSpace(u.FirstName + " " + u.LastName)
+	return nil
+}
+
+func newConfig(provider *Provider, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   AuthURL,
+			TokenURL:  TokenURL,
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+		Scopes: []string{},
+	}
+
+	if len(scopes) > 0 {
+		for _, scope := range scopes {
+			c.Scopes = append(c.Scopes, scope)
+		}
+	} else {
+		c.Scopes = []string{ScopeAuthorizedUserRead}
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is not provided by Webflow
+func (p *Provider) RefreshTokenAvailable() bool {
+	return false
+}
+
+// RefreshToken refresh token is not provided by Webflow
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	return nil, errors.New("Refresh token is not provided by Webflow")
+}
@@ -0,0 +1,93 @@
+package webflow_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/webflow"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("WEBFLOW_KEY"))
+	a.Equal(p.Secret, os.Getenv("WEBFLOW_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	session, err := p.BeginAuth("test_state")
+	s := session.(*webflow.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "webflow.com/oauth/authorize")
+	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("WEBFLOW_KEY")))
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "scope=authorized_user%3Aread")
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://webflow.com/oauth/authorize","AccessToken":"1234567890"}`)
+	a.NoError(err)
+
+	s := session.(*webflow.Session)
+	a.Equal(s.AuthURL, "https://webflow.com/oauth/authorize")
+	a.Equal(s.AccessToken, "1234567890")
+}
+
+func Test_FetchUser(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("Bearer 1234567890", r.Header.Get("Authorization"))
+		switch r.URL.Path {
+		case "/authorized_by":
+			w.Write([]byte(`{"id":"545bbecb7bdd6769632504a7","email":"some@email.com","firstName":"Some","lastName":"One"}`))
+		case "/introspect":
+			w.Write([]byte(`{"authorization":{"id":"55818d58616600637b9a5786","grantType":"authorization_code","scope":"authorized_user:read,sites:read","authorizedTo":{"siteIds":["62f3b1f6b3b3d1c8e1b3b3b3"],"workspaceIds":["62f3b1f6b3b3d1c8e1b3b3b4"],"userIds":["545bbecb7bdd6769632504a7"]}},"application":{"id":"55131cd036c09f7d07883dfc"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	originalProfileURL := webflow.ProfileURL
+	originalIntrospectURL := webflow.IntrospectURL
+	webflow.ProfileURL = ts.URL + "/authorized_by"
+	webflow.IntrospectURL = ts.URL + "/introspect"
+	defer func() {
+		webflow.ProfileURL = originalProfileURL
+		webflow.IntrospectURL = originalIntrospectURL
+	}()
+
+	user, err := provider().FetchUser(&webflow.Session{AccessToken: "1234567890"})
+	a.NoError(err)
+	a.Equal("545bbecb7bdd6769632504a7", user.UserID)
+	a.Equal("some@email.com", user.Email)
+	a.Equal("Some One", user.Name)
+	a.Equal([]string{"62f3b1f6b3b3d1c8e1b3b3b4"}, user.RawData["workspace_ids"])
+	a.Equal([]string{"62f3b1f6b3b3d1c8e1b3b3b3"}, user.RawData["site_ids"])
+}
+
+func provider() *webflow.Provider {
+	return webflow.New(os.Getenv("WEBFLOW_KEY"), os.Getenv("WEBFLOW_SECRET"), "/foo")
+}
@@ -0,0 +1,74 @@
+package wechat
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/markbates/goth"
+)
+
+// Session stores data during the auth process with WeChat.
+type Session struct {
+	AuthURL      string
+	AccessToken  string
+	RefreshToken string
+	ExpiresAt    time.Time
+	OpenID       string
+	UnionID      string
+}
+
+var _ goth.Session = &Session{}
+
+// GetAuthURL will return the URL set by calling the `BeginAuth` function on the WeChat provider.
+func (s Session) GetAuthURL() (string, error) {
+	if s.AuthURL == "" {
+		return "", errors.New(goth.NoAuthUrlErrorMessage)
+	}
+	return s.AuthURL, nil
+}
+
+// Authorize the session with WeChat and return the access token to be stored for future use.
+func (s *Session) Authorize(provider goth.Provider, params goth.Params) (string, error) {
+	p := provider.(*Provider)
+	t := token{}
+	_, err := p.get(TokenURL, url.Values{
+		"appid":      {p.ClientKey},
+		"secret":     {p.Secret},
+		"code":       {params.Get("code")},
+		"grant_type": {"authorization_code"},
+	}, &t)
+	if err != nil {
+		return "", err
+	}
+
+	if t.AccessToken == "" {
+		return "", errors.New("Invalid token received from provider")
+	}
+
+	s.AccessToken = t.AccessToken
+	s.RefreshToken = t.RefreshToken
+	s.ExpiresAt = t.oauth2Token().Expiry
+	s.OpenID = t.OpenID
+	s.UnionID = t.UnionID
+	return t.AccessToken, err
+}
+
+// Marshal the session into a string
+func (s Session) Marshal() string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+func (s Session) String() string {
+	return s.Marshal()
+}
+
+// UnmarshalSession will unmarshal a JSON string into a session.
+func (p *Provider) UnmarshalSession(data string) (goth.Session, error) {
+	s := &Session{}
+	err := json.NewDecoder(strings.NewReader(data)).Decode(s)
+	return s, err
+}
@@ -0,0 +1,259 @@
+// Package wechat implements the OAuth2 protocol for authenticating users through WeChat.
+// This package can be used as a reference implementation of an OAuth2 provider for Goth.
+package wechat
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+)
+
+// These vars define the URLs of WeChat, they can be changed before calling New.
+var (
+	// QRConnectURL is the web login page, showing a QR code to scan with WeChat.
+	QRConnectURL = "https://open.weixin.qq.com/connect/qrconnect"
+	// AuthURL is the login page of official accounts, opened in the WeChat browser.
+	AuthURL    = "https://open.weixin.qq.com/connect/oauth2/authorize"
+	TokenURL   = "https://api.weixin.qq.com/sns/oauth2/access_token"
+	RefreshURL = "https://api.weixin.qq.com/sns/oauth2/refresh_token"
+	ProfileURL = "https://api.weixin.qq.com/sns/userinfo"
+)
+
+// Scopes
+const (
+	// ScopeLogin is the scope of the web login with a QR code.
+	ScopeLogin string = "snsapi_login"
+	// ScopeBase only gives the OpenID of the user, without asking for consent.
+	ScopeBase string = "snsapi_base"
+	// ScopeUserInfo gives the profile of the user, once they agree.
+	ScopeUserInfo string = "snsapi_userinfo"
+)
+
+// Mode is the way users sign in with WeChat.
+type Mode int
+
+const (
+	// QRConnectMode is the web login of websites, users scan a QR code with the WeChat app.
+	QRConnectMode Mode = iota
+	// OfficialAccountMode is the login of web pages of official accounts, opened in WeChat.
+	OfficialAccountMode
+)
+
+// Provider is the implementation of `goth.Provider` for accessing WeChat.
+type Provider struct {
+	ClientKey   string
+	Secret      string
+	CallbackURL string
+	HTTPClient  *http.Client
+	// Lang is the language of the city, province and country of the user, one of
+	// "zh_CN" (the default), "zh_TW" and "en".
+	Lang         string
+	mode         Mode
+	scope        string
+	providerName string
+}
+
+// New creates a new WeChat provider for the web login with a QR code, and sets up
+// important connection details. The clientKey is the AppID of the website application.
+// You should always call `wechat.New` to get a new provider.  Never try to
+// create one manually.
+func New(clientKey, secret, callbackURL string) *Provider {
+	return NewMode(clientKey, secret, callbackURL, QRConnectMode, ScopeLogin)
+}
+
+// NewMode is similar to New(...) but can be used to select the mode and the scope, e.g.
+// OfficialAccountMode with ScopeUserInfo.
+func NewMode(clientKey, secret, callbackURL string, mode Mode, scope string) *Provider {
+	return &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		mode:         mode,
+		scope:        scope,
+		providerName: "wechat",
+	}
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns an HTTP client to be used in all fetch operations.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// Debug is a no-op for the wechat package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks WeChat for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	authURL := QRConnectURL
+	if p.mode == OfficialAccountMode {
+		authURL = AuthURL
+	}
+
+	// WeChat requires the parameters in this order
+	query := "appid=" + url.QueryEscape(p.ClientKey) +
+		"&redirect_uri=" + url.QueryEscape(p.CallbackURL) +
+		"&response_type=code" +
+		"&scope=" + url.QueryEscape(p.scope) +
+		"&state=" + url.QueryEscape(state)
+	return &Session{
+		AuthURL: authURL + "?" + query + "#wechat_redirect",
+	}, nil
+}
+
+// FetchUser will go to WeChat and access basic information about the user.
+//
+// The UserID is the UnionID of the user when the application is bound to a WeChat Open
+// Platform account, it is the same for the apps, official accounts and websites of
+// this account. Otherwise it is the OpenID of the user, which is specific to the
+// application. Both are available in RawData as "unionid" and "openid".
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+		UserID:       sess.OpenID,
+		RawData: map[string]interface{}{
+			"openid":  sess.OpenID,
+			"unionid": sess.UnionID,
+		},
+	}
+	if sess.UnionID != "" {
+		user.UserID = sess.UnionID
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	if p.scope == ScopeBase {
+		// the profile cannot be fetched with this scope
+		return user, nil
+	}
+
+	params := url.Values{
+		"access_token": {sess.AccessToken},
+		"openid":       {sess.OpenID},
+	}
+	if p.Lang != "" {
+		params.Set("lang", p.Lang)
+	}
+	u := struct {
+		apiError
+		OpenID     string `json:"openid"`
+		UnionID    string `json:"unionid"`
+		Nickname   string `json:"nickname"`
+		City       string `json:"city"`
+		Province   string `json:"province"`
+		Country    string `json:"country"`
+		HeadImgURL string `json:"headimgurl"`
+	}{}
+	raw, err := p.get(ProfileURL, params, &u)
+	if err != nil {
+		return user, fmt.Errorf("%s cannot get user information: %v", p.providerName, err)
+	}
+
+	user.RawData = raw
+	user.NickName = u.Nickname
+	user.Name = u.Nickname
+	user.AvatarURL = u.HeadImgURL
+	user.Location = u.City
+	user.UserID = u.OpenID
+	if u.UnionID != "" {
+		user.UserID = u.UnionID
+	}
+	return user, nil
+}
+
+// apiError is the error WeChat returns with a 200 status.
+type apiError struct {
+	ErrCode int    `json:"errcode"`
+	ErrMsg  string `json:"errmsg"`
+}
+
+// get decodes the response of the WeChat API at the URL with the given parameters
+// into v, and returns it as a map.
+func (p *Provider) get(endpoint string, params url.Values, v interface{}) (map[string]interface{}, error) {
+	response, err := p.Client().Get(endpoint + "?" + params.Encode())
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s responded with a %d", p.providerName, response.StatusCode)
+	}
+
+	raw := map[string]interface{}{}
+	err = json.NewDecoder(response.Body).Decode(&raw)
+	if err != nil {
+		return nil, err
+	}
+	bits, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	e := apiError{}
+	if err = json.Unmarshal(bits, &e); err == nil && e.ErrCode != 0 {
+		return nil, fmt.Errorf("error %d: %s", e.ErrCode, e.ErrMsg)
+	}
+	return raw, json.Unmarshal(bits, v)
+}
+
+// token is the access token response of WeChat.
+type token struct {
+	apiError
+	AccessToken  string `json:"access_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+	OpenID       string `json:"openid"`
+	UnionID      string `json:"unionid"`
+}
+
+func (t token) oauth2Token() *oauth2.Token {
+	return (&oauth2.Token{
+		AccessToken:  t.AccessToken,
+		RefreshToken: t.RefreshToken,
+		Expiry:       time.Now().Add(time.Duration(t.ExpiresIn) * time.Second),
+	}).WithExtra(map[string]interface{}{
+		"openid":  t.OpenID,
+		"unionid": t.UnionID,
+	})
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token, the openid and unionid
+// of the user are available as extras of the token
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	t := token{}
+	_, err := p.get(RefreshURL, url.Values{
+		"appid":         {p.ClientKey},
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	}, &t)
+	if err != nil {
+		return nil, err
+	}
+	return t.oauth2Token(), nil
+}
@@ -0,0 +1,157 @@
+package wechat_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/wechat"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("WECHAT_KEY"))
+	a.Equal(p.Secret, os.Getenv("WECHAT_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	session, err := p.BeginAuth("test_state")
+	s := session.(*wechat.Session)
+	a.NoError(err)
+	a.Equal(s.AuthURL, "https://open.weixin.qq.com/connect/qrconnect?appid="+os.Getenv("WECHAT_KEY")+
+		"&redirect_uri=%2Ffoo&response_type=code&scope=snsapi_login&state=test_state#wechat_redirect")
+}
+
+func Test_BeginAuth_OfficialAccount(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := wechat.NewMode("appid", "secret", "/foo", wechat.OfficialAccountMode, wechat.ScopeUserInfo)
+	session, err := p.BeginAuth("test_state")
+	s := session.(*wechat.Session)
+	a.NoError(err)
+	a.Equal(s.AuthURL, "https://open.weixin.qq.com/connect/oauth2/authorize?appid=appid"+
+		"&redirect_uri=%2Ffoo&response_type=code&scope=snsapi_userinfo&state=test_state#wechat_redirect")
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://open.weixin.qq.com/connect/qrconnect","AccessToken":"1234567890","OpenID":"o1","UnionID":"u1"}`)
+	a.NoError(err)
+
+	s := session.(*wechat.Session)
+	a.Equal(s.AuthURL, "https://open.weixin.qq.com/connect/qrconnect")
+	a.Equal(s.AccessToken, "1234567890")
+	a.Equal(s.OpenID, "o1")
+	a.Equal(s.UnionID, "u1")
+}
+
+// Not parallel, the URLs of the package are swapped.
+func Test_AuthorizeAndFetchUser(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/access_token":
+			if r.URL.Query().Get("code") != "good" {
+				fmt.Fprint(w, `{"errcode":40029,"errmsg":"invalid code"}`)
+				return
+			}
+			a.Equal("appid", r.URL.Query().Get("appid"))
+			a.Equal("secret", r.URL.Query().Get("secret"))
+			fmt.Fprint(w, `{"access_token":"at","expires_in":7200,"refresh_token":"rt","openid":"o1","scope":"snsapi_login","unionid":"u1"}`)
+		case "/userinfo":
+			a.Equal("at", r.URL.Query().Get("access_token"))
+			a.Equal("o1", r.URL.Query().Get("openid"))
+			a.Equal("en", r.URL.Query().Get("lang"))
+			fmt.Fprint(w, `{"openid":"o1","nickname":"Wei","sex":1,"city":"Shenzhen","province":"Guangdong","country":"CN","headimgurl":"https://example.com/a.png","unionid":"u1"}`)
+		case "/refresh_token":
+			a.Equal("refresh_token", r.URL.Query().Get("grant_type"))
+			fmt.Fprint(w, `{"access_token":"at2","expires_in":7200,"refresh_token":"rt","openid":"o1","scope":"snsapi_login"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	tokenURL, refreshURL, profileURL := wechat.TokenURL, wechat.RefreshURL, wechat.ProfileURL
+	wechat.TokenURL, wechat.RefreshURL, wechat.ProfileURL = ts.URL+"/access_token", ts.URL+"/refresh_token", ts.URL+"/userinfo"
+	defer func() {
+		wechat.TokenURL, wechat.RefreshURL, wechat.ProfileURL = tokenURL, refreshURL, profileURL
+	}()
+
+	p := wechat.New("appid", "secret", "/foo")
+	p.Lang = "en"
+
+	s := &wechat.Session{}
+	_, err := s.Authorize(p, url{"code": "bad"})
+	a.EqualError(err, "error 40029: invalid code")
+
+	token, err := s.Authorize(p, url{"code": "good"})
+	a.NoError(err)
+	a.Equal("at", token)
+	a.Equal("rt", s.RefreshToken)
+	a.Equal("o1", s.OpenID)
+	a.Equal("u1", s.UnionID)
+
+	user, err := p.FetchUser(s)
+	a.NoError(err)
+	a.Equal("u1", user.UserID)
+	a.Equal("Wei", user.NickName)
+	a.Equal("Shenzhen", user.Location)
+	a.Equal("https://example.com/a.png", user.AvatarURL)
+	a.Equal("o1", user.RawData["openid"])
+
+	// Without a UnionID, the OpenID identifies the user
+	user, err = p.FetchUser(&wechat.Session{AccessToken: "at", OpenID: "o1"})
+	a.NoError(err)
+	a.Equal("u1", user.UserID)
+
+	base := wechat.NewMode("appid", "secret", "/foo", wechat.OfficialAccountMode, wechat.ScopeBase)
+	user, err = base.FetchUser(&wechat.Session{AccessToken: "at", OpenID: "o1"})
+	a.NoError(err)
+	a.Equal("o1", user.UserID)
+	a.Empty(user.NickName)
+
+	newToken, err := p.RefreshToken("rt")
+	a.NoError(err)
+	a.Equal("at2", newToken.AccessToken)
+	a.Equal("o1", newToken.Extra("openid"))
+}
+
+func Test_FetchUser_NoAccessToken(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	_, err := provider().FetchUser(&wechat.Session{})
+	a.EqualError(err, "wechat cannot get user information without accessToken")
+}
+
+type url map[string]string
+
+func (u url) Get(key string) string {
+	return u[key]
+}
+
+func provider() *wechat.Provider {
+	return wechat.New(os.Getenv("WECHAT_KEY"), os.Getenv("WECHAT_SECRET"), "/foo")
+}
@@ -0,0 +1,103 @@
+package whoop_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/whoop"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("WHOOP_KEY"))
+	a.Equal(p.Secret, os.Getenv("WHOOP_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	session, err := p.BeginAuth("test_state")
+	s := session.(*whoop.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "api.prod.whoop.com/oauth/oauth2/auth")
+	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("WHOOP_KEY")))
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "scope=offline+read%3Aprofile")
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://api.prod.whoop.com/oauth/oauth2/auth","AccessToken":"1234567890"}`)
+	a.NoError(err)
+
+	s := session.(*whoop.Session)
+	a.Equal(s.AuthURL, "https://api.prod.whoop.com/oauth/oauth2/auth")
+	a.Equal(s.AccessToken, "1234567890")
+}
+
+func Test_FetchUser(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("Bearer 1234567890", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"user_id":10129,"email":"jsmith123@whoop.com","first_name":"John","last_name":"Smith"}`))
+	}))
+	defer ts.Close()
+
+	originalProfileURL := whoop.ProfileURL
+	whoop.ProfileURL = ts.URL
+	defer func() { whoop.ProfileURL = originalProfileURL }()
+
+	user, err := provider().FetchUser(&whoop.Session{AccessToken: "1234567890"})
+	a.NoError(err)
+	a.Equal("10129", user.UserID)
+	a.Equal("jsmith123@whoop.com", user.Email)
+	a.Equal("John", user.FirstName)
+	a.Equal("Smith", user.LastName)
+	a.Equal("John Smith", user.Name)
+}
+
+func Test_RefreshToken(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.NoError(r.ParseForm())
+		a.Equal("refresh_token", r.Form.Get("grant_type"))
+		a.Equal("old-refresh-token", r.Form.Get("refresh_token"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"new-access-token","expires_in":3600,"refresh_token":"new-refresh-token","scope":"offline read:profile","token_type":"bearer"}`))
+	}))
+	defer ts.Close()
+
+	originalTokenURL := whoop.TokenURL
+	whoop.TokenURL = ts.URL
+	defer func() { whoop.TokenURL = originalTokenURL }()
+
+	token, err := provider().RefreshToken("old-refresh-token")
+	a.NoError(err)
+	a.Equal("new-access-token", token.AccessToken)
+	a.Equal("new-refresh-token", token.RefreshToken)
+}
+
+func provider() *whoop.Provider {
+	return whoop.New(os.Getenv("WHOOP_KEY"), os.Getenv("WHOOP_SECRET"), "/foo")
+}
@@ -0,0 +1,206 @@
+// Package withings implements the OAuth2 protocol for authenticating users through Withings.
+//
+// The Withings token endpoint is not standard: it needs an action=requesttoken parameter,
+// and it wraps the token in a "body" envelope next to a "status" field, which is non-zero
+// on errors even though the HTTP status is 200. The tokens are therefore requested by
+// this package instead of golang.org/x/oauth2.
+package withings
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+)
+
+// These vars define the Authentication and Token URLS for Withings.
+var (
+	AuthURL  = "https://account.withings.com/oauth2_user/authorize2"
+	TokenURL = "https://wbsapi.withings.net/v2/oauth2"
+)
+
+const (
+	// ScopeUserInfo allows to read the user's profile.
+	ScopeUserInfo = "user.info"
+	// ScopeUserMetrics allows to read the user's measures, such as weight and heart rate.
+	ScopeUserMetrics = "user.metrics"
+	// ScopeUserActivity allows to read the user's activities and sleep.
+	ScopeUserActivity = "user.activity"
+)
+
+// Provider is the implementation of `goth.Provider` for accessing Withings.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	HTTPClient   *http.Client
+	config       *oauth2.Config
+	providerName string
+}
+
+// New creates a new Withings provider and sets up important connection details.
+// You should always call `withings.New` to get a new provider.  Never try to
+// create one manually.
+func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "withings",
+	}
+	p.config = newConfig(p, scopes)
+	return p
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns an HTTP client to be used in all fetch operations.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// Debug is a no-op for the withings package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks Withings for an authentication end-point.
+// Withings expects the scopes to be separated by commas rather than spaces.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	url := p.config.AuthCodeURL(state,
+		oauth2.SetAuthURLParam("scope", strings.Join(p.config.Scopes, ",")),
+	)
+	return &Session{
+		AuthURL: url,
+	}, nil
+}
+
+// FetchUser returns the user as reported in the token response, no request is made to
+// Withings. Only the Withings userid is known, the granted scopes are available in RawData
+// as "scope".
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+		UserID:       sess.UserID,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	user.RawData = map[string]interface{}{
+		"userid": sess.UserID,
+		"scope":  sess.Scope,
+	}
+	return user, nil
+}
+
+func newConfig(provider *Provider, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:  AuthURL,
+			TokenURL: TokenURL,
+		},
+		Scopes: []string{},
+	}
+
+	if len(scopes) > 0 {
+		for _, scope := range scopes {
+			c.Scopes = append(c.Scopes, scope)
+		}
+	} else {
+		c.Scopes = []string{ScopeUserInfo}
+	}
+	return c
+}
+
+type tokenResponse struct {
+	Status int    `json:"status"`
+	Error  string `json:"error"`
+	Body   struct {
+		UserID       string `json:"userid"`
+		AccessToken  string `json:"access_token"`
+		RefreshToken string `json:"refresh_token"`
+		ExpiresIn    int64  `json:"expires_in"`
+		Scope        string `json:"scope"`
+		TokenType    string `json:"token_type"`
+	} `json:"body"`
+}
+
+// requestToken posts a token request with the given grant to Withings and unwraps the
+// token from the "body" envelope.
+func (p *Provider) requestToken(grant url.Values) (*oauth2.Token, error) {
+	grant.Set("action", "requesttoken")
+	grant.Set("client_id", p.ClientKey)
+	grant.Set("client_secret", p.Secret)
+
+	response, err := p.Client().PostForm(p.config.Endpoint.TokenURL, grant)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s responded with a %d trying to obtain a token", p.providerName, response.StatusCode)
+	}
+
+	tr := tokenResponse{}
+	err = json.NewDecoder(response.Body).Decode(&tr)
+	if err != nil {
+		return nil, err
+	}
+	if tr.Status != 0 {
+		return nil, fmt.Errorf("%s responded with status %d trying to obtain a token: %s", p.providerName, tr.Status, tr.Error)
+	}
+	if tr.Body.AccessToken == "" {
+		return nil, errors.New("Invalid token received from provider")
+	}
+
+	token := &oauth2.Token{
+		AccessToken:  tr.Body.AccessToken,
+		TokenType:    tr.Body.TokenType,
+		RefreshToken: tr.Body.RefreshToken,
+	}
+	if tr.Body.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(tr.Body.ExpiresIn) * time.Second)
+	}
+	return token.WithExtra(map[string]interface{}{
+		"userid": tr.Body.UserID,
+		"scope":  tr.Body.Scope,
+	}), nil
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token.
+// Withings access tokens expire after 3 hours, a new refresh token is issued on every
+// refresh and the previous one stops working shortly after, so always store the new one.
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	return p.requestToken(url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+	})
+}
@@ -0,0 +1,143 @@
+package withings_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/withings"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("WITHINGS_KEY"))
+	a.Equal(p.Secret, os.Getenv("WITHINGS_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := withings.New(os.Getenv("WITHINGS_KEY"), os.Getenv("WITHINGS_SECRET"), "/foo", withings.ScopeUserInfo, withings.ScopeUserMetrics)
+	session, err := p.BeginAuth("test_state")
+	s := session.(*withings.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "account.withings.com/oauth2_user/authorize2")
+	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("WITHINGS_KEY")))
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "scope=user.info%2Cuser.metrics")
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://account.withings.com/oauth2_user/authorize2","AccessToken":"1234567890","UserID":"363"}`)
+	a.NoError(err)
+
+	s := session.(*withings.Session)
+	a.Equal(s.AuthURL, "https://account.withings.com/oauth2_user/authorize2")
+	a.Equal(s.AccessToken, "1234567890")
+	a.Equal(s.UserID, "363")
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.NoError(r.ParseForm())
+		a.Equal("requesttoken", r.Form.Get("action"))
+		a.Equal("authorization_code", r.Form.Get("grant_type"))
+		a.Equal("code", r.Form.Get("code"))
+		a.Equal("/foo", r.Form.Get("redirect_uri"))
+		a.Equal(os.Getenv("WITHINGS_SECRET"), r.Form.Get("client_secret"))
+		w.Write([]byte(`{"status":0,"body":{"userid":"363","access_token":"a075f8c14fb8df40b08ebc8508533dc332a6910a","refresh_token":"f631236f02b991810feb774765b6ae8e6c6839ca","expires_in":10800,"scope":"user.info,user.metrics","csrf_token":"PACnnxwHTaBQOzF7bQqwFUUotIuvtzSM","token_type":"Bearer"}}`))
+	}))
+	defer ts.Close()
+
+	originalTokenURL := withings.TokenURL
+	withings.TokenURL = ts.URL
+	defer func() { withings.TokenURL = originalTokenURL }()
+
+	p := provider()
+	s := &withings.Session{}
+	token, err := s.Authorize(p, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("a075f8c14fb8df40b08ebc8508533dc332a6910a", token)
+	a.Equal("f631236f02b991810feb774765b6ae8e6c6839ca", s.RefreshToken)
+	a.Equal("363", s.UserID)
+	a.False(s.ExpiresAt.IsZero())
+
+	user, err := p.FetchUser(s)
+	a.NoError(err)
+	a.Equal("363", user.UserID)
+	a.Equal("user.info,user.metrics", user.RawData["scope"])
+}
+
+func Test_AuthorizeError(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"status":503,"body":{},"error":"Invalid Params: invalid code"}`))
+	}))
+	defer ts.Close()
+
+	originalTokenURL := withings.TokenURL
+	withings.TokenURL = ts.URL
+	defer func() { withings.TokenURL = originalTokenURL }()
+
+	s := &withings.Session{}
+	_, err := s.Authorize(provider(), url.Values{"code": {"code"}})
+	a.Error(err)
+	a.Contains(err.Error(), "invalid code")
+}
+
+func Test_RefreshToken(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.NoError(r.ParseForm())
+		a.Equal("requesttoken", r.Form.Get("action"))
+		a.Equal("refresh_token", r.Form.Get("grant_type"))
+		a.Equal("old", r.Form.Get("refresh_token"))
+		w.Write([]byte(`{"status":0,"body":{"userid":"363","access_token":"new-access","refresh_token":"new-refresh","expires_in":10800,"scope":"user.info","token_type":"Bearer"}}`))
+	}))
+	defer ts.Close()
+
+	originalTokenURL := withings.TokenURL
+	withings.TokenURL = ts.URL
+	defer func() { withings.TokenURL = originalTokenURL }()
+
+	token, err := provider().RefreshToken("old")
+	a.NoError(err)
+	a.Equal("new-access", token.AccessToken)
+	a.Equal("new-refresh", token.RefreshToken)
+	a.Equal("363", token.Extra("userid"))
+}
+
+func Test_FetchUser(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	_, err := provider().FetchUser(&withings.Session{})
+	a.Error(err)
+}
+
+func provider() *withings.Provider {
+	return withings.New(os.Getenv("WITHINGS_KEY"), os.Getenv("WITHINGS_SECRET"), "/foo")
+}
@@ -0,0 +1,209 @@
+// Package wordpresscom implements the OAuth2 protocol for authenticating users through WordPress.com.
+//
+// Unless the global scope is requested, WordPress.com issues tokens scoped to a single
+// blog picked by the user, the blog is available in RawData as "blog_id" and "blog_url".
+// This also covers WooCommerce stores hosted on WordPress.com.
+package wordpresscom
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+)
+
+// These vars define the Authentication, Token, and Profile URLS for WordPress.com.
+var (
+	AuthURL    = "https://public-api.wordpress.com/oauth2/authorize"
+	TokenURL   = "https://public-api.wordpress.com/oauth2/token"
+	ProfileURL = "https://public-api.wordpress.com/rest/v1.1/me"
+)
+
+const (
+	// ScopeGlobal gives access to all the blogs of the user instead of a single one.
+	ScopeGlobal = "global"
+	// ScopeAuth only allows to authenticate the user, no blog is selected.
+	ScopeAuth = "auth"
+	// ScopeMedia allows to access the media of the blog.
+	ScopeMedia = "media"
+	// ScopeStats allows to read the stats of the blog.
+	ScopeStats = "stats"
+)
+
+// Provider is the implementation of `goth.Provider` for accessing WordPress.com.
+type Provider struct {
+	ClientKey   string
+	Secret      string
+	CallbackURL string
+	// Blog preselects the blog the token is requested for, it is the URL or id of the blog.
+	Blog         string
+	HTTPClient   *http.Client
+	config       *oauth2.Config
+	providerName string
+}
+
+// New creates a new WordPress.com provider and sets up important connection details.
+// You should always call `wordpresscom.New` to get a new provider.  Never try to
+// create one manually.
+func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		providerName: "wordpresscom",
+	}
+	p.config = newConfig(p, scopes)
+	return p
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns an HTTP client to be used in all fetch operations.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// Debug is a no-op for the wordpresscom package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks WordPress.com for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	opts := []oauth2.AuthCodeOption{}
+	if p.Blog != "" {
+		opts = append(opts, oauth2.SetAuthURLParam("blog", p.Blog))
+	}
+	return &Session{
+		AuthURL: p.config.AuthCodeURL(state, opts...),
+	}, nil
+}
+
+// FetchUser will go to WordPress.com and access basic information about the user.
+// The blog the token is scoped to, if any, is added to RawData.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	req, err := http.NewRequest("GET", ProfileURL, nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+
+	response, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+	}
+
+	bits, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return user, err
+	}
+
+	err = json.NewDecoder(bytes.NewReader(bits)).Decode(&user.RawData)
+	if err != nil {
+		return user, err
+	}
+
+	err = userFromReader(bytes.NewReader(bits), &user)
+	if err != nil {
+		return user, err
+	}
+
+	if sess.BlogID != "" {
+		user.RawData["blog_id"] = sess.BlogID
+		user.RawData["blog_url"] = sess.BlogURL
+	}
+	return user, nil
+}
+
+func userFromReader(r io.Reader, user *goth.User) error {
+	u := struct {
+		ID          int64  `json:"ID"`
+		DisplayName string `json:"display_name"`
+		Username    string `json:"username"`
+		Email       string `json:"email"`
+		AvatarURL   string `json:"avatar_URL"`
+		ProfileURL  string `json:"profile_URL"`
+	}{}
+	err := json.NewDecoder(r).Decode(&u)
+	if err != nil {
+		return err
+	}
+	user.UserID = strconv.FormatInt(u.ID, 10)
+	user.Name = u.DisplayName
+	user.NickName = u.Username
+	user.Email = u.Email
+	user.AvatarURL = u.AvatarURL
+	return nil
+}
+
+// blogID reads the blog_id of the token response, which WordPress.com sends either
+// as a string or as a number.
+func blogID(v interface{}) string {
+	switch id := v.(type) {
+	case string:
+		return id
+	case float64:
+		return strconv.FormatFloat(id, 'f', -1, 64)
+	}
+	return ""
+}
+
+func newConfig(provider *Provider, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   AuthURL,
+			TokenURL:  TokenURL,
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+		Scopes: []string{},
+	}
+
+	for _, scope := range scopes {
+		c.Scopes = append(c.Scopes, scope)
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is not provided by WordPress.com
+func (p *Provider) RefreshTokenAvailable() bool {
+	return false
+}
+
+// RefreshToken refresh token is not provided by WordPress.com
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	return nil, errors.New("Refresh token is not provided by WordPress.com")
+}
@@ -0,0 +1,118 @@
+package wordpresscom_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/wordpresscom"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("WORDPRESSCOM_KEY"))
+	a.Equal(p.Secret, os.Getenv("WORDPRESSCOM_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	session, err := p.BeginAuth("test_state")
+	s := session.(*wordpresscom.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "public-api.wordpress.com/oauth2/authorize")
+	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("WORDPRESSCOM_KEY")))
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "response_type=code")
+}
+
+func Test_BeginAuthWithBlog(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	p.Blog = "example.wordpress.com"
+	session, err := p.BeginAuth("test_state")
+	s := session.(*wordpresscom.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "blog=example.wordpress.com")
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://public-api.wordpress.com/oauth2/authorize","AccessToken":"1234567890"}`)
+	a.NoError(err)
+
+	s := session.(*wordpresscom.Session)
+	a.Equal(s.AuthURL, "https://public-api.wordpress.com/oauth2/authorize")
+	a.Equal(s.AccessToken, "1234567890")
+}
+
+func Test_FetchUser(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("Bearer 1234567890", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"ID":12345,"display_name":"Jane Doe","username":"janedoe","email":"jane@example.com","primary_blog":67890,"avatar_URL":"https://0.gravatar.com/avatar/abc","profile_URL":"https://gravatar.com/janedoe","email_verified":true}`))
+	}))
+	defer ts.Close()
+
+	originalProfileURL := wordpresscom.ProfileURL
+	wordpresscom.ProfileURL = ts.URL
+	defer func() { wordpresscom.ProfileURL = originalProfileURL }()
+
+	user, err := provider().FetchUser(&wordpresscom.Session{AccessToken: "1234567890", BlogID: "67890", BlogURL: "https://example.wordpress.com"})
+	a.NoError(err)
+	a.Equal("12345", user.UserID)
+	a.Equal("Jane Doe", user.Name)
+	a.Equal("janedoe", user.NickName)
+	a.Equal("jane@example.com", user.Email)
+	a.Equal("https://0.gravatar.com/avatar/abc", user.AvatarURL)
+	a.Equal("67890", user.RawData["blog_id"])
+	a.Equal("https://example.wordpress.com", user.RawData["blog_url"])
+}
+
+func Test_Authorize(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.NoError(r.ParseForm())
+		a.Equal("authorization_code", r.Form.Get("grant_type"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"abc","token_type":"bearer","blog_id":67890,"blog_url":"https://example.wordpress.com","scope":""}`))
+	}))
+	defer ts.Close()
+
+	originalTokenURL := wordpresscom.TokenURL
+	wordpresscom.TokenURL = ts.URL
+	defer func() { wordpresscom.TokenURL = originalTokenURL }()
+
+	s := &wordpresscom.Session{}
+	token, err := s.Authorize(provider(), url.Values{"code": {"xyz"}})
+	a.NoError(err)
+	a.Equal("abc", token)
+	a.Equal("67890", s.BlogID)
+	a.Equal("https://example.wordpress.com", s.BlogURL)
+}
+
+func provider() *wordpresscom.Provider {
+	return wordpresscom.New(os.Getenv("WORDPRESSCOM_KEY"), os.Getenv("WORDPRESSCOM_SECRET"), "/foo")
+}
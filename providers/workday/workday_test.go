@@ -0,0 +1,100 @@
+package workday_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/workday"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("WORKDAY_KEY"))
+	a.Equal(p.Secret, os.Getenv("WORKDAY_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+	a.Equal(p.HostURL, "https://wd2-impl-services1.workday.com")
+	a.Equal(p.Tenant, "acme")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	session, err := p.BeginAuth("test_state")
+	s := session.(*workday.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://wd2-impl-services1.workday.com/ccx/oauth2/acme/authorize")
+	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("WORKDAY_KEY")))
+	a.Contains(s.AuthURL, "state=test_state")
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://wd2-impl-services1.workday.com/ccx/oauth2/acme/authorize","AccessToken":"1234567890"}`)
+	a.NoError(err)
+
+	s := session.(*workday.Session)
+	a.Equal(s.AuthURL, "https://wd2-impl-services1.workday.com/ccx/oauth2/acme/authorize")
+	a.Equal(s.AccessToken, "1234567890")
+}
+
+func Test_FetchUser(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/ccx/api/common/v1/acme/workers/me", r.URL.Path)
+		a.Equal("Bearer 1234567890", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"id":"3aa5550b7fe348b98d7b5741afc65534","descriptor":"Logan McNeil","primaryWorkEmail":"lmcneil@example.com","businessTitle":"Chief Human Resources Officer","primarySupervisoryOrganization":{"id":"a1b2","descriptor":"Human Resources"}}`))
+	}))
+	defer ts.Close()
+
+	p := workday.New(os.Getenv("WORKDAY_KEY"), os.Getenv("WORKDAY_SECRET"), "/foo", ts.URL, "acme")
+	user, err := p.FetchUser(&workday.Session{AccessToken: "1234567890"})
+	a.NoError(err)
+	a.Equal("3aa5550b7fe348b98d7b5741afc65534", user.UserID)
+	a.Equal("Logan McNeil", user.Name)
+	a.Equal("lmcneil@example.com", user.Email)
+	a.Equal("Chief Human Resources Officer", user.Description)
+	a.NotNil(user.RawData["primarySupervisoryOrganization"])
+}
+
+func Test_RefreshToken(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/ccx/oauth2/acme/token", r.URL.Path)
+		_, _, ok := r.BasicAuth()
+		a.True(ok)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"1234567890","token_type":"Bearer","refresh_token":"0987654321"}`))
+	}))
+	defer ts.Close()
+
+	p := workday.New(os.Getenv("WORKDAY_KEY"), os.Getenv("WORKDAY_SECRET"), "/foo", ts.URL, "acme")
+	token, err := p.RefreshToken("0987654321")
+	a.NoError(err)
+	a.Equal("1234567890", token.AccessToken)
+}
+
+func provider() *workday.Provider {
+	return workday.New(os.Getenv("WORKDAY_KEY"), os.Getenv("WORKDAY_SECRET"), "/foo", "https://wd2-impl-services1.workday.com/", "acme")
+}
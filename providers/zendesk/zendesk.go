@@ -0,0 +1,213 @@
+// Package zendesk implements the OAuth2 protocol for authenticating users through Zendesk.
+// Every Zendesk account has its own subdomain, which the provider is configured with.
+package zendesk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+)
+
+// BaseURLFormat is formatted with the subdomain to get the URL of a Zendesk account.
+var BaseURLFormat = "https://%s.zendesk.com"
+
+const (
+	// ScopeRead gives read access to all resources.
+	ScopeRead = "read"
+	// ScopeWrite gives write access to all resources.
+	ScopeWrite = "write"
+	// ScopeImpersonate allows admins to make requests on behalf of end users.
+	ScopeImpersonate = "impersonate"
+	// ScopeUsersRead gives read access to users only.
+	ScopeUsersRead = "users:read"
+	// ScopeUsersWrite gives write access to users only.
+	ScopeUsersWrite = "users:write"
+	// ScopeTicketsRead gives read access to tickets only.
+	ScopeTicketsRead = "tickets:read"
+	// ScopeTicketsWrite gives write access to tickets only.
+	ScopeTicketsWrite = "tickets:write"
+	// ScopeOrganizationsRead gives read access to organizations only.
+	ScopeOrganizationsRead = "organizations:read"
+	// ScopeOrganizationsWrite gives write access to organizations only.
+	ScopeOrganizationsWrite = "organizations:write"
+)
+
+// Provider is the implementation of `goth.Provider` for accessing Zendesk.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	Subdomain    string
+	HTTPClient   *http.Client
+	config       *oauth2.Config
+	providerName string
+	baseURL      string
+}
+
+// New creates a new Zendesk provider and sets up important connection details.
+// You should always call `zendesk.New` to get a new provider.  Never try to
+// create one manually.
+// The subdomain is the one of the Zendesk account, e.g. "acme" for acme.zendesk.com.
+func New(clientKey, secret, callbackURL, subdomain string, scopes ...string) *Provider {
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		Subdomain:    subdomain,
+		providerName: "zendesk",
+		baseURL:      fmt.Sprintf(BaseURLFormat, subdomain),
+	}
+	p.config = newConfig(p, scopes)
+	return p
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns an HTTP client to be used in all fetch operations.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// Debug is a no-op for the zendesk package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks Zendesk for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	return &Session{
+		AuthURL: p.config.AuthCodeURL(state),
+	}, nil
+}
+
+// FetchUser will go to Zendesk and access basic information about the user.
+// The user's role in the account (end-user, agent or admin) is available in RawData as "role".
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	req, err := http.NewRequest("GET", p.baseURL+"/api/v2/users/me.json", nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Bearer "+sess.AccessToken)
+
+	response, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+	}
+
+	bits, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return user, err
+	}
+
+	err = userFromReader(bytes.NewReader(bits), &user)
+	return user, err
+}
+
+// userFromReader reads the user from the "user" envelope Zendesk wraps it in,
+// the content of the envelope is used as RawData.
+func userFromReader(r io.Reader, user *goth.User) error {
+	envelope := struct {
+		User json.RawMessage `json:"user"`
+	}{}
+	err := json.NewDecoder(r).Decode(&envelope)
+	if err != nil {
+		return err
+	}
+
+	err = json.Unmarshal(envelope.User, &user.RawData)
+	if err != nil {
+		return err
+	}
+
+	u := struct {
+		ID    int64  `json:"id"`
+		Name  string `json:"name"`
+		Email string `json:"email"`
+		Alias string `json:"alias"`
+		Photo *struct {
+			ContentURL string `json:"content_url"`
+		} `json:"photo"`
+	}{}
+	err = json.Unmarshal(envelope.User, &u)
+	if err != nil {
+		return err
+	}
+	user.UserID = strconv.FormatInt(u.ID, 10)
+	user.Name = u.Name
+	user.NickName = u.Alias
+	user.Email = u.Email
+	if u.Photo != nil {
+		user.AvatarURL = u.Photo.ContentURL
+	}
+	return nil
+}
+
+func newConfig(provider *Provider, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   provider.baseURL + "/oauth/authorizations/new",
+			TokenURL:  provider.baseURL + "/oauth/tokens",
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+		Scopes: []string{},
+	}
+
+	if len(scopes) > 0 {
+		for _, scope := range scopes {
+			c.Scopes = append(c.Scopes, scope)
+		}
+	} else {
+		c.Scopes = []string{ScopeUsersRead}
+	}
+	return c
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	token := &oauth2.Token{RefreshToken: refreshToken}
+	ts := p.config.TokenSource(goth.ContextForClient(p.Client()), token)
+	newToken, err := ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	return newToken, err
+}
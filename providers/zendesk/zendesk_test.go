@@ -0,0 +1,96 @@
+package zendesk_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/zendesk"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("ZENDESK_KEY"))
+	a.Equal(p.Secret, os.Getenv("ZENDESK_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+	a.Equal(p.Subdomain, "acme")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	session, err := p.BeginAuth("test_state")
+	s := session.(*zendesk.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://acme.zendesk.com/oauth/authorizations/new")
+	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("ZENDESK_KEY")))
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "scope=users%3Aread")
+}
+
+func Test_BeginAuthWithScopes(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := zendesk.New(os.Getenv("ZENDESK_KEY"), os.Getenv("ZENDESK_SECRET"), "/foo", "acme", zendesk.ScopeRead, zendesk.ScopeTicketsWrite)
+	session, err := p.BeginAuth("test_state")
+	s := session.(*zendesk.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "scope=read+tickets%3Awrite")
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://acme.zendesk.com/oauth/authorizations/new","AccessToken":"1234567890"}`)
+	a.NoError(err)
+
+	s := session.(*zendesk.Session)
+	a.Equal(s.AuthURL, "https://acme.zendesk.com/oauth/authorizations/new")
+	a.Equal(s.AccessToken, "1234567890")
+}
+
+func Test_FetchUser(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/api/v2/users/me.json", r.URL.Path)
+		a.Equal("Bearer 1234567890", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"user":{"id":35436,"name":"Johnny Agent","email":"johnny@example.com","alias":"Mr. Johnny","role":"agent","photo":{"content_url":"https://acme.zendesk.com/photos/my_funny_profile_pic.png"}}}`))
+	}))
+	defer ts.Close()
+
+	originalBaseURLFormat := zendesk.BaseURLFormat
+	zendesk.BaseURLFormat = strings.Replace(ts.URL, "127.0.0.1", "%s", 1)
+	defer func() { zendesk.BaseURLFormat = originalBaseURLFormat }()
+
+	p := zendesk.New(os.Getenv("ZENDESK_KEY"), os.Getenv("ZENDESK_SECRET"), "/foo", "127.0.0.1")
+	user, err := p.FetchUser(&zendesk.Session{AccessToken: "1234567890"})
+	a.NoError(err)
+	a.Equal("35436", user.UserID)
+	a.Equal("Johnny Agent", user.Name)
+	a.Equal("Mr. Johnny", user.NickName)
+	a.Equal("johnny@example.com", user.Email)
+	a.Equal("https://acme.zendesk.com/photos/my_funny_profile_pic.png", user.AvatarURL)
+	a.Equal("agent", user.RawData["role"])
+}
+
+func provider() *zendesk.Provider {
+	return zendesk.New(os.Getenv("ZENDESK_KEY"), os.Getenv("ZENDESK_SECRET"), "/foo", "acme")
+}
@@ -0,0 +1,243 @@
+// Package zoho implements the OAuth2 protocol for authenticating users through Zoho.
+//
+// Zoho hosts accounts in several data centers, each with its own accounts server.
+// Users are sent to the data center the provider is configured with; when their
+// account lives in another one, Zoho reports it on the callback with the `location`
+// parameter and the code has to be exchanged, and the user fetched, there.
+package zoho
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+)
+
+// DataCenter is the accounts server of a Zoho data center.
+type DataCenter string
+
+// These are the Zoho data centers.
+const (
+	DataCenterUS DataCenter = "https://accounts.zoho.com"
+	DataCenterEU DataCenter = "https://accounts.zoho.eu"
+	DataCenterIN DataCenter = "https://accounts.zoho.in"
+	DataCenterAU DataCenter = "https://accounts.zoho.com.au"
+	DataCenterJP DataCenter = "https://accounts.zoho.jp"
+	DataCenterCA DataCenter = "https://accounts.zohocloud.ca"
+	DataCenterCN DataCenter = "https://accounts.zoho.com.cn"
+	DataCenterSA DataCenter = "https://accounts.zoho.sa"
+)
+
+// locations maps the `location` callback parameter to its data center.
+var locations = map[string]DataCenter{
+	"us": DataCenterUS,
+	"eu": DataCenterEU,
+	"in": DataCenterIN,
+	"au": DataCenterAU,
+	"jp": DataCenterJP,
+	"ca": DataCenterCA,
+	"cn": DataCenterCN,
+	"sa": DataCenterSA,
+}
+
+// DataCenterForLocation returns the data center for a `location` callback parameter.
+func DataCenterForLocation(location string) (DataCenter, bool) {
+	dc, ok := locations[location]
+	return dc, ok
+}
+
+const (
+	// ScopeProfileRead allows to read the user's profile.
+	ScopeProfileRead = "AaaServer.profile.READ"
+)
+
+// Provider is the implementation of `goth.Provider` for accessing Zoho.
+type Provider struct {
+	ClientKey    string
+	Secret       string
+	CallbackURL  string
+	HTTPClient   *http.Client
+	DataCenter   DataCenter
+	config       *oauth2.Config
+	providerName string
+}
+
+// New creates a new Zoho provider using the US data center and sets up important
+// connection details.
+// You should always call `zoho.New` to get a new provider.  Never try to
+// create one manually.
+func New(clientKey, secret, callbackURL string, scopes ...string) *Provider {
+	return NewWithDataCenter(clientKey, secret, callbackURL, DataCenterUS, scopes...)
+}
+
+// NewWithDataCenter is similar to New(...) but sends users to the given data center.
+func NewWithDataCenter(clientKey, secret, callbackURL string, dc DataCenter, scopes ...string) *Provider {
+	p := &Provider{
+		ClientKey:    clientKey,
+		Secret:       secret,
+		CallbackURL:  callbackURL,
+		DataCenter:   dc,
+		providerName: "zoho",
+	}
+	p.config = newConfig(p, dc, scopes)
+	return p
+}
+
+// Name is the name used to retrieve this provider later.
+func (p *Provider) Name() string {
+	return p.providerName
+}
+
+// SetName is to update the name of the provider (needed in case of multiple providers of 1 type)
+func (p *Provider) SetName(name string) {
+	p.providerName = name
+}
+
+// Client returns an HTTP client to be used in all fetch operations.
+func (p *Provider) Client() *http.Client {
+	return goth.HTTPClientWithFallBack(p.HTTPClient)
+}
+
+// Debug is a no-op for the zoho package.
+func (p *Provider) Debug(debug bool) {}
+
+// BeginAuth asks Zoho for an authentication end-point.
+func (p *Provider) BeginAuth(state string) (goth.Session, error) {
+	url := p.config.AuthCodeURL(state,
+		oauth2.AccessTypeOffline,
+	)
+	return &Session{
+		AuthURL: url,
+	}, nil
+}
+
+// FetchUser will go to Zoho and access basic information about the user.
+// The user is fetched from the data center the session was authorized with.
+func (p *Provider) FetchUser(session goth.Session) (goth.User, error) {
+	sess := session.(*Session)
+	user := goth.User{
+		AccessToken:  sess.AccessToken,
+		Provider:     p.Name(),
+		RefreshToken: sess.RefreshToken,
+		ExpiresAt:    sess.ExpiresAt,
+	}
+
+	if user.AccessToken == "" {
+		// data is not yet retrieved since accessToken is still empty
+		return user, fmt.Errorf("%s cannot get user information without accessToken", p.providerName)
+	}
+
+	dc := sess.DataCenter
+	if dc == "" {
+		dc = p.DataCenter
+	}
+
+	req, err := http.NewRequest("GET", string(dc)+"/oauth/user/info", nil)
+	if err != nil {
+		return user, err
+	}
+	req.Header.Add("Authorization", "Zoho-oauthtoken "+sess.AccessToken)
+
+	response, err := p.Client().Do(req)
+	if err != nil {
+		return user, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return user, fmt.Errorf("%s responded with a %d trying to fetch user information", p.providerName, response.StatusCode)
+	}
+
+	bits, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return user, err
+	}
+
+	err = json.NewDecoder(bytes.NewReader(bits)).Decode(&user.RawData)
+	if err != nil {
+		return user, err
+	}
+
+	err = userFromReader(bytes.NewReader(bits), &user)
+	return user, err
+}
+
+func userFromReader(r io.Reader, user *goth.User) error {
+	u := struct {
+		ZUID        json.Number `json:"ZUID"`
+		FirstName   string      `json:"First_Name"`
+		LastName    string      `json:"Last_Name"`
+		DisplayName string      `json:"Display_Name"`
+		Email       string      `json:"Email"`
+	}{}
+	err := json.NewDecoder(r).Decode(&u)
+	if err != nil {
+		return err
+	}
+	user.UserID = u.ZUID.String()
+	user.FirstName = u.FirstName
+	user.LastName = u.LastName
+	user.Name = u.DisplayName
+	user.Email = u.Email
+	return nil
+}
+
+func newConfig(provider *Provider, dc DataCenter, scopes []string) *oauth2.Config {
+	c := &oauth2.Config{
+		ClientID:     provider.ClientKey,
+		ClientSecret: provider.Secret,
+		RedirectURL:  provider.CallbackURL,
+		Endpoint: oauth2.Endpoint{
+			AuthURL:   string(dc) + "/oauth/v2/auth",
+			TokenURL:  string(dc) + "/oauth/v2/token",
+			AuthStyle: oauth2.AuthStyleInParams,
+		},
+		Scopes: []string{},
+	}
+
+	if len(scopes) > 0 {
+		for _, scope := range scopes {
+			c.Scopes = append(c.Scopes, scope)
+		}
+	} else {
+		c.Scopes = []string{ScopeProfileRead}
+	}
+	return c
+}
+
+// configFor returns the oauth2 configuration to use with the accounts server of dc.
+func (p *Provider) configFor(dc DataCenter) *oauth2.Config {
+	if dc == "" || dc == p.DataCenter {
+		return p.config
+	}
+	return newConfig(p, dc, p.config.Scopes)
+}
+
+// RefreshTokenAvailable refresh token is provided by auth provider or not
+func (p *Provider) RefreshTokenAvailable() bool {
+	return true
+}
+
+// RefreshToken get new access token based on the refresh token.
+// Refresh tokens can only be used in the data center they were issued in, this uses
+// the provider's DataCenter; use RefreshTokenInDataCenter for the others.
+func (p *Provider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	return p.RefreshTokenInDataCenter(refreshToken, p.DataCenter)
+}
+
+// RefreshTokenInDataCenter gets a new access token from the accounts server of dc,
+// which should be the DataCenter of the session the refresh token was issued to.
+func (p *Provider) RefreshTokenInDataCenter(refreshToken string, dc DataCenter) (*oauth2.Token, error) {
+	token := &oauth2.Token{RefreshToken: refreshToken}
+	ts := p.configFor(dc).TokenSource(goth.ContextForClient(p.Client()), token)
+	newToken, err := ts.Token()
+	if err != nil {
+		return nil, err
+	}
+	return newToken, err
+}
@@ -0,0 +1,133 @@
+package zoho_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/zoho"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("ZOHO_KEY"))
+	a.Equal(p.Secret, os.Getenv("ZOHO_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+	a.Equal(p.DataCenter, zoho.DataCenterUS)
+}
+
+func Test_NewWithDataCenter(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := zoho.NewWithDataCenter(os.Getenv("ZOHO_KEY"), os.Getenv("ZOHO_SECRET"), "/foo", zoho.DataCenterEU)
+	session, err := p.BeginAuth("test_state")
+	s := session.(*zoho.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://accounts.zoho.eu/oauth/v2/auth")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	session, err := p.BeginAuth("test_state")
+	s := session.(*zoho.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "https://accounts.zoho.com/oauth/v2/auth")
+	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("ZOHO_KEY")))
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "scope=AaaServer.profile.READ")
+	a.Contains(s.AuthURL, "access_type=offline")
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://accounts.zoho.com/oauth/v2/auth","AccessToken":"1234567890","DataCenter":"https://accounts.zoho.eu"}`)
+	a.NoError(err)
+
+	s := session.(*zoho.Session)
+	a.Equal(s.AuthURL, "https://accounts.zoho.com/oauth/v2/auth")
+	a.Equal(s.AccessToken, "1234567890")
+	a.Equal(s.DataCenter, zoho.DataCenterEU)
+}
+
+func Test_DataCenterForLocation(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	dc, ok := zoho.DataCenterForLocation("in")
+	a.True(ok)
+	a.Equal(zoho.DataCenterIN, dc)
+
+	_, ok = zoho.DataCenterForLocation("evil.example.com")
+	a.False(ok)
+}
+
+func Test_Authorize(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/oauth/v2/token", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"1234567890","refresh_token":"0987654321","expires_in":3600,"token_type":"Bearer"}`))
+	}))
+	defer ts.Close()
+
+	p := zoho.NewWithDataCenter(os.Getenv("ZOHO_KEY"), os.Getenv("ZOHO_SECRET"), "/foo", zoho.DataCenter(ts.URL))
+	s := &zoho.Session{}
+	token, err := s.Authorize(p, url.Values{"code": {"code"}})
+	a.NoError(err)
+	a.Equal("1234567890", token)
+	a.Equal(zoho.DataCenter(ts.URL), s.DataCenter)
+}
+
+func Test_Authorize_UnknownLocation(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	s := &zoho.Session{}
+	_, err := s.Authorize(provider(), url.Values{"code": {"code"}, "location": {"mars"}})
+	a.Error(err)
+}
+
+func Test_FetchUser(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("/oauth/user/info", r.URL.Path)
+		a.Equal("Zoho-oauthtoken 1234567890", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"First_Name":"Patricia","Email":"patricia@example.com","Last_Name":"Boyle","Display_Name":"Patricia Boyle","ZUID":60030523}`))
+	}))
+	defer ts.Close()
+
+	user, err := provider().FetchUser(&zoho.Session{AccessToken: "1234567890", DataCenter: zoho.DataCenter(ts.URL)})
+	a.NoError(err)
+	a.Equal("60030523", user.UserID)
+	a.Equal("Patricia", user.FirstName)
+	a.Equal("Boyle", user.LastName)
+	a.Equal("Patricia Boyle", user.Name)
+	a.Equal("patricia@example.com", user.Email)
+}
+
+func provider() *zoho.Provider {
+	return zoho.New(os.Getenv("ZOHO_KEY"), os.Getenv("ZOHO_SECRET"), "/foo")
+}
@@ -0,0 +1,106 @@
+package zoom_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/zoom"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_New(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+
+	a.Equal(p.ClientKey, os.Getenv("ZOOM_KEY"))
+	a.Equal(p.Secret, os.Getenv("ZOOM_SECRET"))
+	a.Equal(p.CallbackURL, "/foo")
+}
+
+func Test_Implements_Provider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Implements((*goth.Provider)(nil), provider())
+}
+
+func Test_BeginAuth(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	p := provider()
+	session, err := p.BeginAuth("test_state")
+	s := session.(*zoom.Session)
+	a.NoError(err)
+	a.Contains(s.AuthURL, "zoom.us/oauth/authorize")
+	a.Contains(s.AuthURL, fmt.Sprintf("client_id=%s", os.Getenv("ZOOM_KEY")))
+	a.Contains(s.AuthURL, "state=test_state")
+	a.Contains(s.AuthURL, "response_type=code")
+}
+
+func Test_SessionFromJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := provider()
+	session, err := p.UnmarshalSession(`{"AuthURL":"https://zoom.us/oauth/authorize","AccessToken":"1234567890"}`)
+	a.NoError(err)
+
+	s := session.(*zoom.Session)
+	a.Equal(s.AuthURL, "https://zoom.us/oauth/authorize")
+	a.Equal(s.AccessToken, "1234567890")
+}
+
+func Test_FetchUser(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("Bearer 1234567890", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"id":"KDcuGIm1QgePTO8WbOqwIQ","first_name":"Jill","last_name":"Chill","display_name":"Jill Chill","email":"jchill@example.com","account_id":"q6gBJVO5TzexKYTb_I2rpg","pic_url":"https://example.com/photo.jpg","location":"Paris"}`))
+	}))
+	defer ts.Close()
+
+	originalProfileURL := zoom.ProfileURL
+	zoom.ProfileURL = ts.URL
+	defer func() { zoom.ProfileURL = originalProfileURL }()
+
+	user, err := provider().FetchUser(&zoom.Session{AccessToken: "1234567890"})
+	a.NoError(err)
+	a.Equal("KDcuGIm1QgePTO8WbOqwIQ", user.UserID)
+	a.Equal("Jill", user.FirstName)
+	a.Equal("Chill", user.LastName)
+	a.Equal("Jill Chill", user.Name)
+	a.Equal("jchill@example.com", user.Email)
+	a.Equal("https://example.com/photo.jpg", user.AvatarURL)
+	a.Equal("Paris", user.Location)
+	a.Equal("q6gBJVO5TzexKYTb_I2rpg", user.RawData["account_id"])
+}
+
+func Test_RefreshToken(t *testing.T) {
+	a := assert.New(t)
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.NoError(r.ParseForm())
+		a.Equal("refresh_token", r.Form.Get("grant_type"))
+		a.Equal("old-refresh-token", r.Form.Get("refresh_token"))
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"new-access-token","token_type":"bearer","refresh_token":"new-refresh-token","expires_in":3599}`))
+	}))
+	defer ts.Close()
+
+	originalTokenURL := zoom.TokenURL
+	zoom.TokenURL = ts.URL
+	defer func() { zoom.TokenURL = originalTokenURL }()
+
+	token, err := provider().RefreshToken("old-refresh-token")
+	a.NoError(err)
+	a.Equal("new-access-token", token.AccessToken)
+	a.Equal("new-refresh-token", token.RefreshToken)
+}
+
+func provider() *zoom.Provider {
+	return zoom.New(os.Getenv("ZOOM_KEY"), os.Getenv("ZOOM_SECRET"), "/foo")
+}
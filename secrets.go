@@ -0,0 +1,214 @@
+package goth
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// SecretSource needs to be implemented to resolve secrets at runtime, e.g. the client
+// IDs, secrets and signing keys of the providers kept in a secret manager.
+type SecretSource interface {
+	// Secret returns the current value of the secret of the name.
+	Secret(ctx context.Context, name string) (string, error)
+}
+
+// SecretSourceFunc is a function used as a SecretSource.
+type SecretSourceFunc func(ctx context.Context, name string) (string, error)
+
+// Secret calls f(ctx, name).
+func (f SecretSourceFunc) Secret(ctx context.Context, name string) (string, error) {
+	return f(ctx, name)
+}
+
+// SecretProvider is a Provider whose credentials come from a SecretSource. It resolves
+// the secrets again once in a while and, when they have changed, builds a new provider
+// with them, so that the secrets can be rotated without restarting the process.
+//
+// The sessions started before a rotation are completed by the new provider.
+type SecretProvider struct {
+	source  SecretSource
+	names   []string
+	build   func(secrets map[string]string) (Provider, error)
+	refresh time.Duration
+
+	mu        sync.Mutex
+	current   Provider
+	secrets   map[string]string
+	resolveAt time.Time
+	name      string
+	debug     bool
+}
+
+var _ Provider = &SecretProvider{}
+
+// NewSecretProvider resolves the secrets of the names from the source and gives them to
+// build, keyed by name, to create the provider. They are resolved again every 5 minutes,
+// see WithRefreshInterval.
+//
+//	p, err := goth.NewSecretProvider(source, func(secrets map[string]string) (goth.Provider, error) {
+//		return github.New(secrets["github/key"], secrets["github/secret"], callbackURL), nil
+//	}, "github/key", "github/secret")
+func NewSecretProvider(source SecretSource, build func(secrets map[string]string) (Provider, error), names ...string) (*SecretProvider, error) {
+	p := &SecretProvider{
+		source:  source,
+		names:   names,
+		build:   build,
+		refresh: 5 * time.Minute,
+	}
+	if _, err := p.resolve(context.Background()); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// WithRefreshInterval sets how often the secrets are resolved again.
+func (p *SecretProvider) WithRefreshInterval(interval time.Duration) *SecretProvider {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.refresh = interval
+	p.resolveAt = time.Now().Add(interval)
+	return p
+}
+
+// Provider returns the provider built with the current secrets, e.g. to call its own
+// methods. When the secrets cannot be resolved again, the previous provider is kept.
+func (p *SecretProvider) Provider() Provider {
+	current, _ := p.resolve(context.Background())
+	return current
+}
+
+// resolve returns the current provider, after building a new one if the secrets have
+// changed since they were last resolved.
+func (p *SecretProvider) resolve(ctx context.Context) (Provider, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.current != nil && time.Now().Before(p.resolveAt) {
+		return p.current, nil
+	}
+
+	secrets := make(map[string]string, len(p.names))
+	for _, name := range p.names {
+		value, err := p.source.Secret(ctx, name)
+		if err != nil {
+			p.retryLater()
+			return p.current, err
+		}
+		secrets[name] = value
+	}
+	p.resolveAt = time.Now().Add(p.refresh)
+	if p.current != nil && equalSecrets(secrets, p.secrets) {
+		return p.current, nil
+	}
+
+	provider, err := p.build(secrets)
+	if err != nil {
+		p.retryLater()
+		return p.current, err
+	}
+	if p.name != "" {
+		provider.SetName(p.name)
+	}
+	provider.Debug(p.debug)
+	p.current = provider
+	p.secrets = secrets
+	return provider, nil
+}
+
+// retryLater keeps the current provider for a while when the secrets cannot be
+// resolved, rather than asking the source again on every request.
+func (p *SecretProvider) retryLater() {
+	retry := p.refresh / 10
+	if retry < time.Second {
+		retry = time.Second
+	}
+	p.resolveAt = time.Now().Add(retry)
+}
+
+func equalSecrets(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for name, value := range a {
+		if v, ok := b[name]; !ok || v != value {
+			return false
+		}
+	}
+	return true
+}
+
+// Name is the name of the current provider.
+func (p *SecretProvider) Name() string {
+	return p.Provider().Name()
+}
+
+// SetName sets the name of the current provider and of the ones built after rotations.
+func (p *SecretProvider) SetName(name string) {
+	current := p.Provider()
+	p.mu.Lock()
+	p.name = name
+	p.mu.Unlock()
+	current.SetName(name)
+}
+
+// BeginAuth asks the current provider for an authentication end-point.
+func (p *SecretProvider) BeginAuth(state string) (Session, error) {
+	session, err := p.Provider().BeginAuth(state)
+	if err != nil {
+		return nil, err
+	}
+	return &secretSession{Session: session}, nil
+}
+
+// UnmarshalSession unmarshals a session of the current provider.
+func (p *SecretProvider) UnmarshalSession(data string) (Session, error) {
+	session, err := p.Provider().UnmarshalSession(data)
+	if err != nil {
+		return nil, err
+	}
+	return &secretSession{Session: session}, nil
+}
+
+// FetchUser fetches the user with the current provider.
+func (p *SecretProvider) FetchUser(session Session) (User, error) {
+	if s, ok := session.(*secretSession); ok {
+		session = s.Session
+	}
+	return p.Provider().FetchUser(session)
+}
+
+// Debug sets the debug mode of the current provider and of the ones built after
+// rotations.
+func (p *SecretProvider) Debug(debug bool) {
+	current := p.Provider()
+	p.mu.Lock()
+	p.debug = debug
+	p.mu.Unlock()
+	current.Debug(debug)
+}
+
+// RefreshToken gets a new access token with the current provider.
+func (p *SecretProvider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	return p.Provider().RefreshToken(refreshToken)
+}
+
+// RefreshTokenAvailable tells if the current provider can refresh tokens.
+func (p *SecretProvider) RefreshTokenAvailable() bool {
+	return p.Provider().RefreshTokenAvailable()
+}
+
+// secretSession is the session of the provider of a SecretProvider, authorized by the
+// provider current at the time, as the sessions cast their provider to their own type.
+type secretSession struct {
+	Session
+}
+
+// Authorize the session with the current provider of the SecretProvider.
+func (s *secretSession) Authorize(provider Provider, params Params) (string, error) {
+	if p, ok := provider.(*SecretProvider); ok {
+		provider = p.Provider()
+	}
+	return s.Session.Authorize(provider, params)
+}
@@ -0,0 +1,75 @@
+/*
+Package awssecrets resolves the secrets of the providers from AWS Secrets Manager, see
+goth.NewSecretProvider.
+
+The names of the secrets are their name or ARN, whose current version is read. Secrets
+holding a JSON object, as the key/value secrets of the console do, are read a field at
+a time by adding it after a '#', e.g. "goth/github#client_secret".
+
+	cfg, err := config.LoadDefaultConfig(ctx)
+	source := awssecrets.New(secretsmanager.NewFromConfig(cfg))
+*/
+package awssecrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/markbates/goth"
+)
+
+// API is the part of the Secrets Manager client used by the Source, implemented by
+// *secretsmanager.Client.
+type API interface {
+	GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error)
+}
+
+var _ API = &secretsmanager.Client{}
+
+// Source is a goth.SecretSource reading the secrets from Secrets Manager.
+type Source struct {
+	client API
+}
+
+var _ goth.SecretSource = &Source{}
+
+// New creates a Source using the client.
+func New(client API) *Source {
+	return &Source{client: client}
+}
+
+// Secret returns the current value of the secret, or its field.
+func (s *Source) Secret(ctx context.Context, name string) (string, error) {
+	id, field := name, ""
+	if i := strings.LastIndexByte(name, '#'); i >= 0 {
+		id, field = name[:i], name[i+1:]
+	}
+
+	out, err := s.client.GetSecretValue(ctx, &secretsmanager.GetSecretValueInput{
+		SecretId: aws.String(id),
+	})
+	if err != nil {
+		return "", err
+	}
+	value := string(out.SecretBinary)
+	if out.SecretString != nil {
+		value = *out.SecretString
+	}
+
+	if field == "" {
+		return value, nil
+	}
+	fields := map[string]interface{}{}
+	if err = json.Unmarshal([]byte(value), &fields); err != nil {
+		return "", fmt.Errorf("secret %q is not a JSON object: %v", id, err)
+	}
+	fieldValue, ok := fields[field].(string)
+	if !ok {
+		return "", fmt.Errorf("secret %q has no field %q", id, field)
+	}
+	return fieldValue, nil
+}
@@ -0,0 +1,56 @@
+package awssecrets_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/markbates/goth/secrets/awssecrets"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeSecretsManager is an in-memory awssecrets.API.
+type fakeSecretsManager map[string]*secretsmanager.GetSecretValueOutput
+
+func (f fakeSecretsManager) GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error) {
+	out, ok := f[aws.ToString(params.SecretId)]
+	if !ok {
+		return nil, errors.New("ResourceNotFoundException")
+	}
+	return out, nil
+}
+
+func Test_Secret(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	s := awssecrets.New(fakeSecretsManager{
+		"github-secret": {SecretString: aws.String("secret")},
+		"goth/github":   {SecretString: aws.String(`{"client_id":"key","client_secret":"secret"}`)},
+		"signing-key":   {SecretBinary: []byte("binary")},
+	})
+	ctx := context.Background()
+
+	value, err := s.Secret(ctx, "github-secret")
+	a.NoError(err)
+	a.Equal("secret", value)
+
+	value, err = s.Secret(ctx, "goth/github#client_id")
+	a.NoError(err)
+	a.Equal("key", value)
+
+	value, err = s.Secret(ctx, "signing-key")
+	a.NoError(err)
+	a.Equal("binary", value)
+
+	_, err = s.Secret(ctx, "goth/github#missing")
+	a.EqualError(err, `secret "goth/github" has no field "missing"`)
+
+	_, err = s.Secret(ctx, "github-secret#client_id")
+	a.Error(err)
+
+	_, err = s.Secret(ctx, "missing")
+	a.EqualError(err, "ResourceNotFoundException")
+}
@@ -0,0 +1,108 @@
+/*
+Package gcpsecrets resolves the secrets of the providers from Google Cloud Secret Manager,
+see goth.NewSecretProvider.
+
+The names of the secrets are their ID in the project, whose latest version is read, or
+the full name of a version, e.g. "projects/my-project/secrets/github-secret/versions/3".
+Secrets holding a JSON object are read a field at a time by adding it after a '#', e.g.
+"github#client_secret".
+
+The HTTP client must authenticate the requests, e.g. with the application default
+credentials:
+
+	client, err := google.DefaultClient(ctx, "https://www.googleapis.com/auth/cloud-platform")
+	source := gcpsecrets.New(client, "my-project")
+*/
+package gcpsecrets
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/markbates/goth"
+)
+
+// BaseURL is the URL of the Secret Manager API, it can be changed before calling New.
+var BaseURL = "https://secretmanager.googleapis.com/v1/"
+
+// Source is a goth.SecretSource reading the secrets from Secret Manager.
+type Source struct {
+	HTTPClient *http.Client
+	project    string
+	baseURL    string
+}
+
+var _ goth.SecretSource = &Source{}
+
+// New creates a Source reading the secrets of the project with the HTTP client.
+func New(client *http.Client, project string) *Source {
+	return &Source{
+		HTTPClient: client,
+		project:    project,
+		baseURL:    BaseURL,
+	}
+}
+
+// Secret returns the payload of the secret version, or its field.
+func (s *Source) Secret(ctx context.Context, name string) (string, error) {
+	version, field := name, ""
+	if i := strings.LastIndexByte(name, '#'); i >= 0 {
+		version, field = name[:i], name[i+1:]
+	}
+	if !strings.HasPrefix(version, "projects/") {
+		version = "projects/" + s.project + "/secrets/" + version + "/versions/latest"
+	}
+
+	req, err := http.NewRequest("GET", s.baseURL+version+":access", nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := goth.HTTPClientWithFallBack(s.HTTPClient).Do(req.WithContext(ctx))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("secret manager responded with a %d trying to access %q", resp.StatusCode, version)
+	}
+	access := struct {
+		Payload struct {
+			Data       string `json:"data"`
+			DataCrc32c string `json:"dataCrc32c"`
+		} `json:"payload"`
+	}{}
+	if err = json.NewDecoder(resp.Body).Decode(&access); err != nil {
+		return "", err
+	}
+	data, err := base64.StdEncoding.DecodeString(access.Payload.Data)
+	if err != nil {
+		return "", err
+	}
+	if access.Payload.DataCrc32c != "" {
+		sum, err := strconv.ParseUint(access.Payload.DataCrc32c, 10, 32)
+		if err != nil || uint32(sum) != crc32.Checksum(data, crc32.MakeTable(crc32.Castagnoli)) {
+			return "", errors.New("secret manager returned a corrupted payload")
+		}
+	}
+
+	if field == "" {
+		return string(data), nil
+	}
+	fields := map[string]interface{}{}
+	if err = json.Unmarshal(data, &fields); err != nil {
+		return "", fmt.Errorf("secret %q is not a JSON object: %v", version, err)
+	}
+	value, ok := fields[field].(string)
+	if !ok {
+		return "", fmt.Errorf("secret %q has no field %q", version, field)
+	}
+	return value, nil
+}
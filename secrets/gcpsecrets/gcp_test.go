@@ -0,0 +1,55 @@
+package gcpsecrets
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"hash/crc32"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func payload(data string) string {
+	sum := crc32.Checksum([]byte(data), crc32.MakeTable(crc32.Castagnoli))
+	return fmt.Sprintf(`{"name":"version","payload":{"data":%q,"dataCrc32c":"%d"}}`, base64.StdEncoding.EncodeToString([]byte(data)), sum)
+}
+
+func Test_Secret(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/projects/goth/secrets/github-secret/versions/latest:access":
+			w.Write([]byte(payload("secret")))
+		case "/projects/other/secrets/github/versions/3:access":
+			w.Write([]byte(payload(`{"client_id":"key"}`)))
+		case "/projects/goth/secrets/corrupted/versions/latest:access":
+			w.Write([]byte(`{"payload":{"data":"c2VjcmV0","dataCrc32c":"1"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	s := New(server.Client(), "goth")
+	s.baseURL = server.URL + "/"
+	ctx := context.Background()
+
+	value, err := s.Secret(ctx, "github-secret")
+	a.NoError(err)
+	a.Equal("secret", value)
+
+	value, err = s.Secret(ctx, "projects/other/secrets/github/versions/3#client_id")
+	a.NoError(err)
+	a.Equal("key", value)
+
+	_, err = s.Secret(ctx, "corrupted")
+	a.EqualError(err, "secret manager returned a corrupted payload")
+
+	_, err = s.Secret(ctx, "missing")
+	a.EqualError(err, `secret manager responded with a 404 trying to access "projects/goth/secrets/missing/versions/latest"`)
+}
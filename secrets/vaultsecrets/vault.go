@@ -0,0 +1,101 @@
+/*
+Package vaultsecrets resolves the secrets of the providers from the KV version 2 secrets
+engine of HashiCorp Vault, see goth.NewSecretProvider.
+
+The names of the secrets are the path of a secret in the engine and the field to read,
+separated by a '#', e.g. "goth/github#client_secret". The field "value" is read when
+there is none.
+
+	source := vaultsecrets.New("https://vault.example.com:8200", os.Getenv("VAULT_TOKEN"))
+	p, err := goth.NewSecretProvider(source, func(secrets map[string]string) (goth.Provider, error) {
+		return github.New(secrets["goth/github#client_id"], secrets["goth/github#client_secret"], callbackURL), nil
+	}, "goth/github#client_id", "goth/github#client_secret")
+*/
+package vaultsecrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/markbates/goth"
+)
+
+// DefaultMount is the mount path of the KV engine of a new Source.
+const DefaultMount = "secret"
+
+// Source is a goth.SecretSource reading the secrets from Vault.
+type Source struct {
+	// Token authenticates the requests to Vault, it can be changed when it is renewed.
+	Token      string
+	HTTPClient *http.Client
+	address    string
+	mount      string
+	namespace  string
+}
+
+var _ goth.SecretSource = &Source{}
+
+// New creates a Source reading the secrets from the Vault server at the address, with
+// the token.
+func New(address, token string) *Source {
+	return &Source{
+		Token:   token,
+		address: strings.TrimSuffix(address, "/"),
+		mount:   DefaultMount,
+	}
+}
+
+// WithMount sets the mount path of the KV engine.
+func (s *Source) WithMount(mount string) *Source {
+	s.mount = strings.Trim(mount, "/")
+	return s
+}
+
+// WithNamespace sets the namespace of the secrets, in Vault Enterprise.
+func (s *Source) WithNamespace(namespace string) *Source {
+	s.namespace = namespace
+	return s
+}
+
+// Secret returns the field of the latest version of the secret.
+func (s *Source) Secret(ctx context.Context, name string) (string, error) {
+	path, field := name, "value"
+	if i := strings.LastIndexByte(name, '#'); i >= 0 {
+		path, field = name[:i], name[i+1:]
+	}
+
+	req, err := http.NewRequest("GET", s.address+"/v1/"+s.mount+"/data/"+strings.TrimPrefix(path, "/"), nil)
+	if err != nil {
+		return "", err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("X-Vault-Token", s.Token)
+	if s.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", s.namespace)
+	}
+	resp, err := goth.HTTPClientWithFallBack(s.HTTPClient).Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("vault responded with a %d trying to read the secret %q", resp.StatusCode, path)
+	}
+	secret := struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}{}
+	if err = json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+		return "", err
+	}
+	value, ok := secret.Data.Data[field].(string)
+	if !ok {
+		return "", fmt.Errorf("vault secret %q has no field %q", path, field)
+	}
+	return value, nil
+}
@@ -0,0 +1,44 @@
+package vaultsecrets_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/markbates/goth/secrets/vaultsecrets"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Secret(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("token", r.Header.Get("X-Vault-Token"))
+		a.Equal("team", r.Header.Get("X-Vault-Namespace"))
+		if r.URL.Path != "/v1/kv/data/goth/github" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"data":{"data":{"client_id":"key","value":"secret"},"metadata":{"version":2}}}`))
+	}))
+	defer server.Close()
+
+	s := vaultsecrets.New(server.URL+"/", "token").WithMount("/kv/").WithNamespace("team")
+	ctx := context.Background()
+
+	value, err := s.Secret(ctx, "goth/github#client_id")
+	a.NoError(err)
+	a.Equal("key", value)
+
+	value, err = s.Secret(ctx, "goth/github")
+	a.NoError(err)
+	a.Equal("secret", value)
+
+	_, err = s.Secret(ctx, "goth/github#missing")
+	a.EqualError(err, `vault secret "goth/github" has no field "missing"`)
+
+	_, err = s.Secret(ctx, "goth/gitlab")
+	a.EqualError(err, `vault responded with a 404 trying to read the secret "goth/gitlab"`)
+}
@@ -0,0 +1,81 @@
+package goth_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/github"
+	"github.com/stretchr/testify/assert"
+)
+
+func secretProvider(t *testing.T, secrets map[string]string) *goth.SecretProvider {
+	source := goth.SecretSourceFunc(func(ctx context.Context, name string) (string, error) {
+		value, ok := secrets[name]
+		if !ok {
+			return "", errors.New("no secret " + name)
+		}
+		return value, nil
+	})
+	p, err := goth.NewSecretProvider(source, func(secrets map[string]string) (goth.Provider, error) {
+		return github.New(secrets["key"], secrets["secret"], "/foo"), nil
+	}, "key", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func Test_SecretProvider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	secrets := map[string]string{"key": "old-key", "secret": "old-secret"}
+	p := secretProvider(t, secrets)
+	a.Implements((*goth.Provider)(nil), p)
+	a.Equal("github", p.Name())
+	p.SetName("github-enterprise")
+
+	session, err := p.BeginAuth("state")
+	a.NoError(err)
+	url, err := session.GetAuthURL()
+	a.NoError(err)
+	a.Contains(url, "client_id=old-key")
+	a.Equal("old-key", p.Provider().(*github.Provider).ClientKey)
+
+	// the secrets are only resolved again after the refresh interval
+	secrets["key"] = "new-key"
+	a.Equal("old-key", p.Provider().(*github.Provider).ClientKey)
+
+	p.WithRefreshInterval(time.Nanosecond)
+	time.Sleep(time.Millisecond)
+	current := p.Provider().(*github.Provider)
+	a.Equal("new-key", current.ClientKey)
+	a.Equal("github-enterprise", current.Name())
+
+	// sessions survive the rotation
+	session, err = p.UnmarshalSession(session.Marshal())
+	a.NoError(err)
+	url, err = session.GetAuthURL()
+	a.NoError(err)
+	a.Contains(url, "client_id=old-key")
+
+	// the previous provider is kept when the secrets cannot be resolved
+	delete(secrets, "secret")
+	a.Equal("new-key", p.Provider().(*github.Provider).ClientKey)
+}
+
+func Test_NewSecretProvider_Error(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	source := goth.SecretSourceFunc(func(ctx context.Context, name string) (string, error) {
+		return "", errors.New("unavailable")
+	})
+	_, err := goth.NewSecretProvider(source, func(secrets map[string]string) (goth.Provider, error) {
+		return github.New(secrets["key"], secrets["secret"], "/foo"), nil
+	}, "key")
+	a.EqualError(err, "unavailable")
+}
@@ -0,0 +1,108 @@
+package goth
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// SessionVersion is the version of the format of the sessions marshaled by
+// MarshalSession. It is increased when the sessions of a provider change in a way
+// which needs a SessionMigration.
+const SessionVersion = 1
+
+// ErrSessionVersion is returned when unmarshaling a session marshaled by a newer
+// version of Goth, e.g. after a rollback.
+var ErrSessionVersion = errors.New("goth: session from a newer version")
+
+// SessionMigration upgrades a session of the provider from the previous version to
+// the version it is registered for.
+type SessionMigration func(providerName, session string) (string, error)
+
+var (
+	sessionMigrationsMu sync.RWMutex
+	sessionMigrations   = map[int][]SessionMigration{}
+)
+
+// RegisterSessionMigration registers a migration upgrading the sessions to the version,
+// from the previous one. The migrations of a version run in the order they have been
+// registered.
+func RegisterSessionMigration(version int, migration SessionMigration) {
+	sessionMigrationsMu.Lock()
+	defer sessionMigrationsMu.Unlock()
+	sessionMigrations[version] = append(sessionMigrations[version], migration)
+}
+
+// MarshalSession marshals the session with the current version, to be unmarshaled by
+// UnmarshalSession.
+func MarshalSession(session Session) string {
+	return fmt.Sprintf("v%d:%s", SessionVersion, session.Marshal())
+}
+
+// ParseSessionVersion returns the version of a session marshaled by MarshalSession and
+// the session marshaled by its provider. The sessions marshaled before the versions
+// were introduced are version 0.
+func ParseSessionVersion(data string) (int, string) {
+	if !strings.HasPrefix(data, "v") {
+		return 0, data
+	}
+	i := strings.IndexByte(data, ':')
+	if i < 0 {
+		return 0, data
+	}
+	version, err := strconv.Atoi(data[1:i])
+	if err != nil || version < 1 {
+		return 0, data
+	}
+	return version, data[i+1:]
+}
+
+// MigrateSession upgrades a session of the provider marshaled by any version to the
+// current one. It returns if the session has changed.
+func MigrateSession(providerName, data string) (string, bool, error) {
+	version, session := ParseSessionVersion(data)
+	if version == SessionVersion {
+		return data, false, nil
+	}
+	if version > SessionVersion {
+		return data, false, ErrSessionVersion
+	}
+
+	sessionMigrationsMu.RLock()
+	defer sessionMigrationsMu.RUnlock()
+	for v := version + 1; v <= SessionVersion; v++ {
+		for _, migration := range sessionMigrations[v] {
+			var err error
+			session, err = migration(providerName, session)
+			if err != nil {
+				return data, false, fmt.Errorf("goth: cannot migrate the session to version %d: %v", v, err)
+			}
+		}
+	}
+	return fmt.Sprintf("v%d:%s", SessionVersion, session), true, nil
+}
+
+// UnmarshalSession unmarshals a session of the provider marshaled by MarshalSession, or
+// by the Marshal method of the session, after migrating it to the current version.
+func UnmarshalSession(provider Provider, data string) (Session, error) {
+	session, err := StripSessionVersion(provider.Name(), data)
+	if err != nil {
+		return nil, err
+	}
+	return provider.UnmarshalSession(session)
+}
+
+// StripSessionVersion migrates a session of the provider marshaled by any version to
+// the current one, and returns it without its version, as marshaled by the Marshal
+// method of the session, to be unmarshaled by the UnmarshalSession method of the
+// provider.
+func StripSessionVersion(providerName, data string) (string, error) {
+	data, _, err := MigrateSession(providerName, data)
+	if err != nil {
+		return "", err
+	}
+	_, session := ParseSessionVersion(data)
+	return session, nil
+}
@@ -0,0 +1,82 @@
+package goth_test
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/faux"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_MarshalSession(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := &faux.Provider{}
+	data := goth.MarshalSession(&faux.Session{ID: "id"})
+	version, session := goth.ParseSessionVersion(data)
+	a.Equal(goth.SessionVersion, version)
+	a.Equal(`{"ID":"id","Name":"","Email":"","AuthURL":"","AccessToken":""}`, session)
+
+	sess, err := goth.UnmarshalSession(p, data)
+	a.NoError(err)
+	a.Equal("id", sess.(*faux.Session).ID)
+
+	// sessions marshaled before the versions are still unmarshaled
+	sess, err = goth.UnmarshalSession(p, session)
+	a.NoError(err)
+	a.Equal("id", sess.(*faux.Session).ID)
+
+	_, err = goth.UnmarshalSession(p, fmt.Sprintf("v%d:%s", goth.SessionVersion+1, session))
+	a.Equal(goth.ErrSessionVersion, err)
+}
+
+func Test_StripSessionVersion(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	legacy := (&faux.Session{ID: "id"}).Marshal()
+	session, err := goth.StripSessionVersion("faux", goth.MarshalSession(&faux.Session{ID: "id"}))
+	a.NoError(err)
+	a.Equal(legacy, session)
+
+	// the sessions marshaled before the versions are returned as is
+	session, err = goth.StripSessionVersion("faux", legacy)
+	a.NoError(err)
+	a.Equal(legacy, session)
+
+	_, err = goth.StripSessionVersion("faux", fmt.Sprintf("v%d:%s", goth.SessionVersion+1, legacy))
+	a.Equal(goth.ErrSessionVersion, err)
+}
+
+func Test_MigrateSession(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	goth.RegisterSessionMigration(goth.SessionVersion, func(providerName, session string) (string, error) {
+		switch providerName {
+		case "renamed":
+			return strings.Replace(session, `"Token"`, `"AccessToken"`, 1), nil
+		case "broken":
+			return "", errors.New("broken session")
+		}
+		return session, nil
+	})
+
+	data, migrated, err := goth.MigrateSession("renamed", `{"Token":"access"}`)
+	a.NoError(err)
+	a.True(migrated)
+	a.Equal(fmt.Sprintf(`v%d:{"AccessToken":"access"}`, goth.SessionVersion), data)
+
+	// sessions of the current version are left as is
+	data, migrated, err = goth.MigrateSession("renamed", data)
+	a.NoError(err)
+	a.False(migrated)
+	a.Equal(fmt.Sprintf(`v%d:{"AccessToken":"access"}`, goth.SessionVersion), data)
+
+	_, _, err = goth.MigrateSession("broken", "{}")
+	a.EqualError(err, fmt.Sprintf("goth: cannot migrate the session to version %d: broken session", goth.SessionVersion))
+}
@@ -0,0 +1,236 @@
+/*
+Package boltstore keeps the web sessions of gothic and the tokens of the users in a
+bbolt database, a single file embedded in the application, for self-hosted applications
+shipped as a single binary.
+
+	s, err := boltstore.Open("goth.db")
+	defer s.Close()
+	defer s.StartCleanup(time.Hour)()
+
+	gothic.Store = s.Sessions([]byte(os.Getenv("SESSION_SECRET")))
+	gothic.TokenStore = s
+
+The file is locked while it is open, so it cannot be shared by several processes.
+*/
+package boltstore
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/markbates/goth/store"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	sessionsBucket = []byte("goth_sessions")
+	tokensBucket   = []byte("goth_tokens")
+)
+
+// Store is a store.TokenStore and a store.SessionBackend keeping the tokens and the
+// sessions in a bbolt database.
+type Store struct {
+	db *bolt.DB
+}
+
+var _ store.TokenStore = &Store{}
+var _ store.SessionBackend = &Store{}
+var _ store.SessionLister = &Store{}
+
+// session is a session in the database.
+type session struct {
+	Data      string    `json:"data"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Open opens, or creates, the database file and returns a Store using it.
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+	s, err := New(db)
+	if err != nil {
+		db.Close()
+	}
+	return s, err
+}
+
+// New creates a Store using the database, which can be shared with the application,
+// and creates its buckets.
+func New(db *bolt.DB) (*Store, error) {
+	err := db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{sessionsBucket, tokensBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return &Store{db: db}, err
+}
+
+// Close closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Sessions returns a store.SessionStore keeping the sessions in the database, to be
+// used as gothic.Store.
+func (s *Store) Sessions(keyPairs ...[]byte) *store.SessionStore {
+	return store.NewSessionStore(s, keyPairs...)
+}
+
+// tokenKey is the key of the token, the provider and the user ID separated by a NUL.
+func tokenKey(provider, userID string) []byte {
+	return []byte(provider + "\x00" + userID)
+}
+
+// Save saves the token, replacing the one of the same provider and user if any.
+func (s *Store) Save(ctx context.Context, token *store.Token) error {
+	bits, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(tokensBucket).Put(tokenKey(token.Provider, token.UserID), bits)
+	})
+}
+
+// Load returns the token of the user at the provider, or store.ErrTokenNotFound.
+func (s *Store) Load(ctx context.Context, provider, userID string) (*store.Token, error) {
+	var token *store.Token
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bits := tx.Bucket(tokensBucket).Get(tokenKey(provider, userID))
+		if bits == nil {
+			return store.ErrTokenNotFound
+		}
+		token = &store.Token{}
+		return json.Unmarshal(bits, token)
+	})
+	return token, err
+}
+
+// Delete deletes the token of the user at the provider, if any.
+func (s *Store) Delete(ctx context.Context, provider, userID string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(tokensBucket).Delete(tokenKey(provider, userID))
+	})
+}
+
+// LoadSession returns the encoded values of the session, or store.ErrSessionNotFound.
+func (s *Store) LoadSession(ctx context.Context, id string) (string, error) {
+	sess := session{}
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bits := tx.Bucket(sessionsBucket).Get([]byte(id))
+		if bits == nil {
+			return store.ErrSessionNotFound
+		}
+		return json.Unmarshal(bits, &sess)
+	})
+	if err != nil {
+		return "", err
+	}
+	if !sess.ExpiresAt.After(time.Now()) {
+		return "", store.ErrSessionNotFound
+	}
+	return sess.Data, nil
+}
+
+// SaveSession saves the encoded values of the session until it expires.
+func (s *Store) SaveSession(ctx context.Context, id, data string, expiresAt time.Time) error {
+	bits, err := json.Marshal(session{Data: data, ExpiresAt: expiresAt})
+	if err != nil {
+		return err
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(sessionsBucket).Put([]byte(id), bits)
+	})
+}
+
+// DeleteSession deletes the session, if any.
+func (s *Store) DeleteSession(ctx context.Context, id string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(sessionsBucket).Delete([]byte(id))
+	})
+}
+
+// ListSessions calls fn with the encoded values of every session which has not expired.
+func (s *Store) ListSessions(ctx context.Context, fn func(id, data string, expiresAt time.Time) error) error {
+	now := time.Now()
+	return s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(sessionsBucket).ForEach(func(k, v []byte) error {
+			sess := session{}
+			if err := json.Unmarshal(v, &sess); err != nil || !sess.ExpiresAt.After(now) {
+				return nil
+			}
+			return fn(string(k), sess.Data, sess.ExpiresAt)
+		})
+	})
+}
+
+// Cleanup deletes the expired sessions, and the expired tokens which cannot be
+// refreshed. It returns the number of deleted entries.
+func (s *Store) Cleanup(ctx context.Context) (int, error) {
+	now := time.Now()
+	deleted := 0
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		expired := [][]byte{}
+		err := tx.Bucket(sessionsBucket).ForEach(func(k, v []byte) error {
+			sess := session{}
+			if err := json.Unmarshal(v, &sess); err != nil || !sess.ExpiresAt.After(now) {
+				expired = append(expired, k)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, k := range expired {
+			if err = tx.Bucket(sessionsBucket).Delete(k); err != nil {
+				return err
+			}
+		}
+		deleted += len(expired)
+
+		expired = expired[:0]
+		err = tx.Bucket(tokensBucket).ForEach(func(k, v []byte) error {
+			token := store.Token{}
+			if err := json.Unmarshal(v, &token); err == nil && token.Expired() && token.RefreshToken == "" {
+				expired = append(expired, k)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for _, k := range expired {
+			if err = tx.Bucket(tokensBucket).Delete(k); err != nil {
+				return err
+			}
+		}
+		deleted += len(expired)
+		return nil
+	})
+	return deleted, err
+}
+
+// StartCleanup calls Cleanup every interval in the background, until the returned
+// function is called. Failed cleanups are retried at the next interval.
+func (s *Store) StartCleanup(interval time.Duration) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.Cleanup(ctx)
+			}
+		}
+	}()
+	return cancel
+}
@@ -0,0 +1,77 @@
+package boltstore
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/markbates/goth/store"
+	"github.com/markbates/goth/store/storetest"
+	"github.com/stretchr/testify/assert"
+)
+
+func newStore(t *testing.T) *Store {
+	s, err := Open(filepath.Join(t.TempDir(), "goth.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func Test_TokenStore(t *testing.T) {
+	t.Parallel()
+	storetest.TestTokenStore(t, newStore(t))
+}
+
+func Test_SessionBackend(t *testing.T) {
+	t.Parallel()
+	storetest.TestSessionBackend(t, newStore(t))
+}
+
+func Test_Persistence(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	ctx := context.Background()
+
+	path := filepath.Join(t.TempDir(), "goth.db")
+	s, err := Open(path)
+	a.NoError(err)
+	a.NoError(s.Save(ctx, &store.Token{Provider: "github", UserID: "1", AccessToken: "access"}))
+	a.NoError(s.Close())
+
+	s, err = Open(path)
+	a.NoError(err)
+	defer s.Close()
+	token, err := s.Load(ctx, "github", "1")
+	a.NoError(err)
+	a.Equal("access", token.AccessToken)
+}
+
+func Test_Cleanup(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	ctx := context.Background()
+
+	s := newStore(t)
+	past, future := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	a.NoError(s.SaveSession(ctx, "expired", "data", past))
+	a.NoError(s.SaveSession(ctx, "valid", "data", future))
+	a.NoError(s.Save(ctx, &store.Token{Provider: "github", UserID: "expired", ExpiresAt: past}))
+	a.NoError(s.Save(ctx, &store.Token{Provider: "github", UserID: "refreshable", RefreshToken: "refresh", ExpiresAt: past}))
+	a.NoError(s.Save(ctx, &store.Token{Provider: "github", UserID: "forever"}))
+
+	deleted, err := s.Cleanup(ctx)
+	a.NoError(err)
+	a.Equal(2, deleted)
+
+	_, err = s.LoadSession(ctx, "valid")
+	a.NoError(err)
+	_, err = s.Load(ctx, "github", "expired")
+	a.Equal(store.ErrTokenNotFound, err)
+	_, err = s.Load(ctx, "github", "refreshable")
+	a.NoError(err)
+	_, err = s.Load(ctx, "github", "forever")
+	a.NoError(err)
+}
@@ -0,0 +1,239 @@
+/*
+Package dynamostore keeps the web sessions of gothic and the tokens of the users in a
+DynamoDB table, e.g. for applications running on AWS Lambda.
+
+The sessions and the tokens share a single table, whose partition key is the "pk"
+string attribute, with the "ttl" attribute enabled as its time to live. CreateTable
+creates such a table.
+
+	cfg, err := config.LoadDefaultConfig(ctx)
+	s := dynamostore.New(dynamodb.NewFromConfig(cfg), "goth")
+
+	gothic.Store = s.Sessions([]byte(os.Getenv("SESSION_SECRET")))
+	gothic.TokenStore = s
+*/
+package dynamostore
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/markbates/goth/store"
+)
+
+// Attributes of the table.
+const (
+	KeyAttribute = "pk"
+	TTLAttribute = "ttl"
+)
+
+// API is the part of the DynamoDB client used by the Store, implemented by
+// *dynamodb.Client.
+type API interface {
+	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error)
+	CreateTable(ctx context.Context, params *dynamodb.CreateTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.CreateTableOutput, error)
+	UpdateTimeToLive(ctx context.Context, params *dynamodb.UpdateTimeToLiveInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateTimeToLiveOutput, error)
+	DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error)
+}
+
+var _ API = &dynamodb.Client{}
+
+// Store is a store.TokenStore and a store.SessionBackend keeping the tokens and the
+// sessions in a DynamoDB table.
+type Store struct {
+	client API
+	table  string
+}
+
+var _ store.TokenStore = &Store{}
+var _ store.SessionBackend = &Store{}
+
+// New creates a Store using the table.
+func New(client API, table string) *Store {
+	return &Store{
+		client: client,
+		table:  table,
+	}
+}
+
+// Sessions returns a store.SessionStore keeping the sessions in DynamoDB, to be used
+// as gothic.Store.
+func (s *Store) Sessions(keyPairs ...[]byte) *store.SessionStore {
+	return store.NewSessionStore(s, keyPairs...)
+}
+
+// CreateTable creates the table, billed on demand, and enables its time to live. The
+// table is usually created beforehand by the infrastructure code instead.
+func (s *Store) CreateTable(ctx context.Context) error {
+	_, err := s.client.CreateTable(ctx, &dynamodb.CreateTableInput{
+		TableName: aws.String(s.table),
+		AttributeDefinitions: []types.AttributeDefinition{
+			{AttributeName: aws.String(KeyAttribute), AttributeType: types.ScalarAttributeTypeS},
+		},
+		KeySchema: []types.KeySchemaElement{
+			{AttributeName: aws.String(KeyAttribute), KeyType: types.KeyTypeHash},
+		},
+		BillingMode: types.BillingModePayPerRequest,
+	})
+	if err != nil {
+		return err
+	}
+
+	waiter := dynamodb.NewTableExistsWaiter(s.client)
+	err = waiter.Wait(ctx, &dynamodb.DescribeTableInput{TableName: aws.String(s.table)}, 5*time.Minute)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.UpdateTimeToLive(ctx, &dynamodb.UpdateTimeToLiveInput{
+		TableName: aws.String(s.table),
+		TimeToLiveSpecification: &types.TimeToLiveSpecification{
+			AttributeName: aws.String(TTLAttribute),
+			Enabled:       aws.Bool(true),
+		},
+	})
+	return err
+}
+
+func sessionKey(id string) string {
+	return "session#" + id
+}
+
+func tokenKey(provider, userID string) string {
+	return "token#" + provider + "#" + userID
+}
+
+func key(pk string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		KeyAttribute: &types.AttributeValueMemberS{Value: pk},
+	}
+}
+
+func unix(t time.Time) types.AttributeValue {
+	return &types.AttributeValueMemberN{Value: strconv.FormatInt(t.Unix(), 10)}
+}
+
+func stringAttribute(item map[string]types.AttributeValue, name string) string {
+	if v, ok := item[name].(*types.AttributeValueMemberS); ok {
+		return v.Value
+	}
+	return ""
+}
+
+func timeAttribute(item map[string]types.AttributeValue, name string) time.Time {
+	if v, ok := item[name].(*types.AttributeValueMemberN); ok {
+		if seconds, err := strconv.ParseInt(v.Value, 10, 64); err == nil {
+			return time.Unix(seconds, 0)
+		}
+	}
+	return time.Time{}
+}
+
+// get returns the item with the key, or nil if it is missing or has expired, as
+// DynamoDB deletes the expired items some time after they expire.
+func (s *Store) get(ctx context.Context, pk string) (map[string]types.AttributeValue, error) {
+	output, err := s.client.GetItem(ctx, &dynamodb.GetItemInput{
+		TableName:      aws.String(s.table),
+		Key:            key(pk),
+		ConsistentRead: aws.Bool(true),
+	})
+	if err != nil {
+		return nil, err
+	}
+	ttl := timeAttribute(output.Item, TTLAttribute)
+	if output.Item == nil || (!ttl.IsZero() && !ttl.After(time.Now())) {
+		return nil, nil
+	}
+	return output.Item, nil
+}
+
+func (s *Store) put(ctx context.Context, item map[string]types.AttributeValue) error {
+	_, err := s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.table),
+		Item:      item,
+	})
+	return err
+}
+
+func (s *Store) delete(ctx context.Context, pk string) error {
+	_, err := s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(s.table),
+		Key:       key(pk),
+	})
+	return err
+}
+
+// Save saves the token, replacing the one of the same provider and user if any. The
+// tokens which cannot be refreshed expire with their access token.
+func (s *Store) Save(ctx context.Context, token *store.Token) error {
+	item := key(tokenKey(token.Provider, token.UserID))
+	item["provider"] = &types.AttributeValueMemberS{Value: token.Provider}
+	item["user_id"] = &types.AttributeValueMemberS{Value: token.UserID}
+	item["access_token"] = &types.AttributeValueMemberS{Value: token.AccessToken}
+	item["access_token_secret"] = &types.AttributeValueMemberS{Value: token.AccessTokenSecret}
+	item["refresh_token"] = &types.AttributeValueMemberS{Value: token.RefreshToken}
+	item["id_token"] = &types.AttributeValueMemberS{Value: token.IDToken}
+	if !token.ExpiresAt.IsZero() {
+		item["expires_at"] = unix(token.ExpiresAt)
+		if token.RefreshToken == "" {
+			item[TTLAttribute] = unix(token.ExpiresAt)
+		}
+	}
+	return s.put(ctx, item)
+}
+
+// Load returns the token of the user at the provider, or store.ErrTokenNotFound.
+func (s *Store) Load(ctx context.Context, provider, userID string) (*store.Token, error) {
+	item, err := s.get(ctx, tokenKey(provider, userID))
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, store.ErrTokenNotFound
+	}
+	return &store.Token{
+		Provider:          provider,
+		UserID:            userID,
+		AccessToken:       stringAttribute(item, "access_token"),
+		AccessTokenSecret: stringAttribute(item, "access_token_secret"),
+		RefreshToken:      stringAttribute(item, "refresh_token"),
+		IDToken:           stringAttribute(item, "id_token"),
+		ExpiresAt:         timeAttribute(item, "expires_at"),
+	}, nil
+}
+
+// Delete deletes the token of the user at the provider, if any.
+func (s *Store) Delete(ctx context.Context, provider, userID string) error {
+	return s.delete(ctx, tokenKey(provider, userID))
+}
+
+// LoadSession returns the encoded values of the session, or store.ErrSessionNotFound.
+func (s *Store) LoadSession(ctx context.Context, id string) (string, error) {
+	item, err := s.get(ctx, sessionKey(id))
+	if err != nil {
+		return "", err
+	}
+	if item == nil {
+		return "", store.ErrSessionNotFound
+	}
+	return stringAttribute(item, "data"), nil
+}
+
+// SaveSession saves the encoded values of the session until it expires.
+func (s *Store) SaveSession(ctx context.Context, id, data string, expiresAt time.Time) error {
+	item := key(sessionKey(id))
+	item["data"] = &types.AttributeValueMemberS{Value: data}
+	item[TTLAttribute] = unix(expiresAt)
+	return s.put(ctx, item)
+}
+
+// DeleteSession deletes the session, if any.
+func (s *Store) DeleteSession(ctx context.Context, id string) error {
+	return s.delete(ctx, sessionKey(id))
+}
@@ -0,0 +1,104 @@
+package dynamostore
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/markbates/goth/store"
+	"github.com/markbates/goth/store/storetest"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeDynamo is an in-memory table, which does not delete the expired items, like
+// DynamoDB until it gets to them.
+type fakeDynamo struct {
+	mu    sync.Mutex
+	items map[string]map[string]types.AttributeValue
+	ttl   string
+}
+
+func newFakeDynamo() *fakeDynamo {
+	return &fakeDynamo{items: map[string]map[string]types.AttributeValue{}}
+}
+
+func (f *fakeDynamo) pk(key map[string]types.AttributeValue) string {
+	return key[KeyAttribute].(*types.AttributeValueMemberS).Value
+}
+
+func (f *fakeDynamo) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return &dynamodb.GetItemOutput{Item: f.items[f.pk(params.Key)]}, nil
+}
+
+func (f *fakeDynamo) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.items[f.pk(params.Item)] = params.Item
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (f *fakeDynamo) DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.items, f.pk(params.Key))
+	return &dynamodb.DeleteItemOutput{}, nil
+}
+
+func (f *fakeDynamo) CreateTable(ctx context.Context, params *dynamodb.CreateTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.CreateTableOutput, error) {
+	return &dynamodb.CreateTableOutput{}, nil
+}
+
+func (f *fakeDynamo) DescribeTable(ctx context.Context, params *dynamodb.DescribeTableInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DescribeTableOutput, error) {
+	return &dynamodb.DescribeTableOutput{Table: &types.TableDescription{TableStatus: types.TableStatusActive}}, nil
+}
+
+func (f *fakeDynamo) UpdateTimeToLive(ctx context.Context, params *dynamodb.UpdateTimeToLiveInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateTimeToLiveOutput, error) {
+	f.ttl = aws.ToString(params.TimeToLiveSpecification.AttributeName)
+	return &dynamodb.UpdateTimeToLiveOutput{}, nil
+}
+
+func Test_TokenStore(t *testing.T) {
+	t.Parallel()
+	storetest.TestTokenStore(t, New(newFakeDynamo(), "goth"))
+}
+
+func Test_SessionBackend(t *testing.T) {
+	t.Parallel()
+	storetest.TestSessionBackend(t, New(newFakeDynamo(), "goth"))
+}
+
+func Test_CreateTable(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	f := newFakeDynamo()
+	a.NoError(New(f, "goth").CreateTable(context.Background()))
+	a.Equal(TTLAttribute, f.ttl)
+}
+
+func Test_TTL(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	ctx := context.Background()
+
+	f := newFakeDynamo()
+	s := New(f, "goth")
+	expiresAt := time.Now().Add(time.Hour)
+	a.NoError(s.Save(ctx, &store.Token{Provider: "github", UserID: "1", ExpiresAt: expiresAt}))
+	a.NoError(s.Save(ctx, &store.Token{Provider: "github", UserID: "2", RefreshToken: "refresh", ExpiresAt: expiresAt}))
+	a.NoError(s.SaveSession(ctx, "id", "data", expiresAt))
+
+	a.Contains(f.items["token#github#1"], TTLAttribute)
+	a.NotContains(f.items["token#github#2"], TTLAttribute)
+	a.Contains(f.items["session#id"], TTLAttribute)
+
+	a.NoError(s.Save(ctx, &store.Token{Provider: "github", UserID: "3", ExpiresAt: time.Now().Add(-time.Hour)}))
+	_, err := s.Load(ctx, "github", "3")
+	a.Equal(store.ErrTokenNotFound, err)
+}
@@ -0,0 +1,264 @@
+package store
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// ErrDecrypt is returned when a payload read from the store cannot be decrypted, e.g.
+// its key is not in the keyring anymore or it has been tampered with.
+var ErrDecrypt = errors.New("store: cannot decrypt payload")
+
+// Store is a TokenStore also keeping the web sessions, as all the stores of Goth do.
+type Store interface {
+	TokenStore
+	SessionBackend
+}
+
+// Keyring holds the keys encrypting the payloads of an EncryptedStore. Every key has
+// an ID, which is saved with the payloads it encrypts so that the keys can be rotated:
+// new payloads are encrypted with the current key while the previous keys still
+// decrypt the old ones.
+type Keyring interface {
+	// Current returns the ID and the key to encrypt new payloads with.
+	Current() (id string, key []byte, err error)
+	// Key returns the key of the ID, to decrypt payloads.
+	Key(id string) ([]byte, error)
+}
+
+// StaticKeyring is a Keyring whose keys are known upfront.
+type StaticKeyring struct {
+	current string
+	keys    map[string][]byte
+}
+
+var _ Keyring = &StaticKeyring{}
+
+// NewKeyring returns a StaticKeyring encrypting with the key of the current ID. The
+// keys must be either 16, 24, or 32 bytes to select AES-128, AES-192, or AES-256, and
+// their IDs must not contain a colon.
+func NewKeyring(current string, keys map[string][]byte) (*StaticKeyring, error) {
+	if _, ok := keys[current]; !ok {
+		return nil, fmt.Errorf("store: no key with the ID %q", current)
+	}
+	k := &StaticKeyring{current: current, keys: map[string][]byte{}}
+	for id, key := range keys {
+		if id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("store: invalid key ID %q", id)
+		}
+		if _, err := aes.NewCipher(key); err != nil {
+			return nil, fmt.Errorf("store: invalid key %q: %v", id, err)
+		}
+		k.keys[id] = key
+	}
+	return k, nil
+}
+
+// Current returns the ID and the key to encrypt new payloads with.
+func (k *StaticKeyring) Current() (string, []byte, error) {
+	return k.current, k.keys[k.current], nil
+}
+
+// Key returns the key of the ID.
+func (k *StaticKeyring) Key(id string) ([]byte, error) {
+	key, ok := k.keys[id]
+	if !ok {
+		return nil, ErrDecrypt
+	}
+	return key, nil
+}
+
+// EncryptedStore is a Store encrypting the tokens and the sessions with AES-GCM before
+// they reach another store, so that they are never kept in plaintext.
+//
+// The provider, the ID of the user and the expiry of the tokens are not encrypted, the
+// stores need them to find and expire the tokens.
+type EncryptedStore struct {
+	inner   Store
+	keyring Keyring
+}
+
+var _ Store = &EncryptedStore{}
+var _ SessionLister = &EncryptedStore{}
+
+// Encrypt returns a Store encrypting the tokens and the sessions kept in inner with the
+// keys of the keyring.
+func Encrypt(inner Store, keyring Keyring) *EncryptedStore {
+	return &EncryptedStore{inner: inner, keyring: keyring}
+}
+
+// Sessions returns a SessionStore keeping the encrypted sessions in the inner store.
+func (s *EncryptedStore) Sessions(keyPairs ...[]byte) *SessionStore {
+	return NewSessionStore(s, keyPairs...)
+}
+
+// Save encrypts the secrets of the token and saves it in the inner store.
+func (s *EncryptedStore) Save(ctx context.Context, token *Token) error {
+	encrypted := *token
+	for _, field := range tokenFields(&encrypted) {
+		var err error
+		*field.value, err = s.encrypt(*field.value, token.Provider, token.UserID, field.name)
+		if err != nil {
+			return err
+		}
+	}
+	return s.inner.Save(ctx, &encrypted)
+}
+
+// Load loads the token of the user at the provider from the inner store and decrypts
+// its secrets.
+func (s *EncryptedStore) Load(ctx context.Context, provider, userID string) (*Token, error) {
+	token, err := s.inner.Load(ctx, provider, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range tokenFields(token) {
+		*field.value, err = s.decrypt(*field.value, provider, userID, field.name)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return token, nil
+}
+
+// Delete deletes the token of the user at the provider from the inner store.
+func (s *EncryptedStore) Delete(ctx context.Context, provider, userID string) error {
+	return s.inner.Delete(ctx, provider, userID)
+}
+
+// LoadSession loads the session from the inner store and decrypts it.
+func (s *EncryptedStore) LoadSession(ctx context.Context, id string) (string, error) {
+	data, err := s.inner.LoadSession(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	return s.decrypt(data, "session", id)
+}
+
+// SaveSession encrypts the session and saves it in the inner store.
+func (s *EncryptedStore) SaveSession(ctx context.Context, id, data string, expiresAt time.Time) error {
+	data, err := s.encrypt(data, "session", id)
+	if err != nil {
+		return err
+	}
+	return s.inner.SaveSession(ctx, id, data, expiresAt)
+}
+
+// DeleteSession deletes the session from the inner store.
+func (s *EncryptedStore) DeleteSession(ctx context.Context, id string) error {
+	return s.inner.DeleteSession(ctx, id)
+}
+
+// ListSessions lists the sessions of the inner store, which must be a SessionLister,
+// and calls fn with them decrypted. The sessions which cannot be decrypted, e.g. whose
+// key is not in the keyring anymore, are listed with empty values.
+func (s *EncryptedStore) ListSessions(ctx context.Context, fn func(id, data string, expiresAt time.Time) error) error {
+	lister, ok := s.inner.(SessionLister)
+	if !ok {
+		return fmt.Errorf("store: %T cannot list its sessions", s.inner)
+	}
+	return lister.ListSessions(ctx, func(id, data string, expiresAt time.Time) error {
+		data, err := s.decrypt(data, "session", id)
+		if err != nil {
+			data = ""
+		}
+		return fn(id, data, expiresAt)
+	})
+}
+
+type tokenField struct {
+	name  string
+	value *string
+}
+
+// tokenFields returns the secrets of the token.
+func tokenFields(t *Token) []tokenField {
+	return []tokenField{
+		{"access_token", &t.AccessToken},
+		{"access_token_secret", &t.AccessTokenSecret},
+		{"refresh_token", &t.RefreshToken},
+		{"id_token", &t.IDToken},
+	}
+}
+
+// encrypt returns the ID of the current key and the sealed plaintext, bound to where it
+// is stored by the additional data so that it cannot be moved to another token or
+// session. Empty strings stay empty, e.g. tokens without a refresh token.
+func (s *EncryptedStore) encrypt(plaintext string, additionalData ...string) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+	id, key, err := s.keyring.Current()
+	if err != nil {
+		return "", err
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), aad(id, additionalData))
+	return id + ":" + base64.RawStdEncoding.EncodeToString(sealed), nil
+}
+
+// decrypt opens a payload sealed by encrypt with the key of its ID.
+func (s *EncryptedStore) decrypt(payload string, additionalData ...string) (string, error) {
+	if payload == "" {
+		return "", nil
+	}
+	i := strings.IndexByte(payload, ':')
+	if i < 0 {
+		return "", ErrDecrypt
+	}
+	id := payload[:i]
+	sealed, err := base64.RawStdEncoding.DecodeString(payload[i+1:])
+	if err != nil {
+		return "", ErrDecrypt
+	}
+	key, err := s.keyring.Key(id)
+	if err != nil {
+		return "", err
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", ErrDecrypt
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, ciphertext, aad(id, additionalData))
+	if err != nil {
+		return "", ErrDecrypt
+	}
+	return string(plaintext), nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// aad joins the key ID and the location of a payload, each prefixed by its length so
+// that different locations never give the same additional data.
+func aad(id string, location []string) []byte {
+	b := []byte{}
+	for _, s := range append([]string{id}, location...) {
+		b = append(b, fmt.Sprintf("%d:%s", len(s), s)...)
+	}
+	return b
+}
@@ -0,0 +1,119 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/markbates/goth/store"
+	"github.com/markbates/goth/store/storetest"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	oldKey = []byte("0123456789abcdef0123456789abcdef")
+	newKey = []byte("fedcba9876543210fedcba9876543210")
+)
+
+func keyring(t *testing.T, current string, keys map[string][]byte) store.Keyring {
+	k, err := store.NewKeyring(current, keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return k
+}
+
+func Test_Encrypt(t *testing.T) {
+	t.Parallel()
+	k := keyring(t, "1", map[string][]byte{"1": oldKey})
+	storetest.TestTokenStore(t, store.Encrypt(store.NewMemoryStore(), k))
+	storetest.TestSessionBackend(t, store.Encrypt(store.NewMemoryStore(), k))
+}
+
+func Test_Encrypt_NoPlaintext(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	ctx := context.Background()
+
+	inner := store.NewMemoryStore()
+	s := store.Encrypt(inner, keyring(t, "1", map[string][]byte{"1": oldKey}))
+	a.NoError(s.Save(ctx, &store.Token{Provider: "github", UserID: "1", AccessToken: "access", RefreshToken: "refresh"}))
+	a.NoError(s.SaveSession(ctx, "id", "data", time.Now().Add(time.Hour)))
+
+	token, err := inner.Load(ctx, "github", "1")
+	a.NoError(err)
+	a.Regexp("^1:", token.AccessToken)
+	a.NotContains(token.AccessToken, "access")
+	a.NotContains(token.RefreshToken, "refresh")
+	a.Empty(token.IDToken)
+	data, err := inner.LoadSession(ctx, "id")
+	a.NoError(err)
+	a.NotContains(data, "data")
+
+	// payloads are bound to their token
+	token.UserID = "2"
+	a.NoError(inner.Save(ctx, token))
+	_, err = s.Load(ctx, "github", "2")
+	a.Equal(store.ErrDecrypt, err)
+}
+
+func Test_Encrypt_Rotation(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	ctx := context.Background()
+
+	inner := store.NewMemoryStore()
+	old := store.Encrypt(inner, keyring(t, "1", map[string][]byte{"1": oldKey}))
+	a.NoError(old.Save(ctx, &store.Token{Provider: "github", UserID: "1", AccessToken: "access"}))
+
+	rotated := store.Encrypt(inner, keyring(t, "2", map[string][]byte{"1": oldKey, "2": newKey}))
+	token, err := rotated.Load(ctx, "github", "1")
+	a.NoError(err)
+	a.Equal("access", token.AccessToken)
+
+	// saving again encrypts with the new key
+	a.NoError(rotated.Save(ctx, token))
+	token, err = inner.Load(ctx, "github", "1")
+	a.NoError(err)
+	a.Regexp("^2:", token.AccessToken)
+
+	_, err = old.Load(ctx, "github", "1")
+	a.Equal(store.ErrDecrypt, err)
+}
+
+func Test_Encrypt_ListSessions(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	ctx := context.Background()
+
+	inner := store.NewMemoryStore()
+	old := store.Encrypt(inner, keyring(t, "1", map[string][]byte{"1": oldKey}))
+	a.NoError(old.SaveSession(ctx, "old", "old data", time.Now().Add(time.Hour)))
+	s := store.Encrypt(inner, keyring(t, "2", map[string][]byte{"2": newKey}))
+	a.NoError(s.SaveSession(ctx, "new", "new data", time.Now().Add(time.Hour)))
+
+	listed := map[string]string{}
+	a.NoError(s.ListSessions(ctx, func(id, data string, expiresAt time.Time) error {
+		listed[id] = data
+		return nil
+	}))
+	a.Equal(map[string]string{"old": "", "new": "new data"}, listed)
+
+	// the inner store cannot list its sessions
+	s = store.Encrypt(struct{ store.Store }{inner}, keyring(t, "2", map[string][]byte{"2": newKey}))
+	a.Error(s.ListSessions(ctx, func(id, data string, expiresAt time.Time) error {
+		return nil
+	}))
+}
+
+func Test_NewKeyring(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	_, err := store.NewKeyring("2", map[string][]byte{"1": oldKey})
+	a.Error(err)
+	_, err = store.NewKeyring("1", map[string][]byte{"1": []byte("short")})
+	a.Error(err)
+	_, err = store.NewKeyring("a:b", map[string][]byte{"a:b": oldKey})
+	a.Error(err)
+}
@@ -0,0 +1,192 @@
+/*
+Package memcachestore keeps the web sessions of gothic and the tokens of the users in
+memcached, spread over the servers with consistent hashing.
+
+	s, err := memcachestore.NewWithServers("10.0.0.1:11211", "10.0.0.2:11211")
+
+	gothic.Store = s.Sessions([]byte(os.Getenv("SESSION_SECRET")))
+	gothic.TokenStore = s
+
+The sessions expire with their cookies, see store.SessionStore.MaxAge. As memcached may
+evict any item, the tokens are better kept in a persistent store.
+*/
+package memcachestore
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+	"github.com/markbates/goth/store"
+)
+
+// DefaultPrefix is the prefix of the keys of a new Store.
+const DefaultPrefix = "goth:"
+
+// DefaultTokenExpiration is how long a new Store keeps the tokens which can be
+// refreshed or never expire.
+const DefaultTokenExpiration = 30 * 24 * time.Hour
+
+// maxRelativeExpiration is the longest expiration memcached takes as a number of
+// seconds, longer ones must be given as Unix times.
+const maxRelativeExpiration = 30 * 24 * time.Hour
+
+// Client is the part of the memcached client used by the Store, implemented by
+// *memcache.Client.
+type Client interface {
+	Get(key string) (*memcache.Item, error)
+	Set(item *memcache.Item) error
+	Delete(key string) error
+}
+
+var _ Client = &memcache.Client{}
+
+// Store is a store.TokenStore and a store.SessionBackend keeping the tokens and the
+// sessions in memcached.
+type Store struct {
+	client          Client
+	prefix          string
+	tokenExpiration time.Duration
+}
+
+var _ store.TokenStore = &Store{}
+var _ store.SessionBackend = &Store{}
+
+// New creates a Store using the client.
+func New(client Client) *Store {
+	return &Store{
+		client:          client,
+		prefix:          DefaultPrefix,
+		tokenExpiration: DefaultTokenExpiration,
+	}
+}
+
+// NewWithServers creates a Store using the servers, with consistent hashing.
+func NewWithServers(servers ...string) (*Store, error) {
+	ring, err := NewRing(servers...)
+	if err != nil {
+		return nil, err
+	}
+	return New(memcache.NewFromSelector(ring)), nil
+}
+
+// WithPrefix sets the prefix of the keys, e.g. to share the servers between applications.
+func (s *Store) WithPrefix(prefix string) *Store {
+	s.prefix = prefix
+	return s
+}
+
+// WithTokenExpiration sets how long the tokens which can be refreshed, or never expire,
+// are kept. Zero keeps them until memcached evicts them.
+func (s *Store) WithTokenExpiration(expiration time.Duration) *Store {
+	s.tokenExpiration = expiration
+	return s
+}
+
+// Sessions returns a store.SessionStore keeping the sessions in memcached, to be used
+// as gothic.Store.
+func (s *Store) Sessions(keyPairs ...[]byte) *store.SessionStore {
+	return store.NewSessionStore(s, keyPairs...)
+}
+
+func (s *Store) sessionKey(id string) string {
+	return s.prefix + "session:" + id
+}
+
+// tokenKey hashes the provider and the user ID, which may contain characters not
+// allowed in the keys of memcached.
+func (s *Store) tokenKey(provider, userID string) string {
+	hash := sha256.Sum256([]byte(provider + "\x00" + userID))
+	return s.prefix + "token:" + hex.EncodeToString(hash[:])
+}
+
+// expiration returns the expiration of an item expiring at the time, as memcached
+// expects it.
+func expiration(expiresAt time.Time) int32 {
+	ttl := time.Until(expiresAt)
+	if ttl > maxRelativeExpiration {
+		return int32(expiresAt.Unix())
+	}
+	return int32(ttl / time.Second)
+}
+
+// set sets the item until it expires, or deletes it if it has expired already.
+func (s *Store) set(key string, value []byte, expiresAt time.Time) error {
+	item := &memcache.Item{Key: key, Value: value}
+	if !expiresAt.IsZero() {
+		item.Expiration = expiration(expiresAt)
+		if item.Expiration <= 0 {
+			return s.delete(key)
+		}
+	}
+	return s.client.Set(item)
+}
+
+func (s *Store) delete(key string) error {
+	err := s.client.Delete(key)
+	if err == memcache.ErrCacheMiss {
+		return nil
+	}
+	return err
+}
+
+// Save saves the token, replacing the one of the same provider and user if any. The
+// tokens which cannot be refreshed expire with their access token.
+func (s *Store) Save(ctx context.Context, token *store.Token) error {
+	bits, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	expiresAt := token.ExpiresAt
+	if token.RefreshToken != "" || expiresAt.IsZero() {
+		expiresAt = time.Time{}
+		if s.tokenExpiration > 0 {
+			expiresAt = time.Now().Add(s.tokenExpiration)
+		}
+	}
+	return s.set(s.tokenKey(token.Provider, token.UserID), bits, expiresAt)
+}
+
+// Load returns the token of the user at the provider, or store.ErrTokenNotFound.
+func (s *Store) Load(ctx context.Context, provider, userID string) (*store.Token, error) {
+	item, err := s.client.Get(s.tokenKey(provider, userID))
+	if err == memcache.ErrCacheMiss {
+		return nil, store.ErrTokenNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	token := &store.Token{}
+	err = json.Unmarshal(item.Value, token)
+	return token, err
+}
+
+// Delete deletes the token of the user at the provider, if any.
+func (s *Store) Delete(ctx context.Context, provider, userID string) error {
+	return s.delete(s.tokenKey(provider, userID))
+}
+
+// LoadSession returns the encoded values of the session, or store.ErrSessionNotFound.
+func (s *Store) LoadSession(ctx context.Context, id string) (string, error) {
+	item, err := s.client.Get(s.sessionKey(id))
+	if err == memcache.ErrCacheMiss {
+		return "", store.ErrSessionNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(item.Value), nil
+}
+
+// SaveSession saves the encoded values of the session until it expires.
+func (s *Store) SaveSession(ctx context.Context, id, data string, expiresAt time.Time) error {
+	return s.set(s.sessionKey(id), []byte(data), expiresAt)
+}
+
+// DeleteSession deletes the session, if any.
+func (s *Store) DeleteSession(ctx context.Context, id string) error {
+	return s.delete(s.sessionKey(id))
+}
@@ -0,0 +1,131 @@
+package memcachestore
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bradfitz/gomemcache/memcache"
+	"github.com/markbates/goth/store"
+	"github.com/markbates/goth/store/storetest"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeMemcache is an in-memory memcached, keeping the items with their expiration.
+type fakeMemcache struct {
+	mu    sync.Mutex
+	items map[string]memcache.Item
+}
+
+func newFakeMemcache() *fakeMemcache {
+	return &fakeMemcache{items: map[string]memcache.Item{}}
+}
+
+func (f *fakeMemcache) Get(key string) (*memcache.Item, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	item, ok := f.items[key]
+	if !ok {
+		return nil, memcache.ErrCacheMiss
+	}
+	return &item, nil
+}
+
+func (f *fakeMemcache) Set(item *memcache.Item) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.items[item.Key] = *item
+	return nil
+}
+
+func (f *fakeMemcache) Delete(key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.items[key]; !ok {
+		return memcache.ErrCacheMiss
+	}
+	delete(f.items, key)
+	return nil
+}
+
+func Test_TokenStore(t *testing.T) {
+	t.Parallel()
+	storetest.TestTokenStore(t, New(newFakeMemcache()))
+}
+
+func Test_SessionBackend(t *testing.T) {
+	t.Parallel()
+	storetest.TestSessionBackend(t, New(newFakeMemcache()))
+}
+
+func Test_Expiration(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	ctx := context.Background()
+
+	f := newFakeMemcache()
+	s := New(f).WithTokenExpiration(time.Hour)
+
+	a.NoError(s.SaveSession(ctx, "id", "data", time.Now().Add(2*time.Hour)))
+	a.InDelta(2*3600, f.items["goth:session:id"].Expiration, 2)
+
+	// beyond 30 days, memcached expects a Unix time
+	expiresAt := time.Now().Add(60 * 24 * time.Hour)
+	a.NoError(s.SaveSession(ctx, "long", "data", expiresAt))
+	a.Equal(int32(expiresAt.Unix()), f.items["goth:session:long"].Expiration)
+
+	a.NoError(s.Save(ctx, &store.Token{Provider: "github", UserID: "1", RefreshToken: "refresh", ExpiresAt: time.Now().Add(time.Minute)}))
+	a.InDelta(3600, f.items[s.tokenKey("github", "1")].Expiration, 2)
+
+	s.WithTokenExpiration(0)
+	a.NoError(s.Save(ctx, &store.Token{Provider: "github", UserID: "1", RefreshToken: "refresh"}))
+	a.Equal(int32(0), f.items[s.tokenKey("github", "1")].Expiration)
+}
+
+func Test_Ring(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	_, err := (&Ring{}).PickServer("key")
+	a.Equal(memcache.ErrNoServers, err)
+
+	ring, err := NewRing("127.0.0.1:11211", "127.0.0.1:11212", "127.0.0.1:11213")
+	a.NoError(err)
+
+	before := map[string]string{}
+	counts := map[string]int{}
+	for i := 0; i < 3000; i++ {
+		key := "goth:session:" + strconv.Itoa(i)
+		addr, err := ring.PickServer(key)
+		a.NoError(err)
+		before[key] = addr.String()
+		counts[addr.String()]++
+	}
+	a.Len(counts, 3)
+	for _, count := range counts {
+		a.True(count > 500, "unbalanced ring: %v", counts)
+	}
+
+	// adding a server only moves some of the keys, to the new server
+	a.NoError(ring.SetServers("127.0.0.1:11211", "127.0.0.1:11212", "127.0.0.1:11213", "127.0.0.1:11214"))
+	moved := 0
+	for key, server := range before {
+		addr, err := ring.PickServer(key)
+		a.NoError(err)
+		if addr.String() != server {
+			a.Equal("127.0.0.1:11214", addr.String())
+			moved++
+		}
+	}
+	a.True(moved < 1500, "%d keys moved", moved)
+
+	servers := 0
+	a.NoError(ring.Each(func(addr net.Addr) error {
+		servers++
+		return nil
+	}))
+	a.Equal(4, servers)
+}
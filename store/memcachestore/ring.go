@@ -0,0 +1,98 @@
+package memcachestore
+
+import (
+	"hash/crc32"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/bradfitz/gomemcache/memcache"
+)
+
+// pointsPerServer is the number of points of each server on the ring.
+const pointsPerServer = 160
+
+// Ring is a memcache.ServerSelector spreading the keys over the servers with
+// consistent hashing, so that adding or removing a server only moves the keys of
+// that server, instead of almost all of them with the default modulo hashing.
+type Ring struct {
+	mu      sync.RWMutex
+	points  []uint32
+	servers map[uint32]net.Addr
+	addrs   []net.Addr
+}
+
+var _ memcache.ServerSelector = &Ring{}
+
+// NewRing returns a Ring of the servers, given as "host:port" or as the path of a
+// Unix socket.
+func NewRing(servers ...string) (*Ring, error) {
+	r := &Ring{}
+	return r, r.SetServers(servers...)
+}
+
+// SetServers replaces the servers of the ring.
+func (r *Ring) SetServers(servers ...string) error {
+	addrs := make([]net.Addr, len(servers))
+	for i, server := range servers {
+		var err error
+		if strings.Contains(server, "/") {
+			addrs[i], err = net.ResolveUnixAddr("unix", server)
+		} else {
+			addrs[i], err = net.ResolveTCPAddr("tcp", server)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	points := make([]uint32, 0, len(servers)*pointsPerServer)
+	byPoint := make(map[uint32]net.Addr, len(servers)*pointsPerServer)
+	for i, server := range servers {
+		for j := 0; j < pointsPerServer; j++ {
+			point := crc32.ChecksumIEEE([]byte(server + "-" + strconv.Itoa(j)))
+			if _, taken := byPoint[point]; taken {
+				continue
+			}
+			byPoint[point] = addrs[i]
+			points = append(points, point)
+		}
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i] < points[j] })
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.points = points
+	r.servers = byPoint
+	r.addrs = addrs
+	return nil
+}
+
+// PickServer returns the server of the key, the first one after its hash on the ring.
+func (r *Ring) PickServer(key string) (net.Addr, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if len(r.points) == 0 {
+		return nil, memcache.ErrNoServers
+	}
+	hash := crc32.ChecksumIEEE([]byte(key))
+	i := sort.Search(len(r.points), func(i int) bool { return r.points[i] >= hash })
+	if i == len(r.points) {
+		i = 0
+	}
+	return r.servers[r.points[i]], nil
+}
+
+// Each calls f with each server of the ring.
+func (r *Ring) Each(f func(net.Addr) error) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, addr := range r.addrs {
+		if err := f(addr); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+package store
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore is a TokenStore and a SessionBackend keeping the tokens and the sessions
+// in memory, they are lost when the process exits. It is meant for tests and single
+// instance applications, which should call StartCleanup to forget the expired sessions.
+type MemoryStore struct {
+	mu       sync.RWMutex
+	tokens   map[[2]string]Token
+	sessions map[string]memorySession
+}
+
+type memorySession struct {
+	data      string
+	expiresAt time.Time
+}
+
+var _ TokenStore = &MemoryStore{}
+var _ SessionBackend = &MemoryStore{}
+var _ SessionLister = &MemoryStore{}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		tokens:   map[[2]string]Token{},
+		sessions: map[string]memorySession{},
+	}
+}
+
+// Sessions returns a SessionStore keeping the sessions in memory.
+func (s *MemoryStore) Sessions(keyPairs ...[]byte) *SessionStore {
+	return NewSessionStore(s, keyPairs...)
+}
+
+// Save saves a copy of the token.
+func (s *MemoryStore) Save(ctx context.Context, token *Token) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens[[2]string{token.Provider, token.UserID}] = *token
+	return nil
+}
+
+// Load returns a copy of the token of the user at the provider.
+func (s *MemoryStore) Load(ctx context.Context, provider, userID string) (*Token, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	token, ok := s.tokens[[2]string{provider, userID}]
+	if !ok {
+		return nil, ErrTokenNotFound
+	}
+	return &token, nil
+}
+
+// Delete deletes the token of the user at the provider.
+func (s *MemoryStore) Delete(ctx context.Context, provider, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.tokens, [2]string{provider, userID})
+	return nil
+}
+
+// LoadSession returns the encoded values of the session.
+func (s *MemoryStore) LoadSession(ctx context.Context, id string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	session, ok := s.sessions[id]
+	if !ok || !session.expiresAt.After(time.Now()) {
+		return "", ErrSessionNotFound
+	}
+	return session.data, nil
+}
+
+// SaveSession saves the encoded values of the session.
+func (s *MemoryStore) SaveSession(ctx context.Context, id, data string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sessions[id] = memorySession{data: data, expiresAt: expiresAt}
+	return nil
+}
+
+// DeleteSession deletes the session.
+func (s *MemoryStore) DeleteSession(ctx context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, id)
+	return nil
+}
+
+// ListSessions calls fn with the encoded values of every session which has not expired.
+func (s *MemoryStore) ListSessions(ctx context.Context, fn func(id, data string, expiresAt time.Time) error) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	now := time.Now()
+	for id, session := range s.sessions {
+		if !session.expiresAt.After(now) {
+			continue
+		}
+		if err := fn(id, session.data, session.expiresAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Cleanup deletes the expired sessions, and the expired tokens which cannot be
+// refreshed. It returns the number of deleted sessions and tokens.
+func (s *MemoryStore) Cleanup() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	deleted := 0
+	for id, session := range s.sessions {
+		if !session.expiresAt.After(now) {
+			delete(s.sessions, id)
+			deleted++
+		}
+	}
+	for key, token := range s.tokens {
+		if !token.ExpiresAt.IsZero() && !token.ExpiresAt.After(now) && token.RefreshToken == "" {
+			delete(s.tokens, key)
+			deleted++
+		}
+	}
+	return deleted
+}
+
+// StartCleanup calls Cleanup every interval in the background, until the returned
+// function is called.
+func (s *MemoryStore) StartCleanup(interval time.Duration) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.Cleanup()
+			}
+		}
+	}()
+	return cancel
+}
@@ -0,0 +1,45 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/markbates/goth/store"
+	"github.com/markbates/goth/store/storetest"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_MemoryStore(t *testing.T) {
+	t.Parallel()
+	storetest.TestTokenStore(t, store.NewMemoryStore())
+}
+
+func Test_MemoryStore_Sessions(t *testing.T) {
+	t.Parallel()
+	storetest.TestSessionBackend(t, store.NewMemoryStore())
+}
+
+func Test_MemoryStore_Cleanup(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	ctx := context.Background()
+
+	s := store.NewMemoryStore()
+	a.NoError(s.SaveSession(ctx, "expired", "data", time.Now().Add(-time.Minute)))
+	a.NoError(s.SaveSession(ctx, "id", "data", time.Now().Add(time.Hour)))
+	a.NoError(s.Save(ctx, &store.Token{Provider: "github", UserID: "1", ExpiresAt: time.Now().Add(-time.Minute)}))
+	a.NoError(s.Save(ctx, &store.Token{Provider: "github", UserID: "2", RefreshToken: "refresh", ExpiresAt: time.Now().Add(-time.Minute)}))
+	a.NoError(s.Save(ctx, &store.Token{Provider: "github", UserID: "3"}))
+
+	a.Equal(2, s.Cleanup())
+	a.Equal(0, s.Cleanup())
+	_, err := s.LoadSession(ctx, "id")
+	a.NoError(err)
+	_, err = s.Load(ctx, "github", "1")
+	a.Equal(store.ErrTokenNotFound, err)
+	_, err = s.Load(ctx, "github", "2")
+	a.NoError(err)
+	_, err = s.Load(ctx, "github", "3")
+	a.NoError(err)
+}
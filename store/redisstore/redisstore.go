@@ -0,0 +1,193 @@
+/*
+Package redisstore keeps the web sessions of gothic and the tokens of the users in Redis,
+so that the sessions are shared by all the instances of an application and the cookies
+only hold their ID, however large the tokens of the providers are.
+
+	client := redis.NewClient(&redis.Options{Addr: "localhost:6379"})
+	s := redisstore.New(client).WithPrefix("myapp:goth:")
+
+	gothic.Store = s.Sessions([]byte(os.Getenv("SESSION_HASH_KEY")), []byte(os.Getenv("SESSION_BLOCK_KEY")))
+	gothic.TokenStore = s
+
+The sessions expire with Redis TTLs. They are encrypted at rest when an encryption
+(block) key of 16, 24 or 32 bytes is given after the authentication key, see
+sessions.NewCookieStore().
+*/
+package redisstore
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/markbates/goth/store"
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultPrefix is the prefix of the keys of a new Store.
+const DefaultPrefix = "goth:"
+
+// Store is a store.TokenStore and a store.SessionBackend keeping the tokens and the
+// sessions in Redis.
+type Store struct {
+	client redis.UniversalClient
+	prefix string
+}
+
+var _ store.TokenStore = &Store{}
+var _ store.SessionBackend = &Store{}
+var _ store.SessionLister = &Store{}
+
+// New creates a Store using the client, which can be a single node, a cluster or a
+// ring client.
+func New(client redis.UniversalClient) *Store {
+	return &Store{
+		client: client,
+		prefix: DefaultPrefix,
+	}
+}
+
+// WithPrefix sets the prefix of the keys, e.g. to share a database between applications.
+func (s *Store) WithPrefix(prefix string) *Store {
+	s.prefix = prefix
+	return s
+}
+
+// Sessions returns a store.SessionStore keeping the sessions in Redis, to be used as
+// gothic.Store.
+func (s *Store) Sessions(keyPairs ...[]byte) *store.SessionStore {
+	return store.NewSessionStore(s, keyPairs...)
+}
+
+func (s *Store) sessionKey(id string) string {
+	return s.prefix + "session:" + id
+}
+
+// tokenKey prefixes the provider with its length, so that the providers and the IDs of
+// the users containing colons never give the same key.
+func (s *Store) tokenKey(provider, userID string) string {
+	return s.prefix + "token:" + strconv.Itoa(len(provider)) + ":" + provider + ":" + userID
+}
+
+// set sets the value of the key until it expires, or deletes it if it has expired
+// already.
+func (s *Store) set(ctx context.Context, key string, value interface{}, expiresAt time.Time) error {
+	if expiresAt.IsZero() {
+		return s.client.Set(ctx, key, value, 0).Err()
+	}
+	ttl := time.Until(expiresAt)
+	if ttl <= 0 {
+		return s.client.Del(ctx, key).Err()
+	}
+	return s.client.Set(ctx, key, value, ttl).Err()
+}
+
+// Save saves the token, replacing the one of the same provider and user if any. The
+// tokens which cannot be refreshed expire with their access token.
+func (s *Store) Save(ctx context.Context, token *store.Token) error {
+	bits, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	expiresAt := token.ExpiresAt
+	if token.RefreshToken != "" {
+		expiresAt = time.Time{}
+	}
+	return s.set(ctx, s.tokenKey(token.Provider, token.UserID), bits, expiresAt)
+}
+
+// Load returns the token of the user at the provider, or store.ErrTokenNotFound.
+func (s *Store) Load(ctx context.Context, provider, userID string) (*store.Token, error) {
+	bits, err := s.client.Get(ctx, s.tokenKey(provider, userID)).Bytes()
+	if err == redis.Nil {
+		return nil, store.ErrTokenNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	token := &store.Token{}
+	err = json.Unmarshal(bits, token)
+	return token, err
+}
+
+// Delete deletes the token of the user at the provider, if any.
+func (s *Store) Delete(ctx context.Context, provider, userID string) error {
+	return s.client.Del(ctx, s.tokenKey(provider, userID)).Err()
+}
+
+// LoadSession returns the encoded values of the session, or store.ErrSessionNotFound.
+func (s *Store) LoadSession(ctx context.Context, id string) (string, error) {
+	data, err := s.client.Get(ctx, s.sessionKey(id)).Result()
+	if err == redis.Nil {
+		return "", store.ErrSessionNotFound
+	}
+	return data, err
+}
+
+// SaveSession saves the encoded values of the session until it expires.
+func (s *Store) SaveSession(ctx context.Context, id, data string, expiresAt time.Time) error {
+	return s.set(ctx, s.sessionKey(id), data, expiresAt)
+}
+
+// DeleteSession deletes the session, if any.
+func (s *Store) DeleteSession(ctx context.Context, id string) error {
+	return s.client.Del(ctx, s.sessionKey(id)).Err()
+}
+
+// ListSessions calls fn with the encoded values of every session which has not expired.
+// The keys are scanned on every master of a cluster and every shard of a ring, and fn
+// is never called concurrently.
+func (s *Store) ListSessions(ctx context.Context, fn func(id, data string, expiresAt time.Time) error) error {
+	mu := sync.Mutex{}
+	scan := func(ctx context.Context, node redis.Cmdable) error {
+		prefix := s.sessionKey("")
+		iter := node.Scan(ctx, 0, globEscaper.Replace(prefix)+"*", 100).Iterator()
+		for iter.Next(ctx) {
+			key := iter.Val()
+			data, err := s.client.Get(ctx, key).Result()
+			if err == redis.Nil {
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			ttl, err := s.client.PTTL(ctx, key).Result()
+			if err != nil {
+				return err
+			}
+			expiresAt := time.Time{}
+			switch {
+			case ttl > 0:
+				expiresAt = time.Now().Add(ttl)
+			case ttl != -1:
+				// expired since it was read
+				continue
+			}
+			mu.Lock()
+			err = fn(strings.TrimPrefix(key, prefix), data, expiresAt)
+			mu.Unlock()
+			if err != nil {
+				return err
+			}
+		}
+		return iter.Err()
+	}
+
+	switch client := s.client.(type) {
+	case *redis.ClusterClient:
+		return client.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+			return scan(ctx, node)
+		})
+	case *redis.Ring:
+		return client.ForEachShard(ctx, func(ctx context.Context, node *redis.Client) error {
+			return scan(ctx, node)
+		})
+	}
+	return scan(ctx, s.client)
+}
+
+// globEscaper escapes the prefix of the keys in the patterns of SCAN.
+var globEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`)
@@ -0,0 +1,112 @@
+package redisstore
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/markbates/goth/store"
+	"github.com/markbates/goth/store/storetest"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func newStore(t *testing.T) (*Store, *miniredis.Miniredis) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return New(client), server
+}
+
+func Test_TokenStore(t *testing.T) {
+	t.Parallel()
+	s, _ := newStore(t)
+	storetest.TestTokenStore(t, s)
+}
+
+func Test_SessionBackend(t *testing.T) {
+	t.Parallel()
+	s, _ := newStore(t)
+	storetest.TestSessionBackend(t, s)
+}
+
+func Test_Expiry(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	ctx := context.Background()
+
+	s, server := newStore(t)
+	s.WithPrefix("app:")
+	a.NoError(s.SaveSession(ctx, "id", "data", time.Now().Add(time.Hour)))
+	a.NoError(s.Save(ctx, &store.Token{Provider: "github", UserID: "1", ExpiresAt: time.Now().Add(time.Hour)}))
+	a.NoError(s.Save(ctx, &store.Token{Provider: "github", UserID: "2", RefreshToken: "refresh", ExpiresAt: time.Now().Add(time.Hour)}))
+	a.True(server.Exists("app:session:id"))
+	a.True(server.Exists("app:token:6:github:1"))
+
+	server.FastForward(2 * time.Hour)
+	_, err := s.LoadSession(ctx, "id")
+	a.Equal(store.ErrSessionNotFound, err)
+	_, err = s.Load(ctx, "github", "1")
+	a.Equal(store.ErrTokenNotFound, err)
+	_, err = s.Load(ctx, "github", "2")
+	a.NoError(err)
+}
+
+func Test_EncryptedSessions(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	s, server := newStore(t)
+	sessions := s.Sessions([]byte("authentication-key"), []byte("0123456789abcdef0123456789abcdef"))
+	req := httptest.NewRequest("GET", "/", nil)
+	session, err := sessions.New(req, "_gothic_session")
+	a.NoError(err)
+	session.Values["github"] = "secret session"
+	a.NoError(session.Save(req, httptest.NewRecorder()))
+
+	data, err := server.Get("goth:session:" + session.ID)
+	a.NoError(err)
+	a.NotContains(data, "secret session")
+}
+
+func Test_ListSessions(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	ctx := context.Background()
+
+	s, server := newStore(t)
+	other := New(s.client).WithPrefix("app:")
+	s.WithPrefix("app[1]:")
+	a.NoError(s.SaveSession(ctx, "id", "data", time.Now().Add(time.Hour)))
+	a.NoError(s.SaveSession(ctx, "forever", "data", time.Time{}))
+	a.NoError(other.SaveSession(ctx, "other", "data", time.Now().Add(time.Hour)))
+	a.NoError(server.Set("app1:session:glob", "data"))
+
+	listed := map[string]time.Time{}
+	a.NoError(s.ListSessions(ctx, func(id, data string, expiresAt time.Time) error {
+		a.Equal("data", data)
+		listed[id] = expiresAt
+		return nil
+	}))
+	a.Len(listed, 2)
+	a.WithinDuration(time.Now().Add(time.Hour), listed["id"], time.Minute)
+	a.True(listed["forever"].IsZero())
+}
+
+func Test_TokenKeys(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	ctx := context.Background()
+
+	s, _ := newStore(t)
+	a.NoError(s.Save(ctx, &store.Token{Provider: "a:b", UserID: "c", AccessToken: "1"}))
+	a.NoError(s.Save(ctx, &store.Token{Provider: "a", UserID: "b:c", AccessToken: "2"}))
+	token, err := s.Load(ctx, "a:b", "c")
+	a.NoError(err)
+	a.Equal("1", token.AccessToken)
+	token, err = s.Load(ctx, "a", "b:c")
+	a.NoError(err)
+	a.Equal("2", token.AccessToken)
+}
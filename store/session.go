@@ -0,0 +1,164 @@
+package store
+
+import (
+	"context"
+	"encoding/base32"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/securecookie"
+	"github.com/gorilla/sessions"
+)
+
+// ErrSessionNotFound is returned by a SessionBackend when it has no session with the ID,
+// or it has expired.
+var ErrSessionNotFound error = notFoundError("store: session not found")
+
+// notFoundError is the type of ErrSessionNotFound.
+type notFoundError string
+
+func (e notFoundError) Error() string {
+	return string(e)
+}
+
+// NotFound tells the session is missing, to the packages using a SessionBackend
+// without importing this package, e.g. stdgothic.
+func (e notFoundError) NotFound() bool {
+	return true
+}
+
+// SessionBackend needs to be implemented to keep the web sessions of a SessionStore,
+// e.g. in a database.
+type SessionBackend interface {
+	// LoadSession returns the encoded values of the session, or ErrSessionNotFound.
+	LoadSession(ctx context.Context, id string) (string, error)
+	// SaveSession saves the encoded values of the session until it expires.
+	SaveSession(ctx context.Context, id, data string, expiresAt time.Time) error
+	// DeleteSession deletes the session, if any.
+	DeleteSession(ctx context.Context, id string) error
+}
+
+// SessionLister is implemented by the SessionBackends able to list their sessions, e.g.
+// to migrate them with gothmigrate.
+type SessionLister interface {
+	// ListSessions calls fn with the encoded values of every session which has not
+	// expired, until fn returns an error. fn must not modify the backend.
+	ListSessions(ctx context.Context, fn func(id, data string, expiresAt time.Time) error) error
+}
+
+// SessionStore is a sessions.Store keeping the sessions in a SessionBackend, so that
+// the cookies only hold their signed ID. It can be used as gothic.Store.
+type SessionStore struct {
+	Codecs  []securecookie.Codec
+	Options *sessions.Options // default configuration
+	backend SessionBackend
+}
+
+var _ sessions.Store = &SessionStore{}
+
+// NewSessionStore returns a new SessionStore keeping the sessions in the backend.
+//
+// See sessions.NewCookieStore() for a description of the key pairs, which sign the
+// cookies and the values of the sessions.
+func NewSessionStore(backend SessionBackend, keyPairs ...[]byte) *SessionStore {
+	s := &SessionStore{
+		Codecs: securecookie.CodecsFromPairs(keyPairs...),
+		Options: &sessions.Options{
+			Path:     "/",
+			MaxAge:   86400 * 30,
+			HttpOnly: true,
+		},
+		backend: backend,
+	}
+	for _, codec := range s.Codecs {
+		if sc, ok := codec.(*securecookie.SecureCookie); ok {
+			// the values are not kept in the cookie, their size is not limited
+			sc.MaxLength(0)
+		}
+	}
+	s.MaxAge(s.Options.MaxAge)
+	return s
+}
+
+// Get returns a session for the given name after adding it to the registry.
+func (s *SessionStore) Get(r *http.Request, name string) (*sessions.Session, error) {
+	return sessions.GetRegistry(r).Get(s, name)
+}
+
+// New returns a session for the given name without adding it to the registry. The
+// session is new when the cookie is missing or the session is not in the backend.
+func (s *SessionStore) New(r *http.Request, name string) (*sessions.Session, error) {
+	session := sessions.NewSession(s, name)
+	opts := *s.Options
+	session.Options = &opts
+	session.IsNew = true
+
+	c, err := r.Cookie(name)
+	if err != nil {
+		return session, nil
+	}
+	err = securecookie.DecodeMulti(name, c.Value, &session.ID, s.Codecs...)
+	if err != nil {
+		return session, err
+	}
+
+	data, err := s.backend.LoadSession(r.Context(), session.ID)
+	if err == ErrSessionNotFound {
+		return session, nil
+	}
+	if err != nil {
+		return session, err
+	}
+	err = securecookie.DecodeMulti(name, data, &session.Values, s.Codecs...)
+	if err != nil {
+		return session, err
+	}
+	session.IsNew = false
+	return session, nil
+}
+
+// Save saves the session into the backend and adds its cookie to the response. The
+// session is deleted when its Options.MaxAge is <= 0.
+func (s *SessionStore) Save(r *http.Request, w http.ResponseWriter, session *sessions.Session) error {
+	if session.Options.MaxAge <= 0 {
+		if session.ID != "" {
+			if err := s.backend.DeleteSession(r.Context(), session.ID); err != nil {
+				return err
+			}
+		}
+		http.SetCookie(w, sessions.NewCookie(session.Name(), "", session.Options))
+		return nil
+	}
+
+	if session.ID == "" {
+		session.ID = strings.TrimRight(base32.StdEncoding.EncodeToString(securecookie.GenerateRandomKey(32)), "=")
+	}
+	data, err := securecookie.EncodeMulti(session.Name(), session.Values, s.Codecs...)
+	if err != nil {
+		return err
+	}
+	expiresAt := time.Now().Add(time.Duration(session.Options.MaxAge) * time.Second)
+	err = s.backend.SaveSession(r.Context(), session.ID, data, expiresAt)
+	if err != nil {
+		return err
+	}
+
+	encoded, err := securecookie.EncodeMulti(session.Name(), session.ID, s.Codecs...)
+	if err != nil {
+		return err
+	}
+	http.SetCookie(w, sessions.NewCookie(session.Name(), encoded, session.Options))
+	return nil
+}
+
+// MaxAge sets the maximum age of the sessions and their cookies. Individual sessions
+// can be deleted by setting their Options.MaxAge to -1.
+func (s *SessionStore) MaxAge(age int) {
+	s.Options.MaxAge = age
+	for _, codec := range s.Codecs {
+		if sc, ok := codec.(*securecookie.SecureCookie); ok {
+			sc.MaxAge(age)
+		}
+	}
+}
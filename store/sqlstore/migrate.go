@@ -0,0 +1,88 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+)
+
+// migrations are the changes of the schema, by version starting at 1, for each dialect.
+var migrations = map[Dialect][][]string{
+	Postgres: {
+		{
+			"CREATE TABLE goth_sessions (id VARCHAR(64) PRIMARY KEY, data TEXT NOT NULL, expires_at BIGINT NOT NULL)",
+			"CREATE INDEX goth_sessions_expires_at ON goth_sessions (expires_at)",
+			"CREATE TABLE goth_tokens (provider VARCHAR(64) NOT NULL, user_id VARCHAR(255) NOT NULL, access_token TEXT NOT NULL, access_token_secret TEXT NOT NULL, refresh_token TEXT NOT NULL, id_token TEXT NOT NULL, expires_at BIGINT NULL, PRIMARY KEY (provider, user_id))",
+		},
+	},
+	MySQL: {
+		{
+			"CREATE TABLE goth_sessions (id VARCHAR(64) PRIMARY KEY, data MEDIUMTEXT NOT NULL, expires_at BIGINT NOT NULL, INDEX goth_sessions_expires_at (expires_at))",
+			"CREATE TABLE goth_tokens (provider VARCHAR(64) NOT NULL, user_id VARCHAR(255) NOT NULL, access_token TEXT NOT NULL, access_token_secret TEXT NOT NULL, refresh_token TEXT NOT NULL, id_token TEXT NOT NULL, expires_at BIGINT NULL, PRIMARY KEY (provider, user_id))",
+		},
+	},
+	SQLite: {
+		{
+			"CREATE TABLE goth_sessions (id TEXT PRIMARY KEY, data TEXT NOT NULL, expires_at INTEGER NOT NULL)",
+			"CREATE INDEX goth_sessions_expires_at ON goth_sessions (expires_at)",
+			"CREATE TABLE goth_tokens (provider TEXT NOT NULL, user_id TEXT NOT NULL, access_token TEXT NOT NULL, access_token_secret TEXT NOT NULL, refresh_token TEXT NOT NULL, id_token TEXT NOT NULL, expires_at INTEGER NULL, PRIMARY KEY (provider, user_id))",
+		},
+	},
+}
+
+// Schema returns the statements creating the tables of the dialect, for the
+// applications managing their schema with their own migration tool.
+func Schema(dialect Dialect) []string {
+	statements := []string{}
+	for _, migration := range migrations[dialect] {
+		statements = append(statements, migration...)
+	}
+	return statements
+}
+
+// Migrate creates or updates the tables of the store. The version of the schema is
+// kept in the goth_schema_migrations table, so Migrate can be called at every start.
+func (s *Store) Migrate(ctx context.Context) error {
+	_, err := s.exec(ctx, "CREATE TABLE IF NOT EXISTS goth_schema_migrations (version INTEGER PRIMARY KEY)")
+	if err != nil {
+		return err
+	}
+
+	var version sql.NullInt64
+	err = s.queryRow(ctx, "SELECT MAX(version) FROM goth_schema_migrations").Scan(&version)
+	if err != nil {
+		return err
+	}
+
+	for i, migration := range migrations[s.dialect] {
+		if int64(i) < version.Int64 {
+			continue
+		}
+		err = s.migrate(ctx, i+1, migration)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrate applies the migration to the version in a transaction, for the databases
+// supporting transactional changes of the schema.
+func (s *Store) migrate(ctx context.Context, version int, migration []string) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, statement := range migration {
+		_, err = tx.ExecContext(ctx, statement)
+		if err != nil {
+			return err
+		}
+	}
+	_, err = tx.ExecContext(ctx, s.dialect.rebind("INSERT INTO goth_schema_migrations (version) VALUES (?)"), version)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
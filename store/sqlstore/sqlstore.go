@@ -0,0 +1,226 @@
+/*
+Package sqlstore keeps the web sessions of gothic and the tokens of the users in a SQL
+database, through database/sql. Postgres, MySQL and SQLite are supported, the driver
+of the database must be imported by the application.
+
+	db, err := sql.Open("postgres", os.Getenv("DATABASE_URL"))
+	s := sqlstore.New(db, sqlstore.Postgres)
+	err = s.Migrate(ctx)
+	defer s.StartCleanup(time.Hour)()
+
+	gothic.Store = s.Sessions([]byte(os.Getenv("SESSION_SECRET")))
+	gothic.TokenStore = s
+
+Expiry times are stored as Unix times, in seconds.
+*/
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/markbates/goth/store"
+)
+
+// Dialect is the SQL dialect of a database.
+type Dialect int
+
+// Dialects
+const (
+	Postgres Dialect = iota
+	MySQL
+	SQLite
+)
+
+// rebind replaces the ? placeholders of the query with the ones of the dialect.
+func (d Dialect) rebind(query string) string {
+	if d != Postgres {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// upsert returns the end of an INSERT query updating the columns of the existing row
+// with the same key.
+func (d Dialect) upsert(key string, columns ...string) string {
+	sets := make([]string, len(columns))
+	if d == MySQL {
+		for i, column := range columns {
+			sets[i] = column + " = VALUES(" + column + ")"
+		}
+		return " ON DUPLICATE KEY UPDATE " + strings.Join(sets, ", ")
+	}
+	for i, column := range columns {
+		sets[i] = column + " = excluded." + column
+	}
+	return " ON CONFLICT (" + key + ") DO UPDATE SET " + strings.Join(sets, ", ")
+}
+
+// Store is a store.TokenStore and a store.SessionBackend keeping the tokens and the
+// sessions in a SQL database.
+type Store struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+var _ store.TokenStore = &Store{}
+var _ store.SessionBackend = &Store{}
+var _ store.SessionLister = &Store{}
+
+// New creates a Store using the database, whose tables are created by Migrate.
+func New(db *sql.DB, dialect Dialect) *Store {
+	return &Store{
+		db:      db,
+		dialect: dialect,
+	}
+}
+
+// Sessions returns a store.SessionStore keeping the sessions in the database, to be
+// used as gothic.Store.
+func (s *Store) Sessions(keyPairs ...[]byte) *store.SessionStore {
+	return store.NewSessionStore(s, keyPairs...)
+}
+
+func (s *Store) exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return s.db.ExecContext(ctx, s.dialect.rebind(query), args...)
+}
+
+func (s *Store) queryRow(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return s.db.QueryRowContext(ctx, s.dialect.rebind(query), args...)
+}
+
+// Save saves the token, replacing the one of the same provider and user if any.
+func (s *Store) Save(ctx context.Context, token *store.Token) error {
+	var expiresAt sql.NullInt64
+	if !token.ExpiresAt.IsZero() {
+		expiresAt = sql.NullInt64{Int64: token.ExpiresAt.Unix(), Valid: true}
+	}
+	_, err := s.exec(ctx, "INSERT INTO goth_tokens (provider, user_id, access_token, access_token_secret, refresh_token, id_token, expires_at) VALUES (?, ?, ?, ?, ?, ?, ?)"+
+		s.dialect.upsert("provider, user_id", "access_token", "access_token_secret", "refresh_token", "id_token", "expires_at"),
+		token.Provider, token.UserID, token.AccessToken, token.AccessTokenSecret, token.RefreshToken, token.IDToken, expiresAt)
+	return err
+}
+
+// Load returns the token of the user at the provider, or store.ErrTokenNotFound.
+func (s *Store) Load(ctx context.Context, provider, userID string) (*store.Token, error) {
+	token := &store.Token{
+		Provider: provider,
+		UserID:   userID,
+	}
+	var expiresAt sql.NullInt64
+	err := s.queryRow(ctx, "SELECT access_token, access_token_secret, refresh_token, id_token, expires_at FROM goth_tokens WHERE provider = ? AND user_id = ?", provider, userID).
+		Scan(&token.AccessToken, &token.AccessTokenSecret, &token.RefreshToken, &token.IDToken, &expiresAt)
+	if err == sql.ErrNoRows {
+		return nil, store.ErrTokenNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if expiresAt.Valid {
+		token.ExpiresAt = time.Unix(expiresAt.Int64, 0)
+	}
+	return token, nil
+}
+
+// Delete deletes the token of the user at the provider, if any.
+func (s *Store) Delete(ctx context.Context, provider, userID string) error {
+	_, err := s.exec(ctx, "DELETE FROM goth_tokens WHERE provider = ? AND user_id = ?", provider, userID)
+	return err
+}
+
+// LoadSession returns the encoded values of the session, or store.ErrSessionNotFound.
+func (s *Store) LoadSession(ctx context.Context, id string) (string, error) {
+	var data string
+	err := s.queryRow(ctx, "SELECT data FROM goth_sessions WHERE id = ? AND expires_at > ?", id, time.Now().Unix()).Scan(&data)
+	if err == sql.ErrNoRows {
+		return "", store.ErrSessionNotFound
+	}
+	return data, err
+}
+
+// SaveSession saves the encoded values of the session until it expires.
+func (s *Store) SaveSession(ctx context.Context, id, data string, expiresAt time.Time) error {
+	_, err := s.exec(ctx, "INSERT INTO goth_sessions (id, data, expires_at) VALUES (?, ?, ?)"+s.dialect.upsert("id", "data", "expires_at"),
+		id, data, expiresAt.Unix())
+	return err
+}
+
+// DeleteSession deletes the session, if any.
+func (s *Store) DeleteSession(ctx context.Context, id string) error {
+	_, err := s.exec(ctx, "DELETE FROM goth_sessions WHERE id = ?", id)
+	return err
+}
+
+// ListSessions calls fn with the encoded values of every session which has not expired.
+func (s *Store) ListSessions(ctx context.Context, fn func(id, data string, expiresAt time.Time) error) error {
+	rows, err := s.db.QueryContext(ctx, s.dialect.rebind("SELECT id, data, expires_at FROM goth_sessions WHERE expires_at > ?"), time.Now().Unix())
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id, data string
+		var expiresAt int64
+		if err = rows.Scan(&id, &data, &expiresAt); err != nil {
+			return err
+		}
+		if err = fn(id, data, time.Unix(expiresAt, 0)); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// Cleanup deletes the expired sessions, and the expired tokens which cannot be
+// refreshed. It returns the number of deleted rows.
+func (s *Store) Cleanup(ctx context.Context) (int64, error) {
+	now := time.Now().Unix()
+	deleted := int64(0)
+	for _, query := range []string{
+		"DELETE FROM goth_sessions WHERE expires_at <= ?",
+		"DELETE FROM goth_tokens WHERE expires_at <= ? AND refresh_token = ''",
+	} {
+		result, err := s.exec(ctx, query, now)
+		if err != nil {
+			return deleted, err
+		}
+		n, err := result.RowsAffected()
+		if err != nil {
+			return deleted, err
+		}
+		deleted += n
+	}
+	return deleted, nil
+}
+
+// StartCleanup calls Cleanup every interval in the background, until the returned
+// function is called. Failed cleanups are retried at the next interval.
+func (s *Store) StartCleanup(interval time.Duration) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.Cleanup(ctx)
+			}
+		}
+	}()
+	return cancel
+}
@@ -0,0 +1,90 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/markbates/goth/store"
+	"github.com/markbates/goth/store/storetest"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/assert"
+)
+
+func newStore(t *testing.T) *Store {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// every connection to :memory: is a different database
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	s := New(db, SQLite)
+	if err = s.Migrate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func Test_TokenStore(t *testing.T) {
+	t.Parallel()
+	storetest.TestTokenStore(t, newStore(t))
+}
+
+func Test_SessionBackend(t *testing.T) {
+	t.Parallel()
+	storetest.TestSessionBackend(t, newStore(t))
+}
+
+func Test_Migrate(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	s := newStore(t)
+	a.NoError(s.Migrate(context.Background()))
+
+	var version int
+	a.NoError(s.db.QueryRow("SELECT MAX(version) FROM goth_schema_migrations").Scan(&version))
+	a.Equal(len(migrations[SQLite]), version)
+	a.Len(Schema(Postgres), 3)
+}
+
+func Test_Cleanup(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	ctx := context.Background()
+
+	s := newStore(t)
+	past, future := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	a.NoError(s.SaveSession(ctx, "expired", "data", past))
+	a.NoError(s.SaveSession(ctx, "valid", "data", future))
+	a.NoError(s.Save(ctx, &store.Token{Provider: "github", UserID: "expired", ExpiresAt: past}))
+	a.NoError(s.Save(ctx, &store.Token{Provider: "github", UserID: "refreshable", RefreshToken: "refresh", ExpiresAt: past}))
+	a.NoError(s.Save(ctx, &store.Token{Provider: "github", UserID: "forever"}))
+
+	deleted, err := s.Cleanup(ctx)
+	a.NoError(err)
+	a.Equal(int64(2), deleted)
+
+	_, err = s.LoadSession(ctx, "valid")
+	a.NoError(err)
+	_, err = s.Load(ctx, "github", "expired")
+	a.Equal(store.ErrTokenNotFound, err)
+	_, err = s.Load(ctx, "github", "refreshable")
+	a.NoError(err)
+	token, err := s.Load(ctx, "github", "forever")
+	a.NoError(err)
+	a.True(token.ExpiresAt.IsZero())
+}
+
+func Test_Dialect(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Equal("SELECT ? FROM t WHERE a = ?", MySQL.rebind("SELECT ? FROM t WHERE a = ?"))
+	a.Equal("SELECT $1 FROM t WHERE a = $2", Postgres.rebind("SELECT ? FROM t WHERE a = ?"))
+	a.Equal(" ON DUPLICATE KEY UPDATE data = VALUES(data)", MySQL.upsert("id", "data"))
+	a.Equal(" ON CONFLICT (id) DO UPDATE SET data = excluded.data", SQLite.upsert("id", "data"))
+}
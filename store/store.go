@@ -0,0 +1,99 @@
+/*
+Package store defines how the tokens of the users, obtained with Goth, are kept outside
+of the web sessions, so that background jobs and APIs can use them on behalf of the
+users without inventing their own persistence.
+
+Tokens are keyed by the name of the provider and the ID of the user at the provider.
+gothic saves them when it is given a TokenStore, see gothic.TokenStore.
+*/
+package store
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/markbates/goth"
+	"golang.org/x/oauth2"
+)
+
+// ErrTokenNotFound is returned by a TokenStore when it has no token for the provider
+// and the user.
+var ErrTokenNotFound = errors.New("store: token not found")
+
+// TokenStore needs to be implemented to keep the tokens of the users,
+// e.g. in memory or in a database.
+type TokenStore interface {
+	// Save saves the token, replacing the one of the same provider and user if any.
+	Save(ctx context.Context, token *Token) error
+	// Load returns the token of the user at the provider, or ErrTokenNotFound.
+	Load(ctx context.Context, provider, userID string) (*Token, error)
+	// Delete deletes the token of the user at the provider, if any.
+	Delete(ctx context.Context, provider, userID string) error
+}
+
+// Token is the token of a user at a provider.
+type Token struct {
+	Provider          string
+	UserID            string
+	AccessToken       string
+	AccessTokenSecret string
+	RefreshToken      string
+	IDToken           string
+	ExpiresAt         time.Time
+}
+
+// TokenFromUser returns the token of the user fetched by a provider.
+func TokenFromUser(user goth.User) *Token {
+	return &Token{
+		Provider:          user.Provider,
+		UserID:            user.UserID,
+		AccessToken:       user.AccessToken,
+		AccessTokenSecret: user.AccessTokenSecret,
+		RefreshToken:      user.RefreshToken,
+		IDToken:           user.IDToken,
+		ExpiresAt:         user.ExpiresAt,
+	}
+}
+
+// Expired tells if the access token has expired. Tokens without an expiry never expire.
+func (t *Token) Expired() bool {
+	return !t.ExpiresAt.IsZero() && !t.ExpiresAt.After(time.Now())
+}
+
+// OAuth2 returns the token as an OAuth2 token, e.g. to call the API of the provider
+// with an oauth2.StaticTokenSource.
+func (t *Token) OAuth2() *oauth2.Token {
+	return &oauth2.Token{
+		AccessToken:  t.AccessToken,
+		TokenType:    "Bearer",
+		RefreshToken: t.RefreshToken,
+		Expiry:       t.ExpiresAt,
+	}
+}
+
+// LoadFresh loads the token of the user at the provider and, when it has expired and
+// the provider can refresh it, refreshes it and saves the new token.
+func LoadFresh(ctx context.Context, s TokenStore, provider goth.Provider, userID string) (*Token, error) {
+	token, err := s.Load(ctx, provider.Name(), userID)
+	if err != nil {
+		return nil, err
+	}
+	if !token.Expired() || token.RefreshToken == "" || !provider.RefreshTokenAvailable() {
+		return token, nil
+	}
+
+	newToken, err := provider.RefreshToken(token.RefreshToken)
+	if err != nil {
+		return nil, err
+	}
+	token.AccessToken = newToken.AccessToken
+	token.ExpiresAt = newToken.Expiry
+	if newToken.RefreshToken != "" {
+		token.RefreshToken = newToken.RefreshToken
+	}
+	if idToken, ok := newToken.Extra("id_token").(string); ok {
+		token.IDToken = idToken
+	}
+	return token, s.Save(ctx, token)
+}
@@ -0,0 +1,83 @@
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/markbates/goth"
+	"github.com/markbates/goth/providers/faux"
+	"github.com/markbates/goth/store"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+)
+
+// refreshingProvider is a faux provider which can refresh tokens.
+type refreshingProvider struct {
+	*faux.Provider
+	refreshes int
+}
+
+func (p *refreshingProvider) RefreshTokenAvailable() bool {
+	return true
+}
+
+func (p *refreshingProvider) RefreshToken(refreshToken string) (*oauth2.Token, error) {
+	p.refreshes++
+	return &oauth2.Token{
+		AccessToken:  "new-access",
+		RefreshToken: "new-refresh",
+		Expiry:       time.Now().Add(time.Hour),
+	}, nil
+}
+
+func Test_TokenFromUser(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	expiresAt := time.Now().Add(time.Hour)
+	token := store.TokenFromUser(goth.User{
+		Provider:     "github",
+		UserID:       "1",
+		AccessToken:  "access",
+		RefreshToken: "refresh",
+		ExpiresAt:    expiresAt,
+	})
+	a.Equal(&store.Token{Provider: "github", UserID: "1", AccessToken: "access", RefreshToken: "refresh", ExpiresAt: expiresAt}, token)
+	a.False(token.Expired())
+	a.Equal("access", token.OAuth2().AccessToken)
+	a.True(token.OAuth2().Valid())
+
+	a.False((&store.Token{}).Expired())
+	a.True((&store.Token{ExpiresAt: time.Now().Add(-time.Minute)}).Expired())
+}
+
+func Test_LoadFresh(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	ctx := context.Background()
+
+	s := store.NewMemoryStore()
+	p := &refreshingProvider{Provider: &faux.Provider{}}
+
+	_, err := store.LoadFresh(ctx, s, p, "1")
+	a.Equal(store.ErrTokenNotFound, err)
+
+	a.NoError(s.Save(ctx, &store.Token{Provider: "faux", UserID: "1", AccessToken: "access", RefreshToken: "refresh", ExpiresAt: time.Now().Add(time.Hour)}))
+	token, err := store.LoadFresh(ctx, s, p, "1")
+	a.NoError(err)
+	a.Equal("access", token.AccessToken)
+	a.Equal(0, p.refreshes)
+
+	a.NoError(s.Save(ctx, &store.Token{Provider: "faux", UserID: "1", AccessToken: "access", RefreshToken: "refresh", ExpiresAt: time.Now().Add(-time.Hour)}))
+	token, err = store.LoadFresh(ctx, s, p, "1")
+	a.NoError(err)
+	a.Equal("new-access", token.AccessToken)
+	a.Equal(1, p.refreshes)
+
+	token, err = s.Load(ctx, "faux", "1")
+	a.NoError(err)
+	a.Equal("new-access", token.AccessToken)
+	a.Equal("new-refresh", token.RefreshToken)
+	a.False(token.Expired())
+}
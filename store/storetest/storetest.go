@@ -0,0 +1,129 @@
+// Package storetest checks implementations of store.TokenStore and store.SessionBackend
+// behave the same.
+package storetest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/markbates/goth/store"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestTokenStore runs the tests every store.TokenStore must pass against s, which
+// must be empty.
+func TestTokenStore(t *testing.T, s store.TokenStore) {
+	a := assert.New(t)
+	ctx := context.Background()
+
+	_, err := s.Load(ctx, "github", "1")
+	a.Equal(store.ErrTokenNotFound, err)
+	a.NoError(s.Delete(ctx, "github", "1"))
+
+	token := &store.Token{
+		Provider:          "github",
+		UserID:            "1",
+		AccessToken:       "access",
+		AccessTokenSecret: "secret",
+		RefreshToken:      "refresh",
+		IDToken:           "id",
+		ExpiresAt:         time.Date(2030, time.January, 2, 3, 4, 5, 0, time.UTC),
+	}
+	a.NoError(s.Save(ctx, token))
+	a.NoError(s.Save(ctx, &store.Token{Provider: "gitlab", UserID: "1", AccessToken: "other"}))
+
+	loaded, err := s.Load(ctx, "github", "1")
+	a.NoError(err)
+	a.Equal(token.AccessToken, loaded.AccessToken)
+	a.Equal(token.AccessTokenSecret, loaded.AccessTokenSecret)
+	a.Equal(token.RefreshToken, loaded.RefreshToken)
+	a.Equal(token.IDToken, loaded.IDToken)
+	a.True(token.ExpiresAt.Equal(loaded.ExpiresAt), "expires at %s instead of %s", loaded.ExpiresAt, token.ExpiresAt)
+
+	// saving again replaces the token
+	token.AccessToken = "rotated"
+	a.NoError(s.Save(ctx, token))
+	loaded, err = s.Load(ctx, "github", "1")
+	a.NoError(err)
+	a.Equal("rotated", loaded.AccessToken)
+
+	a.NoError(s.Delete(ctx, "github", "1"))
+	_, err = s.Load(ctx, "github", "1")
+	a.Equal(store.ErrTokenNotFound, err)
+
+	loaded, err = s.Load(ctx, "gitlab", "1")
+	a.NoError(err)
+	a.Equal("other", loaded.AccessToken)
+}
+
+// TestSessionBackend runs the tests every store.SessionBackend must pass against b, which
+// must be empty, both directly and through a store.SessionStore. The sessions are also
+// listed when b is a store.SessionLister.
+func TestSessionBackend(t *testing.T, b store.SessionBackend) {
+	a := assert.New(t)
+	ctx := context.Background()
+
+	_, err := b.LoadSession(ctx, "missing")
+	a.Equal(store.ErrSessionNotFound, err)
+	a.NoError(b.DeleteSession(ctx, "missing"))
+
+	a.NoError(b.SaveSession(ctx, "expired", "data", time.Now().Add(-time.Minute)))
+	_, err = b.LoadSession(ctx, "expired")
+	a.Equal(store.ErrSessionNotFound, err)
+
+	a.NoError(b.SaveSession(ctx, "id", "data", time.Now().Add(time.Hour)))
+	a.NoError(b.SaveSession(ctx, "id", "updated", time.Now().Add(time.Hour)))
+	data, err := b.LoadSession(ctx, "id")
+	a.NoError(err)
+	a.Equal("updated", data)
+	a.NoError(b.DeleteSession(ctx, "id"))
+	_, err = b.LoadSession(ctx, "id")
+	a.Equal(store.ErrSessionNotFound, err)
+
+	if lister, ok := b.(store.SessionLister); ok {
+		a.NoError(b.SaveSession(ctx, "listed", "data", time.Now().Add(time.Hour)))
+		listed := map[string]string{}
+		a.NoError(lister.ListSessions(ctx, func(id, data string, expiresAt time.Time) error {
+			listed[id] = data
+			a.True(expiresAt.After(time.Now()))
+			return nil
+		}))
+		a.Equal(map[string]string{"listed": "data"}, listed)
+		a.NoError(b.DeleteSession(ctx, "listed"))
+	}
+
+	s := store.NewSessionStore(b, []byte("secret"))
+	req := httptest.NewRequest("GET", "/", nil)
+	session, err := s.Get(req, "_gothic_session")
+	a.NoError(err)
+	a.True(session.IsNew)
+	session.Values["github"] = "marshaled session"
+	res := httptest.NewRecorder()
+	a.NoError(session.Save(req, res))
+	cookie := res.Result().Cookies()[0]
+	a.NotContains(cookie.Value, "marshaled session")
+
+	req = httptest.NewRequest("GET", "/", nil)
+	req.AddCookie(cookie)
+	session, err = s.Get(req, "_gothic_session")
+	a.NoError(err)
+	a.False(session.IsNew)
+	a.Equal("marshaled session", session.Values["github"])
+
+	session.Options.MaxAge = -1
+	a.NoError(session.Save(req, httptest.NewRecorder()))
+	req = httptest.NewRequest("GET", "/", nil)
+	req.AddCookie(cookie)
+	session, err = s.New(req, "_gothic_session")
+	a.NoError(err)
+	a.True(session.IsNew)
+	a.Empty(session.Values)
+
+	req = httptest.NewRequest("GET", "/", nil)
+	req.AddCookie(&http.Cookie{Name: "_gothic_session", Value: "forged"})
+	_, err = s.New(req, "_gothic_session")
+	a.Error(err)
+}
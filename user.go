@@ -28,4 +28,10 @@ type User struct {
 	RefreshToken      string
 	ExpiresAt         time.Time
 	IDToken           string
+	// Groups are the groups the user belongs to, for the providers that can look
+	// them up, e.g. to map them to roles.
+	Groups []string
+	// Roles are the roles the user was given in the application, for the providers
+	// that support them.
+	Roles []string
 }